@@ -0,0 +1,233 @@
+// Package auth authenticates the callers of this service, either by a service
+// token (JWT) signed by one of the trusted keys, or by a verified mTLS client certificate
+package auth
+
+import (
+	"context"
+	"crypto"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	intModels "github.com/ahmad-khatib0-org/megacommerce-inventory/pkg/models"
+	"github.com/ahmad-khatib0-org/megacommerce-shared-go/pkg/models"
+	"github.com/golang-jwt/jwt/v5"
+	grpcAuth "github.com/grpc-ecosystem/go-grpc-middleware/v2/interceptors/auth"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	RoleOrderService = "order_service"
+	RoleAdmin        = "admin"
+	RoleWarehouse    = "warehouse"
+	RoleSeller       = "seller"
+)
+
+const (
+	MethodJWT  = "jwt"
+	MethodMTLS = "mtls"
+)
+
+var validSigningMethods = []string{"RS256", "RS384", "RS512", "PS256", "PS384", "PS512", "ES256", "ES384", "ES512", "EdDSA"}
+
+// Caller is the authenticated identity behind a request
+type Caller struct {
+	Subject string
+	Roles   []string
+	Method  string
+	// SellerID is set for seller callers, their access is limited to this seller's stock
+	SellerID string
+}
+
+// HasAnyRole reports whether the caller holds at least one of the given roles
+func (c *Caller) HasAnyRole(roles ...string) bool {
+	if c == nil {
+		return false
+	}
+	for _, r := range roles {
+		if slices.Contains(c.Roles, r) {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *Caller) Auditable() map[string]any {
+	if c == nil {
+		return map[string]any{}
+	}
+
+	return map[string]any{
+		"subject":   c.Subject,
+		"roles":     c.Roles,
+		"method":    c.Method,
+		"seller_id": c.SellerID,
+	}
+}
+
+// SellerScope returns the seller that the caller is limited to, scoped is false for platform
+// callers (services, admins, warehouse staff) that can act on any seller. A seller caller without
+// a seller id is scoped to an empty seller, which must be denied
+func (c *Caller) SellerScope() (sellerID string, scoped bool) {
+	if c == nil || !c.HasAnyRole(RoleSeller) {
+		return "", false
+	}
+	return c.SellerID, true
+}
+
+type callerKey struct{}
+
+func ContextWithCaller(ctx context.Context, c *Caller) context.Context {
+	return context.WithValue(ctx, callerKey{}, c)
+}
+
+// CallerFromContext returns the caller that was set by the auth interceptor
+func CallerFromContext(ctx context.Context) (*Caller, bool) {
+	c, ok := ctx.Value(callerKey{}).(*Caller)
+	return c, ok && c != nil
+}
+
+type serviceClaims struct {
+	Roles    []string `json:"roles"`
+	SellerID string   `json:"seller_id"`
+	jwt.RegisteredClaims
+}
+
+type Authenticator struct {
+	keys           jwt.VerificationKeySet
+	parser         *jwt.Parser
+	mtlsIdentities map[string][]string
+}
+
+// NewAuthenticator loads the trusted jwt public keys from disk
+func NewAuthenticator(cfg *intModels.Auth) (*Authenticator, *models.InternalError) {
+	ie := func(err error, msg string) *models.InternalError {
+		return &models.InternalError{Path: "inventory.auth.NewAuthenticator", Err: err, Msg: msg}
+	}
+
+	a := &Authenticator{mtlsIdentities: map[string][]string{}}
+	for cn, roles := range cfg.MTLSIdentities {
+		// a certificate carries no seller id, a seller identity would act on every seller
+		if slices.Contains(roles, RoleSeller) {
+			return nil, ie(fmt.Errorf("the mtls identity %s has the %s role", cn, RoleSeller), "sellers must authenticate with a service token")
+		}
+		a.mtlsIdentities[strings.ToLower(cn)] = roles
+	}
+
+	for _, file := range cfg.JWTPublicKeyFiles {
+		key, err := loadPublicKey(file)
+		if err != nil {
+			return nil, ie(err, fmt.Sprintf("failed to load the jwt public key %s", file))
+		}
+		a.keys.Keys = append(a.keys.Keys, key)
+	}
+
+	if len(a.keys.Keys) == 0 && len(a.mtlsIdentities) == 0 {
+		return nil, ie(errors.New("no jwt keys or mtls identities are configured"), "auth is not configured")
+	}
+
+	opts := []jwt.ParserOption{
+		jwt.WithValidMethods(validSigningMethods),
+		jwt.WithExpirationRequired(),
+		jwt.WithLeeway(30 * time.Second),
+	}
+	if cfg.JWTIssuer != "" {
+		opts = append(opts, jwt.WithIssuer(cfg.JWTIssuer))
+	}
+	if cfg.JWTAudience != "" {
+		opts = append(opts, jwt.WithAudience(cfg.JWTAudience))
+	}
+	a.parser = jwt.NewParser(opts...)
+
+	return a, nil
+}
+
+// Authenticate resolves the caller of the request, a bearer token takes precedence
+// over the mtls client certificate if both are present
+func (a *Authenticator) Authenticate(ctx context.Context) (*Caller, error) {
+	token, err := grpcAuth.AuthFromMD(ctx, "bearer")
+	if err == nil {
+		return a.fromToken(token)
+	}
+
+	if caller, ok := a.fromPeer(ctx); ok {
+		return caller, nil
+	}
+
+	return nil, status.Error(codes.Unauthenticated, "missing service credentials")
+}
+
+func (a *Authenticator) fromToken(token string) (*Caller, error) {
+	if len(a.keys.Keys) == 0 {
+		return nil, status.Error(codes.Unauthenticated, "token authentication is disabled")
+	}
+
+	var claims serviceClaims
+	_, err := a.parser.ParseWithClaims(token, &claims, func(*jwt.Token) (any, error) { return a.keys, nil })
+	if err != nil {
+		return nil, status.Error(codes.Unauthenticated, "invalid service token")
+	}
+
+	if claims.Subject == "" {
+		return nil, status.Error(codes.Unauthenticated, "the service token has no subject")
+	}
+
+	if slices.Contains(claims.Roles, RoleSeller) && claims.SellerID == "" {
+		return nil, status.Error(codes.Unauthenticated, "the seller token has no seller_id")
+	}
+
+	return &Caller{Subject: claims.Subject, Roles: claims.Roles, Method: MethodJWT, SellerID: claims.SellerID}, nil
+}
+
+func (a *Authenticator) fromPeer(ctx context.Context) (*Caller, bool) {
+	p, ok := peer.FromContext(ctx)
+	if !ok || p.AuthInfo == nil {
+		return nil, false
+	}
+
+	info, ok := p.AuthInfo.(credentials.TLSInfo)
+	if !ok || len(info.State.VerifiedChains) == 0 || len(info.State.VerifiedChains[0]) == 0 {
+		return nil, false
+	}
+
+	cn := info.State.VerifiedChains[0][0].Subject.CommonName
+	roles, ok := a.mtlsIdentities[strings.ToLower(cn)]
+	if !ok {
+		return nil, false
+	}
+
+	return &Caller{Subject: cn, Roles: roles, Method: MethodMTLS}, true
+}
+
+func loadPublicKey(file string) (crypto.PublicKey, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("no pem block found")
+	}
+
+	switch block.Type {
+	case "PUBLIC KEY":
+		return x509.ParsePKIXPublicKey(block.Bytes)
+	case "CERTIFICATE":
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		return cert.PublicKey, nil
+	default:
+		return nil, fmt.Errorf("unsupported pem block type %s", block.Type)
+	}
+}
@@ -0,0 +1,248 @@
+// Package bulk reads stock import files and writes stock export files and forecast reports, in CSV or JSONL
+package bulk
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	intModels "github.com/ahmad-khatib0-org/megacommerce-inventory/pkg/models"
+	pb "github.com/ahmad-khatib0-org/megacommerce-proto/gen/go/inventory/v1"
+)
+
+type Format string
+
+const (
+	FormatCSV   Format = "csv"
+	FormatJSONL Format = "jsonl"
+)
+
+// FormatFromPath picks the format from the file extension
+func FormatFromPath(path string) (Format, error) {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".csv":
+		return FormatCSV, nil
+	case ".jsonl", ".ndjson":
+		return FormatJSONL, nil
+	default:
+		return "", fmt.Errorf("unsupported file extension %q, expected .csv or .jsonl", filepath.Ext(path))
+	}
+}
+
+// importRecord is a single line of a JSONL import file
+type importRecord struct {
+	Sku        string `json:"sku"`
+	LocationID string `json:"location_id"`
+	Operation  string `json:"operation"`
+	Quantity   uint32 `json:"quantity"`
+}
+
+// ReadRows parses an import file, the CSV header must contain the sku, operation and
+// quantity columns, and may contain location_id, in any order. Only malformed input
+// fails here, the rows content is validated by the server
+func ReadRows(r io.Reader, format Format) ([]*pb.InventoryImportRow, error) {
+	switch format {
+	case FormatCSV:
+		return readCSV(r)
+	case FormatJSONL:
+		return readJSONL(r)
+	default:
+		return nil, fmt.Errorf("unsupported format %q", format)
+	}
+}
+
+func readCSV(r io.Reader) ([]*pb.InventoryImportRow, error) {
+	cr := csv.NewReader(r)
+	cr.TrimLeadingSpace = true
+
+	header, err := cr.Read()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the csv header: %w", err)
+	}
+
+	cols := map[string]int{}
+	for i, name := range header {
+		cols[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	for _, required := range []string{"sku", "operation", "quantity"} {
+		if _, ok := cols[required]; !ok {
+			return nil, fmt.Errorf("the csv header is missing the %s column", required)
+		}
+	}
+
+	get := func(rec []string, col string) string {
+		i, ok := cols[col]
+		if !ok || i >= len(rec) {
+			return ""
+		}
+		return strings.TrimSpace(rec[i])
+	}
+
+	rows := []*pb.InventoryImportRow{}
+	line := 1
+	for {
+		rec, err := cr.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		line++
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+
+		quantity, err := strconv.ParseUint(get(rec, "quantity"), 10, 32)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: invalid quantity %q", line, get(rec, "quantity"))
+		}
+
+		rows = append(rows, &pb.InventoryImportRow{
+			Line:       uint32(line),
+			Sku:        get(rec, "sku"),
+			LocationId: get(rec, "location_id"),
+			Operation:  intModels.GetInventoryUpdateOperationFromString(get(rec, "operation")),
+			Quantity:   uint32(quantity),
+		})
+		if len(rows) > intModels.InventoryImportMaxRows {
+			return nil, fmt.Errorf("the file has more than %d rows", intModels.InventoryImportMaxRows)
+		}
+	}
+
+	return rows, nil
+}
+
+func readJSONL(r io.Reader) ([]*pb.InventoryImportRow, error) {
+	sc := bufio.NewScanner(r)
+	sc.Buffer(make([]byte, 64*1024), 1024*1024)
+
+	rows := []*pb.InventoryImportRow{}
+	line := 0
+	for sc.Scan() {
+		line++
+		text := strings.TrimSpace(sc.Text())
+		if text == "" {
+			continue
+		}
+
+		var rec importRecord
+		if err := json.Unmarshal([]byte(text), &rec); err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+
+		rows = append(rows, &pb.InventoryImportRow{
+			Line:       uint32(line),
+			Sku:        strings.TrimSpace(rec.Sku),
+			LocationId: strings.TrimSpace(rec.LocationID),
+			Operation:  intModels.GetInventoryUpdateOperationFromString(rec.Operation),
+			Quantity:   rec.Quantity,
+		})
+		if len(rows) > intModels.InventoryImportMaxRows {
+			return nil, fmt.Errorf("the file has more than %d rows", intModels.InventoryImportMaxRows)
+		}
+	}
+
+	if err := sc.Err(); err != nil {
+		return nil, fmt.Errorf("line %d: %w", line+1, err)
+	}
+
+	return rows, nil
+}
+
+var exportColumns = []string{"sku", "product_id", "variant_id", "location_id", "quantity_total", "quantity_reserved", "quantity_available", "quantity_in_transit", "quantity_quarantined", "quantity_damaged", "quantity_on_hold"}
+
+// exportRecord is a single line of a JSONL export file
+type exportRecord struct {
+	Sku                 string `json:"sku"`
+	ProductID           string `json:"product_id"`
+	VariantID           string `json:"variant_id"`
+	LocationID          string `json:"location_id"`
+	QuantityTotal       int32  `json:"quantity_total"`
+	QuantityReserved    int32  `json:"quantity_reserved"`
+	QuantityAvailable   int32  `json:"quantity_available"`
+	QuantityInTransit   int32  `json:"quantity_in_transit"`
+	QuantityQuarantined int32  `json:"quantity_quarantined"`
+	QuantityDamaged     int32  `json:"quantity_damaged"`
+	QuantityOnHold      int32  `json:"quantity_on_hold"`
+}
+
+// Writer writes the current stock levels of inventory items, the
+// output of a csv Writer can be fed back to an import after adding an operation column
+type Writer struct {
+	format Format
+	csv    *csv.Writer
+	json   *json.Encoder
+	buf    *bufio.Writer
+	header bool
+}
+
+func NewWriter(w io.Writer, format Format) *Writer {
+	bw := bufio.NewWriter(w)
+	ew := &Writer{format: format, buf: bw}
+	if format == FormatCSV {
+		ew.csv = csv.NewWriter(bw)
+	} else {
+		ew.json = json.NewEncoder(bw)
+	}
+	return ew
+}
+
+func (w *Writer) Write(item *pb.InventoryItem) error {
+	if w.format != FormatCSV {
+		return w.json.Encode(&exportRecord{
+			Sku:                 item.GetSku(),
+			ProductID:           item.GetProductId(),
+			VariantID:           item.GetVariantId(),
+			LocationID:          item.GetLocationId(),
+			QuantityTotal:       item.GetQuantityTotal(),
+			QuantityReserved:    item.GetQuantityReserved(),
+			QuantityAvailable:   item.GetQuantityAvailable(),
+			QuantityInTransit:   item.GetQuantityInTransit(),
+			QuantityQuarantined: item.GetQuantityQuarantined(),
+			QuantityDamaged:     item.GetQuantityDamaged(),
+			QuantityOnHold:      item.GetQuantityOnHold(),
+		})
+	}
+
+	if !w.header {
+		if err := w.csv.Write(exportColumns); err != nil {
+			return err
+		}
+		w.header = true
+	}
+
+	return w.csv.Write([]string{
+		item.GetSku(),
+		item.GetProductId(),
+		item.GetVariantId(),
+		item.GetLocationId(),
+		strconv.Itoa(int(item.GetQuantityTotal())),
+		strconv.Itoa(int(item.GetQuantityReserved())),
+		strconv.Itoa(int(item.GetQuantityAvailable())),
+		strconv.Itoa(int(item.GetQuantityInTransit())),
+		strconv.Itoa(int(item.GetQuantityQuarantined())),
+		strconv.Itoa(int(item.GetQuantityDamaged())),
+		strconv.Itoa(int(item.GetQuantityOnHold())),
+	})
+}
+
+func (w *Writer) Flush() error {
+	if w.csv != nil {
+		// write the header even if there were no items
+		if !w.header {
+			if err := w.csv.Write(exportColumns); err != nil {
+				return err
+			}
+			w.header = true
+		}
+		w.csv.Flush()
+		if err := w.csv.Error(); err != nil {
+			return err
+		}
+	}
+	return w.buf.Flush()
+}
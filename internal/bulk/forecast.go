@@ -0,0 +1,149 @@
+package bulk
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"strconv"
+
+	intModels "github.com/ahmad-khatib0-org/megacommerce-inventory/pkg/models"
+	pb "github.com/ahmad-khatib0-org/megacommerce-proto/gen/go/inventory/v1"
+)
+
+var forecastColumns = []string{
+	"sku", "product_id", "variant_id", "location_id", "method", "history_days", "lead_time_days", "review_period_days",
+	"velocity_moving_average", "velocity_seasonal", "velocity", "demand_std_dev", "quantity_available", "quantity_in_transit",
+	"quantity_incoming", "days_of_cover", "safety_stock", "reorder_point", "order_up_to", "suggested_order_quantity", "reorder",
+}
+
+// forecastRecord is a single line of a JSONL forecast report
+type forecastRecord struct {
+	Sku                    string   `json:"sku"`
+	ProductID              string   `json:"product_id"`
+	VariantID              string   `json:"variant_id"`
+	LocationID             string   `json:"location_id"`
+	Method                 string   `json:"method"`
+	HistoryDays            uint32   `json:"history_days"`
+	LeadTimeDays           uint32   `json:"lead_time_days"`
+	ReviewPeriodDays       uint32   `json:"review_period_days"`
+	VelocityMovingAverage  float64  `json:"velocity_moving_average"`
+	VelocitySeasonal       float64  `json:"velocity_seasonal"`
+	Velocity               float64  `json:"velocity"`
+	DemandStdDev           float64  `json:"demand_std_dev"`
+	QuantityAvailable      uint32   `json:"quantity_available"`
+	QuantityInTransit      uint32   `json:"quantity_in_transit"`
+	QuantityIncoming       uint32   `json:"quantity_incoming"`
+	DaysOfCover            *float64 `json:"days_of_cover"`
+	SafetyStock            uint32   `json:"safety_stock"`
+	ReorderPoint           uint32   `json:"reorder_point"`
+	OrderUpTo              uint32   `json:"order_up_to"`
+	SuggestedOrderQuantity uint32   `json:"suggested_order_quantity"`
+	Reorder                bool     `json:"reorder"`
+}
+
+// ForecastWriter writes the forecasts and the reorder suggestions of inventory items, a csv
+// ForecastWriter leaves days_of_cover empty for the items that have no demand
+type ForecastWriter struct {
+	format Format
+	csv    *csv.Writer
+	json   *json.Encoder
+	buf    *bufio.Writer
+	header bool
+}
+
+func NewForecastWriter(w io.Writer, format Format) *ForecastWriter {
+	bw := bufio.NewWriter(w)
+	fw := &ForecastWriter{format: format, buf: bw}
+	if format == FormatCSV {
+		fw.csv = csv.NewWriter(bw)
+	} else {
+		fw.json = json.NewEncoder(bw)
+	}
+	return fw
+}
+
+func (w *ForecastWriter) Write(f *pb.InventoryForecastData) error {
+	method := intModels.GetInventoryForecastMethod(f.GetMethod())
+	if w.format != FormatCSV {
+		return w.json.Encode(&forecastRecord{
+			Sku:                    f.GetSku(),
+			ProductID:              f.GetProductId(),
+			VariantID:              f.GetVariantId(),
+			LocationID:             f.GetLocationId(),
+			Method:                 method,
+			HistoryDays:            f.GetHistoryDays(),
+			LeadTimeDays:           f.GetLeadTimeDays(),
+			ReviewPeriodDays:       f.GetReviewPeriodDays(),
+			VelocityMovingAverage:  f.GetVelocityMovingAverage(),
+			VelocitySeasonal:       f.GetVelocitySeasonal(),
+			Velocity:               f.GetVelocity(),
+			DemandStdDev:           f.GetDemandStdDev(),
+			QuantityAvailable:      f.GetQuantityAvailable(),
+			QuantityInTransit:      f.GetQuantityInTransit(),
+			QuantityIncoming:       f.GetQuantityIncoming(),
+			DaysOfCover:            f.DaysOfCover,
+			SafetyStock:            f.GetSafetyStock(),
+			ReorderPoint:           f.GetReorderPoint(),
+			OrderUpTo:              f.GetOrderUpTo(),
+			SuggestedOrderQuantity: f.GetSuggestedOrderQuantity(),
+			Reorder:                f.GetReorder(),
+		})
+	}
+
+	if !w.header {
+		if err := w.csv.Write(forecastColumns); err != nil {
+			return err
+		}
+		w.header = true
+	}
+
+	daysOfCover := ""
+	if f.DaysOfCover != nil {
+		daysOfCover = formatFloat(f.GetDaysOfCover())
+	}
+	return w.csv.Write([]string{
+		f.GetSku(),
+		f.GetProductId(),
+		f.GetVariantId(),
+		f.GetLocationId(),
+		method,
+		strconv.Itoa(int(f.GetHistoryDays())),
+		strconv.Itoa(int(f.GetLeadTimeDays())),
+		strconv.Itoa(int(f.GetReviewPeriodDays())),
+		formatFloat(f.GetVelocityMovingAverage()),
+		formatFloat(f.GetVelocitySeasonal()),
+		formatFloat(f.GetVelocity()),
+		formatFloat(f.GetDemandStdDev()),
+		strconv.Itoa(int(f.GetQuantityAvailable())),
+		strconv.Itoa(int(f.GetQuantityInTransit())),
+		strconv.Itoa(int(f.GetQuantityIncoming())),
+		daysOfCover,
+		strconv.Itoa(int(f.GetSafetyStock())),
+		strconv.Itoa(int(f.GetReorderPoint())),
+		strconv.Itoa(int(f.GetOrderUpTo())),
+		strconv.Itoa(int(f.GetSuggestedOrderQuantity())),
+		strconv.FormatBool(f.GetReorder()),
+	})
+}
+
+func (w *ForecastWriter) Flush() error {
+	if w.csv != nil {
+		// write the header even if there were no forecasts
+		if !w.header {
+			if err := w.csv.Write(forecastColumns); err != nil {
+				return err
+			}
+			w.header = true
+		}
+		w.csv.Flush()
+		if err := w.csv.Error(); err != nil {
+			return err
+		}
+	}
+	return w.buf.Flush()
+}
+
+func formatFloat(v float64) string {
+	return strconv.FormatFloat(v, 'f', 2, 64)
+}
@@ -0,0 +1,344 @@
+// Package cli contains the command line tools of this service, they talk to a running
+// inventory service over grpc, E,g: bulk stock import and export, forecast reports
+package cli
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/ahmad-khatib0-org/megacommerce-inventory/internal/bulk"
+	intModels "github.com/ahmad-khatib0-org/megacommerce-inventory/pkg/models"
+	pb "github.com/ahmad-khatib0-org/megacommerce-proto/gen/go/inventory/v1"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+)
+
+// TokenEnv holds the service token that the commands authenticate with
+const TokenEnv = "INVENTORY_TOKEN"
+
+const usage = `usage:
+  inventory import -file stock.csv [-dry-run] [-reason text] [-batch 1000]
+  inventory export -out stock.jsonl [-format csv|jsonl] [-location id] [-seller id]
+  inventory forecast -out forecast.csv [-format csv|jsonl] [-location id] [-seller id]
+                     [-method moving_average|seasonal_smoothing] [-lead-time days] [-review-period days] [-reorder-only]
+
+common flags: -addr host:port -ca ca.crt, the service token is read from $` + TokenEnv
+
+// Run runs the command named by args[0], it returns an error for unknown commands
+func Run(cfg *intModels.Config, args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("missing command\n%s", usage)
+	}
+
+	switch args[0] {
+	case "import":
+		return runImport(cfg, args[1:])
+	case "export":
+		return runExport(cfg, args[1:])
+	case "forecast":
+		return runForecast(cfg, args[1:])
+	default:
+		return fmt.Errorf("unknown command %q\n%s", args[0], usage)
+	}
+}
+
+type connFlags struct {
+	addr string
+	ca   string
+}
+
+func (cf *connFlags) register(fs *flag.FlagSet, cfg *intModels.Config) {
+	fs.StringVar(&cf.addr, "addr", cfg.Service.GrpcURL, "the inventory service grpc address")
+	fs.StringVar(&cf.ca, "ca", "", "ca certificate of the inventory service, enables tls")
+}
+
+func (cf *connFlags) dial() (pb.InventoryServiceClient, *grpc.ClientConn, error) {
+	creds := insecure.NewCredentials()
+	if cf.ca != "" {
+		c, err := credentials.NewClientTLSFromFile(cf.ca, "")
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to load the ca certificate: %w", err)
+		}
+		creds = c
+	}
+
+	conn, err := grpc.NewClient(cf.addr, grpc.WithTransportCredentials(creds))
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to connect to %s: %w", cf.addr, err)
+	}
+
+	return pb.NewInventoryServiceClient(conn), conn, nil
+}
+
+func authContext(ctx context.Context) (context.Context, error) {
+	token := os.Getenv(TokenEnv)
+	if token == "" {
+		return nil, fmt.Errorf("$%s is not set", TokenEnv)
+	}
+	return metadata.AppendToOutgoingContext(ctx, "authorization", "Bearer "+token), nil
+}
+
+func runImport(cfg *intModels.Config, args []string) error {
+	fs := flag.NewFlagSet("import", flag.ContinueOnError)
+	var cf connFlags
+	cf.register(fs, cfg)
+	file := fs.String("file", "", "the csv or jsonl file to import")
+	dryRun := fs.Bool("dry-run", false, "validate and simulate the import without applying it")
+	reason := fs.String("reason", "", "the reason recorded on the inventory movements")
+	batch := fs.Int("batch", 1000, "rows sent per stream message")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *file == "" {
+		return fmt.Errorf("-file is required\n%s", usage)
+	}
+
+	format, err := bulk.FormatFromPath(*file)
+	if err != nil {
+		return err
+	}
+
+	f, err := os.Open(*file)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	rows, err := bulk.ReadRows(f, format)
+	if err != nil {
+		return fmt.Errorf("%s: %w", *file, err)
+	}
+
+	client, conn, err := cf.dial()
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	ctx, err := authContext(context.Background())
+	if err != nil {
+		return err
+	}
+
+	stream, err := client.InventoryImport(ctx)
+	if err != nil {
+		return err
+	}
+
+	var reasonPtr *string
+	if *reason != "" {
+		reasonPtr = reason
+	}
+	for start := 0; start < len(rows); start += *batch {
+		end := min(start+*batch, len(rows))
+		req := &pb.InventoryImportRequest{DryRun: *dryRun, Reason: reasonPtr, Rows: rows[start:end]}
+		if err := stream.Send(req); err != nil {
+			return fmt.Errorf("failed to send the rows %d-%d: %w", start+1, end, err)
+		}
+	}
+
+	res, err := stream.CloseAndRecv()
+	if err != nil {
+		return err
+	}
+	if res.GetError() != nil {
+		return fmt.Errorf("the import was rejected: %s", res.GetError().GetMessage())
+	}
+
+	return printImportResults(os.Stdout, res.GetData())
+}
+
+func printImportResults(w io.Writer, data *pb.InventoryImportResponseData) error {
+	mode := "applied"
+	if data.GetDryRun() {
+		mode = "dry run"
+	}
+	fmt.Fprintf(w, "%s: %d rows, %d applied, %d pending approval, %d failed\n", mode, data.GetTotal(), data.GetApplied(), data.GetPending(), data.GetFailed())
+
+	for _, r := range data.GetResults() {
+		if r.GetErrorId() == "" {
+			continue
+		}
+		status := strings.TrimPrefix(r.GetStatus().String(), "INVENTORY_IMPORT_ROW_STATUS_")
+		fmt.Fprintf(w, "line %d (%s): %s %s\n", r.GetLine(), r.GetSku(), status, r.GetErrorId())
+	}
+
+	if data.GetFailed() > 0 {
+		return fmt.Errorf("%d rows failed", data.GetFailed())
+	}
+	return nil
+}
+
+func runExport(cfg *intModels.Config, args []string) error {
+	fs := flag.NewFlagSet("export", flag.ContinueOnError)
+	var cf connFlags
+	cf.register(fs, cfg)
+	out := fs.String("out", "-", "the output file, - for stdout")
+	formatFlag := fs.String("format", "", "csv or jsonl, defaults to the extension of -out")
+	location := fs.String("location", "", "only export this location")
+	seller := fs.String("seller", "", "only export this seller")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	format, err := outputFormat(*out, *formatFlag)
+	if err != nil {
+		return err
+	}
+
+	var w io.Writer = os.Stdout
+	if *out != "-" {
+		f, err := os.Create(*out)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		w = f
+	}
+
+	client, conn, err := cf.dial()
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	ctx, err := authContext(context.Background())
+	if err != nil {
+		return err
+	}
+
+	stream, err := client.InventoryExport(ctx, &pb.InventoryExportRequest{LocationId: *location, SellerId: *seller})
+	if err != nil {
+		return err
+	}
+
+	writer := bulk.NewWriter(w, format)
+	for {
+		res, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		if res.GetError() != nil {
+			return fmt.Errorf("the export failed: %s", res.GetError().GetMessage())
+		}
+
+		for _, item := range res.GetData().GetItems() {
+			if err := writer.Write(item); err != nil {
+				return err
+			}
+		}
+	}
+
+	return writer.Flush()
+}
+
+// outputFormat is the format of an output file, the one asked for, or the one of its extension,
+// stdout (-) defaults to csv
+func outputFormat(out string, formatFlag string) (bulk.Format, error) {
+	format := bulk.Format(formatFlag)
+	if format == "" {
+		if out == "-" {
+			return bulk.FormatCSV, nil
+		}
+		return bulk.FormatFromPath(out)
+	}
+	if format != bulk.FormatCSV && format != bulk.FormatJSONL {
+		return "", fmt.Errorf("unsupported format %q", format)
+	}
+	return format, nil
+}
+
+func runForecast(cfg *intModels.Config, args []string) error {
+	fs := flag.NewFlagSet("forecast", flag.ContinueOnError)
+	var cf connFlags
+	cf.register(fs, cfg)
+	out := fs.String("out", "-", "the output file, - for stdout")
+	formatFlag := fs.String("format", "", "csv or jsonl, defaults to the extension of -out")
+	location := fs.String("location", "", "only forecast this location")
+	seller := fs.String("seller", "", "only forecast this seller")
+	method := fs.String("method", "", "moving_average or seasonal_smoothing, defaults to the configured method of the service")
+	leadTime := fs.Int("lead-time", -1, "the supplier lead time in days, defaults to the configured lead time of the service")
+	reviewPeriod := fs.Int("review-period", -1, "the days between two orders, defaults to the configured review period of the service")
+	reorderOnly := fs.Bool("reorder-only", false, "only report the items that should be reordered now")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	format, err := outputFormat(*out, *formatFlag)
+	if err != nil {
+		return err
+	}
+
+	req := &pb.InventoryForecastReportRequest{LocationId: *location, SellerId: *seller, ReorderOnly: *reorderOnly}
+	if *method != "" {
+		req.Method = intModels.GetInventoryForecastMethodFromString(*method)
+		if req.Method == pb.InventoryForecastMethod_INVENTORY_FORECAST_METHOD_UNSPECIFIED {
+			return fmt.Errorf("unsupported method %q", *method)
+		}
+	}
+	if *leadTime >= 0 {
+		days := uint32(*leadTime)
+		req.LeadTimeDays = &days
+	}
+	if *reviewPeriod >= 0 {
+		days := uint32(*reviewPeriod)
+		req.ReviewPeriodDays = &days
+	}
+
+	var w io.Writer = os.Stdout
+	if *out != "-" {
+		f, err := os.Create(*out)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		w = f
+	}
+
+	client, conn, err := cf.dial()
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	ctx, err := authContext(context.Background())
+	if err != nil {
+		return err
+	}
+
+	stream, err := client.InventoryForecastReport(ctx, req)
+	if err != nil {
+		return err
+	}
+
+	writer := bulk.NewForecastWriter(w, format)
+	for {
+		res, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		if res.GetError() != nil {
+			return fmt.Errorf("the forecast report failed: %s", res.GetError().GetMessage())
+		}
+
+		for _, f := range res.GetData().GetForecasts() {
+			if err := writer.Write(f); err != nil {
+				return err
+			}
+		}
+	}
+
+	return writer.Flush()
+}
@@ -0,0 +1,86 @@
+package common
+
+import (
+	"os"
+	"path/filepath"
+
+	com "github.com/ahmad-khatib0-org/megacommerce-proto/gen/go/common/v1"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+const (
+	configCacheFile       = "config.json"
+	translationsCacheFile = "translations.json"
+)
+
+// diskCache keeps the last good config and translations received from the
+// common service, so this service can still boot while the common service is down
+type diskCache struct {
+	dir string
+}
+
+func (dc *diskCache) save(name string, msg proto.Message) error {
+	if dc.dir == "" {
+		return nil
+	}
+
+	data, err := protojson.Marshal(msg)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(dc.dir, 0o700); err != nil {
+		return err
+	}
+
+	// write then rename, so a crash never leaves a truncated cache behind
+	tmp, err := os.CreateTemp(dc.dir, name+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), filepath.Join(dc.dir, name))
+}
+
+func (dc *diskCache) load(name string, msg proto.Message) error {
+	data, err := os.ReadFile(filepath.Join(dc.dir, name))
+	if err != nil {
+		return err
+	}
+
+	return protojson.Unmarshal(data, msg)
+}
+
+func (dc *diskCache) saveConfig(config *com.Config) error {
+	return dc.save(configCacheFile, config)
+}
+
+func (dc *diskCache) loadConfig() (*com.Config, error) {
+	var config com.Config
+	if err := dc.load(configCacheFile, &config); err != nil {
+		return nil, err
+	}
+	return &config, nil
+}
+
+func (dc *diskCache) saveTranslations(trans map[string]*com.TranslationElements) error {
+	return dc.save(translationsCacheFile, &com.TranslationsGetResponse{Data: trans})
+}
+
+func (dc *diskCache) loadTranslations() (map[string]*com.TranslationElements, error) {
+	var res com.TranslationsGetResponse
+	if err := dc.load(translationsCacheFile, &res); err != nil {
+		return nil, err
+	}
+	return res.Data, nil
+}
@@ -5,15 +5,21 @@ import (
 	"context"
 	"fmt"
 	"net"
+	"sync/atomic"
 	"time"
 
+	"github.com/ahmad-khatib0-org/megacommerce-inventory/internal/tlsutil"
 	internalModels "github.com/ahmad-khatib0-org/megacommerce-inventory/pkg/models"
 	com "github.com/ahmad-khatib0-org/megacommerce-proto/gen/go/common/v1"
 	shared "github.com/ahmad-khatib0-org/megacommerce-proto/gen/go/shared/v1"
 	"github.com/ahmad-khatib0-org/megacommerce-shared-go/pkg/logger"
 	"github.com/ahmad-khatib0-org/megacommerce-shared-go/pkg/models"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/backoff"
+	"google.golang.org/grpc/connectivity"
+	"google.golang.org/grpc/credentials"
 	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/keepalive"
 )
 
 type CommonClient struct {
@@ -22,6 +28,14 @@ type CommonClient struct {
 	conn   *grpc.ClientConn
 	client com.CommonServiceClient
 	log    *logger.Logger
+	certs  *tlsutil.Reloader
+	cache  *diskCache
+	// breaker is shared by all the calls, since they all go through the same connection
+	breaker breaker
+	// configDegraded and transDegraded are set while the config or the translations
+	// in use come from the disk cache, and cleared once the common service answers again
+	configDegraded atomic.Bool
+	transDegraded  atomic.Bool
 }
 
 type CommonArgs struct {
@@ -31,7 +45,7 @@ type CommonArgs struct {
 
 // NewCommonClient runs the CommonService client
 func NewCommonClient(ca *CommonArgs) (*CommonClient, *models.InternalError) {
-	c := &CommonClient{cfg: ca.Config, log: ca.Log}
+	c := &CommonClient{cfg: ca.Config, log: ca.Log, cache: &diskCache{dir: ca.Config.Service.CommonCacheDir}}
 	if err := c.initCommonClient(); err != nil {
 		return nil, err
 	}
@@ -50,9 +64,23 @@ func (cc *CommonClient) initCommonClient() *models.InternalError {
 		return ie(err, "failed to init common service client, invalid grpc url")
 	}
 
+	creds, errCreds := cc.transportCredentials()
+	if errCreds != nil {
+		return errCreds
+	}
+
 	conn, err := grpc.NewClient(
 		target,
-		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithTransportCredentials(creds),
+		grpc.WithKeepaliveParams(keepalive.ClientParameters{
+			Time:                30 * time.Second,
+			Timeout:             10 * time.Second,
+			PermitWithoutStream: true,
+		}),
+		grpc.WithConnectParams(grpc.ConnectParams{
+			Backoff:           backoff.Config{BaseDelay: time.Second, Multiplier: 1.6, Jitter: 0.2, MaxDelay: 30 * time.Second},
+			MinConnectTimeout: 5 * time.Second,
+		}),
 	)
 	if err != nil {
 		return ie(err, "failed to connect to the shared common service")
@@ -62,21 +90,75 @@ func (cc *CommonClient) initCommonClient() *models.InternalError {
 	cc.client = com.NewCommonServiceClient(conn)
 	cc.conn = conn
 
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-	defer cancel()
-
-	_, err = cc.client.Ping(ctx, &shared.PingRequest{})
+	// an unreachable common service is not fatal, the cached config and translations are used instead
+	err = cc.retry("initCommonClient", func(ctx context.Context) error {
+		_, err := cc.client.Ping(ctx, &shared.PingRequest{})
+		return err
+	})
 	if err != nil {
-		return ie(err, "failed to ping the common service")
+		cc.log.Errorf("user.common.initCommonClient: failed to ping the common service, err: %v", err)
 	}
 
 	return nil
 }
 
+// transportCredentials uses mtls if it's enabled in the config, and plaintext otherwise
+func (cc *CommonClient) transportCredentials() (credentials.TransportCredentials, *models.InternalError) {
+	cfg := cc.cfg.TLS.CommonClient
+	if !cfg.Enabled {
+		return insecure.NewCredentials(), nil
+	}
+
+	certs, err := tlsutil.NewReloader(cfg.CertFile, cfg.KeyFile, cfg.CAFile, cc.log)
+	if err != nil {
+		return nil, err
+	}
+	cc.certs = certs
+
+	serverName := cfg.ServerName
+	if serverName == "" {
+		serverName, _, _ = net.SplitHostPort(cc.cfg.Service.CommonServiceGrpcURL)
+	}
+
+	return credentials.NewTLS(certs.ClientConfig(serverName)), nil
+}
+
 func (cc *CommonClient) Close() error {
+	if cc.certs != nil {
+		cc.certs.Close()
+	}
 	return cc.conn.Close()
 }
 
 func (cc *CommonClient) Conn() *grpc.ClientConn {
 	return cc.conn
 }
+
+// State returns the state of the underlying connection to the common service
+func (cc *CommonClient) State() connectivity.State {
+	return cc.conn.GetState()
+}
+
+// Degraded reports whether the config or the translations in use came from the
+// disk cache, rather than from the common service
+func (cc *CommonClient) Degraded() bool {
+	return cc.configDegraded.Load() || cc.transDegraded.Load()
+}
+
+// Healthy reports whether the common service is currently usable
+func (cc *CommonClient) Healthy() bool {
+	state := cc.State()
+	return !cc.breaker.isOpen() && (state == connectivity.Ready || state == connectivity.Idle)
+}
+
+// WatchState calls fn with the current connection state and on every change after it, until ctx is done
+func (cc *CommonClient) WatchState(ctx context.Context, fn func(state connectivity.State)) {
+	state := cc.State()
+	for {
+		fn(state)
+		if !cc.conn.WaitForStateChange(ctx, state) {
+			return
+		}
+		state = cc.State()
+	}
+}
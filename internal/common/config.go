@@ -2,35 +2,55 @@ package common
 
 import (
 	"context"
+	"errors"
 	"fmt"
-	"time"
 
 	com "github.com/ahmad-khatib0-org/megacommerce-proto/gen/go/common/v1"
 	"github.com/ahmad-khatib0-org/megacommerce-shared-go/pkg/models"
 )
 
+// ConfigGet gets the configurations from the common service, falling back
+// to the last cached copy if the common service can't be reached
 func (cc *CommonClient) ConfigGet() (*com.Config, *models.InternalError) {
-	ctx, cancel := context.WithTimeout(context.Background(), time.Second*10)
-	defer cancel()
-
 	ie := func(err error, msg string) *models.InternalError {
 		return &models.InternalError{Path: "user.common.ConfigGet", Err: err, Msg: msg}
 	}
 
-	res, err := cc.client.ConfigGet(ctx, &com.ConfigGetRequest{})
-	if err != nil {
-		return nil, ie(err, "failed to get configurations from common service")
+	var config *com.Config
+	err := cc.retry("ConfigGet", func(ctx context.Context) error {
+		res, err := cc.client.ConfigGet(ctx, &com.ConfigGetRequest{})
+		if err != nil {
+			return err
+		}
+
+		switch res := res.Response.(type) {
+		case *com.ConfigGetResponse_Data:
+			config = res.Data
+		case *com.ConfigGetResponse_Error:
+			return models.AppErrorFromProto(nil, res.Error) // no need for ctx here
+		}
+		return nil
+	})
+
+	if err == nil && config != nil {
+		if err := cc.cache.saveConfig(config); err != nil {
+			cc.log.Errorf("user.common.ConfigGet: failed to cache the configurations, err: %v", err)
+		}
+		cc.configDegraded.Store(false)
+		return config, nil
+	}
+	if err == nil {
+		err = errors.New("the common service returned an empty config")
 	}
 
-	switch res := res.Response.(type) {
-	case *com.ConfigGetResponse_Data:
-		return res.Data, nil
-	case *com.ConfigGetResponse_Error:
-		err := models.AppErrorFromProto(nil, res.Error) // no need for ctx here
-		return nil, ie(err, "failed to get configurations from common service")
+	cached, errCache := cc.cache.loadConfig()
+	if errCache != nil {
+		return nil, ie(errors.Join(err, errCache), "failed to get configurations from common service, and no cached copy is available")
 	}
 
-	return nil, nil
+	cc.configDegraded.Store(true)
+	cc.log.Errorf("user.common.ConfigGet: using the cached configurations, err: %v", err)
+	return cached, nil
 }
 
 // ConfigListener  TODO: complete this listener
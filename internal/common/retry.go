@@ -0,0 +1,114 @@
+package common
+
+import (
+	"context"
+	"errors"
+	"math/rand/v2"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	retryMaxAttempts  = 5
+	retryInitialDelay = 500 * time.Millisecond
+	retryMaxDelay     = 10 * time.Second
+	callTimeout       = 10 * time.Second
+
+	breakerThreshold = 5
+	breakerCooldown  = 30 * time.Second
+)
+
+var errBreakerOpen = errors.New("the common service circuit breaker is open")
+
+// retryable reports whether err is a transient transport error, errors
+// returned in the response payload by the common service are final
+func retryable(err error) bool {
+	s, ok := status.FromError(err)
+	if !ok {
+		return false
+	}
+
+	switch s.Code() {
+	case codes.Unavailable, codes.DeadlineExceeded, codes.ResourceExhausted, codes.Aborted:
+		return true
+	default:
+		return false
+	}
+}
+
+// retry calls fn with exponential backoff and jitter, until it succeeds, fails
+// with a non retryable error, runs out of attempts, or the breaker opens
+func (cc *CommonClient) retry(name string, fn func(ctx context.Context) error) error {
+	delay := retryInitialDelay
+	var err error
+	for attempt := 1; attempt <= retryMaxAttempts; attempt++ {
+		if !cc.breaker.allow() {
+			return errBreakerOpen
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), callTimeout)
+		err = fn(ctx)
+		cancel()
+
+		cc.breaker.record(err != nil && retryable(err))
+		if err == nil || !retryable(err) || attempt == retryMaxAttempts {
+			return err
+		}
+
+		wait := delay/2 + rand.N(delay/2+1)
+		cc.log.Infof("inventory.common.%s: attempt %d failed, retrying in %s, err: %v", name, attempt, wait, err)
+		time.Sleep(wait)
+		delay = min(delay*2, retryMaxDelay)
+	}
+
+	return err
+}
+
+// breaker opens after breakerThreshold consecutive transport failures, and lets
+// a single probe call through once breakerCooldown has passed
+type breaker struct {
+	mu       sync.Mutex
+	failures int
+	openedAt time.Time
+	probing  bool
+}
+
+func (b *breaker) allow() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.failures < breakerThreshold {
+		return true
+	}
+	if b.probing || time.Since(b.openedAt) < breakerCooldown {
+		return false
+	}
+
+	b.probing = true
+	return true
+}
+
+func (b *breaker) record(failed bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.probing = false
+	if !failed {
+		b.failures = 0
+		return
+	}
+
+	b.failures++
+	if b.failures >= breakerThreshold {
+		b.openedAt = time.Now()
+	}
+}
+
+func (b *breaker) isOpen() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.failures >= breakerThreshold
+}
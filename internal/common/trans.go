@@ -2,28 +2,48 @@ package common
 
 import (
 	"context"
-	"time"
+	"errors"
 
 	com "github.com/ahmad-khatib0-org/megacommerce-proto/gen/go/common/v1"
 	"github.com/ahmad-khatib0-org/megacommerce-shared-go/pkg/models"
 )
 
+// TranslationsGet gets the translations from the common service, falling back
+// to the last cached copy if the common service can't be reached
 func (cc *CommonClient) TranslationsGet() (map[string]*com.TranslationElements, *models.InternalError) {
-	ctx, cancel := context.WithTimeout(context.Background(), time.Second*10)
-	defer cancel()
-
 	ie := func(err error, msg string) *models.InternalError {
 		return &models.InternalError{Path: "inventory.common.TranslationsGet", Err: err, Msg: msg}
 	}
 
-	res, err := cc.client.TranslationsGet(ctx, &com.TranslationsGetRequest{})
-	if err != nil {
-		return nil, ie(err, "failed to get translations from the common service")
+	var trans map[string]*com.TranslationElements
+	err := cc.retry("TranslationsGet", func(ctx context.Context) error {
+		res, err := cc.client.TranslationsGet(ctx, &com.TranslationsGetRequest{})
+		if err != nil {
+			return err
+		}
+
+		if res.Error != nil {
+			return models.AppErrorFromProto(nil, res.Error)
+		}
+
+		trans = res.Data
+		return nil
+	})
+
+	if err == nil {
+		if err := cc.cache.saveTranslations(trans); err != nil {
+			cc.log.Errorf("inventory.common.TranslationsGet: failed to cache the translations, err: %v", err)
+		}
+		cc.transDegraded.Store(false)
+		return trans, nil
 	}
 
-	if res.Error != nil {
-		return nil, ie(models.AppErrorFromProto(nil, res.Error), "failed to get translations from the common service")
+	cached, errCache := cc.cache.loadTranslations()
+	if errCache != nil {
+		return nil, ie(errors.Join(err, errCache), "failed to get translations from the common service, and no cached copy is available")
 	}
 
-	return res.Data, nil
+	cc.transDegraded.Store(true)
+	cc.log.Errorf("inventory.common.TranslationsGet: using the cached translations, err: %v", err)
+	return cached, nil
 }
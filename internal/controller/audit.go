@@ -1,9 +1,18 @@
 package controller
 
-import "github.com/ahmad-khatib0-org/megacommerce-shared-go/pkg/models"
+import (
+	"context"
 
-// ProcessAudit process the given audit and save it
+	"github.com/ahmad-khatib0-org/megacommerce-inventory/internal/auth"
+	"github.com/ahmad-khatib0-org/megacommerce-shared-go/pkg/models"
+)
+
+// ProcessAudit process the given audit alongside the caller that triggered it, and save it
 // TODO: implement the function
-func (c *Controller) ProcessAudit(ar *models.AuditRecord) {
-	c.log.DebugStruct("the following record should be processed", ar)
+func (c *Controller) ProcessAudit(ctx context.Context, ar *models.AuditRecord) {
+	caller, _ := auth.CallerFromContext(ctx)
+	c.log.DebugStruct("the following record should be processed", map[string]any{
+		"caller": caller.Auditable(),
+		"record": ar,
+	})
 }
@@ -0,0 +1,100 @@
+package controller
+
+import (
+	"context"
+	"strings"
+
+	"github.com/ahmad-khatib0-org/megacommerce-inventory/internal/auth"
+	pb "github.com/ahmad-khatib0-org/megacommerce-proto/gen/go/inventory/v1"
+	"github.com/grpc-ecosystem/go-grpc-middleware/v2/interceptors"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// methodRoles lists the roles that are allowed to call each rpc,
+// an rpc that is missing from this map is denied for everyone
+var methodRoles = map[string][]string{
+	pb.InventoryService_InventoryReserve_FullMethodName:              {auth.RoleOrderService},
+	pb.InventoryService_InventoryRelease_FullMethodName:              {auth.RoleOrderService},
+	pb.InventoryService_InventoryUpdate_FullMethodName:               {auth.RoleAdmin, auth.RoleWarehouse, auth.RoleSeller},
+	pb.InventoryService_InventoryReservationGet_FullMethodName:       {auth.RoleOrderService, auth.RoleAdmin, auth.RoleWarehouse, auth.RoleSeller},
+	pb.InventoryService_InventoryReservationList_FullMethodName:      {auth.RoleOrderService, auth.RoleAdmin, auth.RoleWarehouse, auth.RoleSeller},
+	pb.InventoryService_InventoryReservationExtend_FullMethodName:    {auth.RoleOrderService},
+	pb.InventoryService_InventoryReservationModify_FullMethodName:    {auth.RoleOrderService},
+	pb.InventoryService_InventoryFulfill_FullMethodName:              {auth.RoleOrderService, auth.RoleWarehouse},
+	pb.InventoryService_InventoryReturnCreate_FullMethodName:         {auth.RoleOrderService, auth.RoleAdmin},
+	pb.InventoryService_InventoryReturnReceive_FullMethodName:        {auth.RoleAdmin, auth.RoleWarehouse},
+	pb.InventoryService_InventoryReturnGet_FullMethodName:            {auth.RoleOrderService, auth.RoleAdmin, auth.RoleWarehouse, auth.RoleSeller},
+	pb.InventoryService_InventoryTransferCreate_FullMethodName:       {auth.RoleAdmin, auth.RoleWarehouse, auth.RoleSeller},
+	pb.InventoryService_InventoryTransferShip_FullMethodName:         {auth.RoleAdmin, auth.RoleWarehouse, auth.RoleSeller},
+	pb.InventoryService_InventoryTransferReceive_FullMethodName:      {auth.RoleAdmin, auth.RoleWarehouse, auth.RoleSeller},
+	pb.InventoryService_InventoryTransferCancel_FullMethodName:       {auth.RoleAdmin, auth.RoleWarehouse, auth.RoleSeller},
+	pb.InventoryService_InventoryTransferGet_FullMethodName:          {auth.RoleAdmin, auth.RoleWarehouse, auth.RoleSeller},
+	pb.InventoryService_InventoryPurchaseOrderCreate_FullMethodName:  {auth.RoleAdmin, auth.RoleWarehouse, auth.RoleSeller},
+	pb.InventoryService_InventoryPurchaseOrderReceive_FullMethodName: {auth.RoleAdmin, auth.RoleWarehouse, auth.RoleSeller},
+	pb.InventoryService_InventoryPurchaseOrderCancel_FullMethodName:  {auth.RoleAdmin, auth.RoleWarehouse, auth.RoleSeller},
+	pb.InventoryService_InventoryPurchaseOrderGet_FullMethodName:     {auth.RoleAdmin, auth.RoleWarehouse, auth.RoleSeller},
+	pb.InventoryService_InventoryAvailableToPromise_FullMethodName:   {auth.RoleOrderService, auth.RoleAdmin, auth.RoleWarehouse, auth.RoleSeller},
+	pb.InventoryService_InventoryBackorderPolicySet_FullMethodName:   {auth.RoleAdmin, auth.RoleSeller},
+	pb.InventoryService_InventoryAllocationSet_FullMethodName:        {auth.RoleAdmin, auth.RoleSeller},
+	pb.InventoryService_InventoryAllocationGet_FullMethodName:        {auth.RoleAdmin, auth.RoleWarehouse, auth.RoleSeller},
+	pb.InventoryService_InventoryKitSet_FullMethodName:               {auth.RoleAdmin, auth.RoleSeller},
+	pb.InventoryService_InventoryKitGet_FullMethodName:               {auth.RoleOrderService, auth.RoleAdmin, auth.RoleWarehouse, auth.RoleSeller},
+	pb.InventoryService_InventoryLotReceive_FullMethodName:           {auth.RoleAdmin, auth.RoleWarehouse, auth.RoleSeller},
+	pb.InventoryService_InventoryLotList_FullMethodName:              {auth.RoleAdmin, auth.RoleWarehouse, auth.RoleSeller},
+	pb.InventoryService_InventorySerialRegister_FullMethodName:       {auth.RoleAdmin, auth.RoleWarehouse, auth.RoleSeller},
+	pb.InventoryService_InventorySerialGet_FullMethodName:            {auth.RoleOrderService, auth.RoleAdmin, auth.RoleWarehouse, auth.RoleSeller},
+	pb.InventoryService_InventoryCycleCountCreate_FullMethodName:     {auth.RoleAdmin, auth.RoleWarehouse, auth.RoleSeller},
+	pb.InventoryService_InventoryCycleCountSubmit_FullMethodName:     {auth.RoleAdmin, auth.RoleWarehouse, auth.RoleSeller},
+	pb.InventoryService_InventoryCycleCountApprove_FullMethodName:    {auth.RoleAdmin, auth.RoleSeller},
+	pb.InventoryService_InventoryCycleCountReject_FullMethodName:     {auth.RoleAdmin, auth.RoleSeller},
+	pb.InventoryService_InventoryCycleCountGet_FullMethodName:        {auth.RoleAdmin, auth.RoleWarehouse, auth.RoleSeller},
+	pb.InventoryService_InventoryAdjustmentApprove_FullMethodName:    {auth.RoleAdmin, auth.RoleSeller},
+	pb.InventoryService_InventoryAdjustmentReject_FullMethodName:     {auth.RoleAdmin, auth.RoleSeller},
+	pb.InventoryService_InventoryAdjustmentList_FullMethodName:       {auth.RoleAdmin, auth.RoleWarehouse, auth.RoleSeller},
+	pb.InventoryService_InventoryStockMove_FullMethodName:            {auth.RoleAdmin, auth.RoleWarehouse, auth.RoleSeller},
+	pb.InventoryService_InventoryUnitSet_FullMethodName:              {auth.RoleAdmin, auth.RoleSeller},
+	pb.InventoryService_InventoryUnitGet_FullMethodName:              {auth.RoleOrderService, auth.RoleAdmin, auth.RoleWarehouse, auth.RoleSeller},
+	pb.InventoryService_InventoryValuation_FullMethodName:            {auth.RoleAdmin, auth.RoleSeller},
+	pb.InventoryService_InventoryStockAsOf_FullMethodName:            {auth.RoleAdmin, auth.RoleWarehouse, auth.RoleSeller},
+	pb.InventoryService_InventoryForecast_FullMethodName:             {auth.RoleAdmin, auth.RoleWarehouse, auth.RoleSeller},
+	pb.InventoryService_InventoryForecastReport_FullMethodName:       {auth.RoleAdmin, auth.RoleWarehouse, auth.RoleSeller},
+	pb.InventoryService_InventoryReorderPolicySet_FullMethodName:     {auth.RoleAdmin, auth.RoleSeller},
+	pb.InventoryService_InventoryReorderPolicyGet_FullMethodName:     {auth.RoleAdmin, auth.RoleWarehouse, auth.RoleSeller},
+	pb.InventoryService_InventoryReplenishmentRun_FullMethodName:     {auth.RoleAdmin, auth.RoleSeller},
+	pb.InventoryService_InventoryPurchaseOrderList_FullMethodName:    {auth.RoleAdmin, auth.RoleWarehouse, auth.RoleSeller},
+	pb.InventoryService_InventoryPurchaseOrderSubmit_FullMethodName:  {auth.RoleAdmin, auth.RoleWarehouse, auth.RoleSeller},
+	pb.InventoryService_InventoryImport_FullMethodName:               {auth.RoleAdmin, auth.RoleWarehouse, auth.RoleSeller},
+	pb.InventoryService_InventoryExport_FullMethodName:               {auth.RoleAdmin, auth.RoleWarehouse, auth.RoleSeller},
+}
+
+// authMatcher skips auth for the grpc reflection and health services
+func authMatcher(ctx context.Context, callMeta interceptors.CallMeta) bool {
+	return !strings.HasPrefix(callMeta.Service, "grpc.reflection.") && !strings.HasPrefix(callMeta.Service, "grpc.health.")
+}
+
+// authMiddleware authenticates calls to services that don't implement AuthFuncOverride
+func (c *Controller) authMiddleware(ctx context.Context) (context.Context, error) {
+	caller, err := c.authenticator.Authenticate(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return auth.ContextWithCaller(ctx, caller), nil
+}
+
+// AuthFuncOverride authenticates the caller and checks it against methodRoles
+func (c *Controller) AuthFuncOverride(ctx context.Context, fullMethodName string) (context.Context, error) {
+	caller, err := c.authenticator.Authenticate(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	roles, ok := methodRoles[fullMethodName]
+	if !ok || !caller.HasAnyRole(roles...) {
+		c.log.Infof("inventory.controller.AuthFuncOverride: %s is not allowed to call %s", caller.Subject, fullMethodName)
+		return nil, status.Error(codes.PermissionDenied, "the caller is not allowed to call this method")
+	}
+
+	return auth.ContextWithCaller(ctx, caller), nil
+}
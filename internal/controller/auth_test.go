@@ -0,0 +1,131 @@
+package controller
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ahmad-khatib0-org/megacommerce-inventory/internal/auth"
+	intModels "github.com/ahmad-khatib0-org/megacommerce-inventory/pkg/models"
+	pb "github.com/ahmad-khatib0-org/megacommerce-proto/gen/go/inventory/v1"
+	"github.com/ahmad-khatib0-org/megacommerce-shared-go/pkg/logger"
+	"github.com/golang-jwt/jwt/v5"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+type serviceTokenClaims struct {
+	Subject  string
+	Roles    []string
+	SellerID string
+}
+
+// newTestAuthenticator trusts a freshly generated ES256 key, and returns it to sign the test tokens
+func newTestAuthenticator(t *testing.T) (*auth.Authenticator, *ecdsa.PrivateKey) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	der, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	file := filepath.Join(t.TempDir(), "jwt.pub")
+	if err := os.WriteFile(file, pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	a, ie := auth.NewAuthenticator(&intModels.Auth{JWTPublicKeyFiles: []string{file}})
+	if ie != nil {
+		t.Fatalf("failed to create the authenticator: %v", ie.Err)
+	}
+	return a, key
+}
+
+func newTestController(t *testing.T, a *auth.Authenticator) *Controller {
+	t.Helper()
+	log, err := logger.InitLogger("test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &Controller{authenticator: a, log: log}
+}
+
+// bearerContext returns an incoming call context that carries a token signed by key
+func bearerContext(t *testing.T, key *ecdsa.PrivateKey, claims serviceTokenClaims) context.Context {
+	t.Helper()
+	token := jwt.NewWithClaims(jwt.SigningMethodES256, jwt.MapClaims{
+		"sub":       claims.Subject,
+		"roles":     claims.Roles,
+		"seller_id": claims.SellerID,
+		"exp":       time.Now().Add(time.Minute).Unix(),
+	})
+	signed, err := token.SignedString(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return metadata.NewIncomingContext(context.Background(), metadata.Pairs("authorization", "Bearer "+signed))
+}
+
+func TestAuthFuncOverride(t *testing.T) {
+	a, key := newTestAuthenticator(t)
+	c := newTestController(t, a)
+
+	orderService := serviceTokenClaims{Subject: "orders", Roles: []string{auth.RoleOrderService}}
+	seller := serviceTokenClaims{Subject: "seller-1", Roles: []string{auth.RoleSeller}, SellerID: "s1"}
+
+	tests := []struct {
+		name   string
+		ctx    context.Context
+		method string
+		code   codes.Code
+	}{
+		{name: "allowed role", ctx: bearerContext(t, key, orderService), method: pb.InventoryService_InventoryReserve_FullMethodName, code: codes.OK},
+		{name: "seller on a seller method", ctx: bearerContext(t, key, seller), method: pb.InventoryService_InventoryTransferGet_FullMethodName, code: codes.OK},
+		{name: "seller on an order service method", ctx: bearerContext(t, key, seller), method: pb.InventoryService_InventoryReserve_FullMethodName, code: codes.PermissionDenied},
+		{name: "method missing from methodRoles", ctx: bearerContext(t, key, orderService), method: "/inventory.v1.InventoryService/Unknown", code: codes.PermissionDenied},
+		{name: "seller token without a seller", ctx: bearerContext(t, key, serviceTokenClaims{Subject: "seller-2", Roles: []string{auth.RoleSeller}}), method: pb.InventoryService_InventoryTransferGet_FullMethodName, code: codes.Unauthenticated},
+		{name: "no credentials", ctx: context.Background(), method: pb.InventoryService_InventoryReserve_FullMethodName, code: codes.Unauthenticated},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, err := c.AuthFuncOverride(tt.ctx, tt.method)
+			if got := status.Code(err); got != tt.code {
+				t.Fatalf("AuthFuncOverride returned %v, want %v", got, tt.code)
+			}
+			if tt.code != codes.OK {
+				return
+			}
+
+			caller, ok := auth.CallerFromContext(ctx)
+			if !ok {
+				t.Fatal("the caller wasn't set on the context")
+			}
+			if caller.Method != auth.MethodJWT || caller.Subject == "" {
+				t.Fatalf("unexpected caller %+v", caller)
+			}
+		})
+	}
+}
+
+func TestNewAuthenticatorRejectsSellerMTLSIdentities(t *testing.T) {
+	_, ie := auth.NewAuthenticator(&intModels.Auth{MTLSIdentities: map[string][]string{"seller-app": {auth.RoleSeller}}})
+	if ie == nil {
+		t.Fatal("expected an mtls identity with the seller role to be rejected, a certificate has no seller id")
+	}
+
+	if _, ie := auth.NewAuthenticator(&intModels.Auth{MTLSIdentities: map[string][]string{"orders": {auth.RoleOrderService}}}); ie != nil {
+		t.Fatalf("failed to create the authenticator: %v", ie.Err)
+	}
+}
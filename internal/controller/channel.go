@@ -0,0 +1,26 @@
+package controller
+
+import (
+	"context"
+	"strings"
+
+	"google.golang.org/grpc/metadata"
+)
+
+// salesChannelHeader is the request metadata that names the sales channel a reservation comes from
+const salesChannelHeader = "x-sales-channel"
+
+// salesChannel returns the sales channel of the request in lower case, or an empty
+// string if the caller didn't send one, in which case the shared pool is used
+func salesChannel(ctx context.Context) string {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return ""
+	}
+
+	values := md.Get(salesChannelHeader)
+	if len(values) == 0 {
+		return ""
+	}
+	return strings.ToLower(strings.TrimSpace(values[0]))
+}
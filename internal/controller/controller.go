@@ -5,44 +5,62 @@ import (
 	"net"
 	"net/http"
 
+	"github.com/ahmad-khatib0-org/megacommerce-inventory/internal/auth"
 	"github.com/ahmad-khatib0-org/megacommerce-inventory/internal/store"
+	intModels "github.com/ahmad-khatib0-org/megacommerce-inventory/pkg/models"
 	common "github.com/ahmad-khatib0-org/megacommerce-proto/gen/go/common/v1"
 	pb "github.com/ahmad-khatib0-org/megacommerce-proto/gen/go/inventory/v1"
 	"github.com/ahmad-khatib0-org/megacommerce-shared-go/pkg/logger"
 	"github.com/ahmad-khatib0-org/megacommerce-shared-go/pkg/models"
 	"github.com/ahmad-khatib0-org/megacommerce-shared-go/pkg/utils"
 	grpcprom "github.com/grpc-ecosystem/go-grpc-middleware/providers/prometheus"
+	grpcAuth "github.com/grpc-ecosystem/go-grpc-middleware/v2/interceptors/auth"
+	"github.com/grpc-ecosystem/go-grpc-middleware/v2/interceptors/recovery"
+	"github.com/grpc-ecosystem/go-grpc-middleware/v2/interceptors/selector"
 	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
 	sdktrace "go.opentelemetry.io/otel/sdk/trace"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
 	"google.golang.org/grpc/reflection"
 )
 
 type Controller struct {
 	pb.UnimplementedInventoryServiceServer
 	config         func() *common.Config
+	cfg            *intModels.Config
 	tracerProvider *sdktrace.TracerProvider
 	metrics        *grpcprom.ServerMetrics
 	log            *logger.Logger
 	http           *http.Client
 	store          store.InventoryDBStore
+	authenticator  *auth.Authenticator
 }
 
 type ControllerArgs struct {
 	Config         func() *common.Config
+	Cfg            *intModels.Config
 	TracerProvider *sdktrace.TracerProvider
 	Metrics        *grpcprom.ServerMetrics
 	Log            *logger.Logger
 	DBStore        store.InventoryDBStore
+	Authenticator  *auth.Authenticator
+	// ServerCreds are the listener transport credentials, nil means plaintext
+	ServerCreds credentials.TransportCredentials
+	// Health is registered as the grpc health service if not nil
+	Health *health.Server
 }
 
 func NewController(ca *ControllerArgs) (*Controller, *models.InternalError) {
 	c := &Controller{
 		config:         ca.Config,
+		cfg:            ca.Cfg,
 		tracerProvider: ca.TracerProvider,
 		metrics:        ca.Metrics,
 		log:            ca.Log,
 		store:          ca.DBStore,
+		authenticator:  ca.Authenticator,
 	}
 
 	c.http = utils.GetHTTPClient()
@@ -55,21 +73,28 @@ func NewController(ca *ControllerArgs) (*Controller, *models.InternalError) {
 	availableLangs := c.config().GetLocalization().GetAvailableLocales()
 	msgSize := c.config().Services.GetInventoryServiceMaxReceiveMessageSizeBytes()
 
-	s := grpc.NewServer(
+	opts := []grpc.ServerOption{
 		grpc.MaxRecvMsgSize(int(msgSize)),
 		grpc.StatsHandler(otelgrpc.NewServerHandler()),
 		grpc.ChainUnaryInterceptor(
+			recovery.UnaryServerInterceptor(recovery.WithRecoveryHandler(c.recoverPanic)),
 			models.ResponseInterceptor(defaultLang, availableLangs),
 			models.UnaryMetadataInterceptor(defaultLang, availableLangs),
 			// c.metrics.UnaryServerInterceptor(grpcprom.WithExemplarFromContext(traceID)),
-			// selector.UnaryServerInterceptor(auth.UnaryServerInterceptor(authMiddleware), selector.MatchFunc(authMatcher)),
+			selector.UnaryServerInterceptor(grpcAuth.UnaryServerInterceptor(c.authMiddleware), selector.MatchFunc(authMatcher)),
 		),
 		grpc.ChainStreamInterceptor(
+			recovery.StreamServerInterceptor(recovery.WithRecoveryHandler(c.recoverPanic)),
 			models.StreamMetadataInterceptor(defaultLang, availableLangs),
 			// c.metrics.StreamServerInterceptor(grpcprom.WithExemplarFromContext(traceID)),
-			// selector.StreamServerInterceptor(auth.StreamServerInterceptor(authMiddleware), selector.MatchFunc(authMatcher)),
+			selector.StreamServerInterceptor(grpcAuth.StreamServerInterceptor(c.authMiddleware), selector.MatchFunc(authMatcher)),
 		),
-	)
+	}
+	if ca.ServerCreds != nil {
+		opts = append(opts, grpc.Creds(ca.ServerCreds))
+	}
+
+	s := grpc.NewServer(opts...)
 
 	addr := c.config().GetServices().GetInventoryServiceGrpcUrl()
 	listener, err := net.Listen("tcp", addr)
@@ -78,6 +103,9 @@ func NewController(ca *ControllerArgs) (*Controller, *models.InternalError) {
 	}
 
 	reflection.Register(s)
+	if ca.Health != nil {
+		healthpb.RegisterHealthServer(s, ca.Health)
+	}
 	pb.RegisterInventoryServiceServer(s, c)
 	// c.metrics.InitializeMetrics(s)
 
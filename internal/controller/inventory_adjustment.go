@@ -0,0 +1,133 @@
+package controller
+
+import (
+	"context"
+	"time"
+
+	"github.com/ahmad-khatib0-org/megacommerce-inventory/internal/auth"
+	intModels "github.com/ahmad-khatib0-org/megacommerce-inventory/pkg/models"
+	pb "github.com/ahmad-khatib0-org/megacommerce-proto/gen/go/inventory/v1"
+	"github.com/ahmad-khatib0-org/megacommerce-shared-go/pkg/models"
+	"github.com/ahmad-khatib0-org/megacommerce-shared-go/pkg/utils"
+	"github.com/jackc/pgx/v5"
+	"google.golang.org/grpc/codes"
+)
+
+// adjustmentDefaultTTL is used if adjustments.approval_ttl_seconds is not configured
+const adjustmentDefaultTTL = 24 * time.Hour
+
+// callerSubject returns the subject of the authenticated caller, or an empty string if there's none
+func callerSubject(ctx context.Context) string {
+	caller, ok := auth.CallerFromContext(ctx)
+	if !ok {
+		return ""
+	}
+	return caller.Subject
+}
+
+// adjustmentRequestNew builds a pending adjustment request for a manual update of an inventory item
+// that would take its on hand units to after, it's requested by the caller of ctx
+func (c *Controller) adjustmentRequestNew(ctx context.Context, inventory *pb.InventoryItem, op pb.InventoryUpdateOperation, quantity uint32, unitCost *int64, after int32, reason *string) *pb.InventoryAdjustmentRequest {
+	ttl := adjustmentDefaultTTL
+	if c.cfg.Adjustments.ApprovalTTLSeconds > 0 {
+		ttl = time.Duration(c.cfg.Adjustments.ApprovalTTLSeconds) * time.Second
+	}
+
+	now := utils.TimeGetMillis()
+	return &pb.InventoryAdjustmentRequest{
+		Id:                  utils.NewID(),
+		RequestNumber:       "adj_" + utils.NewID(),
+		SellerId:            inventory.SellerId,
+		InventoryItemId:     inventory.Id,
+		Operation:           intModels.GetInventoryUpdateOperation(op),
+		Quantity:            int32(quantity),
+		UnitCost:            unitCost,
+		QuantityTotalBefore: inventory.QuantityTotal,
+		QuantityTotalAfter:  after,
+		Reason:              reason,
+		Status:              intModels.GetInventoryAdjustmentStatus(pb.InventoryAdjustmentStatus_INVENTORY_ADJUSTMENT_STATUS_PENDING),
+		RequestedBy:         callerSubject(ctx),
+		ExpiresAt:           now + ttl.Milliseconds(),
+		CreatedAt:           now,
+	}
+}
+
+// adjustmentAudit records a step of the trail of an adjustment request that isn't the rpc that was called,
+// such as the request that an update created, or the expiry of a request that someone tried to review
+func (c *Controller) adjustmentAudit(ctx context.Context, mctx *models.Context, event string, adjustment *pb.InventoryAdjustmentRequest) {
+	ar := models.AuditRecordNew(mctx, event, models.EventStatusFail)
+	ar.AuditEventDataPriorState(intModels.InventoryAdjustmentRequestAuditable(adjustment))
+	ar.Success()
+	c.ProcessAudit(ctx, ar)
+}
+
+// adjustmentGet gets an adjustment request by its number, it's locked if tx is not nil.
+// A seller can only get its own adjustment requests, other requests are reported as not found
+func (c *Controller) adjustmentGet(ctx context.Context, mctx *models.Context, path string, tx pgx.Tx, requestNumber string) (*pb.InventoryAdjustmentRequest, *models.AppError) {
+	adjustment, err := c.store.InventoryAdjustmentRequestGetByNumber(mctx, tx, requestNumber)
+	if err != nil {
+		if err.ErrType == models.DBErrorTypeNoRows {
+			return nil, models.NewAppError(mctx, path, "inventory.adjustment.not_found", nil, err.Details, int(codes.NotFound), &models.AppErrorErrorsArgs{Err: err})
+		}
+		return nil, models.NewAppError(mctx, path, models.ErrMsgInternal, nil, "failed to get the adjustment request", int(codes.Internal), &models.AppErrorErrorsArgs{Err: err})
+	}
+
+	if _, ok := sellerScope(ctx, adjustment.SellerId); !ok {
+		return nil, models.NewAppError(mctx, path, "inventory.adjustment.not_found", nil, "", int(codes.NotFound), nil)
+	}
+
+	return adjustment, nil
+}
+
+// adjustmentReviewable checks that a locked adjustment request can be reviewed by the caller of ctx, it must be
+// pending, and reviewed by someone else than who requested it. A request that is past its expiry is marked as
+// expired, expired is set then so the caller commits that before returning the error
+func (c *Controller) adjustmentReviewable(ctx context.Context, mctx *models.Context, path string, tx pgx.Tx, adjustment *pb.InventoryAdjustmentRequest) (expired bool, appErr *models.AppError) {
+	status := intModels.InventoryAdjustmentStatusAt(adjustment, utils.TimeGetMillis())
+	if status == pb.InventoryAdjustmentStatus_INVENTORY_ADJUSTMENT_STATUS_EXPIRED &&
+		intModels.GetInventoryAdjustmentStatusFromString(adjustment.Status) == pb.InventoryAdjustmentStatus_INVENTORY_ADJUSTMENT_STATUS_PENDING {
+		adjustment.Status = intModels.GetInventoryAdjustmentStatus(status)
+		if err := c.store.InventoryAdjustmentRequestReview(mctx, tx, adjustment.Id, adjustment.Status, nil, nil); err != nil {
+			return false, models.NewAppError(mctx, path, models.ErrMsgInternal, nil, "failed to expire the adjustment request", int(codes.Internal), &models.AppErrorErrorsArgs{Err: err})
+		}
+		return true, models.NewAppError(mctx, path, "inventory.adjustment.expired", nil, "", int(codes.FailedPrecondition), nil)
+	}
+	if status != pb.InventoryAdjustmentStatus_INVENTORY_ADJUSTMENT_STATUS_PENDING {
+		params := map[string]any{"Status": adjustment.Status}
+		return false, models.NewAppError(mctx, path, "inventory.adjustment.invalid_status", params, "", int(codes.FailedPrecondition), nil)
+	}
+
+	// an empty subject can't be told apart from another one, so anonymous callers can't review
+	if subject := callerSubject(ctx); subject == "" || subject == adjustment.RequestedBy {
+		return false, models.NewAppError(mctx, path, "inventory.adjustment.second_approver_required", nil, "", int(codes.PermissionDenied), nil)
+	}
+
+	return false, nil
+}
+
+// adjustmentData converts an adjustment request of an inventory item to the response format
+func adjustmentData(adjustment *pb.InventoryAdjustmentRequest, inventory *pb.InventoryItem, now int64) *pb.InventoryAdjustmentRequestData {
+	data := &pb.InventoryAdjustmentRequestData{
+		RequestNumber:       adjustment.RequestNumber,
+		SellerId:            adjustment.SellerId,
+		Operation:           intModels.GetInventoryUpdateOperationFromString(adjustment.Operation),
+		Quantity:            uint32(adjustment.Quantity),
+		QuantityTotalBefore: uint32(adjustment.QuantityTotalBefore),
+		QuantityTotalAfter:  uint32(adjustment.QuantityTotalAfter),
+		Reason:              adjustment.Reason,
+		Status:              intModels.InventoryAdjustmentStatusAt(adjustment, now),
+		RequestedBy:         adjustment.RequestedBy,
+		ReviewedBy:          adjustment.ReviewedBy,
+		ReviewNote:          adjustment.ReviewNote,
+		ExpiresAt:           adjustment.ExpiresAt,
+		CreatedAt:           adjustment.CreatedAt,
+		UpdatedAt:           adjustment.UpdatedAt,
+	}
+	if inventory != nil {
+		data.ProductId = inventory.ProductId
+		data.VariantId = inventory.VariantId
+		data.Sku = inventory.Sku
+	}
+
+	return data
+}
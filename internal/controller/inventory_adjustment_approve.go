@@ -0,0 +1,110 @@
+package controller
+
+import (
+	"context"
+	"time"
+
+	intModels "github.com/ahmad-khatib0-org/megacommerce-inventory/pkg/models"
+	pb "github.com/ahmad-khatib0-org/megacommerce-proto/gen/go/inventory/v1"
+	"github.com/ahmad-khatib0-org/megacommerce-shared-go/pkg/models"
+	"github.com/ahmad-khatib0-org/megacommerce-shared-go/pkg/utils"
+	"github.com/jackc/pgx/v5"
+	"google.golang.org/grpc/codes"
+)
+
+// InventoryAdjustmentApprove applies a pending adjustment request, it must be approved before it expires and by
+// someone else than who requested it. The update is applied to the stock that the item has now, so a SET
+// sets the requested units while ADD and SUBTRACT change the current units, and the movement it creates
+// references the request and both of the people behind it
+func (c *Controller) InventoryAdjustmentApprove(ctx context.Context, req *pb.InventoryAdjustmentReviewRequest) (*pb.InventoryAdjustmentApproveResponse, error) {
+	path := "inventory.controller.InventoryAdjustmentApprove"
+	modelsCtx, ctxErr := models.ContextGet(ctx)
+	errBuilder := func(e *models.AppError, tx pgx.Tx) (*pb.InventoryAdjustmentApproveResponse, error) {
+		if tx != nil {
+			if rbErr := tx.Rollback(modelsCtx.Context); rbErr != nil {
+				c.log.Errorf("%s: an error rolling back a transaction, err: %s", path, rbErr.Error())
+			}
+		}
+		return &pb.InventoryAdjustmentApproveResponse{Response: &pb.InventoryAdjustmentApproveResponse_Error{Error: models.AppErrorToProto(e)}}, nil
+	}
+
+	if ctxErr != nil {
+		return errBuilder(ctxErr, nil)
+	}
+	internalErr := func(err error, details string, tx pgx.Tx) (*pb.InventoryAdjustmentApproveResponse, error) {
+		return errBuilder(models.NewAppError(modelsCtx, path, models.ErrMsgInternal, nil, details, int(codes.Internal), &models.AppErrorErrorsArgs{Err: err}), tx)
+	}
+	sucBuilder := func(data *pb.InventoryAdjustmentRequestData) (*pb.InventoryAdjustmentApproveResponse, error) {
+		return &pb.InventoryAdjustmentApproveResponse{Response: &pb.InventoryAdjustmentApproveResponse_Data{Data: data}}, nil
+	}
+
+	rctx, cancel := context.WithTimeout(context.Background(), time.Second*12)
+	defer cancel()
+	modelsCtx.Context = rctx
+
+	var adjustment *pb.InventoryAdjustmentRequest
+	ar := models.AuditRecordNew(modelsCtx, intModels.EventNameInventoryAdjustmentApprove, models.EventStatusFail)
+	defer func() {
+		auditable := intModels.InventoryAdjustmentReviewRequestAuditable(req)
+		if adjustment != nil {
+			auditable["adjustment"] = intModels.InventoryAdjustmentRequestAuditable(adjustment)
+		}
+		ar.AuditEventDataPriorState(auditable)
+		c.ProcessAudit(ctx, ar)
+	}()
+
+	tx, err := c.store.GetTx(modelsCtx.Context, pgx.TxOptions{})
+	if err != nil {
+		return internalErr(err, "failed to begin transaction", nil)
+	}
+
+	adjustment, appErr := c.adjustmentGet(ctx, modelsCtx, path, tx, req.GetRequestNumber())
+	if appErr != nil {
+		return errBuilder(appErr, tx)
+	}
+
+	expired, appErr := c.adjustmentReviewable(ctx, modelsCtx, path, tx, adjustment)
+	if expired {
+		if err := tx.Commit(modelsCtx.Context); err != nil {
+			return internalErr(err, "failed to commit transaction", tx)
+		}
+		c.adjustmentAudit(ctx, modelsCtx, intModels.EventNameInventoryAdjustmentExpire, adjustment)
+		return errBuilder(appErr, nil)
+	}
+	if appErr != nil {
+		return errBuilder(appErr, tx)
+	}
+
+	items, err := c.store.InventoryItemsLockByIDs(modelsCtx, tx, []string{adjustment.InventoryItemId})
+	if err != nil {
+		return internalErr(err, "failed to query inventory_items table", tx)
+	}
+	if len(items) == 0 {
+		return errBuilder(models.NewAppError(modelsCtx, path, "error.not_found", nil, "", int(codes.NotFound), nil), tx)
+	}
+	inventory := items[0]
+
+	reviewer := callerSubject(ctx)
+	metadata := map[string]string{
+		"adjustment_request": adjustment.RequestNumber,
+		"requested_by":       adjustment.RequestedBy,
+		"approved_by":        reviewer,
+	}
+	op := intModels.GetInventoryUpdateOperationFromString(adjustment.Operation)
+	if appErr := c.stockUpdate(modelsCtx, path, tx, inventory, op, uint32(adjustment.Quantity), adjustment.RequestNumber, adjustment.UnitCost, adjustment.Reason, metadata); appErr != nil {
+		return errBuilder(appErr, tx)
+	}
+
+	adjustment.Status = intModels.GetInventoryAdjustmentStatus(pb.InventoryAdjustmentStatus_INVENTORY_ADJUSTMENT_STATUS_APPROVED)
+	adjustment.ReviewedBy, adjustment.ReviewNote = &reviewer, req.Note
+	if err := c.store.InventoryAdjustmentRequestReview(modelsCtx, tx, adjustment.Id, adjustment.Status, adjustment.ReviewedBy, adjustment.ReviewNote); err != nil {
+		return internalErr(err, "failed to update the adjustment request", tx)
+	}
+
+	if err := tx.Commit(modelsCtx.Context); err != nil {
+		return internalErr(err, "failed to commit transaction", tx)
+	}
+
+	ar.Success()
+	return sucBuilder(adjustmentData(adjustment, inventory, utils.TimeGetMillis()))
+}
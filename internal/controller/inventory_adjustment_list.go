@@ -0,0 +1,89 @@
+package controller
+
+import (
+	"context"
+	"time"
+
+	intModels "github.com/ahmad-khatib0-org/megacommerce-inventory/pkg/models"
+	pb "github.com/ahmad-khatib0-org/megacommerce-proto/gen/go/inventory/v1"
+	"github.com/ahmad-khatib0-org/megacommerce-shared-go/pkg/models"
+	"github.com/ahmad-khatib0-org/megacommerce-shared-go/pkg/utils"
+	"google.golang.org/grpc/codes"
+)
+
+const (
+	adjustmentListDefaultPageSize = 50
+	adjustmentListMaxPageSize     = 100
+)
+
+// InventoryAdjustmentList lists the adjustment requests newest first, optionally by status. The status is the one
+// they have now, a pending request that is past its expiry is listed as expired even before it's marked as such
+func (c *Controller) InventoryAdjustmentList(ctx context.Context, req *pb.InventoryAdjustmentListRequest) (*pb.InventoryAdjustmentListResponse, error) {
+	path := "inventory.controller.InventoryAdjustmentList"
+	errBuilder := func(e *models.AppError) (*pb.InventoryAdjustmentListResponse, error) {
+		return &pb.InventoryAdjustmentListResponse{Response: &pb.InventoryAdjustmentListResponse_Error{Error: models.AppErrorToProto(e)}}, nil
+	}
+
+	modelsCtx, ctxErr := models.ContextGet(ctx)
+	if ctxErr != nil {
+		return errBuilder(ctxErr)
+	}
+	internalErr := func(err error, details string) (*pb.InventoryAdjustmentListResponse, error) {
+		return errBuilder(models.NewAppError(modelsCtx, path, models.ErrMsgInternal, nil, details, int(codes.Internal), &models.AppErrorErrorsArgs{Err: err}))
+	}
+
+	rctx, cancel := context.WithTimeout(context.Background(), time.Second*12)
+	defer cancel()
+	modelsCtx.Context = rctx
+
+	sellerID, ok := sellerScope(ctx, req.GetSellerId())
+	if !ok {
+		return errBuilder(models.NewAppError(modelsCtx, path, "inventory.seller.forbidden", nil, "", int(codes.PermissionDenied), nil))
+	}
+
+	pageSize := int(req.GetPageSize())
+	if pageSize == 0 {
+		pageSize = adjustmentListDefaultPageSize
+	}
+	pageSize = min(pageSize, adjustmentListMaxPageSize)
+
+	// expired requests may still be stored as pending, so both are queried and told apart by their expiry
+	statuses := []string{}
+	switch status := req.GetStatus(); status {
+	case pb.InventoryAdjustmentStatus_INVENTORY_ADJUSTMENT_STATUS_UNSPECIFIED:
+	case pb.InventoryAdjustmentStatus_INVENTORY_ADJUSTMENT_STATUS_EXPIRED:
+		statuses = append(statuses, intModels.GetInventoryAdjustmentStatus(pb.InventoryAdjustmentStatus_INVENTORY_ADJUSTMENT_STATUS_PENDING), intModels.GetInventoryAdjustmentStatus(status))
+	default:
+		statuses = append(statuses, intModels.GetInventoryAdjustmentStatus(status))
+	}
+
+	adjustments, err := c.store.InventoryAdjustmentRequestsList(modelsCtx, sellerID, statuses, pageSize)
+	if err != nil {
+		return internalErr(err, "failed to query inventory_adjustment_requests table")
+	}
+
+	ids := make([]string, 0, len(adjustments))
+	for _, adjustment := range adjustments {
+		ids = append(ids, adjustment.InventoryItemId)
+	}
+	items, err := c.store.InventoryItemGetByIDs(modelsCtx, sellerID, ids)
+	if err != nil {
+		return internalErr(err, "failed to query inventory_items table")
+	}
+	byID := make(map[string]*pb.InventoryItem, len(items))
+	for _, item := range items {
+		byID[item.Id] = item
+	}
+
+	now := utils.TimeGetMillis()
+	data := &pb.InventoryAdjustmentListResponseData{Adjustments: make([]*pb.InventoryAdjustmentRequestData, 0, len(adjustments))}
+	for _, adjustment := range adjustments {
+		if req.GetStatus() != pb.InventoryAdjustmentStatus_INVENTORY_ADJUSTMENT_STATUS_UNSPECIFIED &&
+			intModels.InventoryAdjustmentStatusAt(adjustment, now) != req.GetStatus() {
+			continue
+		}
+		data.Adjustments = append(data.Adjustments, adjustmentData(adjustment, byID[adjustment.InventoryItemId], now))
+	}
+
+	return &pb.InventoryAdjustmentListResponse{Response: &pb.InventoryAdjustmentListResponse_Data{Data: data}}, nil
+}
@@ -0,0 +1,98 @@
+package controller
+
+import (
+	"context"
+	"time"
+
+	intModels "github.com/ahmad-khatib0-org/megacommerce-inventory/pkg/models"
+	pb "github.com/ahmad-khatib0-org/megacommerce-proto/gen/go/inventory/v1"
+	"github.com/ahmad-khatib0-org/megacommerce-shared-go/pkg/models"
+	"github.com/ahmad-khatib0-org/megacommerce-shared-go/pkg/utils"
+	"github.com/jackc/pgx/v5"
+	"google.golang.org/grpc/codes"
+)
+
+// InventoryAdjustmentReject rejects a pending adjustment request, so the stock it would have changed is left as it is.
+// Like an approval it must be done before the request expires and by someone else than who requested it
+func (c *Controller) InventoryAdjustmentReject(ctx context.Context, req *pb.InventoryAdjustmentReviewRequest) (*pb.InventoryAdjustmentRejectResponse, error) {
+	path := "inventory.controller.InventoryAdjustmentReject"
+	modelsCtx, ctxErr := models.ContextGet(ctx)
+	errBuilder := func(e *models.AppError, tx pgx.Tx) (*pb.InventoryAdjustmentRejectResponse, error) {
+		if tx != nil {
+			if rbErr := tx.Rollback(modelsCtx.Context); rbErr != nil {
+				c.log.Errorf("%s: an error rolling back a transaction, err: %s", path, rbErr.Error())
+			}
+		}
+		return &pb.InventoryAdjustmentRejectResponse{Response: &pb.InventoryAdjustmentRejectResponse_Error{Error: models.AppErrorToProto(e)}}, nil
+	}
+
+	if ctxErr != nil {
+		return errBuilder(ctxErr, nil)
+	}
+	internalErr := func(err error, details string, tx pgx.Tx) (*pb.InventoryAdjustmentRejectResponse, error) {
+		return errBuilder(models.NewAppError(modelsCtx, path, models.ErrMsgInternal, nil, details, int(codes.Internal), &models.AppErrorErrorsArgs{Err: err}), tx)
+	}
+	sucBuilder := func(data *pb.InventoryAdjustmentRequestData) (*pb.InventoryAdjustmentRejectResponse, error) {
+		return &pb.InventoryAdjustmentRejectResponse{Response: &pb.InventoryAdjustmentRejectResponse_Data{Data: data}}, nil
+	}
+
+	rctx, cancel := context.WithTimeout(context.Background(), time.Second*12)
+	defer cancel()
+	modelsCtx.Context = rctx
+
+	var adjustment *pb.InventoryAdjustmentRequest
+	ar := models.AuditRecordNew(modelsCtx, intModels.EventNameInventoryAdjustmentReject, models.EventStatusFail)
+	defer func() {
+		auditable := intModels.InventoryAdjustmentReviewRequestAuditable(req)
+		if adjustment != nil {
+			auditable["adjustment"] = intModels.InventoryAdjustmentRequestAuditable(adjustment)
+		}
+		ar.AuditEventDataPriorState(auditable)
+		c.ProcessAudit(ctx, ar)
+	}()
+
+	tx, err := c.store.GetTx(modelsCtx.Context, pgx.TxOptions{})
+	if err != nil {
+		return internalErr(err, "failed to begin transaction", nil)
+	}
+
+	adjustment, appErr := c.adjustmentGet(ctx, modelsCtx, path, tx, req.GetRequestNumber())
+	if appErr != nil {
+		return errBuilder(appErr, tx)
+	}
+
+	expired, appErr := c.adjustmentReviewable(ctx, modelsCtx, path, tx, adjustment)
+	if expired {
+		if err := tx.Commit(modelsCtx.Context); err != nil {
+			return internalErr(err, "failed to commit transaction", tx)
+		}
+		c.adjustmentAudit(ctx, modelsCtx, intModels.EventNameInventoryAdjustmentExpire, adjustment)
+		return errBuilder(appErr, nil)
+	}
+	if appErr != nil {
+		return errBuilder(appErr, tx)
+	}
+
+	items, err := c.store.InventoryItemGetByIDs(modelsCtx, adjustment.SellerId, []string{adjustment.InventoryItemId})
+	if err != nil {
+		return internalErr(err, "failed to query inventory_items table", tx)
+	}
+	var inventory *pb.InventoryItem
+	if len(items) > 0 {
+		inventory = items[0]
+	}
+
+	reviewer := callerSubject(ctx)
+	adjustment.Status = intModels.GetInventoryAdjustmentStatus(pb.InventoryAdjustmentStatus_INVENTORY_ADJUSTMENT_STATUS_REJECTED)
+	adjustment.ReviewedBy, adjustment.ReviewNote = &reviewer, req.Note
+	if err := c.store.InventoryAdjustmentRequestReview(modelsCtx, tx, adjustment.Id, adjustment.Status, adjustment.ReviewedBy, adjustment.ReviewNote); err != nil {
+		return internalErr(err, "failed to update the adjustment request", tx)
+	}
+
+	if err := tx.Commit(modelsCtx.Context); err != nil {
+		return internalErr(err, "failed to commit transaction", tx)
+	}
+
+	ar.Success()
+	return sucBuilder(adjustmentData(adjustment, inventory, utils.TimeGetMillis()))
+}
@@ -0,0 +1,96 @@
+package controller
+
+import (
+	intModels "github.com/ahmad-khatib0-org/megacommerce-inventory/pkg/models"
+	pb "github.com/ahmad-khatib0-org/megacommerce-proto/gen/go/inventory/v1"
+	"github.com/ahmad-khatib0-org/megacommerce-shared-go/pkg/models"
+	"github.com/jackc/pgx/v5"
+)
+
+// inventoryAllocation is the safety stock and channel allocation rules of an inventory item,
+// along with the units that the active reservations of every channel hold
+type inventoryAllocation struct {
+	safetyStock int32
+	rules       []*pb.InventoryChannelAllocation
+	reserved    map[string]int32
+}
+
+// allocationGet gets the allocation of an inventory item, an item without an allocation policy has no
+// safety stock, and the reserved units are only summed if the item has channel rules, since they don't
+// matter otherwise. You can pass nil for the tx argument, and normal db queries will be used
+func (c *Controller) allocationGet(mctx *models.Context, tx pgx.Tx, inventoryItemID string) (*inventoryAllocation, *models.DBError) {
+	allocation := &inventoryAllocation{}
+
+	policy, err := c.store.InventoryAllocationPolicyGet(mctx, tx, inventoryItemID)
+	if err != nil && err.ErrType != models.DBErrorTypeNoRows {
+		return nil, err
+	}
+	if err == nil {
+		allocation.safetyStock = policy.SafetyStock
+	}
+
+	allocation.rules, err = c.store.InventoryChannelAllocationsGet(mctx, tx, inventoryItemID)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(allocation.rules) > 0 {
+		allocation.reserved, err = c.store.InventoryReservationItemsReservedByChannel(mctx, tx, inventoryItemID)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return allocation, nil
+}
+
+// stockSellable returns how many more units of a locked inventory item a reservation from channel can take,
+// the units of an item with lots are also limited by the lots that aren't expired
+func (c *Controller) stockSellable(mctx *models.Context, tx pgx.Tx, inventory *pb.InventoryItem, channel string) (int32, *models.DBError) {
+	allocation, err := c.allocationGet(mctx, tx, inventory.Id)
+	if err != nil {
+		return 0, err
+	}
+	sellable := intModels.InventoryChannelSellable(inventory, allocation.safetyStock, allocation.rules, allocation.reserved, channel)
+
+	lots, tracked, err := c.lotsSellable(mctx, tx, inventory.Id)
+	if err != nil {
+		return 0, err
+	}
+	if tracked {
+		sellable = min(sellable, lots)
+	}
+
+	return sellable, nil
+}
+
+// allocationData converts the allocation of an inventory item to the response format, with the pool
+// and the sellable units of every channel that has a rule, and of the shared pool
+func allocationData(inventory *pb.InventoryItem, allocation *inventoryAllocation) *pb.InventoryAllocationData {
+	pools, shared := intModels.InventoryChannelPools(inventory.QuantityTotal, allocation.safetyStock, allocation.rules)
+
+	data := &pb.InventoryAllocationData{
+		ProductId:      inventory.ProductId,
+		VariantId:      inventory.VariantId,
+		Sku:            inventory.Sku,
+		SafetyStock:    uint32(allocation.safetyStock),
+		QuantityShared: uint32(shared),
+		QuantitySharedSellable: uint32(intModels.InventoryChannelSellable(
+			inventory, allocation.safetyStock, allocation.rules, allocation.reserved, "",
+		)),
+	}
+	for _, rule := range allocation.rules {
+		data.Channels = append(data.Channels, &pb.InventoryChannelAllocationData{
+			Channel:           rule.Channel,
+			AllocationType:    intModels.GetInventoryChannelAllocationTypeFromString(rule.AllocationType),
+			Value:             uint32(rule.Value),
+			QuantityAllocated: uint32(pools[rule.Channel]),
+			QuantityReserved:  uint32(allocation.reserved[rule.Channel]),
+			QuantitySellable: uint32(intModels.InventoryChannelSellable(
+				inventory, allocation.safetyStock, allocation.rules, allocation.reserved, rule.Channel,
+			)),
+		})
+	}
+
+	return data
+}
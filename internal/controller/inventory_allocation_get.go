@@ -0,0 +1,57 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	pb "github.com/ahmad-khatib0-org/megacommerce-proto/gen/go/inventory/v1"
+	"github.com/ahmad-khatib0-org/megacommerce-shared-go/pkg/models"
+	"google.golang.org/grpc/codes"
+)
+
+// InventoryAllocationGet gets the safety stock and channel allocation rules of an item, along
+// with the pool, reserved and sellable units of every channel and of the shared pool
+func (c *Controller) InventoryAllocationGet(ctx context.Context, req *pb.InventoryAllocationGetRequest) (*pb.InventoryAllocationGetResponse, error) {
+	path := "inventory.controller.InventoryAllocationGet"
+	errBuilder := func(e *models.AppError) (*pb.InventoryAllocationGetResponse, error) {
+		return &pb.InventoryAllocationGetResponse{Response: &pb.InventoryAllocationGetResponse_Error{Error: models.AppErrorToProto(e)}}, nil
+	}
+
+	modelsCtx, ctxErr := models.ContextGet(ctx)
+	if ctxErr != nil {
+		return errBuilder(ctxErr)
+	}
+	internalErr := func(err error, details string) (*pb.InventoryAllocationGetResponse, error) {
+		return errBuilder(models.NewAppError(modelsCtx, path, models.ErrMsgInternal, nil, details, int(codes.Internal), &models.AppErrorErrorsArgs{Err: err}))
+	}
+
+	rctx, cancel := context.WithTimeout(context.Background(), time.Second*12)
+	defer cancel()
+	modelsCtx.Context = rctx
+
+	sellerID, ok := sellerScope(ctx, req.GetSellerId())
+	if !ok {
+		return errBuilder(models.NewAppError(modelsCtx, path, "inventory.seller.forbidden", nil, "", int(codes.PermissionDenied), nil))
+	}
+
+	inventory, err := c.store.InventoryItemGetByProductVariant(modelsCtx, nil, sellerID, req.GetProductId(), req.GetVariantId(), req.GetLocationId())
+	if err != nil {
+		if appErr := itemAmbiguous(modelsCtx, path, err, fmt.Sprintf("%s.%s", req.GetProductId(), req.GetVariantId())); appErr != nil {
+			return errBuilder(appErr)
+		}
+		if err.ErrType == models.DBErrorTypeNoRows {
+			key := fmt.Sprintf("%s.%s", req.GetProductId(), req.GetVariantId())
+			ei := map[string]*models.AppErrorError{key: {ID: "orders.items.not_found_in_inventory"}}
+			return errBuilder(models.NewAppError(modelsCtx, path, "error.not_found", nil, "", int(codes.NotFound), &models.AppErrorErrorsArgs{Err: err, ErrorsInternal: ei}))
+		}
+		return internalErr(err, "failed to query inventory_items table")
+	}
+
+	allocation, err := c.allocationGet(modelsCtx, nil, inventory.Id)
+	if err != nil {
+		return internalErr(err, "failed to get the allocation of the inventory item")
+	}
+
+	return &pb.InventoryAllocationGetResponse{Response: &pb.InventoryAllocationGetResponse_Data{Data: allocationData(inventory, allocation)}}, nil
+}
@@ -0,0 +1,146 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	intModels "github.com/ahmad-khatib0-org/megacommerce-inventory/pkg/models"
+	pb "github.com/ahmad-khatib0-org/megacommerce-proto/gen/go/inventory/v1"
+	"github.com/ahmad-khatib0-org/megacommerce-shared-go/pkg/models"
+	"github.com/ahmad-khatib0-org/megacommerce-shared-go/pkg/utils"
+	"github.com/jackc/pgx/v5"
+	"google.golang.org/grpc/codes"
+)
+
+// InventoryAllocationSet sets the safety stock of an item, the units that are never sellable, and replaces
+// its channel allocation rules. A rule gives a sales channel a pool of a fixed number of units, or a percentage
+// of the units above the safety stock, and reservations from that channel only consume its pool, while the
+// channels without a rule share what the rules leave. Reservations that already hold units keep them
+func (c *Controller) InventoryAllocationSet(ctx context.Context, req *pb.InventoryAllocationSetRequest) (*pb.InventoryAllocationSetResponse, error) {
+	path := "inventory.controller.InventoryAllocationSet"
+	modelsCtx, ctxErr := models.ContextGet(ctx)
+	errBuilder := func(e *models.AppError, tx pgx.Tx) (*pb.InventoryAllocationSetResponse, error) {
+		if tx != nil {
+			if rbErr := tx.Rollback(modelsCtx.Context); rbErr != nil {
+				c.log.Errorf("%s: an error rolling back a transaction, err: %s", path, rbErr.Error())
+			}
+		}
+		return &pb.InventoryAllocationSetResponse{Response: &pb.InventoryAllocationSetResponse_Error{Error: models.AppErrorToProto(e)}}, nil
+	}
+
+	if ctxErr != nil {
+		return errBuilder(ctxErr, nil)
+	}
+	internalErr := func(err error, details string, tx pgx.Tx) (*pb.InventoryAllocationSetResponse, error) {
+		return errBuilder(models.NewAppError(modelsCtx, path, models.ErrMsgInternal, nil, details, int(codes.Internal), &models.AppErrorErrorsArgs{Err: err}), tx)
+	}
+	invalidChannel := func(channel string, id string) (*pb.InventoryAllocationSetResponse, error) {
+		ei := map[string]*models.AppErrorError{channel: {ID: id}}
+		return errBuilder(models.NewAppError(modelsCtx, path, id, nil, "", int(codes.InvalidArgument), &models.AppErrorErrorsArgs{ErrorsInternal: ei}), nil)
+	}
+	sucBuilder := func(data *pb.InventoryAllocationData) (*pb.InventoryAllocationSetResponse, error) {
+		return &pb.InventoryAllocationSetResponse{Response: &pb.InventoryAllocationSetResponse_Data{Data: data}}, nil
+	}
+
+	rctx, cancel := context.WithTimeout(context.Background(), time.Second*12)
+	defer cancel()
+	modelsCtx.Context = rctx
+
+	ar := models.AuditRecordNew(modelsCtx, intModels.EventNameInventoryAllocationSet, models.EventStatusFail)
+	defer func() {
+		ar.AuditEventDataPriorState(intModels.InventoryAllocationSetRequestAuditable(req))
+		c.ProcessAudit(ctx, ar)
+	}()
+
+	sellerID, ok := sellerScope(ctx, req.GetSellerId())
+	if !ok {
+		return errBuilder(models.NewAppError(modelsCtx, path, "inventory.seller.forbidden", nil, "", int(codes.PermissionDenied), nil), nil)
+	}
+
+	// channels are matched in lower case, like the channel of the requests
+	percent := uint32(0)
+	seen := make(map[string]bool, len(req.GetChannels()))
+	for _, ch := range req.GetChannels() {
+		channel := strings.ToLower(strings.TrimSpace(ch.GetChannel()))
+		if channel == "" {
+			return invalidChannel(ch.GetChannel(), "inventory.allocation.channel_required")
+		}
+		if seen[channel] {
+			return invalidChannel(channel, "inventory.allocation.duplicate_channel")
+		}
+		seen[channel] = true
+
+		switch ch.GetAllocationType() {
+		case pb.InventoryChannelAllocationType_INVENTORY_CHANNEL_ALLOCATION_TYPE_FIXED:
+		case pb.InventoryChannelAllocationType_INVENTORY_CHANNEL_ALLOCATION_TYPE_PERCENT:
+			if ch.GetValue() == 0 || ch.GetValue() > 100 {
+				return invalidChannel(channel, "inventory.allocation.invalid_percent")
+			}
+			percent += ch.GetValue()
+		default:
+			return invalidChannel(channel, "inventory.allocation.invalid_type")
+		}
+	}
+	if percent > 100 {
+		return errBuilder(models.NewAppError(modelsCtx, path, "inventory.allocation.percent_exceeds_total", nil, "", int(codes.InvalidArgument), nil), nil)
+	}
+
+	tx, err := c.store.GetTx(modelsCtx.Context, pgx.TxOptions{})
+	if err != nil {
+		return internalErr(err, "failed to begin transaction", nil)
+	}
+
+	inventory, err := c.store.InventoryItemGetByProductVariant(modelsCtx, tx, sellerID, req.GetProductId(), req.GetVariantId(), req.GetLocationId())
+	if err != nil {
+		if appErr := itemAmbiguous(modelsCtx, path, err, fmt.Sprintf("%s.%s", req.GetProductId(), req.GetVariantId())); appErr != nil {
+			return errBuilder(appErr, tx)
+		}
+		if err.ErrType == models.DBErrorTypeNoRows {
+			key := fmt.Sprintf("%s.%s", req.GetProductId(), req.GetVariantId())
+			ei := map[string]*models.AppErrorError{key: {ID: "orders.items.not_found_in_inventory"}}
+			return errBuilder(models.NewAppError(modelsCtx, path, "error.not_found", nil, "", int(codes.NotFound), &models.AppErrorErrorsArgs{Err: err, ErrorsInternal: ei}), tx)
+		}
+		return internalErr(err, "failed to query inventory_items table", tx)
+	}
+
+	now := utils.TimeGetMillis()
+	err = c.store.InventoryAllocationPolicyUpsert(modelsCtx, tx, &pb.InventoryAllocationPolicy{
+		InventoryItemId: inventory.Id,
+		SafetyStock:     int32(req.GetSafetyStock()),
+		CreatedAt:       now,
+	})
+	if err != nil {
+		return internalErr(err, "failed to set the allocation policy", tx)
+	}
+
+	if err := c.store.InventoryChannelAllocationsDelete(modelsCtx, tx, inventory.Id); err != nil {
+		return internalErr(err, "failed to remove the channel allocations", tx)
+	}
+	for _, ch := range req.GetChannels() {
+		err := c.store.InventoryChannelAllocationCreate(modelsCtx, tx, &pb.InventoryChannelAllocation{
+			Id:              utils.NewID(),
+			InventoryItemId: inventory.Id,
+			Channel:         strings.ToLower(strings.TrimSpace(ch.GetChannel())),
+			AllocationType:  intModels.GetInventoryChannelAllocationType(ch.GetAllocationType()),
+			Value:           int32(ch.GetValue()),
+			CreatedAt:       now,
+		})
+		if err != nil {
+			return internalErr(err, "failed to create a channel allocation", tx)
+		}
+	}
+
+	allocation, err := c.allocationGet(modelsCtx, tx, inventory.Id)
+	if err != nil {
+		return internalErr(err, "failed to get the allocation of the inventory item", tx)
+	}
+
+	if err := tx.Commit(modelsCtx.Context); err != nil {
+		return internalErr(err, "failed to commit transaction", tx)
+	}
+
+	ar.Success()
+	return sucBuilder(allocationData(inventory, allocation))
+}
@@ -0,0 +1,110 @@
+package controller
+
+import (
+	"context"
+	"time"
+
+	pb "github.com/ahmad-khatib0-org/megacommerce-proto/gen/go/inventory/v1"
+	"github.com/ahmad-khatib0-org/megacommerce-shared-go/pkg/models"
+	"github.com/ahmad-khatib0-org/megacommerce-shared-go/pkg/utils"
+	"google.golang.org/grpc/codes"
+)
+
+// InventoryAvailableToPromise tells how many units of a sku can be promised now and at every date that
+// incoming stock of open purchase orders is expected, at a single location or across all locations.
+// If a quantity is given, available_at is the earliest time that quantity can be promised
+func (c *Controller) InventoryAvailableToPromise(ctx context.Context, req *pb.InventoryAvailableToPromiseRequest) (*pb.InventoryAvailableToPromiseResponse, error) {
+	path := "inventory.controller.InventoryAvailableToPromise"
+	errBuilder := func(e *models.AppError) (*pb.InventoryAvailableToPromiseResponse, error) {
+		return &pb.InventoryAvailableToPromiseResponse{Response: &pb.InventoryAvailableToPromiseResponse_Error{Error: models.AppErrorToProto(e)}}, nil
+	}
+
+	modelsCtx, ctxErr := models.ContextGet(ctx)
+	if ctxErr != nil {
+		return errBuilder(ctxErr)
+	}
+	internalErr := func(err error, details string) (*pb.InventoryAvailableToPromiseResponse, error) {
+		return errBuilder(models.NewAppError(modelsCtx, path, models.ErrMsgInternal, nil, details, int(codes.Internal), &models.AppErrorErrorsArgs{Err: err}))
+	}
+	sucBuilder := func(data *pb.InventoryAvailableToPromiseResponseData) (*pb.InventoryAvailableToPromiseResponse, error) {
+		return &pb.InventoryAvailableToPromiseResponse{Response: &pb.InventoryAvailableToPromiseResponse_Data{Data: data}}, nil
+	}
+
+	rctx, cancel := context.WithTimeout(context.Background(), time.Second*12)
+	defer cancel()
+	modelsCtx.Context = rctx
+
+	sellerID, ok := sellerScope(ctx, req.GetSellerId())
+	if !ok {
+		return errBuilder(models.NewAppError(modelsCtx, path, "inventory.seller.forbidden", nil, "", int(codes.PermissionDenied), nil))
+	}
+	if req.GetSku() == "" {
+		return errBuilder(models.NewAppError(modelsCtx, path, "inventory.available_to_promise.sku_required", nil, "", int(codes.InvalidArgument), nil))
+	}
+
+	items, err := c.store.InventoryItemsGetBySku(modelsCtx, sellerID, req.GetSku(), req.GetLocationId())
+	if err != nil {
+		return internalErr(err, "failed to query inventory_items table")
+	}
+	if len(items) == 0 {
+		return errBuilder(models.NewAppError(modelsCtx, path, "inventory.available_to_promise.sku_not_found", nil, "", int(codes.NotFound), nil))
+	}
+
+	incoming, err := c.store.InventoryPurchaseOrderIncoming(modelsCtx, sellerID, req.GetSku(), req.GetLocationId())
+	if err != nil {
+		return internalErr(err, "failed to query inventory_purchase_order_items table")
+	}
+
+	data := &pb.InventoryAvailableToPromiseResponseData{Sku: req.GetSku(), LocationId: req.LocationId}
+	for _, item := range items {
+		data.QuantityOnHand += uint32(item.QuantityTotal)
+		data.QuantityReserved += uint32(item.QuantityReserved)
+		data.QuantityAvailable += uint32(item.QuantityAvailable)
+
+		policy, err := c.store.InventoryBackorderPolicyGet(modelsCtx, nil, item.Id)
+		if err != nil && err.ErrType != models.DBErrorTypeNoRows {
+			return internalErr(err, "failed to get the backorder policy")
+		}
+		if err == nil {
+			data.QuantityBackordered += uint32(policy.QuantityBackordered)
+		}
+
+		allocationPolicy, err := c.store.InventoryAllocationPolicyGet(modelsCtx, nil, item.Id)
+		if err != nil && err.ErrType != models.DBErrorTypeNoRows {
+			return internalErr(err, "failed to get the allocation policy")
+		}
+		if err == nil {
+			data.QuantitySafetyStock += uint32(allocationPolicy.SafetyStock)
+		}
+	}
+
+	// safety stock is never promised, and backorders are served first, so they consume the incoming units before
+	// anything else can be promised. Units that are overdue are still expected, they are promised from now on
+	now := utils.TimeGetMillis()
+	promisable := int64(data.QuantityAvailable) - int64(data.QuantitySafetyStock) - int64(data.QuantityBackordered)
+	if req.Quantity != nil && promisable >= int64(req.GetQuantity()) {
+		data.AvailableAt = &now
+	}
+	for _, in := range incoming {
+		expectedAt := max(in.ExpectedAt, now)
+		promisable += int64(in.Quantity)
+		data.QuantityIncoming += uint32(in.Quantity)
+
+		if n := len(data.Timeline); n > 0 && data.Timeline[n-1].Date == expectedAt {
+			data.Timeline[n-1].QuantityIncoming += uint32(in.Quantity)
+			data.Timeline[n-1].QuantityAvailableToPromise = uint32(max(promisable, 0))
+		} else {
+			data.Timeline = append(data.Timeline, &pb.InventoryAvailableToPromiseEntry{
+				Date:                       expectedAt,
+				QuantityIncoming:           uint32(in.Quantity),
+				QuantityAvailableToPromise: uint32(max(promisable, 0)),
+			})
+		}
+
+		if req.Quantity != nil && data.AvailableAt == nil && promisable >= int64(req.GetQuantity()) {
+			data.AvailableAt = &expectedAt
+		}
+	}
+
+	return sucBuilder(data)
+}
@@ -0,0 +1,179 @@
+package controller
+
+import (
+	"fmt"
+
+	intModels "github.com/ahmad-khatib0-org/megacommerce-inventory/pkg/models"
+	pb "github.com/ahmad-khatib0-org/megacommerce-proto/gen/go/inventory/v1"
+	"github.com/ahmad-khatib0-org/megacommerce-shared-go/pkg/models"
+	"github.com/ahmad-khatib0-org/megacommerce-shared-go/pkg/utils"
+	"github.com/jackc/pgx/v5"
+	"google.golang.org/grpc/codes"
+)
+
+// stockReserve reserves quantity more units of a locked inventory item for a reservation line of channel that
+// already holds current units, only the units that are sellable to the channel can be reserved, and what they
+// can't cover is backordered if the backorder policy of the item allows it. An item that a cycle count froze
+// can't be reserved, key identifies the line in the errors. The units are reserved in whole steps, such as the
+// cases of a line that is ordered by the case, and the most the line can get is reported in steps too
+func (c *Controller) stockReserve(mctx *models.Context, path string, tx pgx.Tx, inventory *pb.InventoryItem, channel string, key string, current int32, quantity int32, step int32) (reserved int32, backordered int32, appErr *models.AppError) {
+	if appErr := c.stockFrozen(mctx, path, tx, inventory.Id, key); appErr != nil {
+		return 0, 0, appErr
+	}
+
+	sellable, err := c.stockSellable(mctx, tx, inventory, channel)
+	if err != nil {
+		return 0, 0, models.NewAppError(mctx, path, models.ErrMsgInternal, nil, "failed to get the allocation of the inventory item", int(codes.Internal), &models.AppErrorErrorsArgs{Err: err})
+	}
+
+	reserved = min(quantity, sellable)
+	reserved -= reserved % step
+	backordered = quantity - reserved
+
+	if backordered > 0 {
+		policy, err := c.store.InventoryBackorderPolicyGet(mctx, tx, inventory.Id)
+		if err != nil && err.ErrType != models.DBErrorTypeNoRows {
+			return 0, 0, models.NewAppError(mctx, path, models.ErrMsgInternal, nil, "failed to get the backorder policy", int(codes.Internal), &models.AppErrorErrorsArgs{Err: err})
+		}
+
+		backorderable, unlimited := intModels.InventoryBackorderable(policy, utils.TimeGetMillis())
+		if !unlimited && backorderable < backordered {
+			return 0, 0, stockUnavailable(mctx, path, key, (current+reserved+backorderable)/step)
+		}
+
+		added, err := c.store.InventoryBackorderPolicyBackorderedAdd(mctx, tx, inventory.Id, backordered)
+		if err != nil {
+			return 0, 0, models.NewAppError(mctx, path, models.ErrMsgInternal, nil, "failed to backorder inventory", int(codes.Internal), &models.AppErrorErrorsArgs{Err: err})
+		}
+		if !added {
+			return 0, 0, stockUnavailable(mctx, path, key, (current+reserved+backorderable)/step)
+		}
+	}
+
+	if reserved > 0 {
+		ok, err := c.store.InventoryItemReserve(mctx, tx, inventory.Id, int(reserved))
+		if err != nil {
+			return 0, 0, models.NewAppError(mctx, path, models.ErrMsgInternal, nil, "failed to reserve inventory", int(codes.Internal), &models.AppErrorErrorsArgs{Err: err})
+		}
+		if !ok {
+			return 0, 0, stockUnavailable(mctx, path, key, current/step)
+		}
+	}
+
+	return reserved, backordered, nil
+}
+
+// stockUnavailable is returned when a line can't get the requested quantity, quantity is the most it can get
+func stockUnavailable(mctx *models.Context, path string, key string, quantity int32) *models.AppError {
+	if quantity <= 0 {
+		ei := map[string]*models.AppErrorError{key: {ID: "orders.items.out_of_stock_for_variant"}}
+		return models.NewAppError(mctx, path, "orders.items.out_of_stock", nil, "", int(codes.Aborted), &models.AppErrorErrorsArgs{ErrorsInternal: ei})
+	}
+
+	ei := map[string]*models.AppErrorError{key: {ID: "orders.items.only_some_available", Params: map[string]any{"Quantity": uint32(quantity)}}}
+	return models.NewAppError(mctx, path, "orders.items.partially_available", nil, "", int(codes.Aborted), &models.AppErrorErrorsArgs{ErrorsInternal: ei})
+}
+
+// backorderAllocate hands the available units of a locked inventory item that aren't safety stock (nor in expired
+// lots) to the lines that wait for its backordered units, first come first served, available is the
+// quantity_available of the item. Each allocation is recorded as a RESERVATION movement per lot that
+// references the reservation of the line
+func (c *Controller) backorderAllocate(mctx *models.Context, tx pgx.Tx, inventoryItemID string, available int32) error {
+	if available <= 0 {
+		return nil
+	}
+
+	policy, err := c.store.InventoryBackorderPolicyGet(mctx, tx, inventoryItemID)
+	if err != nil {
+		if err.ErrType == models.DBErrorTypeNoRows {
+			return nil
+		}
+		return err
+	}
+	if policy.QuantityBackordered == 0 {
+		return nil
+	}
+
+	allocationPolicy, err := c.store.InventoryAllocationPolicyGet(mctx, tx, inventoryItemID)
+	if err != nil && err.ErrType != models.DBErrorTypeNoRows {
+		return err
+	}
+	if err == nil {
+		available -= allocationPolicy.SafetyStock
+	}
+
+	lotsSellable, tracked, err := c.lotsSellable(mctx, tx, inventoryItemID)
+	if err != nil {
+		return err
+	}
+	if tracked {
+		available = min(available, lotsSellable)
+	}
+	if available <= 0 {
+		return nil
+	}
+
+	lines, err := c.store.InventoryReservationItemsBackordered(mctx, tx, inventoryItemID, utils.TimeGetMillis())
+	if err != nil {
+		return err
+	}
+
+	for _, line := range lines {
+		quantity := min(available, line.QuantityBackordered)
+		if quantity == 0 {
+			break
+		}
+
+		// the rows are locked, so these only fail if the counters went out of sync
+		reserved, err := c.store.InventoryItemReserve(mctx, tx, inventoryItemID, int(quantity))
+		if err != nil {
+			return err
+		}
+		allocated, err := c.store.InventoryReservationItemAllocate(mctx, tx, line.Id, quantity)
+		if err != nil {
+			return err
+		}
+		added, err := c.store.InventoryBackorderPolicyBackorderedAdd(mctx, tx, inventoryItemID, -quantity)
+		if err != nil {
+			return err
+		}
+		// TODO: this should not happen, and should be added to DLQ to be reviewed
+		if !reserved || !allocated || !added {
+			return fmt.Errorf("failed to allocate backordered units of the inventory item %s to the reservation line %s", inventoryItemID, line.Id)
+		}
+		available -= quantity
+
+		lots, lotsErr := c.lotsReserve(mctx, tx, inventoryItemID, line.Id, quantity)
+		if lotsErr != nil {
+			return lotsErr
+		}
+
+		err = c.lotMovementsCreate(mctx, tx, &pb.InventoryMovement{
+			Id:              utils.NewID(),
+			InventoryItemId: inventoryItemID,
+			MovementType:    intModels.GetInventoryMovementType(pb.InventoryMovementType_INVENTORY_MOVEMENT_TYPE_RESERVATION),
+			Quantity:        quantity,
+			ReferenceId:     &line.ReservationId,
+			Metadata:        map[string]string{"backorder": "allocated"},
+			CreatedAt:       utils.TimeGetMillis(),
+		}, lots)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// backorderPolicyData converts the backorder policy of an inventory item to the response format
+func backorderPolicyData(inventory *pb.InventoryItem, policy *pb.InventoryBackorderPolicy) *pb.InventoryBackorderPolicyData {
+	return &pb.InventoryBackorderPolicyData{
+		ProductId:           inventory.ProductId,
+		VariantId:           inventory.VariantId,
+		Sku:                 inventory.Sku,
+		Policy:              intModels.GetInventoryBackorderPolicyTypeFromString(policy.Policy),
+		BackorderLimit:      uint32(policy.BackorderLimit),
+		PreorderReleaseAt:   policy.PreorderReleaseAt,
+		QuantityBackordered: uint32(policy.QuantityBackordered),
+	}
+}
@@ -0,0 +1,118 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	intModels "github.com/ahmad-khatib0-org/megacommerce-inventory/pkg/models"
+	pb "github.com/ahmad-khatib0-org/megacommerce-proto/gen/go/inventory/v1"
+	"github.com/ahmad-khatib0-org/megacommerce-shared-go/pkg/models"
+	"github.com/ahmad-khatib0-org/megacommerce-shared-go/pkg/utils"
+	"github.com/jackc/pgx/v5"
+	"google.golang.org/grpc/codes"
+)
+
+// InventoryBackorderPolicySet sets what happens when an item is reserved beyond its available stock: DENY
+// rejects the reservation, BACKORDER lets up to backorder_limit units wait for incoming stock, and PREORDER
+// lets any number of units wait until preorder_release_at (or forever if it's not set). Units that
+// are already backordered stay backordered when the policy changes
+func (c *Controller) InventoryBackorderPolicySet(ctx context.Context, req *pb.InventoryBackorderPolicySetRequest) (*pb.InventoryBackorderPolicySetResponse, error) {
+	path := "inventory.controller.InventoryBackorderPolicySet"
+	modelsCtx, ctxErr := models.ContextGet(ctx)
+	errBuilder := func(e *models.AppError, tx pgx.Tx) (*pb.InventoryBackorderPolicySetResponse, error) {
+		if tx != nil {
+			if rbErr := tx.Rollback(modelsCtx.Context); rbErr != nil {
+				c.log.Errorf("%s: an error rolling back a transaction, err: %s", path, rbErr.Error())
+			}
+		}
+		return &pb.InventoryBackorderPolicySetResponse{Response: &pb.InventoryBackorderPolicySetResponse_Error{Error: models.AppErrorToProto(e)}}, nil
+	}
+
+	if ctxErr != nil {
+		return errBuilder(ctxErr, nil)
+	}
+	internalErr := func(err error, details string, tx pgx.Tx) (*pb.InventoryBackorderPolicySetResponse, error) {
+		return errBuilder(models.NewAppError(modelsCtx, path, models.ErrMsgInternal, nil, details, int(codes.Internal), &models.AppErrorErrorsArgs{Err: err}), tx)
+	}
+	invalidArg := func(id string) (*pb.InventoryBackorderPolicySetResponse, error) {
+		return errBuilder(models.NewAppError(modelsCtx, path, id, nil, "", int(codes.InvalidArgument), nil), nil)
+	}
+	sucBuilder := func(data *pb.InventoryBackorderPolicyData) (*pb.InventoryBackorderPolicySetResponse, error) {
+		return &pb.InventoryBackorderPolicySetResponse{Response: &pb.InventoryBackorderPolicySetResponse_Data{Data: data}}, nil
+	}
+
+	rctx, cancel := context.WithTimeout(context.Background(), time.Second*12)
+	defer cancel()
+	modelsCtx.Context = rctx
+
+	ar := models.AuditRecordNew(modelsCtx, intModels.EventNameInventoryBackorderPolicySet, models.EventStatusFail)
+	defer func() {
+		ar.AuditEventDataPriorState(intModels.InventoryBackorderPolicySetRequestAuditable(req))
+		c.ProcessAudit(ctx, ar)
+	}()
+
+	sellerID, ok := sellerScope(ctx, req.GetSellerId())
+	if !ok {
+		return errBuilder(models.NewAppError(modelsCtx, path, "inventory.seller.forbidden", nil, "", int(codes.PermissionDenied), nil), nil)
+	}
+
+	switch req.GetPolicy() {
+	case pb.InventoryBackorderPolicyType_INVENTORY_BACKORDER_POLICY_TYPE_DENY:
+	case pb.InventoryBackorderPolicyType_INVENTORY_BACKORDER_POLICY_TYPE_BACKORDER:
+		if req.GetBackorderLimit() == 0 {
+			return invalidArg("inventory.backorder.limit_required")
+		}
+	case pb.InventoryBackorderPolicyType_INVENTORY_BACKORDER_POLICY_TYPE_PREORDER:
+		if req.PreorderReleaseAt != nil && req.GetPreorderReleaseAt() <= utils.TimeGetMillis() {
+			return invalidArg("inventory.backorder.invalid_release_at")
+		}
+	default:
+		return invalidArg("inventory.backorder.invalid_policy")
+	}
+
+	tx, err := c.store.GetTx(modelsCtx.Context, pgx.TxOptions{})
+	if err != nil {
+		return internalErr(err, "failed to begin transaction", nil)
+	}
+
+	inventory, err := c.store.InventoryItemGetByProductVariant(modelsCtx, tx, sellerID, req.GetProductId(), req.GetVariantId(), req.GetLocationId())
+	if err != nil {
+		if appErr := itemAmbiguous(modelsCtx, path, err, fmt.Sprintf("%s.%s", req.GetProductId(), req.GetVariantId())); appErr != nil {
+			return errBuilder(appErr, tx)
+		}
+		if err.ErrType == models.DBErrorTypeNoRows {
+			key := fmt.Sprintf("%s.%s", req.GetProductId(), req.GetVariantId())
+			ei := map[string]*models.AppErrorError{key: {ID: "orders.items.not_found_in_inventory"}}
+			return errBuilder(models.NewAppError(modelsCtx, path, "error.not_found", nil, "", int(codes.NotFound), &models.AppErrorErrorsArgs{Err: err, ErrorsInternal: ei}), tx)
+		}
+		return internalErr(err, "failed to query inventory_items table", tx)
+	}
+
+	policy := &pb.InventoryBackorderPolicy{
+		InventoryItemId: inventory.Id,
+		Policy:          intModels.GetInventoryBackorderPolicyType(req.GetPolicy()),
+		CreatedAt:       utils.TimeGetMillis(),
+	}
+	switch req.GetPolicy() {
+	case pb.InventoryBackorderPolicyType_INVENTORY_BACKORDER_POLICY_TYPE_BACKORDER:
+		policy.BackorderLimit = int32(req.GetBackorderLimit())
+	case pb.InventoryBackorderPolicyType_INVENTORY_BACKORDER_POLICY_TYPE_PREORDER:
+		policy.PreorderReleaseAt = req.PreorderReleaseAt
+	}
+	if err := c.store.InventoryBackorderPolicyUpsert(modelsCtx, tx, policy); err != nil {
+		return internalErr(err, "failed to set the backorder policy", tx)
+	}
+
+	policy, err = c.store.InventoryBackorderPolicyGet(modelsCtx, tx, inventory.Id)
+	if err != nil {
+		return internalErr(err, "failed to get the backorder policy", tx)
+	}
+
+	if err := tx.Commit(modelsCtx.Context); err != nil {
+		return internalErr(err, "failed to commit transaction", tx)
+	}
+
+	ar.Success()
+	return sucBuilder(backorderPolicyData(inventory, policy))
+}
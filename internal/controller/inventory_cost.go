@@ -0,0 +1,117 @@
+package controller
+
+import (
+	"fmt"
+
+	intModels "github.com/ahmad-khatib0-org/megacommerce-inventory/pkg/models"
+	pb "github.com/ahmad-khatib0-org/megacommerce-proto/gen/go/inventory/v1"
+	"github.com/ahmad-khatib0-org/megacommerce-shared-go/pkg/models"
+	"github.com/ahmad-khatib0-org/megacommerce-shared-go/pkg/utils"
+	"github.com/jackc/pgx/v5"
+)
+
+// stockCost values the units that movement moves in or out of the on hand stock of a locked inventory item, delta is
+// the change of its on hand units, which is recorded on the movement, so it must be called before the movement is
+// created. Received units are added
+// as a cost layer at unitCost, or at the average unit cost of the item if unitCost is nil, which is set on the
+// movement. Units that leave are taken out of the layers first in first out, and what they cost is recorded under
+// both valuation methods, as cost of goods sold if sold is set. The first in first out cost of the units that
+// leave is returned, so they can be received elsewhere at the cost they left at
+func (c *Controller) stockCost(mctx *models.Context, tx pgx.Tx, movement *pb.InventoryMovement, delta int32, unitCost *int64, sold bool) (int64, *models.DBError) {
+	movement.QuantityChange = delta
+	if delta == 0 {
+		return 0, nil
+	}
+
+	balanceQuantity, balanceValue, err := c.store.InventoryCostBalance(mctx, tx, movement.InventoryItemId)
+	if err != nil {
+		return 0, err
+	}
+
+	if delta > 0 {
+		if unitCost == nil {
+			average := intModels.InventoryCostAverage(balanceQuantity, balanceValue)
+			unitCost = &average
+		}
+		movement.UnitCost = unitCost
+
+		return 0, c.store.InventoryCostLayerCreate(mctx, tx, &pb.InventoryCostLayer{
+			Id:                utils.NewID(),
+			InventoryItemId:   movement.InventoryItemId,
+			MovementId:        movement.Id,
+			Quantity:          delta,
+			QuantityRemaining: delta,
+			UnitCost:          *unitCost,
+			CreatedAt:         movement.CreatedAt,
+		})
+	}
+
+	layers, err := c.store.InventoryCostLayersOpen(mctx, tx, movement.InventoryItemId)
+	if err != nil {
+		return 0, err
+	}
+
+	quantity := -delta
+	remaining, costFifo := quantity, int64(0)
+	for _, layer := range layers {
+		if remaining == 0 {
+			break
+		}
+
+		take := min(remaining, layer.QuantityRemaining)
+		ok, err := c.store.InventoryCostLayerConsume(mctx, tx, layer.Id, take)
+		if err != nil {
+			return 0, err
+		}
+		// TODO: this should not happen, and should be added to DLQ to be reviewed
+		if !ok {
+			return 0, &models.DBError{Err: fmt.Errorf("the cost layer %s has less than %d units remaining", layer.Id, take), Msg: "failed to consume a cost layer"}
+		}
+
+		costFifo += int64(take) * layer.UnitCost
+		remaining -= take
+	}
+	// the units that were on hand before the item was costed have no layer, they're valued at the average
+	costFifo += int64(remaining) * intModels.InventoryCostAverage(balanceQuantity, balanceValue)
+
+	reference := movement.ReferenceId
+	if reference == nil {
+		reference = &movement.Id
+	}
+	return costFifo, c.store.InventoryCostIssueCreate(mctx, tx, &pb.InventoryCostIssue{
+		Id:              utils.NewID(),
+		InventoryItemId: movement.InventoryItemId,
+		ReferenceId:     reference,
+		MovementType:    movement.MovementType,
+		Quantity:        quantity,
+		CostFifo:        costFifo,
+		CostAverage:     intModels.InventoryCostIssueAverage(int64(quantity), balanceQuantity, balanceValue),
+		Sold:            sold,
+		CreatedAt:       movement.CreatedAt,
+	})
+}
+
+// stockCostAverage is the weighted average unit cost of the costed units that an inventory item has on hand
+func (c *Controller) stockCostAverage(mctx *models.Context, tx pgx.Tx, inventoryItemID string) (int64, *models.DBError) {
+	quantity, value, err := c.store.InventoryCostBalance(mctx, tx, inventoryItemID)
+	if err != nil {
+		return 0, err
+	}
+	return intModels.InventoryCostAverage(quantity, value), nil
+}
+
+// stockCostBucket is the unit cost that units moved back into the sellable bucket are received at, the first in
+// first out cost that the units moved into the bucket they come from left the cost layers at, or nil when none of
+// them did, for units that entered the bucket without leaving the sellable one, so they're received at the average
+func (c *Controller) stockCostBucket(mctx *models.Context, tx pgx.Tx, inventoryItemID string, bucket pb.InventoryStockBucket) (*int64, *models.DBError) {
+	movementType := intModels.GetInventoryMovementType(intModels.InventoryStockBucketMovementType(bucket))
+	quantity, value, err := c.store.InventoryCostIssued(mctx, tx, inventoryItemID, movementType)
+	if err != nil {
+		return nil, err
+	}
+	if quantity <= 0 {
+		return nil, nil
+	}
+	unitCost := intModels.InventoryCostAverage(quantity, value)
+	return &unitCost, nil
+}
@@ -0,0 +1,106 @@
+package controller
+
+import (
+	"context"
+
+	intModels "github.com/ahmad-khatib0-org/megacommerce-inventory/pkg/models"
+	pb "github.com/ahmad-khatib0-org/megacommerce-proto/gen/go/inventory/v1"
+	"github.com/ahmad-khatib0-org/megacommerce-shared-go/pkg/models"
+	"github.com/jackc/pgx/v5"
+	"google.golang.org/grpc/codes"
+)
+
+// cycleCountGet gets a cycle count and its items, the cycle count is locked if tx is not nil.
+// A seller can only get its own cycle counts, other cycle counts are reported as not found
+func (c *Controller) cycleCountGet(ctx context.Context, mctx *models.Context, path string, tx pgx.Tx, countNumber string) (*pb.InventoryCycleCount, []*pb.InventoryCycleCountItem, *models.AppError) {
+	count, err := c.store.InventoryCycleCountGetByNumber(mctx, tx, countNumber)
+	if err != nil {
+		if err.ErrType == models.DBErrorTypeNoRows {
+			return nil, nil, models.NewAppError(mctx, path, "inventory.cycle_count.not_found", nil, err.Details, int(codes.NotFound), &models.AppErrorErrorsArgs{Err: err})
+		}
+		return nil, nil, models.NewAppError(mctx, path, models.ErrMsgInternal, nil, "failed to get the cycle count", int(codes.Internal), &models.AppErrorErrorsArgs{Err: err})
+	}
+
+	if _, ok := sellerScope(ctx, count.SellerId); !ok {
+		return nil, nil, models.NewAppError(mctx, path, "inventory.cycle_count.not_found", nil, "", int(codes.NotFound), nil)
+	}
+
+	lines, err := c.store.InventoryCycleCountItemsGetByCycleCountID(mctx, tx, count.Id)
+	if err != nil {
+		return nil, nil, models.NewAppError(mctx, path, models.ErrMsgInternal, nil, "failed to get the cycle count items", int(codes.Internal), &models.AppErrorErrorsArgs{Err: err})
+	}
+
+	return count, lines, nil
+}
+
+// cycleCountStatusInvalid is returned when a cycle count can't go through an operation in its current status
+func cycleCountStatusInvalid(mctx *models.Context, path string, count *pb.InventoryCycleCount) *models.AppError {
+	params := map[string]any{"Status": count.Status}
+	return models.NewAppError(mctx, path, "inventory.cycle_count.invalid_status", params, "", int(codes.FailedPrecondition), nil)
+}
+
+// stockFrozen fails if an inventory item is frozen by a cycle count that isn't reviewed yet, its stock
+// can't be changed, reserved or shipped until then. key identifies the item in the errors
+func (c *Controller) stockFrozen(mctx *models.Context, path string, tx pgx.Tx, inventoryItemID string, key string) *models.AppError {
+	frozen, err := c.store.InventoryCycleCountItemActive(mctx, tx, inventoryItemID, true)
+	if err != nil {
+		return models.NewAppError(mctx, path, models.ErrMsgInternal, nil, "failed to query inventory_cycle_counts table", int(codes.Internal), &models.AppErrorErrorsArgs{Err: err})
+	}
+	if frozen {
+		ei := map[string]*models.AppErrorError{key: {ID: "inventory.cycle_count.item_frozen"}}
+		return models.NewAppError(mctx, path, "inventory.cycle_count.item_frozen", nil, "", int(codes.FailedPrecondition), &models.AppErrorErrorsArgs{ErrorsInternal: ei})
+	}
+
+	return nil
+}
+
+// cycleCountData converts a cycle count to the response format, with the variance of every counted line
+// and a report that sums the variances of the cycle count
+func cycleCountData(count *pb.InventoryCycleCount, lines []*pb.InventoryCycleCountItem) *pb.InventoryCycleCountData {
+	report := &pb.InventoryCycleCountReport{LinesTotal: uint32(len(lines))}
+	items := make([]*pb.InventoryCycleCountListItem, 0, len(lines))
+	for _, line := range lines {
+		item := &pb.InventoryCycleCountListItem{
+			Sku:       line.Sku,
+			CountedAt: line.CountedAt,
+		}
+		if line.QuantityCounted != nil {
+			variance := intModels.InventoryCycleCountVariance(line)
+			shortOfReserved := intModels.InventoryCycleCountShortOfReserved(line)
+			counted := uint32(*line.QuantityCounted)
+
+			item.QuantityExpected = uint32(line.QuantityExpected)
+			item.QuantityReserved = uint32(line.QuantityReserved)
+			item.QuantityCounted = &counted
+			item.Variance = variance
+			item.QuantityShortOfReserved = uint32(shortOfReserved)
+
+			report.LinesCounted++
+			if variance != 0 {
+				report.LinesWithVariance++
+			}
+			if variance > 0 {
+				report.QuantityGained += uint32(variance)
+			} else {
+				report.QuantityLost += uint32(-variance)
+			}
+			report.NetVariance += variance
+			report.QuantityShortOfReserved += uint32(shortOfReserved)
+		}
+		items = append(items, item)
+	}
+
+	return &pb.InventoryCycleCountData{
+		CountNumber: count.CountNumber,
+		SellerId:    count.SellerId,
+		LocationId:  count.LocationId,
+		Status:      intModels.GetInventoryCycleCountStatusFromString(count.Status),
+		Freeze:      count.Freeze,
+		Note:        count.Note,
+		ReviewNote:  count.ReviewNote,
+		CreatedAt:   count.CreatedAt,
+		UpdatedAt:   count.UpdatedAt,
+		Items:       items,
+		Report:      report,
+	}
+}
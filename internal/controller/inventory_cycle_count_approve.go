@@ -0,0 +1,127 @@
+package controller
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	intModels "github.com/ahmad-khatib0-org/megacommerce-inventory/pkg/models"
+	pb "github.com/ahmad-khatib0-org/megacommerce-proto/gen/go/inventory/v1"
+	"github.com/ahmad-khatib0-org/megacommerce-shared-go/pkg/models"
+	"github.com/ahmad-khatib0-org/megacommerce-shared-go/pkg/utils"
+	"github.com/jackc/pgx/v5"
+	"google.golang.org/grpc/codes"
+)
+
+// InventoryCycleCountApprove applies the variances of a counted cycle count as ADJUSTMENT movements that reference
+// it, each variance is added to the current on hand units of its item, so the stock that moved since the sku
+// was counted is kept. A loss can't leave an item with fewer units on hand than its reservations hold, those
+// reservations have to be released before the cycle count can be approved. Found units go to backordered
+// reservation lines first
+func (c *Controller) InventoryCycleCountApprove(ctx context.Context, req *pb.InventoryCycleCountReviewRequest) (*pb.InventoryCycleCountApproveResponse, error) {
+	path := "inventory.controller.InventoryCycleCountApprove"
+	modelsCtx, ctxErr := models.ContextGet(ctx)
+	errBuilder := func(e *models.AppError, tx pgx.Tx) (*pb.InventoryCycleCountApproveResponse, error) {
+		if tx != nil {
+			if rbErr := tx.Rollback(modelsCtx.Context); rbErr != nil {
+				c.log.Errorf("%s: an error rolling back a transaction, err: %s", path, rbErr.Error())
+			}
+		}
+		return &pb.InventoryCycleCountApproveResponse{Response: &pb.InventoryCycleCountApproveResponse_Error{Error: models.AppErrorToProto(e)}}, nil
+	}
+
+	if ctxErr != nil {
+		return errBuilder(ctxErr, nil)
+	}
+	internalErr := func(err error, details string, tx pgx.Tx) (*pb.InventoryCycleCountApproveResponse, error) {
+		return errBuilder(models.NewAppError(modelsCtx, path, models.ErrMsgInternal, nil, details, int(codes.Internal), &models.AppErrorErrorsArgs{Err: err}), tx)
+	}
+	sucBuilder := func(data *pb.InventoryCycleCountData) (*pb.InventoryCycleCountApproveResponse, error) {
+		return &pb.InventoryCycleCountApproveResponse{Response: &pb.InventoryCycleCountApproveResponse_Data{Data: data}}, nil
+	}
+
+	rctx, cancel := context.WithTimeout(context.Background(), time.Second*12)
+	defer cancel()
+	modelsCtx.Context = rctx
+
+	ar := models.AuditRecordNew(modelsCtx, intModels.EventNameInventoryCycleCountApprove, models.EventStatusFail)
+	defer func() {
+		ar.AuditEventDataPriorState(intModels.InventoryCycleCountReviewRequestAuditable(req))
+		c.ProcessAudit(ctx, ar)
+	}()
+
+	tx, err := c.store.GetTx(modelsCtx.Context, pgx.TxOptions{})
+	if err != nil {
+		return internalErr(err, "failed to begin transaction", nil)
+	}
+
+	count, lines, appErr := c.cycleCountGet(ctx, modelsCtx, path, tx, req.GetCountNumber())
+	if appErr != nil {
+		return errBuilder(appErr, tx)
+	}
+	if intModels.GetInventoryCycleCountStatusFromString(count.Status) != pb.InventoryCycleCountStatus_INVENTORY_CYCLE_COUNT_STATUS_COUNTED {
+		return errBuilder(cycleCountStatusInvalid(modelsCtx, path, count), tx)
+	}
+
+	for _, line := range lines {
+		variance := intModels.InventoryCycleCountVariance(line)
+		if variance == 0 {
+			continue
+		}
+
+		inventory, err := c.store.InventoryItemGetBySku(modelsCtx, tx, count.SellerId, line.Sku, count.LocationId)
+		if err != nil {
+			return internalErr(err, "failed to query inventory_items table", tx)
+		}
+
+		total := inventory.QuantityTotal + variance
+		if total < inventory.QuantityReserved {
+			ei := map[string]*models.AppErrorError{line.Sku: {ID: "inventory.cycle_count.short_of_reserved", Params: map[string]any{"Quantity": inventory.QuantityReserved - total}}}
+			return errBuilder(models.NewAppError(modelsCtx, path, "inventory.cycle_count.short_of_reserved", nil, "", int(codes.FailedPrecondition), &models.AppErrorErrorsArgs{ErrorsInternal: ei}), tx)
+		}
+		available := total - inventory.QuantityReserved
+
+		if err := c.store.InventoryItemUpdate(modelsCtx, tx, inventory.Id, int(total), inventory.QuantityReserved, int(available)); err != nil {
+			return internalErr(err, "failed to update inventory", tx)
+		}
+
+		movement := &pb.InventoryMovement{
+			Id:              utils.NewID(),
+			InventoryItemId: inventory.Id,
+			MovementType:    intModels.GetInventoryMovementType(pb.InventoryMovementType_INVENTORY_MOVEMENT_TYPE_ADJUSTMENT),
+			Quantity:        max(variance, -variance),
+			ReferenceId:     &count.Id,
+			Reason:          req.Note,
+			Metadata: map[string]string{
+				"count_number": count.CountNumber,
+				"variance":     strconv.Itoa(int(variance)),
+			},
+			CreatedAt: utils.TimeGetMillis(),
+		}
+		if _, err := c.stockCost(modelsCtx, tx, movement, variance, nil, false); err != nil {
+			return internalErr(err, "failed to cost the inventory movement", tx)
+		}
+		if err := c.store.InventoryMovementCreate(modelsCtx, tx, movement); err != nil {
+			return internalErr(err, "failed to create inventory movement", tx)
+		}
+
+		if variance > 0 {
+			if err := c.backorderAllocate(modelsCtx, tx, inventory.Id, available); err != nil {
+				return internalErr(err, "failed to allocate backordered inventory", tx)
+			}
+		}
+	}
+
+	count.Status = intModels.GetInventoryCycleCountStatus(pb.InventoryCycleCountStatus_INVENTORY_CYCLE_COUNT_STATUS_APPROVED)
+	count.ReviewNote = req.Note
+	if err := c.store.InventoryCycleCountUpdateStatus(modelsCtx, tx, count.Id, count.Status, req.Note); err != nil {
+		return internalErr(err, "failed to update the cycle count status", tx)
+	}
+
+	if err := tx.Commit(modelsCtx.Context); err != nil {
+		return internalErr(err, "failed to commit transaction", tx)
+	}
+
+	ar.Success()
+	return sucBuilder(cycleCountData(count, lines))
+}
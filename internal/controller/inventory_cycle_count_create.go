@@ -0,0 +1,170 @@
+package controller
+
+import (
+	"context"
+	"time"
+
+	intModels "github.com/ahmad-khatib0-org/megacommerce-inventory/pkg/models"
+	pb "github.com/ahmad-khatib0-org/megacommerce-proto/gen/go/inventory/v1"
+	"github.com/ahmad-khatib0-org/megacommerce-shared-go/pkg/models"
+	"github.com/ahmad-khatib0-org/megacommerce-shared-go/pkg/utils"
+	"github.com/jackc/pgx/v5"
+	"google.golang.org/grpc/codes"
+)
+
+// cycleCountMaxItems is the most items a cycle count of a whole location can have
+const cycleCountMaxItems = 1000
+
+// InventoryCycleCountCreate opens a cycle count of a seller's skus at a location, or of every sku the seller
+// has there if none is given. Nothing is counted yet, the stock that the counts are compared to is read
+// when each sku is counted. If freeze is set, the stock of the items can't be changed, reserved or shipped
+// until the cycle count is reviewed, an item can only be part of a single cycle count at a time
+func (c *Controller) InventoryCycleCountCreate(ctx context.Context, req *pb.InventoryCycleCountCreateRequest) (*pb.InventoryCycleCountCreateResponse, error) {
+	path := "inventory.controller.InventoryCycleCountCreate"
+	modelsCtx, ctxErr := models.ContextGet(ctx)
+	errBuilder := func(e *models.AppError, tx pgx.Tx) (*pb.InventoryCycleCountCreateResponse, error) {
+		if tx != nil {
+			if rbErr := tx.Rollback(modelsCtx.Context); rbErr != nil {
+				c.log.Errorf("%s: an error rolling back a transaction, err: %s", path, rbErr.Error())
+			}
+		}
+		return &pb.InventoryCycleCountCreateResponse{Response: &pb.InventoryCycleCountCreateResponse_Error{Error: models.AppErrorToProto(e)}}, nil
+	}
+
+	if ctxErr != nil {
+		return errBuilder(ctxErr, nil)
+	}
+	internalErr := func(err error, details string, tx pgx.Tx) (*pb.InventoryCycleCountCreateResponse, error) {
+		return errBuilder(models.NewAppError(modelsCtx, path, models.ErrMsgInternal, nil, details, int(codes.Internal), &models.AppErrorErrorsArgs{Err: err}), tx)
+	}
+	invalidArg := func(id string) (*pb.InventoryCycleCountCreateResponse, error) {
+		return errBuilder(models.NewAppError(modelsCtx, path, id, nil, "", int(codes.InvalidArgument), nil), nil)
+	}
+	sucBuilder := func(data *pb.InventoryCycleCountData) (*pb.InventoryCycleCountCreateResponse, error) {
+		return &pb.InventoryCycleCountCreateResponse{Response: &pb.InventoryCycleCountCreateResponse_Data{Data: data}}, nil
+	}
+
+	rctx, cancel := context.WithTimeout(context.Background(), time.Second*12)
+	defer cancel()
+	modelsCtx.Context = rctx
+
+	auditable := intModels.InventoryCycleCountCreateRequestAuditable(req)
+	ar := models.AuditRecordNew(modelsCtx, intModels.EventNameInventoryCycleCountCreate, models.EventStatusFail)
+	defer func() {
+		ar.AuditEventDataPriorState(auditable)
+		c.ProcessAudit(ctx, ar)
+	}()
+
+	sellerID, ok := sellerScope(ctx, req.GetSellerId())
+	if !ok {
+		return errBuilder(models.NewAppError(modelsCtx, path, "inventory.seller.forbidden", nil, "", int(codes.PermissionDenied), nil), nil)
+	}
+	if sellerID == "" {
+		return invalidArg("inventory.cycle_count.seller_required")
+	}
+	if req.GetLocationId() == "" {
+		return invalidArg("inventory.cycle_count.location_required")
+	}
+	seen := make(map[string]bool, len(req.GetSkus()))
+	for _, sku := range req.GetSkus() {
+		if sku == "" {
+			return invalidArg("inventory.cycle_count.invalid_item")
+		}
+		if seen[sku] {
+			return invalidArg("inventory.cycle_count.duplicate_item")
+		}
+		seen[sku] = true
+	}
+
+	tx, err := c.store.GetTx(modelsCtx.Context, pgx.TxOptions{})
+	if err != nil {
+		return internalErr(err, "failed to begin transaction", nil)
+	}
+
+	items := make([]*pb.InventoryItem, 0, len(req.GetSkus()))
+	for _, sku := range req.GetSkus() {
+		inventory, err := c.store.InventoryItemGetBySku(modelsCtx, tx, sellerID, sku, req.GetLocationId())
+		if err != nil {
+			if appErr := itemAmbiguous(modelsCtx, path, err, sku); appErr != nil {
+				return errBuilder(appErr, tx)
+			}
+			if err.ErrType == models.DBErrorTypeNoRows {
+				ei := map[string]*models.AppErrorError{sku: {ID: "inventory.cycle_count.sku_not_found_at_location"}}
+				return errBuilder(models.NewAppError(modelsCtx, path, "error.not_found", nil, "", int(codes.NotFound), &models.AppErrorErrorsArgs{Err: err, ErrorsInternal: ei}), tx)
+			}
+			return internalErr(err, "failed to query inventory_items table", tx)
+		}
+		items = append(items, inventory)
+	}
+	if len(req.GetSkus()) == 0 {
+		items, err = c.store.InventoryItemsList(modelsCtx, sellerID, req.GetLocationId(), "", cycleCountMaxItems+1)
+		if err != nil {
+			return internalErr(err, "failed to query inventory_items table", tx)
+		}
+		if len(items) > cycleCountMaxItems {
+			params := map[string]any{"Max": cycleCountMaxItems}
+			return errBuilder(models.NewAppError(modelsCtx, path, "inventory.cycle_count.too_many_items", params, "", int(codes.InvalidArgument), nil), tx)
+		}
+		if len(items) == 0 {
+			return errBuilder(models.NewAppError(modelsCtx, path, "inventory.cycle_count.items_required", nil, "", int(codes.InvalidArgument), nil), tx)
+		}
+	}
+
+	now := utils.TimeGetMillis()
+	count := &pb.InventoryCycleCount{
+		Id:          utils.NewID(),
+		CountNumber: "cc_" + utils.NewID(),
+		SellerId:    sellerID,
+		LocationId:  req.GetLocationId(),
+		Status:      intModels.GetInventoryCycleCountStatus(pb.InventoryCycleCountStatus_INVENTORY_CYCLE_COUNT_STATUS_OPEN),
+		Freeze:      req.GetFreeze(),
+		Note:        req.Note,
+		CreatedAt:   now,
+	}
+	auditable["count_number"] = count.CountNumber
+
+	if err := c.store.InventoryCycleCountCreate(modelsCtx, tx, count); err != nil {
+		return internalErr(err, "failed to create the cycle count", tx)
+	}
+
+	lines := make([]*pb.InventoryCycleCountItem, 0, len(items))
+	for _, inventory := range items {
+		// the stock of an item with lots changes through its lots, so every unit keeps a lot
+		_, tracked, err := c.lotsSellable(modelsCtx, tx, inventory.Id)
+		if err != nil {
+			return internalErr(err, "failed to get the lots of the inventory item", tx)
+		}
+		if tracked {
+			ei := map[string]*models.AppErrorError{inventory.Sku: {ID: "inventory.lot.item_is_lot_tracked"}}
+			return errBuilder(models.NewAppError(modelsCtx, path, "inventory.lot.item_is_lot_tracked", nil, "", int(codes.FailedPrecondition), &models.AppErrorErrorsArgs{ErrorsInternal: ei}), tx)
+		}
+
+		active, err := c.store.InventoryCycleCountItemActive(modelsCtx, tx, inventory.Id, false)
+		if err != nil {
+			return internalErr(err, "failed to query inventory_cycle_counts table", tx)
+		}
+		if active {
+			ei := map[string]*models.AppErrorError{inventory.Sku: {ID: "inventory.cycle_count.item_already_counting"}}
+			return errBuilder(models.NewAppError(modelsCtx, path, "inventory.cycle_count.item_already_counting", nil, "", int(codes.FailedPrecondition), &models.AppErrorErrorsArgs{ErrorsInternal: ei}), tx)
+		}
+
+		line := &pb.InventoryCycleCountItem{
+			Id:              utils.NewID(),
+			CycleCountId:    count.Id,
+			InventoryItemId: inventory.Id,
+			Sku:             inventory.Sku,
+			CreatedAt:       now,
+		}
+		if err := c.store.InventoryCycleCountItemCreate(modelsCtx, tx, line); err != nil {
+			return internalErr(err, "failed to create a cycle count item", tx)
+		}
+		lines = append(lines, line)
+	}
+
+	if err := tx.Commit(modelsCtx.Context); err != nil {
+		return internalErr(err, "failed to commit transaction", tx)
+	}
+
+	ar.Success()
+	return sucBuilder(cycleCountData(count, lines))
+}
@@ -0,0 +1,33 @@
+package controller
+
+import (
+	"context"
+	"time"
+
+	pb "github.com/ahmad-khatib0-org/megacommerce-proto/gen/go/inventory/v1"
+	"github.com/ahmad-khatib0-org/megacommerce-shared-go/pkg/models"
+)
+
+// InventoryCycleCountGet gets a cycle count by its number, with the variance report of its counted skus
+func (c *Controller) InventoryCycleCountGet(ctx context.Context, req *pb.InventoryCycleCountGetRequest) (*pb.InventoryCycleCountGetResponse, error) {
+	path := "inventory.controller.InventoryCycleCountGet"
+	errBuilder := func(e *models.AppError) (*pb.InventoryCycleCountGetResponse, error) {
+		return &pb.InventoryCycleCountGetResponse{Response: &pb.InventoryCycleCountGetResponse_Error{Error: models.AppErrorToProto(e)}}, nil
+	}
+
+	modelsCtx, ctxErr := models.ContextGet(ctx)
+	if ctxErr != nil {
+		return errBuilder(ctxErr)
+	}
+
+	rctx, cancel := context.WithTimeout(context.Background(), time.Second*12)
+	defer cancel()
+	modelsCtx.Context = rctx
+
+	count, lines, appErr := c.cycleCountGet(ctx, modelsCtx, path, nil, req.GetCountNumber())
+	if appErr != nil {
+		return errBuilder(appErr)
+	}
+
+	return &pb.InventoryCycleCountGetResponse{Response: &pb.InventoryCycleCountGetResponse_Data{Data: cycleCountData(count, lines)}}, nil
+}
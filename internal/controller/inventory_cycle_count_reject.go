@@ -0,0 +1,75 @@
+package controller
+
+import (
+	"context"
+	"time"
+
+	intModels "github.com/ahmad-khatib0-org/megacommerce-inventory/pkg/models"
+	pb "github.com/ahmad-khatib0-org/megacommerce-proto/gen/go/inventory/v1"
+	"github.com/ahmad-khatib0-org/megacommerce-shared-go/pkg/models"
+	"github.com/jackc/pgx/v5"
+	"google.golang.org/grpc/codes"
+)
+
+// InventoryCycleCountReject closes an open or counted cycle count without adjusting anything,
+// the items it froze can be changed again, and they can be counted by a new cycle count
+func (c *Controller) InventoryCycleCountReject(ctx context.Context, req *pb.InventoryCycleCountReviewRequest) (*pb.InventoryCycleCountRejectResponse, error) {
+	path := "inventory.controller.InventoryCycleCountReject"
+	modelsCtx, ctxErr := models.ContextGet(ctx)
+	errBuilder := func(e *models.AppError, tx pgx.Tx) (*pb.InventoryCycleCountRejectResponse, error) {
+		if tx != nil {
+			if rbErr := tx.Rollback(modelsCtx.Context); rbErr != nil {
+				c.log.Errorf("%s: an error rolling back a transaction, err: %s", path, rbErr.Error())
+			}
+		}
+		return &pb.InventoryCycleCountRejectResponse{Response: &pb.InventoryCycleCountRejectResponse_Error{Error: models.AppErrorToProto(e)}}, nil
+	}
+
+	if ctxErr != nil {
+		return errBuilder(ctxErr, nil)
+	}
+	internalErr := func(err error, details string, tx pgx.Tx) (*pb.InventoryCycleCountRejectResponse, error) {
+		return errBuilder(models.NewAppError(modelsCtx, path, models.ErrMsgInternal, nil, details, int(codes.Internal), &models.AppErrorErrorsArgs{Err: err}), tx)
+	}
+	sucBuilder := func(data *pb.InventoryCycleCountData) (*pb.InventoryCycleCountRejectResponse, error) {
+		return &pb.InventoryCycleCountRejectResponse{Response: &pb.InventoryCycleCountRejectResponse_Data{Data: data}}, nil
+	}
+
+	rctx, cancel := context.WithTimeout(context.Background(), time.Second*12)
+	defer cancel()
+	modelsCtx.Context = rctx
+
+	ar := models.AuditRecordNew(modelsCtx, intModels.EventNameInventoryCycleCountReject, models.EventStatusFail)
+	defer func() {
+		ar.AuditEventDataPriorState(intModels.InventoryCycleCountReviewRequestAuditable(req))
+		c.ProcessAudit(ctx, ar)
+	}()
+
+	tx, err := c.store.GetTx(modelsCtx.Context, pgx.TxOptions{})
+	if err != nil {
+		return internalErr(err, "failed to begin transaction", nil)
+	}
+
+	count, lines, appErr := c.cycleCountGet(ctx, modelsCtx, path, tx, req.GetCountNumber())
+	if appErr != nil {
+		return errBuilder(appErr, tx)
+	}
+	status := intModels.GetInventoryCycleCountStatusFromString(count.Status)
+	if status != pb.InventoryCycleCountStatus_INVENTORY_CYCLE_COUNT_STATUS_OPEN &&
+		status != pb.InventoryCycleCountStatus_INVENTORY_CYCLE_COUNT_STATUS_COUNTED {
+		return errBuilder(cycleCountStatusInvalid(modelsCtx, path, count), tx)
+	}
+
+	count.Status = intModels.GetInventoryCycleCountStatus(pb.InventoryCycleCountStatus_INVENTORY_CYCLE_COUNT_STATUS_REJECTED)
+	count.ReviewNote = req.Note
+	if err := c.store.InventoryCycleCountUpdateStatus(modelsCtx, tx, count.Id, count.Status, req.Note); err != nil {
+		return internalErr(err, "failed to update the cycle count status", tx)
+	}
+
+	if err := tx.Commit(modelsCtx.Context); err != nil {
+		return internalErr(err, "failed to commit transaction", tx)
+	}
+
+	ar.Success()
+	return sucBuilder(cycleCountData(count, lines))
+}
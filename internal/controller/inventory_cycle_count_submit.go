@@ -0,0 +1,114 @@
+package controller
+
+import (
+	"context"
+	"time"
+
+	intModels "github.com/ahmad-khatib0-org/megacommerce-inventory/pkg/models"
+	pb "github.com/ahmad-khatib0-org/megacommerce-proto/gen/go/inventory/v1"
+	"github.com/ahmad-khatib0-org/megacommerce-shared-go/pkg/models"
+	"github.com/ahmad-khatib0-org/megacommerce-shared-go/pkg/utils"
+	"github.com/jackc/pgx/v5"
+	"google.golang.org/grpc/codes"
+)
+
+// InventoryCycleCountSubmit records the counted units of skus of an open cycle count, each count is compared to
+// the on hand units of the item at the time it's counted, which include the units that reservations hold but
+// that weren't shipped yet, so the stock that moved since the cycle count was opened doesn't show up as a
+// variance. A sku can be counted again while the cycle count is open, and the cycle count waits for its
+// review once every sku is counted. Nothing is adjusted until the cycle count is approved
+func (c *Controller) InventoryCycleCountSubmit(ctx context.Context, req *pb.InventoryCycleCountSubmitRequest) (*pb.InventoryCycleCountSubmitResponse, error) {
+	path := "inventory.controller.InventoryCycleCountSubmit"
+	modelsCtx, ctxErr := models.ContextGet(ctx)
+	errBuilder := func(e *models.AppError, tx pgx.Tx) (*pb.InventoryCycleCountSubmitResponse, error) {
+		if tx != nil {
+			if rbErr := tx.Rollback(modelsCtx.Context); rbErr != nil {
+				c.log.Errorf("%s: an error rolling back a transaction, err: %s", path, rbErr.Error())
+			}
+		}
+		return &pb.InventoryCycleCountSubmitResponse{Response: &pb.InventoryCycleCountSubmitResponse_Error{Error: models.AppErrorToProto(e)}}, nil
+	}
+
+	if ctxErr != nil {
+		return errBuilder(ctxErr, nil)
+	}
+	internalErr := func(err error, details string, tx pgx.Tx) (*pb.InventoryCycleCountSubmitResponse, error) {
+		return errBuilder(models.NewAppError(modelsCtx, path, models.ErrMsgInternal, nil, details, int(codes.Internal), &models.AppErrorErrorsArgs{Err: err}), tx)
+	}
+	sucBuilder := func(data *pb.InventoryCycleCountData) (*pb.InventoryCycleCountSubmitResponse, error) {
+		return &pb.InventoryCycleCountSubmitResponse{Response: &pb.InventoryCycleCountSubmitResponse_Data{Data: data}}, nil
+	}
+
+	rctx, cancel := context.WithTimeout(context.Background(), time.Second*12)
+	defer cancel()
+	modelsCtx.Context = rctx
+
+	ar := models.AuditRecordNew(modelsCtx, intModels.EventNameInventoryCycleCountSubmit, models.EventStatusFail)
+	defer func() {
+		ar.AuditEventDataPriorState(intModels.InventoryCycleCountSubmitRequestAuditable(req))
+		c.ProcessAudit(ctx, ar)
+	}()
+
+	if len(req.GetItems()) == 0 {
+		return errBuilder(models.NewAppError(modelsCtx, path, "inventory.cycle_count.items_required", nil, "", int(codes.InvalidArgument), nil), nil)
+	}
+	seen := make(map[string]bool, len(req.GetItems()))
+	for _, item := range req.GetItems() {
+		if seen[item.GetSku()] {
+			return errBuilder(models.NewAppError(modelsCtx, path, "inventory.cycle_count.duplicate_item", nil, "", int(codes.InvalidArgument), nil), nil)
+		}
+		seen[item.GetSku()] = true
+	}
+
+	tx, err := c.store.GetTx(modelsCtx.Context, pgx.TxOptions{})
+	if err != nil {
+		return internalErr(err, "failed to begin transaction", nil)
+	}
+
+	count, lines, appErr := c.cycleCountGet(ctx, modelsCtx, path, tx, req.GetCountNumber())
+	if appErr != nil {
+		return errBuilder(appErr, tx)
+	}
+	if intModels.GetInventoryCycleCountStatusFromString(count.Status) != pb.InventoryCycleCountStatus_INVENTORY_CYCLE_COUNT_STATUS_OPEN {
+		return errBuilder(cycleCountStatusInvalid(modelsCtx, path, count), tx)
+	}
+
+	for _, item := range req.GetItems() {
+		line, found := utils.Find(lines, func(i *pb.InventoryCycleCountItem) bool { return i.Sku == item.GetSku() })
+		if !found {
+			ei := map[string]*models.AppErrorError{item.GetSku(): {ID: "inventory.cycle_count.item_not_found"}}
+			return errBuilder(models.NewAppError(modelsCtx, path, "error.not_found", nil, "", int(codes.NotFound), &models.AppErrorErrorsArgs{ErrorsInternal: ei}), tx)
+		}
+
+		inventory, err := c.store.InventoryItemGetBySku(modelsCtx, tx, count.SellerId, line.Sku, count.LocationId)
+		if err != nil {
+			return internalErr(err, "failed to query inventory_items table", tx)
+		}
+
+		counted := int32(item.GetQuantityCounted())
+		err = c.store.InventoryCycleCountItemCount(modelsCtx, tx, line.Id, inventory.QuantityTotal, inventory.QuantityReserved, counted)
+		if err != nil {
+			return internalErr(err, "failed to update the cycle count items", tx)
+		}
+		countedAt := utils.TimeGetMillis()
+		line.QuantityExpected, line.QuantityReserved, line.QuantityCounted, line.CountedAt = inventory.QuantityTotal, inventory.QuantityReserved, &counted, &countedAt
+	}
+
+	complete := true
+	for _, line := range lines {
+		complete = complete && line.QuantityCounted != nil
+	}
+	if complete {
+		count.Status = intModels.GetInventoryCycleCountStatus(pb.InventoryCycleCountStatus_INVENTORY_CYCLE_COUNT_STATUS_COUNTED)
+		if err := c.store.InventoryCycleCountUpdateStatus(modelsCtx, tx, count.Id, count.Status, nil); err != nil {
+			return internalErr(err, "failed to update the cycle count status", tx)
+		}
+	}
+
+	if err := tx.Commit(modelsCtx.Context); err != nil {
+		return internalErr(err, "failed to commit transaction", tx)
+	}
+
+	ar.Success()
+	return sucBuilder(cycleCountData(count, lines))
+}
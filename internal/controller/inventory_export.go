@@ -0,0 +1,63 @@
+package controller
+
+import (
+	pb "github.com/ahmad-khatib0-org/megacommerce-proto/gen/go/inventory/v1"
+	"github.com/ahmad-khatib0-org/megacommerce-shared-go/pkg/models"
+	"google.golang.org/grpc/codes"
+)
+
+const (
+	exportDefaultBatchSize = 500
+	exportMaxBatchSize     = 2000
+)
+
+// InventoryExport streams the current stock levels in batches, ordered by inventory item id
+func (c *Controller) InventoryExport(req *pb.InventoryExportRequest, stream pb.InventoryService_InventoryExportServer) error {
+	ctx := stream.Context()
+	path := "inventory.controller.InventoryExport"
+	errBuilder := func(e *models.AppError) error {
+		return stream.Send(&pb.InventoryExportResponse{Response: &pb.InventoryExportResponse_Error{Error: models.AppErrorToProto(e)}})
+	}
+
+	modelsCtx, ctxErr := models.ContextGet(ctx)
+	if ctxErr != nil {
+		return errBuilder(ctxErr)
+	}
+	internalErr := func(err error, details string) error {
+		return errBuilder(models.NewAppError(modelsCtx, path, models.ErrMsgInternal, nil, details, int(codes.Internal), &models.AppErrorErrorsArgs{Err: err}))
+	}
+	// the export lives as long as the client keeps the stream open
+	modelsCtx.Context = ctx
+
+	sellerID, ok := sellerScope(ctx, req.GetSellerId())
+	if !ok {
+		return errBuilder(models.NewAppError(modelsCtx, path, "inventory.seller.forbidden", nil, "", int(codes.PermissionDenied), nil))
+	}
+
+	batchSize := int(req.GetBatchSize())
+	if batchSize == 0 {
+		batchSize = exportDefaultBatchSize
+	}
+	batchSize = min(batchSize, exportMaxBatchSize)
+
+	afterID := ""
+	for {
+		items, err := c.store.InventoryItemsList(modelsCtx, sellerID, req.GetLocationId(), afterID, batchSize)
+		if err != nil {
+			return internalErr(err, "failed to list the inventory items")
+		}
+		if len(items) == 0 {
+			return nil
+		}
+
+		data := &pb.InventoryExportResponseData{Items: items}
+		if err := stream.Send(&pb.InventoryExportResponse{Response: &pb.InventoryExportResponse_Data{Data: data}}); err != nil {
+			return err
+		}
+
+		if len(items) < batchSize {
+			return nil
+		}
+		afterID = items[len(items)-1].Id
+	}
+}
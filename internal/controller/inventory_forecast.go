@@ -0,0 +1,179 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	intModels "github.com/ahmad-khatib0-org/megacommerce-inventory/pkg/models"
+	pb "github.com/ahmad-khatib0-org/megacommerce-proto/gen/go/inventory/v1"
+	"github.com/ahmad-khatib0-org/megacommerce-shared-go/pkg/models"
+	"github.com/ahmad-khatib0-org/megacommerce-shared-go/pkg/utils"
+	"google.golang.org/grpc/codes"
+)
+
+// forecastParams are what the forecasts of a request are computed with, from the request or the config. A nil
+// leadTimeDays uses the lead time of the reorder policy of each item, or the configured one if it has none
+type forecastParams struct {
+	method           pb.InventoryForecastMethod
+	leadTimeDays     *uint32
+	reviewPeriodDays uint32
+}
+
+// InventoryForecast forecasts the daily demand of an item from its movements, with a moving average and with seasonal
+// exponential smoothing, and suggests its reorder point and how many units to order now, given a supplier lead time
+func (c *Controller) InventoryForecast(ctx context.Context, req *pb.InventoryForecastRequest) (*pb.InventoryForecastResponse, error) {
+	path := "inventory.controller.InventoryForecast"
+	errBuilder := func(e *models.AppError) (*pb.InventoryForecastResponse, error) {
+		return &pb.InventoryForecastResponse{Response: &pb.InventoryForecastResponse_Error{Error: models.AppErrorToProto(e)}}, nil
+	}
+
+	modelsCtx, ctxErr := models.ContextGet(ctx)
+	if ctxErr != nil {
+		return errBuilder(ctxErr)
+	}
+	internalErr := func(err error, details string) (*pb.InventoryForecastResponse, error) {
+		return errBuilder(models.NewAppError(modelsCtx, path, models.ErrMsgInternal, nil, details, int(codes.Internal), &models.AppErrorErrorsArgs{Err: err}))
+	}
+
+	rctx, cancel := context.WithTimeout(context.Background(), time.Second*12)
+	defer cancel()
+	modelsCtx.Context = rctx
+
+	sellerID, ok := sellerScope(ctx, req.GetSellerId())
+	if !ok {
+		return errBuilder(models.NewAppError(modelsCtx, path, "inventory.seller.forbidden", nil, "", int(codes.PermissionDenied), nil))
+	}
+	params, errID := c.forecastParamsGet(req.GetMethod(), req.LeadTimeDays, req.ReviewPeriodDays)
+	if errID != "" {
+		return errBuilder(models.NewAppError(modelsCtx, path, errID, nil, "", int(codes.InvalidArgument), nil))
+	}
+
+	inventory, err := c.store.InventoryItemGetByProductVariant(modelsCtx, nil, sellerID, req.GetProductId(), req.GetVariantId(), req.GetLocationId())
+	if err != nil {
+		if appErr := itemAmbiguous(modelsCtx, path, err, fmt.Sprintf("%s.%s", req.GetProductId(), req.GetVariantId())); appErr != nil {
+			return errBuilder(appErr)
+		}
+		if err.ErrType == models.DBErrorTypeNoRows {
+			key := fmt.Sprintf("%s.%s", req.GetProductId(), req.GetVariantId())
+			ei := map[string]*models.AppErrorError{key: {ID: "orders.items.not_found_in_inventory"}}
+			return errBuilder(models.NewAppError(modelsCtx, path, "error.not_found", nil, "", int(codes.NotFound), &models.AppErrorErrorsArgs{Err: err, ErrorsInternal: ei}))
+		}
+		return internalErr(err, "failed to query inventory_items table")
+	}
+
+	forecasts, err := c.forecastItems(modelsCtx, []*pb.InventoryItem{inventory}, params)
+	if err != nil {
+		return internalErr(err, "failed to forecast the demand of the inventory item")
+	}
+
+	return &pb.InventoryForecastResponse{Response: &pb.InventoryForecastResponse_Data{Data: forecasts[0]}}, nil
+}
+
+// forecastParamsGet fills what the request doesn't give from the config, errID is set to a translation id if the
+// method is unknown. A lead time of 0 days is valid, an item that is delivered the same day it's ordered
+func (c *Controller) forecastParamsGet(method pb.InventoryForecastMethod, leadTimeDays *uint32, reviewPeriodDays *uint32) (params forecastParams, errID string) {
+	cfg := c.cfg.Forecasting
+	params = forecastParams{method: method, leadTimeDays: leadTimeDays, reviewPeriodDays: cfg.ReviewPeriodDays}
+
+	switch method {
+	case pb.InventoryForecastMethod_INVENTORY_FORECAST_METHOD_UNSPECIFIED:
+		params.method = intModels.GetInventoryForecastMethodFromString(cfg.Method)
+		if params.method == pb.InventoryForecastMethod_INVENTORY_FORECAST_METHOD_UNSPECIFIED {
+			params.method = pb.InventoryForecastMethod_INVENTORY_FORECAST_METHOD_MOVING_AVERAGE
+		}
+	case pb.InventoryForecastMethod_INVENTORY_FORECAST_METHOD_MOVING_AVERAGE:
+	case pb.InventoryForecastMethod_INVENTORY_FORECAST_METHOD_SEASONAL_SMOOTHING:
+	default:
+		return params, "inventory.forecast.invalid_method"
+	}
+
+	if reviewPeriodDays != nil {
+		params.reviewPeriodDays = *reviewPeriodDays
+	}
+	return params, ""
+}
+
+// forecastItems forecasts the demand of inventory items from the full days (UTC) of their movement history, the
+// history of an item starts the day it was created. The stock position of an item is its available units, the units
+// in transit to it, and the units still expected on open purchase orders
+func (c *Controller) forecastItems(mctx *models.Context, items []*pb.InventoryItem, params forecastParams) ([]*pb.InventoryForecastData, *models.DBError) {
+	cfg := c.cfg.Forecasting
+	today := utils.TimeGetMillis() / intModels.InventoryDayMillis * intModels.InventoryDayMillis
+	from := today - int64(cfg.HistoryDays)*intModels.InventoryDayMillis
+
+	ids := make([]string, len(items))
+	for i, item := range items {
+		ids[i] = item.Id
+	}
+
+	movements, err := c.store.InventoryMovementsDaily(mctx, ids, from)
+	if err != nil {
+		return nil, err
+	}
+	demandByItem := make(map[string][]*intModels.InventoryDemandDay, len(items))
+	for _, d := range intModels.InventoryDemandFromMovements(movements) {
+		demandByItem[d.InventoryItemID] = append(demandByItem[d.InventoryItemID], d)
+	}
+
+	incoming, err := c.store.InventoryPurchaseOrderIncomingByItems(mctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	policies, err := c.store.InventoryReorderPoliciesGetByItems(mctx, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]*pb.InventoryForecastData, len(items))
+	for i, item := range items {
+		leadTimeDays := cfg.LeadTimeDays
+		if policy, ok := policies[item.Id]; ok {
+			leadTimeDays = uint32(policy.LeadTimeDays)
+		}
+		if params.leadTimeDays != nil {
+			leadTimeDays = *params.leadTimeDays
+		}
+
+		itemFrom := max(from, item.CreatedAt/intModels.InventoryDayMillis*intModels.InventoryDayMillis)
+		series := intModels.InventoryDemandSeries(demandByItem[item.Id], itemFrom, int(max(today-itemFrom, 0)/intModels.InventoryDayMillis))
+
+		movingAverage := intModels.InventoryForecastMovingAverage(series, int(cfg.MovingAverageDays))
+		seasonal := intModels.InventoryForecastSeasonal(series, int(cfg.SeasonDays), cfg.Alpha, cfg.Beta, cfg.Gamma, int(leadTimeDays))
+		velocity := movingAverage
+		if params.method == pb.InventoryForecastMethod_INVENTORY_FORECAST_METHOD_SEASONAL_SMOOTHING {
+			velocity = seasonal
+		}
+		stdDev := intModels.InventoryDemandStdDev(series)
+
+		available := int64(max(item.QuantityAvailable, 0))
+		position := available + int64(item.QuantityInTransit) + incoming[item.Id]
+		suggestion := intModels.InventoryReorderSuggest(velocity, stdDev, cfg.ServiceLevelZ, leadTimeDays, params.reviewPeriodDays, position)
+
+		result[i] = &pb.InventoryForecastData{
+			ProductId:              item.ProductId,
+			VariantId:              item.VariantId,
+			Sku:                    item.Sku,
+			LocationId:             item.LocationId,
+			Method:                 params.method,
+			HistoryDays:            uint32(len(series)),
+			LeadTimeDays:           leadTimeDays,
+			ReviewPeriodDays:       params.reviewPeriodDays,
+			VelocityMovingAverage:  movingAverage,
+			VelocitySeasonal:       seasonal,
+			Velocity:               velocity,
+			DemandStdDev:           stdDev,
+			QuantityAvailable:      uint32(available),
+			QuantityInTransit:      uint32(item.QuantityInTransit),
+			QuantityIncoming:       uint32(incoming[item.Id]),
+			DaysOfCover:            intModels.InventoryDaysOfCover(available, velocity),
+			SafetyStock:            uint32(suggestion.SafetyStock),
+			ReorderPoint:           uint32(suggestion.ReorderPoint),
+			OrderUpTo:              uint32(suggestion.OrderUpTo),
+			SuggestedOrderQuantity: uint32(suggestion.OrderQuantity),
+			Reorder:                suggestion.Reorder,
+		}
+	}
+
+	return result, nil
+}
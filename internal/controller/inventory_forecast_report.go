@@ -0,0 +1,79 @@
+package controller
+
+import (
+	pb "github.com/ahmad-khatib0-org/megacommerce-proto/gen/go/inventory/v1"
+	"github.com/ahmad-khatib0-org/megacommerce-shared-go/pkg/models"
+	"google.golang.org/grpc/codes"
+)
+
+// InventoryForecastReport streams the forecasts and the reorder suggestions of every inventory item in batches,
+// ordered by inventory item id, or only of the items that should be reordered now if reorder_only is set
+func (c *Controller) InventoryForecastReport(req *pb.InventoryForecastReportRequest, stream pb.InventoryService_InventoryForecastReportServer) error {
+	ctx := stream.Context()
+	path := "inventory.controller.InventoryForecastReport"
+	errBuilder := func(e *models.AppError) error {
+		return stream.Send(&pb.InventoryForecastReportResponse{Response: &pb.InventoryForecastReportResponse_Error{Error: models.AppErrorToProto(e)}})
+	}
+
+	modelsCtx, ctxErr := models.ContextGet(ctx)
+	if ctxErr != nil {
+		return errBuilder(ctxErr)
+	}
+	internalErr := func(err error, details string) error {
+		return errBuilder(models.NewAppError(modelsCtx, path, models.ErrMsgInternal, nil, details, int(codes.Internal), &models.AppErrorErrorsArgs{Err: err}))
+	}
+	// the report lives as long as the client keeps the stream open
+	modelsCtx.Context = ctx
+
+	sellerID, ok := sellerScope(ctx, req.GetSellerId())
+	if !ok {
+		return errBuilder(models.NewAppError(modelsCtx, path, "inventory.seller.forbidden", nil, "", int(codes.PermissionDenied), nil))
+	}
+	params, errID := c.forecastParamsGet(req.GetMethod(), req.LeadTimeDays, req.ReviewPeriodDays)
+	if errID != "" {
+		return errBuilder(models.NewAppError(modelsCtx, path, errID, nil, "", int(codes.InvalidArgument), nil))
+	}
+
+	batchSize := int(req.GetBatchSize())
+	if batchSize == 0 {
+		batchSize = exportDefaultBatchSize
+	}
+	batchSize = min(batchSize, exportMaxBatchSize)
+
+	afterID := ""
+	for {
+		items, err := c.store.InventoryItemsList(modelsCtx, sellerID, req.GetLocationId(), afterID, batchSize)
+		if err != nil {
+			return internalErr(err, "failed to list the inventory items")
+		}
+		if len(items) == 0 {
+			return nil
+		}
+
+		forecasts, err := c.forecastItems(modelsCtx, items, params)
+		if err != nil {
+			return internalErr(err, "failed to forecast the demand of the inventory items")
+		}
+		if req.GetReorderOnly() {
+			reorder := forecasts[:0]
+			for _, f := range forecasts {
+				if f.Reorder {
+					reorder = append(reorder, f)
+				}
+			}
+			forecasts = reorder
+		}
+
+		if len(forecasts) > 0 {
+			data := &pb.InventoryForecastReportResponseData{Forecasts: forecasts}
+			if err := stream.Send(&pb.InventoryForecastReportResponse{Response: &pb.InventoryForecastReportResponse_Data{Data: data}}); err != nil {
+				return err
+			}
+		}
+
+		if len(items) < batchSize {
+			return nil
+		}
+		afterID = items[len(items)-1].Id
+	}
+}
@@ -0,0 +1,92 @@
+package controller
+
+import (
+	"context"
+	"time"
+
+	intModels "github.com/ahmad-khatib0-org/megacommerce-inventory/pkg/models"
+	pb "github.com/ahmad-khatib0-org/megacommerce-proto/gen/go/inventory/v1"
+	"github.com/ahmad-khatib0-org/megacommerce-shared-go/pkg/models"
+	"github.com/ahmad-khatib0-org/megacommerce-shared-go/pkg/utils"
+	"github.com/jackc/pgx/v5"
+	"google.golang.org/grpc/codes"
+)
+
+// InventoryFulfill ships the given lines (or quantities of lines) of a reservation, or the whole
+// reservation if no items are given, the shipped units leave the stock of their inventory items
+func (c *Controller) InventoryFulfill(ctx context.Context, req *pb.InventoryFulfillRequest) (*pb.InventoryFulfillResponse, error) {
+	path := "inventory.controller.InventoryFulfill"
+	modelsCtx, ctxErr := models.ContextGet(ctx)
+	errBuilder := func(e *models.AppError, tx pgx.Tx) (*pb.InventoryFulfillResponse, error) {
+		if tx != nil {
+			if rbErr := tx.Rollback(modelsCtx.Context); rbErr != nil {
+				c.log.Errorf("%s: an error rolling back a transaction, err: %s", path, rbErr.Error())
+			}
+		}
+		return &pb.InventoryFulfillResponse{Response: &pb.InventoryFulfillResponse_Error{Error: models.AppErrorToProto(e)}}, nil
+	}
+
+	if ctxErr != nil {
+		return errBuilder(ctxErr, nil)
+	}
+	internalErr := func(err error, details string, tx pgx.Tx) (*pb.InventoryFulfillResponse, error) {
+		return errBuilder(models.NewAppError(modelsCtx, path, models.ErrMsgInternal, nil, details, int(codes.Internal), &models.AppErrorErrorsArgs{Err: err}), tx)
+	}
+	sucBuilder := func(data *pb.InventoryReservationGetResponseData) (*pb.InventoryFulfillResponse, error) {
+		return &pb.InventoryFulfillResponse{Response: &pb.InventoryFulfillResponse_Data{Data: data}}, nil
+	}
+
+	rctx, cancel := context.WithTimeout(context.Background(), time.Second*12)
+	defer cancel()
+	modelsCtx.Context = rctx
+
+	ar := models.AuditRecordNew(modelsCtx, intModels.EventNameInventoryFulfill, models.EventStatusFail)
+	defer func() {
+		ar.AuditEventDataPriorState(intModels.InventoryReservationSettleAuditable(req.GetReservationToken(), req.GetItems()))
+		c.ProcessAudit(ctx, ar)
+	}()
+
+	tx, err := c.store.GetTx(modelsCtx.Context, pgx.TxOptions{})
+	if err != nil {
+		return internalErr(err, "failed to begin transaction", nil)
+	}
+
+	reservation, err := c.store.InventoryReservationGetByToken(modelsCtx, tx, req.GetReservationToken())
+	if err != nil {
+		if err.ErrType == models.DBErrorTypeNoRows {
+			return errBuilder(models.NewAppError(modelsCtx, path, "inventory.reservation.not_found", nil, err.Details, int(codes.NotFound), &models.AppErrorErrorsArgs{Err: err}), tx)
+		}
+		return internalErr(err, "failed to get reservation", tx)
+	}
+
+	status := intModels.GetInventoryReservationStatusFromString(reservation.Status)
+	if status != pb.InventoryReservationStatus_INVENTORY_RESERVATION_STATUS_RESERVED &&
+		status != pb.InventoryReservationStatus_INVENTORY_RESERVATION_STATUS_PARTIALLY_RESERVED {
+		return errBuilder(models.NewAppError(modelsCtx, path, "inventory.reservation.already_processed", nil, "", int(codes.FailedPrecondition), nil), tx)
+	}
+	// the stock of an expired reservation is no longer guaranteed to the order
+	if reservation.ExpiresAt <= utils.TimeGetMillis() {
+		return errBuilder(models.NewAppError(modelsCtx, path, "inventory.reservation.expired", nil, "", int(codes.FailedPrecondition), nil), tx)
+	}
+
+	if appErr := c.reservationSettle(ctx, modelsCtx, path, tx, reservation, req.GetItems(), true); appErr != nil {
+		return errBuilder(appErr, tx)
+	}
+
+	if err := tx.Commit(modelsCtx.Context); err != nil {
+		return internalErr(err, "failed to commit transaction", tx)
+	}
+
+	ar.Success()
+
+	reservationItems, err := c.store.InventoryReservationItemsGetByReservationID(modelsCtx, nil, "", reservation.Id)
+	if err != nil {
+		return internalErr(err, "failed to get reservation items", nil)
+	}
+	data, err := c.reservationGetData(modelsCtx, "", reservation, reservationItems)
+	if err != nil {
+		return internalErr(err, "failed to get inventory item", nil)
+	}
+
+	return sucBuilder(data)
+}
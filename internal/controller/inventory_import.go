@@ -0,0 +1,250 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"io"
+	"time"
+
+	intModels "github.com/ahmad-khatib0-org/megacommerce-inventory/pkg/models"
+	pb "github.com/ahmad-khatib0-org/megacommerce-proto/gen/go/inventory/v1"
+	"github.com/ahmad-khatib0-org/megacommerce-shared-go/pkg/models"
+	"github.com/ahmad-khatib0-org/megacommerce-shared-go/pkg/utils"
+	"github.com/jackc/pgx/v5"
+	"google.golang.org/grpc/codes"
+)
+
+// importChunkSize is the number of rows applied per transaction
+const importChunkSize = 500
+
+// InventoryImport applies a bulk stock import that the client streams in batches of rows,
+// every row is validated before anything is applied, then the rows are applied in chunked
+// transactions, or applied in a single transaction that is rolled back if dry_run is set
+func (c *Controller) InventoryImport(stream pb.InventoryService_InventoryImportServer) error {
+	ctx := stream.Context()
+	path := "inventory.controller.InventoryImport"
+	errBuilder := func(e *models.AppError) error {
+		return stream.SendAndClose(&pb.InventoryImportResponse{Response: &pb.InventoryImportResponse_Error{Error: models.AppErrorToProto(e)}})
+	}
+
+	modelsCtx, ctxErr := models.ContextGet(ctx)
+	if ctxErr != nil {
+		return errBuilder(ctxErr)
+	}
+	sucBuilder := func(data *pb.InventoryImportResponseData) error {
+		return stream.SendAndClose(&pb.InventoryImportResponse{Response: &pb.InventoryImportResponse_Data{Data: data}})
+	}
+
+	var rows []*pb.InventoryImportRow
+	var reason *string
+	dryRun := false
+	for {
+		req, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return err
+		}
+
+		dryRun = dryRun || req.GetDryRun()
+		if req.Reason != nil {
+			reason = req.Reason
+		}
+		rows = append(rows, req.GetRows()...)
+		if len(rows) > intModels.InventoryImportMaxRows {
+			return errBuilder(models.NewAppError(modelsCtx, path, "inventory.import.too_many_rows", map[string]any{"Max": intModels.InventoryImportMaxRows}, "", int(codes.InvalidArgument), nil))
+		}
+	}
+
+	if len(rows) == 0 {
+		return errBuilder(models.NewAppError(modelsCtx, path, "inventory.import.empty", nil, "", int(codes.InvalidArgument), nil))
+	}
+
+	rctx, cancel := context.WithTimeout(context.Background(), time.Minute*5)
+	defer cancel()
+	modelsCtx.Context = rctx
+
+	data := &pb.InventoryImportResponseData{DryRun: dryRun, Total: uint32(len(rows)), Results: make([]*pb.InventoryImportRowResult, len(rows))}
+	ar := models.AuditRecordNew(modelsCtx, intModels.EventNameInventoryImport, models.EventStatusFail)
+	defer func() {
+		ar.AuditEventDataPriorState(intModels.InventoryImportAuditable(dryRun, reason, data))
+		c.ProcessAudit(ctx, ar)
+	}()
+
+	for i, row := range rows {
+		if errID := intModels.InventoryImportRowIsValid(row); errID != "" {
+			data.Results[i] = importRowResult(row, pb.InventoryImportRowStatus_INVENTORY_IMPORT_ROW_STATUS_FAILED, errID)
+			data.Failed++
+		}
+	}
+	if data.Failed > 0 {
+		for i, row := range rows {
+			if data.Results[i] == nil {
+				data.Results[i] = importRowResult(row, pb.InventoryImportRowStatus_INVENTORY_IMPORT_ROW_STATUS_SKIPPED, "")
+			}
+		}
+		return sucBuilder(data)
+	}
+
+	sellerID, ok := sellerScope(ctx, "")
+	if !ok {
+		return errBuilder(models.NewAppError(modelsCtx, path, "inventory.seller.forbidden", nil, "", int(codes.PermissionDenied), nil))
+	}
+	importID := utils.NewID()
+	abort := func(start, end int, err error) error {
+		// the previous chunks are already committed or, in a dry run, checked, report exactly what was applied
+		c.log.Errorf("%s: failed to apply the rows %d-%d, err: %v", path, start+1, end, err)
+		for i := start; i < len(rows); i++ {
+			data.Results[i] = importRowResult(rows[i], pb.InventoryImportRowStatus_INVENTORY_IMPORT_ROW_STATUS_SKIPPED, models.ErrMsgInternal)
+		}
+		return sucBuilder(data)
+	}
+
+	// a dry run applies every chunk in one transaction that is rolled back once at the end, so the rows of a
+	// chunk are checked against what the previous chunks would have changed
+	var dryRunTx pgx.Tx
+	if dryRun {
+		tx, err := c.store.GetTx(modelsCtx.Context, pgx.TxOptions{})
+		if err != nil {
+			return abort(0, min(importChunkSize, len(rows)), err)
+		}
+		dryRunTx = tx
+		defer func() {
+			if err := dryRunTx.Rollback(modelsCtx.Context); err != nil {
+				c.log.Errorf("%s: an error rolling back the dry run transaction, err: %s", path, err.Error())
+			}
+		}()
+	}
+
+	for start := 0; start < len(rows); start += importChunkSize {
+		end := min(start+importChunkSize, len(rows))
+		tx := dryRunTx
+		if !dryRun {
+			var err *models.DBError
+			if tx, err = c.store.GetTx(modelsCtx.Context, pgx.TxOptions{}); err != nil {
+				return abort(start, end, err)
+			}
+		}
+
+		chunk, err := c.inventoryImportChunk(ctx, modelsCtx, tx, sellerID, importID, reason, dryRun, rows[start:end])
+		if err != nil {
+			if !dryRun {
+				tx.Rollback(modelsCtx.Context)
+			}
+			return abort(start, end, err)
+		}
+		if !dryRun {
+			if err := tx.Commit(modelsCtx.Context); err != nil {
+				return abort(start, end, err)
+			}
+		}
+
+		// only report the chunk once it's committed, so an aborted chunk isn't reported as applied
+		copy(data.Results[start:end], chunk.results)
+		data.Failed += chunk.failed
+		if !dryRun {
+			data.Applied += chunk.applied
+			data.Pending += uint32(len(chunk.pending))
+			for _, adjustment := range chunk.pending {
+				c.adjustmentAudit(ctx, modelsCtx, intModels.EventNameInventoryAdjustmentRequest, adjustment)
+			}
+		}
+	}
+
+	ar.Success()
+	return sucBuilder(data)
+}
+
+// importChunkResult is what applying a chunk of import rows did, it's only reported once the chunk is committed
+type importChunkResult struct {
+	results []*pb.InventoryImportRowResult
+	pending []*pb.InventoryAdjustmentRequest
+	applied uint32
+	failed  uint32
+}
+
+// inventoryImportChunk applies a chunk of rows in the transaction tx, a row that can't be applied is reported
+// as failed and the rest of the chunk still goes through, a db error aborts the whole chunk and the caller
+// rolls tx back. A row that changes an item by more than the adjustment thresholds is held for approval, like
+// a manual update
+func (c *Controller) inventoryImportChunk(ctx context.Context, mctx *models.Context, tx pgx.Tx, sellerID, importID string, reason *string, dryRun bool, rows []*pb.InventoryImportRow) (*importChunkResult, *models.DBError) {
+	chunk := &importChunkResult{results: make([]*pb.InventoryImportRowResult, len(rows))}
+	for i, row := range rows {
+		inventory, err := c.store.InventoryItemGetBySku(mctx, tx, sellerID, row.GetSku(), row.GetLocationId())
+		if err != nil {
+			if err.ErrType == models.DBErrorTypeNoRows {
+				chunk.results[i] = importRowResult(row, pb.InventoryImportRowStatus_INVENTORY_IMPORT_ROW_STATUS_FAILED, "orders.items.not_found_in_inventory")
+				chunk.failed++
+				continue
+			}
+			// a sku that is stocked at more than one location needs the location of the row
+			if errors.Is(err.Err, intModels.ErrInventoryItemAmbiguous) {
+				chunk.results[i] = importRowResult(row, pb.InventoryImportRowStatus_INVENTORY_IMPORT_ROW_STATUS_FAILED, "inventory.item.ambiguous")
+				chunk.failed++
+				continue
+			}
+			return nil, err
+		}
+
+		// items with lots and items frozen by a cycle count fail like a manual update, a frozen item
+		// can be imported again once the count is reviewed
+		total, available, movementType, errID, err := c.stockUpdateGuard(mctx, tx, inventory, row.GetOperation(), row.GetQuantity())
+		if err != nil {
+			return nil, err
+		}
+		if errID != "" {
+			chunk.results[i] = importRowResult(row, pb.InventoryImportRowStatus_INVENTORY_IMPORT_ROW_STATUS_FAILED, errID)
+			chunk.failed++
+			continue
+		}
+
+		if intModels.InventoryAdjustmentNeedsApproval(inventory.QuantityTotal, int32(total), &c.cfg.Adjustments) {
+			adjustment := c.adjustmentRequestNew(ctx, inventory, row.GetOperation(), row.GetQuantity(), nil, int32(total), reason)
+			if err := c.store.InventoryAdjustmentRequestCreate(mctx, tx, adjustment); err != nil {
+				return nil, err
+			}
+			chunk.pending = append(chunk.pending, adjustment)
+
+			chunk.results[i] = importRowResult(row, pb.InventoryImportRowStatus_INVENTORY_IMPORT_ROW_STATUS_PENDING_APPROVAL, "inventory.update.pending_approval")
+			chunk.results[i].QuantityBefore = inventory.QuantityTotal
+			chunk.results[i].QuantityAfter = inventory.QuantityTotal
+			continue
+		}
+
+		movement := &pb.InventoryMovement{
+			Id:              utils.NewID(),
+			InventoryItemId: inventory.Id,
+			MovementType:    movementType,
+			Quantity:        int32(row.GetQuantity()),
+			ReferenceId:     &importID,
+			Reason:          reason,
+			CreatedAt:       utils.TimeGetMillis(),
+		}
+		if err := c.stockApply(mctx, tx, inventory, total, available, movement, nil); err != nil {
+			return nil, &models.DBError{Err: err, Msg: "failed to apply the import row"}
+		}
+
+		status := pb.InventoryImportRowStatus_INVENTORY_IMPORT_ROW_STATUS_APPLIED
+		if dryRun {
+			status = pb.InventoryImportRowStatus_INVENTORY_IMPORT_ROW_STATUS_VALID
+		}
+		chunk.results[i] = importRowResult(row, status, "")
+		chunk.results[i].QuantityBefore = inventory.QuantityTotal
+		chunk.results[i].QuantityAfter = int32(total)
+		chunk.applied++
+	}
+
+	return chunk, nil
+}
+
+func importRowResult(row *pb.InventoryImportRow, status pb.InventoryImportRowStatus, errID string) *pb.InventoryImportRowResult {
+	return &pb.InventoryImportRowResult{
+		Line:      row.GetLine(),
+		Sku:       row.GetSku(),
+		Status:    status,
+		ErrorId:   errID,
+		Operation: row.GetOperation(),
+		Quantity:  row.GetQuantity(),
+	}
+}
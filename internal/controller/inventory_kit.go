@@ -0,0 +1,173 @@
+package controller
+
+import (
+	"math"
+
+	pb "github.com/ahmad-khatib0-org/megacommerce-proto/gen/go/inventory/v1"
+	"github.com/ahmad-khatib0-org/megacommerce-shared-go/pkg/models"
+	"github.com/ahmad-khatib0-org/megacommerce-shared-go/pkg/utils"
+	"github.com/jackc/pgx/v5"
+	"google.golang.org/grpc/codes"
+)
+
+// kitAvailable returns how many kits a reservation from channel can take, which is the least number of
+// kits that the sellable units of any component can make, items must contain the item of every component
+func (c *Controller) kitAvailable(mctx *models.Context, tx pgx.Tx, components []*pb.InventoryKitComponent, items []*pb.InventoryItem, channel string) (int32, *models.DBError) {
+	if len(components) == 0 {
+		return 0, nil
+	}
+
+	available := int32(math.MaxInt32)
+	for _, component := range components {
+		inventory, found := utils.Find(items, func(i *pb.InventoryItem) bool { return i.Id == component.InventoryItemId })
+		if !found {
+			return 0, nil
+		}
+
+		sellable, err := c.stockSellable(mctx, tx, inventory, channel)
+		if err != nil {
+			return 0, err
+		}
+		available = min(available, sellable/component.Quantity)
+	}
+
+	return available, nil
+}
+
+// kitReserve reserves quantity kits for a reservation of channel, the components are reserved all together
+// or not at all, and each of them gets its own reservation line that remembers the kit and its units per
+// kit. Kits are never backordered, key identifies the kit in the errors
+func (c *Controller) kitReserve(mctx *models.Context, path string, tx pgx.Tx, reservationID string, channel string, kit *pb.InventoryKit, key string, quantity uint32) *models.AppError {
+	internalErr := func(err error, details string) *models.AppError {
+		return models.NewAppError(mctx, path, models.ErrMsgInternal, nil, details, int(codes.Internal), &models.AppErrorErrorsArgs{Err: err})
+	}
+
+	components, err := c.store.InventoryKitComponentsGet(mctx, tx, kit.Id)
+	if err != nil {
+		return internalErr(err, "failed to get the kit components")
+	}
+
+	ids := make([]string, 0, len(components))
+	for _, component := range components {
+		ids = append(ids, component.InventoryItemId)
+	}
+
+	items := []*pb.InventoryItem{}
+	if len(ids) > 0 {
+		items, err = c.store.InventoryItemsLockByIDs(mctx, tx, ids)
+		if err != nil {
+			return internalErr(err, "failed to query inventory_items table")
+		}
+	}
+
+	available, err := c.kitAvailable(mctx, tx, components, items, channel)
+	if err != nil {
+		return internalErr(err, "failed to get the allocation of the kit components")
+	}
+	// compared before the cast, a quantity above MaxInt32 would turn negative
+	if quantity == 0 || int64(available) < int64(quantity) {
+		return stockUnavailable(mctx, path, key, available)
+	}
+	kits := int32(quantity)
+
+	for _, component := range components {
+		if appErr := c.stockFrozen(mctx, path, tx, component.InventoryItemId, key); appErr != nil {
+			return appErr
+		}
+
+		// the units of a component fit its available units, but the product is computed wide all the same
+		units64 := int64(kits) * int64(component.Quantity)
+		if units64 > math.MaxInt32 {
+			return stockUnavailable(mctx, path, key, available)
+		}
+		units := int32(units64)
+
+		// the items are locked and were checked above, so this only fails if the counters went out of sync
+		ok, err := c.store.InventoryItemReserve(mctx, tx, component.InventoryItemId, int(units))
+		if err != nil {
+			return internalErr(err, "failed to reserve inventory")
+		}
+		if !ok {
+			return stockUnavailable(mctx, path, key, 0)
+		}
+
+		lineID := utils.NewID()
+		err = c.store.InventoryReservationItemCreate(mctx, tx, &pb.InventoryReservationItem{
+			Id:              lineID,
+			ReservationId:   reservationID,
+			InventoryItemId: component.InventoryItemId,
+			Quantity:        units,
+			KitId:           &kit.Id,
+			KitQuantity:     component.Quantity,
+			CreatedAt:       utils.TimeGetMillis(),
+		})
+		if err != nil {
+			return internalErr(err, "failed to create a reservation item")
+		}
+		if _, err := c.lotsReserve(mctx, tx, component.InventoryItemId, lineID, units); err != nil {
+			return internalErr(err, "failed to reserve the lots of the inventory item")
+		}
+	}
+
+	return nil
+}
+
+// kitLines returns the reservation lines that hold the components of a kit
+func kitLines(lines []*pb.InventoryReservationItem, kitID string) []*pb.InventoryReservationItem {
+	result := make([]*pb.InventoryReservationItem, 0)
+	for _, line := range lines {
+		if line.KitId != nil && *line.KitId == kitID {
+			result = append(result, line)
+		}
+	}
+	return result
+}
+
+// kitSettleable is the number of kits whose components can still be released, or fulfilled if fulfil is set
+func kitSettleable(lines []*pb.InventoryReservationItem, fulfil bool) int32 {
+	if len(lines) == 0 {
+		return 0
+	}
+
+	settleableKits := int32(math.MaxInt32)
+	for _, line := range lines {
+		settleableKits = min(settleableKits, settleable(line, fulfil)/line.KitQuantity)
+	}
+	return settleableKits
+}
+
+// kitData converts a kit definition to the response format, with the kits and the component units that are
+// sellable to channel, items must contain the item of every component
+func (c *Controller) kitData(mctx *models.Context, kit *pb.InventoryKit, components []*pb.InventoryKitComponent, items []*pb.InventoryItem, channel string) (*pb.InventoryKitData, *models.DBError) {
+	available, err := c.kitAvailable(mctx, nil, components, items, channel)
+	if err != nil {
+		return nil, err
+	}
+
+	data := &pb.InventoryKitData{
+		ProductId:         kit.ProductId,
+		VariantId:         kit.VariantId,
+		Sku:               kit.Sku,
+		QuantityAvailable: uint32(available),
+	}
+	for _, component := range components {
+		inventory, found := utils.Find(items, func(i *pb.InventoryItem) bool { return i.Id == component.InventoryItemId })
+		if !found {
+			continue
+		}
+
+		sellable, err := c.stockSellable(mctx, nil, inventory, channel)
+		if err != nil {
+			return nil, err
+		}
+		data.Components = append(data.Components, &pb.InventoryKitComponentData{
+			ProductId:         inventory.ProductId,
+			VariantId:         inventory.VariantId,
+			Sku:               inventory.Sku,
+			Quantity:          uint32(component.Quantity),
+			QuantityAvailable: uint32(sellable),
+		})
+	}
+
+	return data, nil
+}
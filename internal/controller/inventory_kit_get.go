@@ -0,0 +1,71 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	pb "github.com/ahmad-khatib0-org/megacommerce-proto/gen/go/inventory/v1"
+	"github.com/ahmad-khatib0-org/megacommerce-shared-go/pkg/models"
+	"google.golang.org/grpc/codes"
+)
+
+// InventoryKitGet gets the components of a kit, and how many kits can be reserved, which is the least
+// number of kits that the sellable units of any component can make, for the sales channel of the request
+func (c *Controller) InventoryKitGet(ctx context.Context, req *pb.InventoryKitGetRequest) (*pb.InventoryKitGetResponse, error) {
+	path := "inventory.controller.InventoryKitGet"
+	errBuilder := func(e *models.AppError) (*pb.InventoryKitGetResponse, error) {
+		return &pb.InventoryKitGetResponse{Response: &pb.InventoryKitGetResponse_Error{Error: models.AppErrorToProto(e)}}, nil
+	}
+
+	modelsCtx, ctxErr := models.ContextGet(ctx)
+	if ctxErr != nil {
+		return errBuilder(ctxErr)
+	}
+	internalErr := func(err error, details string) (*pb.InventoryKitGetResponse, error) {
+		return errBuilder(models.NewAppError(modelsCtx, path, models.ErrMsgInternal, nil, details, int(codes.Internal), &models.AppErrorErrorsArgs{Err: err}))
+	}
+
+	rctx, cancel := context.WithTimeout(context.Background(), time.Second*12)
+	defer cancel()
+	modelsCtx.Context = rctx
+
+	sellerID, ok := sellerScope(ctx, req.GetSellerId())
+	if !ok {
+		return errBuilder(models.NewAppError(modelsCtx, path, "inventory.seller.forbidden", nil, "", int(codes.PermissionDenied), nil))
+	}
+
+	kit, err := c.store.InventoryKitGetByProductVariant(modelsCtx, nil, sellerID, req.GetProductId(), req.GetVariantId())
+	if err != nil {
+		if err.ErrType == models.DBErrorTypeNoRows {
+			key := fmt.Sprintf("%s.%s", req.GetProductId(), req.GetVariantId())
+			ei := map[string]*models.AppErrorError{key: {ID: "inventory.kit.not_found"}}
+			return errBuilder(models.NewAppError(modelsCtx, path, "error.not_found", nil, "", int(codes.NotFound), &models.AppErrorErrorsArgs{Err: err, ErrorsInternal: ei}))
+		}
+		return internalErr(err, "failed to query inventory_kits table")
+	}
+
+	components, err := c.store.InventoryKitComponentsGet(modelsCtx, nil, kit.Id)
+	if err != nil {
+		return internalErr(err, "failed to get the kit components")
+	}
+
+	ids := make([]string, 0, len(components))
+	for _, component := range components {
+		ids = append(ids, component.InventoryItemId)
+	}
+	items := []*pb.InventoryItem{}
+	if len(ids) > 0 {
+		items, err = c.store.InventoryItemGetByIDs(modelsCtx, kit.SellerId, ids)
+		if err != nil {
+			return internalErr(err, "failed to query inventory_items table")
+		}
+	}
+
+	data, err := c.kitData(modelsCtx, kit, components, items, salesChannel(ctx))
+	if err != nil {
+		return internalErr(err, "failed to get the allocation of the kit components")
+	}
+
+	return &pb.InventoryKitGetResponse{Response: &pb.InventoryKitGetResponse_Data{Data: data}}, nil
+}
@@ -0,0 +1,173 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	intModels "github.com/ahmad-khatib0-org/megacommerce-inventory/pkg/models"
+	pb "github.com/ahmad-khatib0-org/megacommerce-proto/gen/go/inventory/v1"
+	"github.com/ahmad-khatib0-org/megacommerce-shared-go/pkg/models"
+	"github.com/ahmad-khatib0-org/megacommerce-shared-go/pkg/utils"
+	"github.com/jackc/pgx/v5"
+	"google.golang.org/grpc/codes"
+)
+
+// InventoryKitSet defines a kit, a product that isn't stocked itself but is made of the given units of
+// component inventory items of the same seller, or replaces the components of an existing kit. Reserving
+// a kit reserves its components, and the reservations that already hold a kit keep their components
+func (c *Controller) InventoryKitSet(ctx context.Context, req *pb.InventoryKitSetRequest) (*pb.InventoryKitSetResponse, error) {
+	path := "inventory.controller.InventoryKitSet"
+	modelsCtx, ctxErr := models.ContextGet(ctx)
+	errBuilder := func(e *models.AppError, tx pgx.Tx) (*pb.InventoryKitSetResponse, error) {
+		if tx != nil {
+			if rbErr := tx.Rollback(modelsCtx.Context); rbErr != nil {
+				c.log.Errorf("%s: an error rolling back a transaction, err: %s", path, rbErr.Error())
+			}
+		}
+		return &pb.InventoryKitSetResponse{Response: &pb.InventoryKitSetResponse_Error{Error: models.AppErrorToProto(e)}}, nil
+	}
+
+	if ctxErr != nil {
+		return errBuilder(ctxErr, nil)
+	}
+	internalErr := func(err error, details string, tx pgx.Tx) (*pb.InventoryKitSetResponse, error) {
+		return errBuilder(models.NewAppError(modelsCtx, path, models.ErrMsgInternal, nil, details, int(codes.Internal), &models.AppErrorErrorsArgs{Err: err}), tx)
+	}
+	invalidArg := func(id string) (*pb.InventoryKitSetResponse, error) {
+		return errBuilder(models.NewAppError(modelsCtx, path, id, nil, "", int(codes.InvalidArgument), nil), nil)
+	}
+	sucBuilder := func(data *pb.InventoryKitData) (*pb.InventoryKitSetResponse, error) {
+		return &pb.InventoryKitSetResponse{Response: &pb.InventoryKitSetResponse_Data{Data: data}}, nil
+	}
+
+	rctx, cancel := context.WithTimeout(context.Background(), time.Second*12)
+	defer cancel()
+	modelsCtx.Context = rctx
+
+	ar := models.AuditRecordNew(modelsCtx, intModels.EventNameInventoryKitSet, models.EventStatusFail)
+	defer func() {
+		ar.AuditEventDataPriorState(intModels.InventoryKitSetRequestAuditable(req))
+		c.ProcessAudit(ctx, ar)
+	}()
+
+	sellerID, ok := sellerScope(ctx, req.GetSellerId())
+	if !ok {
+		return errBuilder(models.NewAppError(modelsCtx, path, "inventory.seller.forbidden", nil, "", int(codes.PermissionDenied), nil), nil)
+	}
+	// a kit belongs to a single seller, like its components
+	if sellerID == "" {
+		return invalidArg("inventory.kit.seller_required")
+	}
+	if req.GetSku() == "" {
+		return invalidArg("inventory.kit.sku_required")
+	}
+	if len(req.GetComponents()) == 0 {
+		return invalidArg("inventory.kit.components_required")
+	}
+
+	seen := make(map[string]bool, len(req.GetComponents()))
+	for _, component := range req.GetComponents() {
+		key := fmt.Sprintf("%s.%s", component.GetProductId(), component.GetVariantId())
+		if seen[key] {
+			ei := map[string]*models.AppErrorError{key: {ID: "inventory.kit.duplicate_component"}}
+			return errBuilder(models.NewAppError(modelsCtx, path, "inventory.kit.duplicate_component", nil, "", int(codes.InvalidArgument), &models.AppErrorErrorsArgs{ErrorsInternal: ei}), nil)
+		}
+		seen[key] = true
+
+		if component.GetQuantity() == 0 {
+			ei := map[string]*models.AppErrorError{key: {ID: "inventory.kit.quantity_required"}}
+			return errBuilder(models.NewAppError(modelsCtx, path, "inventory.kit.quantity_required", nil, "", int(codes.InvalidArgument), &models.AppErrorErrorsArgs{ErrorsInternal: ei}), nil)
+		}
+	}
+
+	tx, err := c.store.GetTx(modelsCtx.Context, pgx.TxOptions{})
+	if err != nil {
+		return internalErr(err, "failed to begin transaction", nil)
+	}
+
+	// a stocked product can't be a kit too, reservations would not know which one to reserve
+	_, err = c.store.InventoryItemGetByProductVariant(modelsCtx, tx, sellerID, req.GetProductId(), req.GetVariantId(), req.GetLocationId())
+	stocked := err == nil || errors.Is(err.Err, intModels.ErrInventoryItemAmbiguous)
+	if !stocked && err.ErrType != models.DBErrorTypeNoRows {
+		return internalErr(err, "failed to query inventory_items table", tx)
+	}
+	if stocked {
+		return errBuilder(models.NewAppError(modelsCtx, path, "inventory.kit.product_is_stocked", nil, "", int(codes.FailedPrecondition), nil), tx)
+	}
+
+	items := make([]*pb.InventoryItem, 0, len(req.GetComponents()))
+	for _, component := range req.GetComponents() {
+		inventory, err := c.store.InventoryItemGetByProductVariant(modelsCtx, tx, sellerID, component.GetProductId(), component.GetVariantId(), req.GetLocationId())
+		if err != nil {
+			if appErr := itemAmbiguous(modelsCtx, path, err, fmt.Sprintf("%s.%s", component.GetProductId(), component.GetVariantId())); appErr != nil {
+				return errBuilder(appErr, tx)
+			}
+			if err.ErrType == models.DBErrorTypeNoRows {
+				key := fmt.Sprintf("%s.%s", component.GetProductId(), component.GetVariantId())
+				ei := map[string]*models.AppErrorError{key: {ID: "orders.items.not_found_in_inventory"}}
+				return errBuilder(models.NewAppError(modelsCtx, path, "error.not_found", nil, "", int(codes.NotFound), &models.AppErrorErrorsArgs{Err: err, ErrorsInternal: ei}), tx)
+			}
+			return internalErr(err, "failed to query inventory_items table", tx)
+		}
+		items = append(items, inventory)
+	}
+
+	kit, err := c.store.InventoryKitGetByProductVariant(modelsCtx, tx, sellerID, req.GetProductId(), req.GetVariantId())
+	if err != nil && err.ErrType != models.DBErrorTypeNoRows {
+		return internalErr(err, "failed to query inventory_kits table", tx)
+	}
+	if err != nil {
+		kit = &pb.InventoryKit{
+			Id:        utils.NewID(),
+			SellerId:  sellerID,
+			ProductId: req.GetProductId(),
+			VariantId: req.GetVariantId(),
+			Sku:       req.GetSku(),
+			CreatedAt: utils.TimeGetMillis(),
+		}
+		if err := c.store.InventoryKitCreate(modelsCtx, tx, kit); err != nil {
+			return internalErr(err, "failed to create the kit", tx)
+		}
+	} else {
+		if err := c.store.InventoryKitUpdateSku(modelsCtx, tx, kit.Id, req.GetSku()); err != nil {
+			return internalErr(err, "failed to update the kit", tx)
+		}
+		kit.Sku = req.GetSku()
+
+		if err := c.store.InventoryKitComponentsDelete(modelsCtx, tx, kit.Id); err != nil {
+			return internalErr(err, "failed to remove the kit components", tx)
+		}
+	}
+
+	for i, component := range req.GetComponents() {
+		err := c.store.InventoryKitComponentCreate(modelsCtx, tx, &pb.InventoryKitComponent{
+			Id:              utils.NewID(),
+			KitId:           kit.Id,
+			InventoryItemId: items[i].Id,
+			Quantity:        int32(component.GetQuantity()),
+			CreatedAt:       utils.TimeGetMillis(),
+		})
+		if err != nil {
+			return internalErr(err, "failed to create a kit component", tx)
+		}
+	}
+
+	if err := tx.Commit(modelsCtx.Context); err != nil {
+		return internalErr(err, "failed to commit transaction", tx)
+	}
+
+	ar.Success()
+
+	components, err := c.store.InventoryKitComponentsGet(modelsCtx, nil, kit.Id)
+	if err != nil {
+		return internalErr(err, "failed to get the kit components", nil)
+	}
+	data, err := c.kitData(modelsCtx, kit, components, items, salesChannel(ctx))
+	if err != nil {
+		return internalErr(err, "failed to get the allocation of the kit components", nil)
+	}
+
+	return sucBuilder(data)
+}
@@ -0,0 +1,233 @@
+package controller
+
+import (
+	"fmt"
+
+	intModels "github.com/ahmad-khatib0-org/megacommerce-inventory/pkg/models"
+	pb "github.com/ahmad-khatib0-org/megacommerce-proto/gen/go/inventory/v1"
+	"github.com/ahmad-khatib0-org/megacommerce-shared-go/pkg/models"
+	"github.com/ahmad-khatib0-org/megacommerce-shared-go/pkg/utils"
+	"github.com/jackc/pgx/v5"
+	"google.golang.org/grpc/codes"
+)
+
+// lotQuantity is a number of units of a single lot
+type lotQuantity struct {
+	lotID    string
+	quantity int32
+}
+
+// lotReceipt is a number of units received into a lot that is named by its number, and may not exist yet
+type lotReceipt struct {
+	lotNumber string
+	expiresAt *int64
+	quantity  int32
+}
+
+// lotsSellable returns the units of the lots of an inventory item that can be sold now, tracked is false if
+// the item has no lots, in which case its stock isn't limited by lots. You can pass nil for the tx argument
+func (c *Controller) lotsSellable(mctx *models.Context, tx pgx.Tx, inventoryItemID string) (sellable int32, tracked bool, err *models.DBError) {
+	lots, err := c.store.InventoryLotsGet(mctx, tx, inventoryItemID)
+	if err != nil {
+		return 0, false, err
+	}
+	if len(lots) == 0 {
+		return 0, false, nil
+	}
+
+	return intModels.InventoryLotsSellable(lots, utils.TimeGetMillis()), true, nil
+}
+
+// lotByNumber returns the lot of an inventory item that an operation names by its number. The units of an item
+// with lots are received, moved and written off through them, so an operation on such an item must name one, and
+// nil is returned if the item has no lots and none is named. key identifies the item in the errors
+func (c *Controller) lotByNumber(mctx *models.Context, path string, tx pgx.Tx, inventoryItemID string, lotNumber string, key string) (*pb.InventoryLot, *models.AppError) {
+	if lotNumber == "" {
+		_, tracked, err := c.lotsSellable(mctx, tx, inventoryItemID)
+		if err != nil {
+			return nil, models.NewAppError(mctx, path, models.ErrMsgInternal, nil, "failed to get the lots of the inventory item", int(codes.Internal), &models.AppErrorErrorsArgs{Err: err})
+		}
+		if tracked {
+			ei := map[string]*models.AppErrorError{key: {ID: "inventory.lot.lot_number_required"}}
+			return nil, models.NewAppError(mctx, path, "inventory.lot.lot_number_required", nil, "", int(codes.FailedPrecondition), &models.AppErrorErrorsArgs{ErrorsInternal: ei})
+		}
+		return nil, nil
+	}
+
+	lot, err := c.store.InventoryLotGetByNumber(mctx, tx, inventoryItemID, lotNumber)
+	if err != nil {
+		if err.ErrType == models.DBErrorTypeNoRows {
+			ei := map[string]*models.AppErrorError{key: {ID: "inventory.lot.not_found", Params: map[string]any{"LotNumber": lotNumber}}}
+			return nil, models.NewAppError(mctx, path, "error.not_found", nil, "", int(codes.NotFound), &models.AppErrorErrorsArgs{Err: err, ErrorsInternal: ei})
+		}
+		return nil, models.NewAppError(mctx, path, models.ErrMsgInternal, nil, "failed to query inventory_lots table", int(codes.Internal), &models.AppErrorErrorsArgs{Err: err})
+	}
+
+	return lot, nil
+}
+
+// lotReceive receives quantity units of an inventory item into its lot lotNumber, the lot is created with
+// expiresAt the first time it's received, and a later receipt that carries an expiry date must carry the
+// same one, errID is set if it doesn't. It only changes the lot, not the stock of the item
+func (c *Controller) lotReceive(mctx *models.Context, tx pgx.Tx, inventoryItemID string, lotNumber string, expiresAt *int64, quantity int32) (lot *pb.InventoryLot, errID string, errDB *models.DBError) {
+	lot, err := c.store.InventoryLotGetByNumber(mctx, tx, inventoryItemID, lotNumber)
+	if err != nil && err.ErrType != models.DBErrorTypeNoRows {
+		return nil, "", err
+	}
+	if err != nil {
+		lot = &pb.InventoryLot{
+			Id:              utils.NewID(),
+			InventoryItemId: inventoryItemID,
+			LotNumber:       lotNumber,
+			ExpiresAt:       expiresAt,
+			Quantity:        quantity,
+			CreatedAt:       utils.TimeGetMillis(),
+		}
+		return lot, "", c.store.InventoryLotCreate(mctx, tx, lot)
+	}
+
+	if expiresAt != nil && (lot.ExpiresAt == nil || *lot.ExpiresAt != *expiresAt) {
+		return nil, "inventory.lot.expiry_mismatch", nil
+	}
+	if err := c.store.InventoryLotReceive(mctx, tx, lot.Id, quantity); err != nil {
+		return nil, "", err
+	}
+	lot.Quantity += quantity
+	return lot, "", nil
+}
+
+// lotsReserve reserves quantity units of the lots of a locked inventory item for a reservation line, first
+// expired first out, skipping the expired lots, and records which lots the line holds. It does nothing
+// for an item without lots, the lots that were reserved are returned to reference them in movements
+func (c *Controller) lotsReserve(mctx *models.Context, tx pgx.Tx, inventoryItemID string, reservationItemID string, quantity int32) ([]lotQuantity, error) {
+	lots, err := c.store.InventoryLotsGet(mctx, tx, inventoryItemID)
+	if err != nil {
+		return nil, err
+	}
+	if len(lots) == 0 || quantity <= 0 {
+		return nil, nil
+	}
+
+	now := utils.TimeGetMillis()
+	reserved := make([]lotQuantity, 0, 1)
+	for _, lot := range lots {
+		if quantity == 0 {
+			break
+		}
+		if intModels.InventoryLotExpired(lot, now) {
+			continue
+		}
+
+		take := min(quantity, lot.Quantity-lot.QuantityReserved)
+		if take <= 0 {
+			continue
+		}
+
+		ok, err := c.store.InventoryLotReserve(mctx, tx, lot.Id, take)
+		if err != nil {
+			return nil, err
+		}
+		// TODO: this should not happen, and should be added to DLQ to be reviewed
+		if !ok {
+			return nil, fmt.Errorf("failed to reserve the lot %s, the quantity is bigger than its unreserved units", lot.Id)
+		}
+		if err := c.store.InventoryReservationLotAdd(mctx, tx, reservationItemID, lot.Id, take); err != nil {
+			return nil, err
+		}
+
+		reserved = append(reserved, lotQuantity{lotID: lot.Id, quantity: take})
+		quantity -= take
+	}
+
+	// the sellable units of an item are limited by its lots, so this only fails if the counters went out of sync
+	if quantity > 0 {
+		return nil, fmt.Errorf("failed to reserve the lots of the inventory item %s, %d units are missing", inventoryItemID, quantity)
+	}
+
+	return reserved, nil
+}
+
+// lotsSettle releases (or fulfils if fulfil is set) quantity units of the lots that a reservation line holds,
+// first expired first out, and returns the lots that were settled. A line that reserved its units before
+// its item had lots holds fewer lot units than its quantity, and the rest is settled without a lot
+func (c *Controller) lotsSettle(mctx *models.Context, tx pgx.Tx, reservationItemID string, quantity int32, fulfil bool) ([]lotQuantity, error) {
+	held, err := c.store.InventoryReservationLotsGet(mctx, tx, reservationItemID)
+	if err != nil {
+		return nil, err
+	}
+
+	settled := make([]lotQuantity, 0, len(held))
+	for _, rl := range held {
+		if quantity == 0 {
+			break
+		}
+
+		take := min(quantity, rl.Quantity)
+		ok, err := c.store.InventoryReservationLotSettle(mctx, tx, rl.Id, take)
+		if err != nil {
+			return nil, err
+		}
+
+		var lotOk bool
+		if fulfil {
+			lotOk, err = c.store.InventoryLotFulfill(mctx, tx, rl.LotId, take)
+		} else {
+			lotOk, err = c.store.InventoryLotRelease(mctx, tx, rl.LotId, take)
+		}
+		if err != nil {
+			return nil, err
+		}
+		// TODO: this should not happen, and should be added to DLQ to be reviewed
+		if !ok || !lotOk {
+			return nil, fmt.Errorf("failed to settle the lot %s of the reservation line %s, the quantity is bigger than the reserved units", rl.LotId, reservationItemID)
+		}
+
+		settled = append(settled, lotQuantity{lotID: rl.LotId, quantity: take})
+		quantity -= take
+	}
+
+	return settled, nil
+}
+
+// lotMovementsCreate records movement split by the lots it concerns, one movement per lot, and a movement
+// without a lot for the units of movement.Quantity that the lots don't cover, if any. The change of the
+// on hand units that movement records is split the same way
+func (c *Controller) lotMovementsCreate(mctx *models.Context, tx pgx.Tx, movement *pb.InventoryMovement, lots []lotQuantity) *models.DBError {
+	change := func(quantity int32) int32 {
+		switch {
+		case movement.QuantityChange < 0:
+			return -quantity
+		case movement.QuantityChange > 0:
+			return quantity
+		default:
+			return 0
+		}
+	}
+
+	remaining := movement.Quantity
+	for _, lot := range lots {
+		m := &pb.InventoryMovement{
+			Id:              utils.NewID(),
+			InventoryItemId: movement.InventoryItemId,
+			LotId:           &lot.lotID,
+			MovementType:    movement.MovementType,
+			Quantity:        lot.quantity,
+			QuantityChange:  change(lot.quantity),
+			ReferenceId:     movement.ReferenceId,
+			Reason:          movement.Reason,
+			Metadata:        movement.Metadata,
+			CreatedAt:       movement.CreatedAt,
+		}
+		if err := c.store.InventoryMovementCreate(mctx, tx, m); err != nil {
+			return err
+		}
+		remaining -= lot.quantity
+	}
+
+	if remaining > 0 {
+		movement.Quantity, movement.QuantityChange = remaining, change(remaining)
+		return c.store.InventoryMovementCreate(mctx, tx, movement)
+	}
+
+	return nil
+}
@@ -0,0 +1,74 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	intModels "github.com/ahmad-khatib0-org/megacommerce-inventory/pkg/models"
+	pb "github.com/ahmad-khatib0-org/megacommerce-proto/gen/go/inventory/v1"
+	"github.com/ahmad-khatib0-org/megacommerce-shared-go/pkg/models"
+	"github.com/ahmad-khatib0-org/megacommerce-shared-go/pkg/utils"
+	"google.golang.org/grpc/codes"
+)
+
+// InventoryLotList lists the lots of an item first expired first out, the expired lots are only listed
+// if include_expired is set, their units are blocked from sale but still on hand until they're removed
+func (c *Controller) InventoryLotList(ctx context.Context, req *pb.InventoryLotListRequest) (*pb.InventoryLotListResponse, error) {
+	path := "inventory.controller.InventoryLotList"
+	errBuilder := func(e *models.AppError) (*pb.InventoryLotListResponse, error) {
+		return &pb.InventoryLotListResponse{Response: &pb.InventoryLotListResponse_Error{Error: models.AppErrorToProto(e)}}, nil
+	}
+
+	modelsCtx, ctxErr := models.ContextGet(ctx)
+	if ctxErr != nil {
+		return errBuilder(ctxErr)
+	}
+	internalErr := func(err error, details string) (*pb.InventoryLotListResponse, error) {
+		return errBuilder(models.NewAppError(modelsCtx, path, models.ErrMsgInternal, nil, details, int(codes.Internal), &models.AppErrorErrorsArgs{Err: err}))
+	}
+
+	rctx, cancel := context.WithTimeout(context.Background(), time.Second*12)
+	defer cancel()
+	modelsCtx.Context = rctx
+
+	sellerID, ok := sellerScope(ctx, req.GetSellerId())
+	if !ok {
+		return errBuilder(models.NewAppError(modelsCtx, path, "inventory.seller.forbidden", nil, "", int(codes.PermissionDenied), nil))
+	}
+
+	inventory, err := c.store.InventoryItemGetByProductVariant(modelsCtx, nil, sellerID, req.GetProductId(), req.GetVariantId(), req.GetLocationId())
+	if err != nil {
+		if appErr := itemAmbiguous(modelsCtx, path, err, fmt.Sprintf("%s.%s", req.GetProductId(), req.GetVariantId())); appErr != nil {
+			return errBuilder(appErr)
+		}
+		if err.ErrType == models.DBErrorTypeNoRows {
+			key := fmt.Sprintf("%s.%s", req.GetProductId(), req.GetVariantId())
+			ei := map[string]*models.AppErrorError{key: {ID: "orders.items.not_found_in_inventory"}}
+			return errBuilder(models.NewAppError(modelsCtx, path, "error.not_found", nil, "", int(codes.NotFound), &models.AppErrorErrorsArgs{Err: err, ErrorsInternal: ei}))
+		}
+		return internalErr(err, "failed to query inventory_items table")
+	}
+
+	lots, err := c.store.InventoryLotsGet(modelsCtx, nil, inventory.Id)
+	if err != nil {
+		return internalErr(err, "failed to query inventory_lots table")
+	}
+
+	now := utils.TimeGetMillis()
+	data := &pb.InventoryLotListResponseData{
+		QuantitySellable: uint32(intModels.InventoryLotsSellable(lots, now)),
+		Lots:             make([]*pb.InventoryLotData, 0, len(lots)),
+	}
+	for _, lot := range lots {
+		if intModels.InventoryLotExpired(lot, now) {
+			data.QuantityExpired += uint32(lot.Quantity)
+			if !req.GetIncludeExpired() {
+				continue
+			}
+		}
+		data.Lots = append(data.Lots, lotData(inventory, lot, now))
+	}
+
+	return &pb.InventoryLotListResponse{Response: &pb.InventoryLotListResponse_Data{Data: data}}, nil
+}
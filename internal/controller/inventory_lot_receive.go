@@ -0,0 +1,151 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	intModels "github.com/ahmad-khatib0-org/megacommerce-inventory/pkg/models"
+	pb "github.com/ahmad-khatib0-org/megacommerce-proto/gen/go/inventory/v1"
+	"github.com/ahmad-khatib0-org/megacommerce-shared-go/pkg/models"
+	"github.com/ahmad-khatib0-org/megacommerce-shared-go/pkg/utils"
+	"github.com/jackc/pgx/v5"
+	"google.golang.org/grpc/codes"
+)
+
+// InventoryLotReceive receives units of an item into a lot, the lot is created with its expiry date the first time
+// it's received, and every later receipt must carry the same expiry date. The units move into the on hand stock
+// with an IN movement that references the lot, and go to backordered reservation lines first. Once an item
+// has lots, its stock only changes through them, its units are reserved first expired first out, and the
+// units it had on hand before its first lot are no longer sold
+func (c *Controller) InventoryLotReceive(ctx context.Context, req *pb.InventoryLotReceiveRequest) (*pb.InventoryLotReceiveResponse, error) {
+	path := "inventory.controller.InventoryLotReceive"
+	modelsCtx, ctxErr := models.ContextGet(ctx)
+	errBuilder := func(e *models.AppError, tx pgx.Tx) (*pb.InventoryLotReceiveResponse, error) {
+		if tx != nil {
+			if rbErr := tx.Rollback(modelsCtx.Context); rbErr != nil {
+				c.log.Errorf("%s: an error rolling back a transaction, err: %s", path, rbErr.Error())
+			}
+		}
+		return &pb.InventoryLotReceiveResponse{Response: &pb.InventoryLotReceiveResponse_Error{Error: models.AppErrorToProto(e)}}, nil
+	}
+
+	if ctxErr != nil {
+		return errBuilder(ctxErr, nil)
+	}
+	internalErr := func(err error, details string, tx pgx.Tx) (*pb.InventoryLotReceiveResponse, error) {
+		return errBuilder(models.NewAppError(modelsCtx, path, models.ErrMsgInternal, nil, details, int(codes.Internal), &models.AppErrorErrorsArgs{Err: err}), tx)
+	}
+	invalidArg := func(id string) (*pb.InventoryLotReceiveResponse, error) {
+		return errBuilder(models.NewAppError(modelsCtx, path, id, nil, "", int(codes.InvalidArgument), nil), nil)
+	}
+	sucBuilder := func(data *pb.InventoryLotData) (*pb.InventoryLotReceiveResponse, error) {
+		return &pb.InventoryLotReceiveResponse{Response: &pb.InventoryLotReceiveResponse_Data{Data: data}}, nil
+	}
+
+	rctx, cancel := context.WithTimeout(context.Background(), time.Second*12)
+	defer cancel()
+	modelsCtx.Context = rctx
+
+	ar := models.AuditRecordNew(modelsCtx, intModels.EventNameInventoryLotReceive, models.EventStatusFail)
+	defer func() {
+		ar.AuditEventDataPriorState(intModels.InventoryLotReceiveRequestAuditable(req))
+		c.ProcessAudit(ctx, ar)
+	}()
+
+	sellerID, ok := sellerScope(ctx, req.GetSellerId())
+	if !ok {
+		return errBuilder(models.NewAppError(modelsCtx, path, "inventory.seller.forbidden", nil, "", int(codes.PermissionDenied), nil), nil)
+	}
+	if req.GetLotNumber() == "" {
+		return invalidArg("inventory.lot.lot_number_required")
+	}
+	if req.GetQuantity() == 0 {
+		return invalidArg("inventory.lot.quantity_required")
+	}
+
+	tx, err := c.store.GetTx(modelsCtx.Context, pgx.TxOptions{})
+	if err != nil {
+		return internalErr(err, "failed to begin transaction", nil)
+	}
+
+	inventory, err := c.store.InventoryItemGetByProductVariant(modelsCtx, tx, sellerID, req.GetProductId(), req.GetVariantId(), req.GetLocationId())
+	if err != nil {
+		if appErr := itemAmbiguous(modelsCtx, path, err, fmt.Sprintf("%s.%s", req.GetProductId(), req.GetVariantId())); appErr != nil {
+			return errBuilder(appErr, tx)
+		}
+		if err.ErrType == models.DBErrorTypeNoRows {
+			key := fmt.Sprintf("%s.%s", req.GetProductId(), req.GetVariantId())
+			ei := map[string]*models.AppErrorError{key: {ID: "orders.items.not_found_in_inventory"}}
+			return errBuilder(models.NewAppError(modelsCtx, path, "error.not_found", nil, "", int(codes.NotFound), &models.AppErrorErrorsArgs{Err: err, ErrorsInternal: ei}), tx)
+		}
+		return internalErr(err, "failed to query inventory_items table", tx)
+	}
+
+	if appErr := c.stockFrozen(modelsCtx, path, tx, inventory.Id, fmt.Sprintf("%s.%s", req.GetProductId(), req.GetVariantId())); appErr != nil {
+		return errBuilder(appErr, tx)
+	}
+
+	quantity := int32(req.GetQuantity())
+	lot, errID, err := c.lotReceive(modelsCtx, tx, inventory.Id, req.GetLotNumber(), req.ExpiresAt, quantity)
+	if err != nil {
+		return internalErr(err, "failed to receive the lot", tx)
+	}
+	if errID != "" {
+		return errBuilder(models.NewAppError(modelsCtx, path, errID, nil, "", int(codes.FailedPrecondition), nil), tx)
+	}
+
+	total := int(inventory.QuantityTotal + quantity)
+	available := int(inventory.QuantityAvailable + quantity)
+	if err := c.store.InventoryItemUpdate(modelsCtx, tx, inventory.Id, total, inventory.QuantityReserved, available); err != nil {
+		return internalErr(err, "failed to update the inventory item", tx)
+	}
+
+	movement := &pb.InventoryMovement{
+		Id:              utils.NewID(),
+		InventoryItemId: inventory.Id,
+		LotId:           &lot.Id,
+		MovementType:    intModels.GetInventoryMovementType(pb.InventoryMovementType_INVENTORY_MOVEMENT_TYPE_IN),
+		Quantity:        quantity,
+		Reason:          req.Reason,
+		CreatedAt:       utils.TimeGetMillis(),
+	}
+	if _, err := c.stockCost(modelsCtx, tx, movement, quantity, req.UnitCost, false); err != nil {
+		return internalErr(err, "failed to cost the inventory movement", tx)
+	}
+	if err := c.store.InventoryMovementCreate(modelsCtx, tx, movement); err != nil {
+		return internalErr(err, "failed to create inventory movement", tx)
+	}
+
+	if err := c.backorderAllocate(modelsCtx, tx, inventory.Id, int32(available)); err != nil {
+		return internalErr(err, "failed to allocate backordered inventory", tx)
+	}
+
+	// backordered lines may have reserved units of this lot
+	lot, err = c.store.InventoryLotGetByNumber(modelsCtx, tx, inventory.Id, req.GetLotNumber())
+	if err != nil {
+		return internalErr(err, "failed to query inventory_lots table", tx)
+	}
+
+	if err := tx.Commit(modelsCtx.Context); err != nil {
+		return internalErr(err, "failed to commit transaction", tx)
+	}
+
+	ar.Success()
+	return sucBuilder(lotData(inventory, lot, utils.TimeGetMillis()))
+}
+
+// lotData converts a lot of an inventory item to the response format
+func lotData(inventory *pb.InventoryItem, lot *pb.InventoryLot, now int64) *pb.InventoryLotData {
+	return &pb.InventoryLotData{
+		ProductId:         inventory.ProductId,
+		VariantId:         inventory.VariantId,
+		Sku:               inventory.Sku,
+		LotNumber:         lot.LotNumber,
+		ExpiresAt:         lot.ExpiresAt,
+		Quantity:          uint32(lot.Quantity),
+		QuantityReserved:  uint32(lot.QuantityReserved),
+		QuantityAvailable: uint32(max(lot.Quantity-lot.QuantityReserved, 0)),
+		Expired:           intModels.InventoryLotExpired(lot, now),
+	}
+}
@@ -0,0 +1,118 @@
+package controller
+
+import (
+	"context"
+
+	intModels "github.com/ahmad-khatib0-org/megacommerce-inventory/pkg/models"
+	pb "github.com/ahmad-khatib0-org/megacommerce-proto/gen/go/inventory/v1"
+	"github.com/ahmad-khatib0-org/megacommerce-shared-go/pkg/models"
+	"github.com/ahmad-khatib0-org/megacommerce-shared-go/pkg/utils"
+	"github.com/jackc/pgx/v5"
+	"google.golang.org/grpc/codes"
+)
+
+// purchaseOrderGet gets a purchase order and its items, the purchase order is locked if tx is not nil.
+// A seller can only get its own purchase orders, other ones are reported as not found
+func (c *Controller) purchaseOrderGet(ctx context.Context, mctx *models.Context, path string, tx pgx.Tx, poNumber string) (*pb.InventoryPurchaseOrder, []*pb.InventoryPurchaseOrderItem, *models.AppError) {
+	po, err := c.store.InventoryPurchaseOrderGetByNumber(mctx, tx, poNumber)
+	if err != nil {
+		if err.ErrType == models.DBErrorTypeNoRows {
+			return nil, nil, models.NewAppError(mctx, path, "inventory.purchase_order.not_found", nil, err.Details, int(codes.NotFound), &models.AppErrorErrorsArgs{Err: err})
+		}
+		return nil, nil, models.NewAppError(mctx, path, models.ErrMsgInternal, nil, "failed to get the purchase order", int(codes.Internal), &models.AppErrorErrorsArgs{Err: err})
+	}
+
+	if _, ok := sellerScope(ctx, po.SellerId); !ok {
+		return nil, nil, models.NewAppError(mctx, path, "inventory.purchase_order.not_found", nil, "", int(codes.NotFound), nil)
+	}
+
+	lines, err := c.store.InventoryPurchaseOrderItemsGetByPurchaseOrderID(mctx, tx, po.Id)
+	if err != nil {
+		return nil, nil, models.NewAppError(mctx, path, models.ErrMsgInternal, nil, "failed to get the purchase order items", int(codes.Internal), &models.AppErrorErrorsArgs{Err: err})
+	}
+
+	return po, lines, nil
+}
+
+// purchaseOrderIsOpen reports whether a purchase order still expects units
+func purchaseOrderIsOpen(po *pb.InventoryPurchaseOrder) bool {
+	status := intModels.GetInventoryPurchaseOrderStatusFromString(po.Status)
+	return status == pb.InventoryPurchaseOrderStatus_INVENTORY_PURCHASE_ORDER_STATUS_OPEN ||
+		status == pb.InventoryPurchaseOrderStatus_INVENTORY_PURCHASE_ORDER_STATUS_PARTIALLY_RECEIVED
+}
+
+// purchaseOrderStatusInvalid is returned when a purchase order can't go through an operation in its current status
+func purchaseOrderStatusInvalid(mctx *models.Context, path string, po *pb.InventoryPurchaseOrder) *models.AppError {
+	params := map[string]any{"Status": po.Status}
+	return models.NewAppError(mctx, path, "inventory.purchase_order.invalid_status", params, "", int(codes.FailedPrecondition), nil)
+}
+
+// purchaseOrderMovement records an IN movement of a received purchase order line into the lot lotID, nil if the
+// units have no lot, costed at unitCost or at the average unit cost of the item if it's nil, movements of the
+// same purchase order share its id as reference
+func (c *Controller) purchaseOrderMovement(mctx *models.Context, tx pgx.Tx, po *pb.InventoryPurchaseOrder, inventoryItemID string, lotID *string, quantity int32, unitCost *int64) *models.DBError {
+	movement := &pb.InventoryMovement{
+		Id:              utils.NewID(),
+		InventoryItemId: inventoryItemID,
+		LotId:           lotID,
+		MovementType:    intModels.GetInventoryMovementType(pb.InventoryMovementType_INVENTORY_MOVEMENT_TYPE_IN),
+		Quantity:        quantity,
+		ReferenceId:     &po.Id,
+		Reason:          po.Note,
+		Metadata:        map[string]string{"po_number": po.PoNumber},
+		CreatedAt:       utils.TimeGetMillis(),
+	}
+	if _, err := c.stockCost(mctx, tx, movement, quantity, unitCost, false); err != nil {
+		return err
+	}
+	return c.store.InventoryMovementCreate(mctx, tx, movement)
+}
+
+// purchaseOrderStatusFromLines derives the status of an open purchase order from what its lines received
+func purchaseOrderStatusFromLines(lines []*pb.InventoryPurchaseOrderItem) pb.InventoryPurchaseOrderStatus {
+	received, complete := false, true
+	for _, line := range lines {
+		received = received || line.QuantityReceived > 0
+		complete = complete && line.QuantityReceived >= line.QuantityOrdered
+	}
+
+	switch {
+	case complete:
+		return pb.InventoryPurchaseOrderStatus_INVENTORY_PURCHASE_ORDER_STATUS_RECEIVED
+	case received:
+		return pb.InventoryPurchaseOrderStatus_INVENTORY_PURCHASE_ORDER_STATUS_PARTIALLY_RECEIVED
+	default:
+		return pb.InventoryPurchaseOrderStatus_INVENTORY_PURCHASE_ORDER_STATUS_OPEN
+	}
+}
+
+// purchaseOrderData converts a purchase order to the response format, the units of a
+// cancelled purchase order are no longer incoming
+func purchaseOrderData(po *pb.InventoryPurchaseOrder, lines []*pb.InventoryPurchaseOrderItem) *pb.InventoryPurchaseOrderGetResponseData {
+	open := purchaseOrderIsOpen(po)
+	items := make([]*pb.InventoryPurchaseOrderListItem, 0, len(lines))
+	for _, line := range lines {
+		item := &pb.InventoryPurchaseOrderListItem{
+			Sku:              line.Sku,
+			QuantityOrdered:  uint32(line.QuantityOrdered),
+			QuantityReceived: uint32(line.QuantityReceived),
+			ExpectedAt:       line.ExpectedAt,
+		}
+		if open {
+			item.QuantityIncoming = uint32(line.QuantityOrdered - line.QuantityReceived)
+		}
+		items = append(items, item)
+	}
+
+	return &pb.InventoryPurchaseOrderGetResponseData{
+		PoNumber:          po.PoNumber,
+		SellerId:          po.SellerId,
+		LocationId:        po.LocationId,
+		SupplierReference: po.SupplierReference,
+		Status:            intModels.GetInventoryPurchaseOrderStatusFromString(po.Status),
+		ExpectedAt:        po.ExpectedAt,
+		Note:              po.Note,
+		CreatedAt:         po.CreatedAt,
+		Items:             items,
+	}
+}
@@ -0,0 +1,74 @@
+package controller
+
+import (
+	"context"
+	"time"
+
+	intModels "github.com/ahmad-khatib0-org/megacommerce-inventory/pkg/models"
+	pb "github.com/ahmad-khatib0-org/megacommerce-proto/gen/go/inventory/v1"
+	"github.com/ahmad-khatib0-org/megacommerce-shared-go/pkg/models"
+	"github.com/jackc/pgx/v5"
+	"google.golang.org/grpc/codes"
+)
+
+// InventoryPurchaseOrderCancel cancels a purchase order that isn't fully received, the units
+// that are still incoming are no longer expected, received units stay on hand. Cancelling
+// a draft discards it, its items are drafted again by the next replenishment run
+func (c *Controller) InventoryPurchaseOrderCancel(ctx context.Context, req *pb.InventoryPurchaseOrderCancelRequest) (*pb.InventoryPurchaseOrderCancelResponse, error) {
+	path := "inventory.controller.InventoryPurchaseOrderCancel"
+	modelsCtx, ctxErr := models.ContextGet(ctx)
+	errBuilder := func(e *models.AppError, tx pgx.Tx) (*pb.InventoryPurchaseOrderCancelResponse, error) {
+		if tx != nil {
+			if rbErr := tx.Rollback(modelsCtx.Context); rbErr != nil {
+				c.log.Errorf("%s: an error rolling back a transaction, err: %s", path, rbErr.Error())
+			}
+		}
+		return &pb.InventoryPurchaseOrderCancelResponse{Response: &pb.InventoryPurchaseOrderCancelResponse_Error{Error: models.AppErrorToProto(e)}}, nil
+	}
+
+	if ctxErr != nil {
+		return errBuilder(ctxErr, nil)
+	}
+	internalErr := func(err error, details string, tx pgx.Tx) (*pb.InventoryPurchaseOrderCancelResponse, error) {
+		return errBuilder(models.NewAppError(modelsCtx, path, models.ErrMsgInternal, nil, details, int(codes.Internal), &models.AppErrorErrorsArgs{Err: err}), tx)
+	}
+	sucBuilder := func(data *pb.InventoryPurchaseOrderGetResponseData) (*pb.InventoryPurchaseOrderCancelResponse, error) {
+		return &pb.InventoryPurchaseOrderCancelResponse{Response: &pb.InventoryPurchaseOrderCancelResponse_Data{Data: data}}, nil
+	}
+
+	rctx, cancel := context.WithTimeout(context.Background(), time.Second*12)
+	defer cancel()
+	modelsCtx.Context = rctx
+
+	ar := models.AuditRecordNew(modelsCtx, intModels.EventNameInventoryPurchaseOrderCancel, models.EventStatusFail)
+	defer func() {
+		ar.AuditEventDataPriorState(map[string]any{"po_number": req.GetPoNumber(), "reason": req.Reason})
+		c.ProcessAudit(ctx, ar)
+	}()
+
+	tx, err := c.store.GetTx(modelsCtx.Context, pgx.TxOptions{})
+	if err != nil {
+		return internalErr(err, "failed to begin transaction", nil)
+	}
+
+	po, lines, appErr := c.purchaseOrderGet(ctx, modelsCtx, path, tx, req.GetPoNumber())
+	if appErr != nil {
+		return errBuilder(appErr, tx)
+	}
+	draft := intModels.GetInventoryPurchaseOrderStatusFromString(po.Status) == pb.InventoryPurchaseOrderStatus_INVENTORY_PURCHASE_ORDER_STATUS_DRAFT
+	if !draft && !purchaseOrderIsOpen(po) {
+		return errBuilder(purchaseOrderStatusInvalid(modelsCtx, path, po), tx)
+	}
+
+	po.Status = intModels.GetInventoryPurchaseOrderStatus(pb.InventoryPurchaseOrderStatus_INVENTORY_PURCHASE_ORDER_STATUS_CANCELLED)
+	if err := c.store.InventoryPurchaseOrderUpdateStatus(modelsCtx, tx, po.Id, po.Status); err != nil {
+		return internalErr(err, "failed to update the purchase order status", tx)
+	}
+
+	if err := tx.Commit(modelsCtx.Context); err != nil {
+		return internalErr(err, "failed to commit transaction", tx)
+	}
+
+	ar.Success()
+	return sucBuilder(purchaseOrderData(po, lines))
+}
@@ -0,0 +1,171 @@
+package controller
+
+import (
+	"context"
+	"slices"
+	"time"
+
+	intModels "github.com/ahmad-khatib0-org/megacommerce-inventory/pkg/models"
+	pb "github.com/ahmad-khatib0-org/megacommerce-proto/gen/go/inventory/v1"
+	"github.com/ahmad-khatib0-org/megacommerce-shared-go/pkg/models"
+	"github.com/ahmad-khatib0-org/megacommerce-shared-go/pkg/utils"
+	"github.com/jackc/pgx/v5"
+	"google.golang.org/grpc/codes"
+)
+
+// InventoryPurchaseOrderCreate records an inbound purchase order of a seller for a location, its units
+// are incoming stock from then on, each line is expected at the purchase order date unless it has its own
+func (c *Controller) InventoryPurchaseOrderCreate(ctx context.Context, req *pb.InventoryPurchaseOrderCreateRequest) (*pb.InventoryPurchaseOrderCreateResponse, error) {
+	path := "inventory.controller.InventoryPurchaseOrderCreate"
+	modelsCtx, ctxErr := models.ContextGet(ctx)
+	errBuilder := func(e *models.AppError, tx pgx.Tx) (*pb.InventoryPurchaseOrderCreateResponse, error) {
+		if tx != nil {
+			if rbErr := tx.Rollback(modelsCtx.Context); rbErr != nil {
+				c.log.Errorf("%s: an error rolling back a transaction, err: %s", path, rbErr.Error())
+			}
+		}
+		return &pb.InventoryPurchaseOrderCreateResponse{Response: &pb.InventoryPurchaseOrderCreateResponse_Error{Error: models.AppErrorToProto(e)}}, nil
+	}
+
+	if ctxErr != nil {
+		return errBuilder(ctxErr, nil)
+	}
+	internalErr := func(err error, details string, tx pgx.Tx) (*pb.InventoryPurchaseOrderCreateResponse, error) {
+		return errBuilder(models.NewAppError(modelsCtx, path, models.ErrMsgInternal, nil, details, int(codes.Internal), &models.AppErrorErrorsArgs{Err: err}), tx)
+	}
+	invalidArg := func(id string) (*pb.InventoryPurchaseOrderCreateResponse, error) {
+		return errBuilder(models.NewAppError(modelsCtx, path, id, nil, "", int(codes.InvalidArgument), nil), nil)
+	}
+	sucBuilder := func(data *pb.InventoryPurchaseOrderGetResponseData) (*pb.InventoryPurchaseOrderCreateResponse, error) {
+		return &pb.InventoryPurchaseOrderCreateResponse{Response: &pb.InventoryPurchaseOrderCreateResponse_Data{Data: data}}, nil
+	}
+
+	rctx, cancel := context.WithTimeout(context.Background(), time.Second*12)
+	defer cancel()
+	modelsCtx.Context = rctx
+
+	auditable := intModels.InventoryPurchaseOrderCreateRequestAuditable(req)
+	ar := models.AuditRecordNew(modelsCtx, intModels.EventNameInventoryPurchaseOrderCreate, models.EventStatusFail)
+	defer func() {
+		ar.AuditEventDataPriorState(auditable)
+		c.ProcessAudit(ctx, ar)
+	}()
+
+	sellerID, ok := sellerScope(ctx, req.GetSellerId())
+	if !ok {
+		return errBuilder(models.NewAppError(modelsCtx, path, "inventory.seller.forbidden", nil, "", int(codes.PermissionDenied), nil), nil)
+	}
+	if sellerID == "" {
+		return invalidArg("inventory.purchase_order.seller_required")
+	}
+	if req.GetLocationId() == "" {
+		return invalidArg("inventory.purchase_order.location_required")
+	}
+	if req.GetExpectedAt() == 0 {
+		return invalidArg("inventory.purchase_order.expected_at_required")
+	}
+	if len(req.GetItems()) == 0 {
+		return invalidArg("inventory.purchase_order.items_required")
+	}
+
+	seen := make(map[string]bool, len(req.GetItems()))
+	for _, item := range req.GetItems() {
+		if item.GetSku() == "" || item.GetQuantity() == 0 {
+			return invalidArg("inventory.purchase_order.invalid_item")
+		}
+		if seen[item.GetSku()] {
+			return invalidArg("inventory.purchase_order.duplicate_item")
+		}
+		seen[item.GetSku()] = true
+	}
+
+	tx, err := c.store.GetTx(modelsCtx.Context, pgx.TxOptions{})
+	if err != nil {
+		return internalErr(err, "failed to begin transaction", nil)
+	}
+
+	now := utils.TimeGetMillis()
+	po := &pb.InventoryPurchaseOrder{
+		Id:                utils.NewID(),
+		PoNumber:          "po_" + utils.NewID(),
+		SellerId:          sellerID,
+		LocationId:        req.GetLocationId(),
+		SupplierReference: req.SupplierReference,
+		Status:            intModels.GetInventoryPurchaseOrderStatus(pb.InventoryPurchaseOrderStatus_INVENTORY_PURCHASE_ORDER_STATUS_OPEN),
+		ExpectedAt:        req.GetExpectedAt(),
+		Note:              req.Note,
+		CreatedAt:         now,
+	}
+	auditable["po_number"] = po.PoNumber
+
+	if err := c.store.InventoryPurchaseOrderCreate(modelsCtx, tx, po); err != nil {
+		return internalErr(err, "failed to create the purchase order", tx)
+	}
+
+	lines := make([]*pb.InventoryPurchaseOrderItem, 0, len(req.GetItems()))
+	for _, item := range req.GetItems() {
+		inventory, err := c.store.InventoryItemGetBySku(modelsCtx, tx, sellerID, item.GetSku(), req.GetLocationId())
+		if appErr := itemAmbiguous(modelsCtx, path, err, item.GetSku()); appErr != nil {
+			return errBuilder(appErr, tx)
+		}
+		if err != nil && err.ErrType != models.DBErrorTypeNoRows {
+			return internalErr(err, "failed to query inventory_items table", tx)
+		}
+
+		// the first purchase of a sku for a location creates its inventory item there,
+		// from the inventory item of the same sku at another location
+		if err != nil {
+			templates, err := c.store.InventoryItemsGetBySku(modelsCtx, sellerID, item.GetSku(), "")
+			if err != nil {
+				return internalErr(err, "failed to query inventory_items table", tx)
+			}
+			if len(templates) == 0 {
+				ei := map[string]*models.AppErrorError{item.GetSku(): {ID: "inventory.purchase_order.sku_not_found"}}
+				return errBuilder(models.NewAppError(modelsCtx, path, "error.not_found", nil, "", int(codes.NotFound), &models.AppErrorErrorsArgs{ErrorsInternal: ei}), tx)
+			}
+			// any location of the sku can be the template, but it must belong to a single seller
+			template := templates[0]
+			if slices.ContainsFunc(templates, func(i *pb.InventoryItem) bool { return i.SellerId != template.SellerId }) {
+				ei := map[string]*models.AppErrorError{item.GetSku(): {ID: "inventory.item.ambiguous"}}
+				return errBuilder(models.NewAppError(modelsCtx, path, "inventory.item.ambiguous", nil, "", int(codes.InvalidArgument), &models.AppErrorErrorsArgs{ErrorsInternal: ei}), tx)
+			}
+
+			inventory = &pb.InventoryItem{
+				Id:         utils.NewID(),
+				SellerId:   template.SellerId,
+				ProductId:  template.ProductId,
+				VariantId:  template.VariantId,
+				Sku:        template.Sku,
+				LocationId: &po.LocationId,
+				CreatedAt:  now,
+			}
+			if err := c.store.InventoryItemCreate(modelsCtx, tx, inventory); err != nil {
+				return internalErr(err, "failed to create an inventory item", tx)
+			}
+		}
+
+		line := &pb.InventoryPurchaseOrderItem{
+			Id:              utils.NewID(),
+			PurchaseOrderId: po.Id,
+			Sku:             item.GetSku(),
+			InventoryItemId: inventory.Id,
+			QuantityOrdered: int32(item.GetQuantity()),
+			ExpectedAt:      po.ExpectedAt,
+			CreatedAt:       now,
+		}
+		if item.ExpectedAt != nil {
+			line.ExpectedAt = item.GetExpectedAt()
+		}
+		if err := c.store.InventoryPurchaseOrderItemCreate(modelsCtx, tx, line); err != nil {
+			return internalErr(err, "failed to create a purchase order item", tx)
+		}
+		lines = append(lines, line)
+	}
+
+	if err := tx.Commit(modelsCtx.Context); err != nil {
+		return internalErr(err, "failed to commit transaction", tx)
+	}
+
+	ar.Success()
+	return sucBuilder(purchaseOrderData(po, lines))
+}
@@ -0,0 +1,33 @@
+package controller
+
+import (
+	"context"
+	"time"
+
+	pb "github.com/ahmad-khatib0-org/megacommerce-proto/gen/go/inventory/v1"
+	"github.com/ahmad-khatib0-org/megacommerce-shared-go/pkg/models"
+)
+
+// InventoryPurchaseOrderGet gets the details of a purchase order by its number
+func (c *Controller) InventoryPurchaseOrderGet(ctx context.Context, req *pb.InventoryPurchaseOrderGetRequest) (*pb.InventoryPurchaseOrderGetResponse, error) {
+	path := "inventory.controller.InventoryPurchaseOrderGet"
+	errBuilder := func(e *models.AppError) (*pb.InventoryPurchaseOrderGetResponse, error) {
+		return &pb.InventoryPurchaseOrderGetResponse{Response: &pb.InventoryPurchaseOrderGetResponse_Error{Error: models.AppErrorToProto(e)}}, nil
+	}
+
+	modelsCtx, ctxErr := models.ContextGet(ctx)
+	if ctxErr != nil {
+		return errBuilder(ctxErr)
+	}
+
+	rctx, cancel := context.WithTimeout(context.Background(), time.Second*12)
+	defer cancel()
+	modelsCtx.Context = rctx
+
+	po, lines, appErr := c.purchaseOrderGet(ctx, modelsCtx, path, nil, req.GetPoNumber())
+	if appErr != nil {
+		return errBuilder(appErr)
+	}
+
+	return &pb.InventoryPurchaseOrderGetResponse{Response: &pb.InventoryPurchaseOrderGetResponse_Data{Data: purchaseOrderData(po, lines)}}, nil
+}
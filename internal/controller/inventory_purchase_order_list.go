@@ -0,0 +1,69 @@
+package controller
+
+import (
+	"context"
+	"time"
+
+	intModels "github.com/ahmad-khatib0-org/megacommerce-inventory/pkg/models"
+	pb "github.com/ahmad-khatib0-org/megacommerce-proto/gen/go/inventory/v1"
+	"github.com/ahmad-khatib0-org/megacommerce-shared-go/pkg/models"
+	"google.golang.org/grpc/codes"
+)
+
+const (
+	purchaseOrderListDefaultPageSize = 50
+	purchaseOrderListMaxPageSize     = 100
+)
+
+// InventoryPurchaseOrderList lists the purchase orders of a seller newest first, optionally only the ones at a
+// location or in a status, e.g. the drafts of the replenishment job that are waiting to be reviewed
+func (c *Controller) InventoryPurchaseOrderList(ctx context.Context, req *pb.InventoryPurchaseOrderListRequest) (*pb.InventoryPurchaseOrderListResponse, error) {
+	path := "inventory.controller.InventoryPurchaseOrderList"
+	errBuilder := func(e *models.AppError) (*pb.InventoryPurchaseOrderListResponse, error) {
+		return &pb.InventoryPurchaseOrderListResponse{Response: &pb.InventoryPurchaseOrderListResponse_Error{Error: models.AppErrorToProto(e)}}, nil
+	}
+
+	modelsCtx, ctxErr := models.ContextGet(ctx)
+	if ctxErr != nil {
+		return errBuilder(ctxErr)
+	}
+	internalErr := func(err error, details string) (*pb.InventoryPurchaseOrderListResponse, error) {
+		return errBuilder(models.NewAppError(modelsCtx, path, models.ErrMsgInternal, nil, details, int(codes.Internal), &models.AppErrorErrorsArgs{Err: err}))
+	}
+
+	rctx, cancel := context.WithTimeout(context.Background(), time.Second*12)
+	defer cancel()
+	modelsCtx.Context = rctx
+
+	sellerID, ok := sellerScope(ctx, req.GetSellerId())
+	if !ok {
+		return errBuilder(models.NewAppError(modelsCtx, path, "inventory.seller.forbidden", nil, "", int(codes.PermissionDenied), nil))
+	}
+
+	pageSize := int(req.GetPageSize())
+	if pageSize == 0 {
+		pageSize = purchaseOrderListDefaultPageSize
+	}
+	pageSize = min(pageSize, purchaseOrderListMaxPageSize)
+
+	statuses := []string{}
+	if status := req.GetStatus(); status != pb.InventoryPurchaseOrderStatus_INVENTORY_PURCHASE_ORDER_STATUS_UNSPECIFIED {
+		statuses = append(statuses, intModels.GetInventoryPurchaseOrderStatus(status))
+	}
+
+	pos, err := c.store.InventoryPurchaseOrdersList(modelsCtx, sellerID, req.GetLocationId(), statuses, pageSize)
+	if err != nil {
+		return internalErr(err, "failed to list the purchase orders")
+	}
+
+	data := &pb.InventoryPurchaseOrderListResponseData{PurchaseOrders: make([]*pb.InventoryPurchaseOrderGetResponseData, 0, len(pos))}
+	for _, po := range pos {
+		lines, err := c.store.InventoryPurchaseOrderItemsGetByPurchaseOrderID(modelsCtx, nil, po.Id)
+		if err != nil {
+			return internalErr(err, "failed to get the purchase order items")
+		}
+		data.PurchaseOrders = append(data.PurchaseOrders, purchaseOrderData(po, lines))
+	}
+
+	return &pb.InventoryPurchaseOrderListResponse{Response: &pb.InventoryPurchaseOrderListResponse_Data{Data: data}}, nil
+}
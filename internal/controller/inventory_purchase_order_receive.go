@@ -0,0 +1,168 @@
+package controller
+
+import (
+	"context"
+	"time"
+
+	intModels "github.com/ahmad-khatib0-org/megacommerce-inventory/pkg/models"
+	pb "github.com/ahmad-khatib0-org/megacommerce-proto/gen/go/inventory/v1"
+	"github.com/ahmad-khatib0-org/megacommerce-shared-go/pkg/models"
+	"github.com/ahmad-khatib0-org/megacommerce-shared-go/pkg/utils"
+	"github.com/jackc/pgx/v5"
+	"google.golang.org/grpc/codes"
+)
+
+// InventoryPurchaseOrderReceive receives units of an open purchase order at its location, the given
+// quantities of the given skus, or everything still incoming if no items are given. The units move
+// from incoming into the on hand stock with an IN movement, and go to backordered reservation lines first.
+// The units of an item with lots are received into the lot that their item names
+func (c *Controller) InventoryPurchaseOrderReceive(ctx context.Context, req *pb.InventoryPurchaseOrderReceiveRequest) (*pb.InventoryPurchaseOrderReceiveResponse, error) {
+	path := "inventory.controller.InventoryPurchaseOrderReceive"
+	modelsCtx, ctxErr := models.ContextGet(ctx)
+	errBuilder := func(e *models.AppError, tx pgx.Tx) (*pb.InventoryPurchaseOrderReceiveResponse, error) {
+		if tx != nil {
+			if rbErr := tx.Rollback(modelsCtx.Context); rbErr != nil {
+				c.log.Errorf("%s: an error rolling back a transaction, err: %s", path, rbErr.Error())
+			}
+		}
+		return &pb.InventoryPurchaseOrderReceiveResponse{Response: &pb.InventoryPurchaseOrderReceiveResponse_Error{Error: models.AppErrorToProto(e)}}, nil
+	}
+
+	if ctxErr != nil {
+		return errBuilder(ctxErr, nil)
+	}
+	internalErr := func(err error, details string, tx pgx.Tx) (*pb.InventoryPurchaseOrderReceiveResponse, error) {
+		return errBuilder(models.NewAppError(modelsCtx, path, models.ErrMsgInternal, nil, details, int(codes.Internal), &models.AppErrorErrorsArgs{Err: err}), tx)
+	}
+	sucBuilder := func(data *pb.InventoryPurchaseOrderGetResponseData) (*pb.InventoryPurchaseOrderReceiveResponse, error) {
+		return &pb.InventoryPurchaseOrderReceiveResponse{Response: &pb.InventoryPurchaseOrderReceiveResponse_Data{Data: data}}, nil
+	}
+
+	rctx, cancel := context.WithTimeout(context.Background(), time.Second*12)
+	defer cancel()
+	modelsCtx.Context = rctx
+
+	ar := models.AuditRecordNew(modelsCtx, intModels.EventNameInventoryPurchaseOrderReceive, models.EventStatusFail)
+	defer func() {
+		ar.AuditEventDataPriorState(intModels.InventoryPurchaseOrderReceiveRequestAuditable(req))
+		c.ProcessAudit(ctx, ar)
+	}()
+
+	tx, err := c.store.GetTx(modelsCtx.Context, pgx.TxOptions{})
+	if err != nil {
+		return internalErr(err, "failed to begin transaction", nil)
+	}
+
+	po, lines, appErr := c.purchaseOrderGet(ctx, modelsCtx, path, tx, req.GetPoNumber())
+	if appErr != nil {
+		return errBuilder(appErr, tx)
+	}
+	if !purchaseOrderIsOpen(po) {
+		return errBuilder(purchaseOrderStatusInvalid(modelsCtx, path, po), tx)
+	}
+
+	receipts := make(map[string]int32, len(lines))
+	if len(req.GetItems()) == 0 {
+		for _, line := range lines {
+			if outstanding := line.QuantityOrdered - line.QuantityReceived; outstanding > 0 {
+				receipts[line.Sku] = outstanding
+			}
+		}
+	}
+	// the units of a line without a unit cost are costed at the average unit cost of their item
+	unitCosts := make(map[string]*int64, len(req.GetItems()))
+	// the units of an item with lots are received into the lot their item names
+	lotReceipts := make(map[string][]lotReceipt, len(req.GetItems()))
+	for _, item := range req.GetItems() {
+		line, found := utils.Find(lines, func(i *pb.InventoryPurchaseOrderItem) bool { return i.Sku == item.GetSku() })
+		if !found {
+			ei := map[string]*models.AppErrorError{item.GetSku(): {ID: "inventory.purchase_order.item_not_found"}}
+			return errBuilder(models.NewAppError(modelsCtx, path, "error.not_found", nil, "", int(codes.NotFound), &models.AppErrorErrorsArgs{ErrorsInternal: ei}), tx)
+		}
+
+		// a quantity of 0 receives whatever is still incoming, duplicated skus add up
+		quantity := int32(item.GetQuantity())
+		if quantity == 0 {
+			quantity = line.QuantityOrdered - line.QuantityReceived
+		}
+		receipts[line.Sku] += quantity
+		if item.GetLotNumber() != "" {
+			lotReceipts[line.Sku] = append(lotReceipts[line.Sku], lotReceipt{lotNumber: item.GetLotNumber(), expiresAt: item.ExpiresAt, quantity: quantity})
+		}
+		if item.UnitCost != nil {
+			unitCosts[line.Sku] = item.UnitCost
+		}
+	}
+
+	for _, line := range lines {
+		quantity, ok := receipts[line.Sku]
+		if !ok {
+			continue
+		}
+
+		received, err := c.store.InventoryPurchaseOrderItemReceive(modelsCtx, tx, line.Id, quantity)
+		if err != nil {
+			return internalErr(err, "failed to update the purchase order items", tx)
+		}
+		if quantity <= 0 || !received {
+			ei := map[string]*models.AppErrorError{
+				line.Sku: {ID: "inventory.purchase_order.quantity_exceeds_incoming", Params: map[string]any{"Quantity": line.QuantityOrdered - line.QuantityReceived}},
+			}
+			return errBuilder(models.NewAppError(modelsCtx, path, "inventory.purchase_order.quantity_exceeds_incoming", nil, "", int(codes.InvalidArgument), &models.AppErrorErrorsArgs{ErrorsInternal: ei}), tx)
+		}
+		line.QuantityReceived += quantity
+
+		inventory, err := c.store.InventoryItemGetBySku(modelsCtx, tx, po.SellerId, line.Sku, po.LocationId)
+		if err != nil {
+			return internalErr(err, "failed to get the inventory item", tx)
+		}
+		if appErr := c.stockFrozen(modelsCtx, path, tx, inventory.Id, line.Sku); appErr != nil {
+			return errBuilder(appErr, tx)
+		}
+
+		total := int(inventory.QuantityTotal + quantity)
+		available := int(inventory.QuantityAvailable + quantity)
+		if err := c.store.InventoryItemUpdate(modelsCtx, tx, inventory.Id, total, inventory.QuantityReserved, available); err != nil {
+			return internalErr(err, "failed to update the inventory item", tx)
+		}
+
+		for _, receipt := range lotReceipts[line.Sku] {
+			lot, errID, err := c.lotReceive(modelsCtx, tx, inventory.Id, receipt.lotNumber, receipt.expiresAt, receipt.quantity)
+			if err != nil {
+				return internalErr(err, "failed to receive the lot", tx)
+			}
+			if errID != "" {
+				ei := map[string]*models.AppErrorError{line.Sku: {ID: errID}}
+				return errBuilder(models.NewAppError(modelsCtx, path, errID, nil, "", int(codes.FailedPrecondition), &models.AppErrorErrorsArgs{ErrorsInternal: ei}), tx)
+			}
+			if err := c.purchaseOrderMovement(modelsCtx, tx, po, inventory.Id, &lot.Id, receipt.quantity, unitCosts[line.Sku]); err != nil {
+				return internalErr(err, "failed to create inventory movement", tx)
+			}
+			quantity -= receipt.quantity
+		}
+		// the units that no item named a lot for can only be received by an item without lots
+		if quantity > 0 {
+			if _, appErr := c.lotByNumber(modelsCtx, path, tx, inventory.Id, "", line.Sku); appErr != nil {
+				return errBuilder(appErr, tx)
+			}
+			if err := c.purchaseOrderMovement(modelsCtx, tx, po, inventory.Id, nil, quantity, unitCosts[line.Sku]); err != nil {
+				return internalErr(err, "failed to create inventory movement", tx)
+			}
+		}
+		if err := c.backorderAllocate(modelsCtx, tx, inventory.Id, int32(available)); err != nil {
+			return internalErr(err, "failed to allocate backordered inventory", tx)
+		}
+	}
+
+	po.Status = intModels.GetInventoryPurchaseOrderStatus(purchaseOrderStatusFromLines(lines))
+	if err := c.store.InventoryPurchaseOrderUpdateStatus(modelsCtx, tx, po.Id, po.Status); err != nil {
+		return internalErr(err, "failed to update the purchase order status", tx)
+	}
+
+	if err := tx.Commit(modelsCtx.Context); err != nil {
+		return internalErr(err, "failed to commit transaction", tx)
+	}
+
+	ar.Success()
+	return sucBuilder(purchaseOrderData(po, lines))
+}
@@ -0,0 +1,135 @@
+package controller
+
+import (
+	"context"
+	"time"
+
+	intModels "github.com/ahmad-khatib0-org/megacommerce-inventory/pkg/models"
+	pb "github.com/ahmad-khatib0-org/megacommerce-proto/gen/go/inventory/v1"
+	"github.com/ahmad-khatib0-org/megacommerce-shared-go/pkg/models"
+	"github.com/jackc/pgx/v5"
+	"google.golang.org/grpc/codes"
+)
+
+// InventoryPurchaseOrderSubmit submits a draft purchase order to its supplier, its units are incoming stock from
+// then on. The buyer can change the ordered units of a sku before submitting it (0 removes the sku), and the
+// expected date of the purchase order, which then becomes the expected date of every line
+func (c *Controller) InventoryPurchaseOrderSubmit(ctx context.Context, req *pb.InventoryPurchaseOrderSubmitRequest) (*pb.InventoryPurchaseOrderSubmitResponse, error) {
+	path := "inventory.controller.InventoryPurchaseOrderSubmit"
+	modelsCtx, ctxErr := models.ContextGet(ctx)
+	errBuilder := func(e *models.AppError, tx pgx.Tx) (*pb.InventoryPurchaseOrderSubmitResponse, error) {
+		if tx != nil {
+			if rbErr := tx.Rollback(modelsCtx.Context); rbErr != nil {
+				c.log.Errorf("%s: an error rolling back a transaction, err: %s", path, rbErr.Error())
+			}
+		}
+		return &pb.InventoryPurchaseOrderSubmitResponse{Response: &pb.InventoryPurchaseOrderSubmitResponse_Error{Error: models.AppErrorToProto(e)}}, nil
+	}
+
+	if ctxErr != nil {
+		return errBuilder(ctxErr, nil)
+	}
+	internalErr := func(err error, details string, tx pgx.Tx) (*pb.InventoryPurchaseOrderSubmitResponse, error) {
+		return errBuilder(models.NewAppError(modelsCtx, path, models.ErrMsgInternal, nil, details, int(codes.Internal), &models.AppErrorErrorsArgs{Err: err}), tx)
+	}
+	invalidArg := func(id string, tx pgx.Tx) (*pb.InventoryPurchaseOrderSubmitResponse, error) {
+		return errBuilder(models.NewAppError(modelsCtx, path, id, nil, "", int(codes.InvalidArgument), nil), tx)
+	}
+	sucBuilder := func(data *pb.InventoryPurchaseOrderGetResponseData) (*pb.InventoryPurchaseOrderSubmitResponse, error) {
+		return &pb.InventoryPurchaseOrderSubmitResponse{Response: &pb.InventoryPurchaseOrderSubmitResponse_Data{Data: data}}, nil
+	}
+
+	rctx, cancel := context.WithTimeout(context.Background(), time.Second*12)
+	defer cancel()
+	modelsCtx.Context = rctx
+
+	ar := models.AuditRecordNew(modelsCtx, intModels.EventNameInventoryPurchaseOrderSubmit, models.EventStatusFail)
+	defer func() {
+		ar.AuditEventDataPriorState(intModels.InventoryPurchaseOrderSubmitRequestAuditable(req))
+		c.ProcessAudit(ctx, ar)
+	}()
+
+	if req.ExpectedAt != nil && req.GetExpectedAt() == 0 {
+		return invalidArg("inventory.purchase_order.expected_at_required", nil)
+	}
+	quantities := make(map[string]uint32, len(req.GetItems()))
+	for _, item := range req.GetItems() {
+		if item.GetSku() == "" {
+			return invalidArg("inventory.purchase_order.invalid_item", nil)
+		}
+		if _, ok := quantities[item.GetSku()]; ok {
+			return invalidArg("inventory.purchase_order.duplicate_item", nil)
+		}
+		quantities[item.GetSku()] = item.GetQuantity()
+	}
+
+	tx, err := c.store.GetTx(modelsCtx.Context, pgx.TxOptions{})
+	if err != nil {
+		return internalErr(err, "failed to begin transaction", nil)
+	}
+
+	po, lines, appErr := c.purchaseOrderGet(ctx, modelsCtx, path, tx, req.GetPoNumber())
+	if appErr != nil {
+		return errBuilder(appErr, tx)
+	}
+	if intModels.GetInventoryPurchaseOrderStatusFromString(po.Status) != pb.InventoryPurchaseOrderStatus_INVENTORY_PURCHASE_ORDER_STATUS_DRAFT {
+		return errBuilder(purchaseOrderStatusInvalid(modelsCtx, path, po), tx)
+	}
+
+	onOrder := make(map[string]bool, len(lines))
+	for _, line := range lines {
+		onOrder[line.Sku] = true
+	}
+	for sku := range quantities {
+		if !onOrder[sku] {
+			ei := map[string]*models.AppErrorError{sku: {ID: "inventory.purchase_order.sku_not_on_order"}}
+			return errBuilder(models.NewAppError(modelsCtx, path, "error.not_found", nil, "", int(codes.NotFound), &models.AppErrorErrorsArgs{ErrorsInternal: ei}), tx)
+		}
+	}
+
+	if req.ExpectedAt != nil {
+		po.ExpectedAt = req.GetExpectedAt()
+	}
+	if req.Note != nil {
+		po.Note = req.Note
+	}
+
+	kept := lines[:0]
+	for _, line := range lines {
+		quantity, changed := quantities[line.Sku]
+		if changed && quantity == 0 {
+			if err := c.store.InventoryPurchaseOrderItemDelete(modelsCtx, tx, line.Id); err != nil {
+				return internalErr(err, "failed to delete a purchase order item", tx)
+			}
+			continue
+		}
+
+		if changed || req.ExpectedAt != nil {
+			if changed {
+				line.QuantityOrdered = int32(quantity)
+			}
+			if req.ExpectedAt != nil {
+				line.ExpectedAt = po.ExpectedAt
+			}
+			if err := c.store.InventoryPurchaseOrderItemUpdate(modelsCtx, tx, line.Id, line.QuantityOrdered, line.ExpectedAt); err != nil {
+				return internalErr(err, "failed to update a purchase order item", tx)
+			}
+		}
+		kept = append(kept, line)
+	}
+	if len(kept) == 0 {
+		return invalidArg("inventory.purchase_order.items_required", tx)
+	}
+
+	po.Status = intModels.GetInventoryPurchaseOrderStatus(pb.InventoryPurchaseOrderStatus_INVENTORY_PURCHASE_ORDER_STATUS_OPEN)
+	if err := c.store.InventoryPurchaseOrderUpdate(modelsCtx, tx, po); err != nil {
+		return internalErr(err, "failed to update the purchase order", tx)
+	}
+
+	if err := tx.Commit(modelsCtx.Context); err != nil {
+		return internalErr(err, "failed to commit transaction", tx)
+	}
+
+	ar.Success()
+	return sucBuilder(purchaseOrderData(po, kept))
+}
@@ -2,7 +2,6 @@ package controller
 
 import (
 	"context"
-	"fmt"
 	"time"
 
 	modelsInt "github.com/ahmad-khatib0-org/megacommerce-inventory/pkg/models"
@@ -13,14 +12,16 @@ import (
 	"google.golang.org/grpc/codes"
 )
 
-// InventoryRelease releases inventory reservation
+// InventoryRelease releases the given lines (or quantities of lines) of a reservation,
+// or the whole reservation if no items are given
 func (c *Controller) InventoryRelease(ctx context.Context, req *pb.InventoryReleaseRequest) (*pb.InventoryReleaseResponse, error) {
 	path := "inventory.controller.InventoryRelease"
 	modelsCtx, ctxErr := models.ContextGet(ctx)
 	errBuilder := func(e *models.AppError, tx pgx.Tx) (*pb.InventoryReleaseResponse, error) {
 		if tx != nil {
-			rbErr := tx.Rollback(modelsCtx.Context)
-			c.log.Errorf("%s: an error rolling back a transaction, err: %s", path, rbErr.Error())
+			if rbErr := tx.Rollback(modelsCtx.Context); rbErr != nil {
+				c.log.Errorf("%s: an error rolling back a transaction, err: %s", path, rbErr.Error())
+			}
 		}
 		return &pb.InventoryReleaseResponse{Response: &pb.InventoryReleaseResponse_Error{Error: models.AppErrorToProto(e)}}, nil
 	}
@@ -41,8 +42,8 @@ func (c *Controller) InventoryRelease(ctx context.Context, req *pb.InventoryRele
 
 	ar := models.AuditRecordNew(modelsCtx, modelsInt.EventNameInventoryRelease, models.EventStatusFail)
 	defer func() {
-		ar.AuditEventDataPriorState(map[string]any{"reservation_token": req.ReservationToken})
-		c.ProcessAudit(ar)
+		ar.AuditEventDataPriorState(modelsInt.InventoryReservationSettleAuditable(req.GetReservationToken(), req.GetItems()))
+		c.ProcessAudit(ctx, ar)
 	}()
 
 	tx, err := c.store.GetTx(modelsCtx.Context, pgx.TxOptions{})
@@ -66,29 +67,8 @@ func (c *Controller) InventoryRelease(ctx context.Context, req *pb.InventoryRele
 		return errBuilder(models.NewAppError(modelsCtx, path, "inventory.reservation.already_processed", nil, "", int(codes.InvalidArgument), nil), tx)
 	}
 
-	// Get reservation items
-	reservationItems, err := c.store.InventoryReservationItemsGetByReservationID(modelsCtx, tx, reservation.Id)
-	if err != nil {
-		return internalErr(err, "failed to get reservation items", tx)
-	}
-
-	// Release inventory for each item
-	for _, reservationItem := range reservationItems {
-		released, errDB := c.store.InventoryItemRelease(modelsCtx, tx, reservationItem.InventoryItemId, reservationItem.Quantity)
-		if errDB != nil {
-			return internalErr(err, "failed to release inventory", tx)
-		}
-		// TODO: this should not happen, and should be added to DLQ to be reviewed
-		if !released {
-			msg := "The requested quantity to be released is bigger than the quantity_reserved value"
-			return internalErr(err, fmt.Sprintf("failed to release inventory, %s", msg), tx)
-		}
-	}
-
-	// Update reservation status
-	released := modelsInt.GetInventoryReservationStatus(pb.InventoryReservationStatus_INVENTORY_RESERVATION_STATUS_RELEASED)
-	if err = c.store.InventoryReservationUpdateStatus(modelsCtx, tx, reservation.Id, released); err != nil {
-		return internalErr(err, "failed to update reservation status", tx)
+	if appErr := c.reservationSettle(ctx, modelsCtx, path, tx, reservation, req.GetItems(), false); appErr != nil {
+		return errBuilder(appErr, tx)
 	}
 
 	// Commit transaction
@@ -0,0 +1,187 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	intModels "github.com/ahmad-khatib0-org/megacommerce-inventory/pkg/models"
+	pb "github.com/ahmad-khatib0-org/megacommerce-proto/gen/go/inventory/v1"
+	"github.com/ahmad-khatib0-org/megacommerce-shared-go/pkg/models"
+	"github.com/ahmad-khatib0-org/megacommerce-shared-go/pkg/utils"
+	"github.com/jackc/pgx/v5"
+	"google.golang.org/grpc/codes"
+)
+
+// InventoryReorderPolicySet sets who an item is replenished from and how: the supplier, its lead time, its minimum
+// order quantity and the case pack that orders are rounded up to. The reorder point and the order up to level
+// are fixed if they're given, otherwise they're suggested by the demand forecast of the item each time it's replenished
+func (c *Controller) InventoryReorderPolicySet(ctx context.Context, req *pb.InventoryReorderPolicySetRequest) (*pb.InventoryReorderPolicySetResponse, error) {
+	path := "inventory.controller.InventoryReorderPolicySet"
+	modelsCtx, ctxErr := models.ContextGet(ctx)
+	errBuilder := func(e *models.AppError, tx pgx.Tx) (*pb.InventoryReorderPolicySetResponse, error) {
+		if tx != nil {
+			if rbErr := tx.Rollback(modelsCtx.Context); rbErr != nil {
+				c.log.Errorf("%s: an error rolling back a transaction, err: %s", path, rbErr.Error())
+			}
+		}
+		return &pb.InventoryReorderPolicySetResponse{Response: &pb.InventoryReorderPolicySetResponse_Error{Error: models.AppErrorToProto(e)}}, nil
+	}
+
+	if ctxErr != nil {
+		return errBuilder(ctxErr, nil)
+	}
+	internalErr := func(err error, details string, tx pgx.Tx) (*pb.InventoryReorderPolicySetResponse, error) {
+		return errBuilder(models.NewAppError(modelsCtx, path, models.ErrMsgInternal, nil, details, int(codes.Internal), &models.AppErrorErrorsArgs{Err: err}), tx)
+	}
+	invalidArg := func(id string) (*pb.InventoryReorderPolicySetResponse, error) {
+		return errBuilder(models.NewAppError(modelsCtx, path, id, nil, "", int(codes.InvalidArgument), nil), nil)
+	}
+	sucBuilder := func(data *pb.InventoryReorderPolicyData) (*pb.InventoryReorderPolicySetResponse, error) {
+		return &pb.InventoryReorderPolicySetResponse{Response: &pb.InventoryReorderPolicySetResponse_Data{Data: data}}, nil
+	}
+
+	rctx, cancel := context.WithTimeout(context.Background(), time.Second*12)
+	defer cancel()
+	modelsCtx.Context = rctx
+
+	ar := models.AuditRecordNew(modelsCtx, intModels.EventNameInventoryReorderPolicySet, models.EventStatusFail)
+	defer func() {
+		ar.AuditEventDataPriorState(intModels.InventoryReorderPolicySetRequestAuditable(req))
+		c.ProcessAudit(ctx, ar)
+	}()
+
+	sellerID, ok := sellerScope(ctx, req.GetSellerId())
+	if !ok {
+		return errBuilder(models.NewAppError(modelsCtx, path, "inventory.seller.forbidden", nil, "", int(codes.PermissionDenied), nil), nil)
+	}
+	if req.GetSupplierReference() == "" {
+		return invalidArg("inventory.reorder_policy.supplier_required")
+	}
+	if req.ReorderPoint != nil && req.OrderUpTo != nil && req.GetOrderUpTo() < req.GetReorderPoint() {
+		return invalidArg("inventory.reorder_policy.invalid_order_up_to")
+	}
+
+	tx, err := c.store.GetTx(modelsCtx.Context, pgx.TxOptions{})
+	if err != nil {
+		return internalErr(err, "failed to begin transaction", nil)
+	}
+
+	inventory, err := c.store.InventoryItemGetByProductVariant(modelsCtx, tx, sellerID, req.GetProductId(), req.GetVariantId(), req.GetLocationId())
+	if err != nil {
+		if appErr := itemAmbiguous(modelsCtx, path, err, fmt.Sprintf("%s.%s", req.GetProductId(), req.GetVariantId())); appErr != nil {
+			return errBuilder(appErr, tx)
+		}
+		if err.ErrType == models.DBErrorTypeNoRows {
+			key := fmt.Sprintf("%s.%s", req.GetProductId(), req.GetVariantId())
+			ei := map[string]*models.AppErrorError{key: {ID: "orders.items.not_found_in_inventory"}}
+			return errBuilder(models.NewAppError(modelsCtx, path, "error.not_found", nil, "", int(codes.NotFound), &models.AppErrorErrorsArgs{Err: err, ErrorsInternal: ei}), tx)
+		}
+		return internalErr(err, "failed to query inventory_items table", tx)
+	}
+
+	// an item is ordered in single units unless it's packed in cases
+	policy := &pb.InventoryReorderPolicy{
+		InventoryItemId:   inventory.Id,
+		SupplierReference: req.GetSupplierReference(),
+		LeadTimeDays:      int32(req.GetLeadTimeDays()),
+		MinOrderQuantity:  int32(req.GetMinOrderQuantity()),
+		CasePack:          int32(max(req.GetCasePack(), 1)),
+		CreatedAt:         utils.TimeGetMillis(),
+	}
+	if req.ReorderPoint != nil {
+		reorderPoint := int32(req.GetReorderPoint())
+		policy.ReorderPoint = &reorderPoint
+	}
+	if req.OrderUpTo != nil {
+		orderUpTo := int32(req.GetOrderUpTo())
+		policy.OrderUpTo = &orderUpTo
+	}
+	if err := c.store.InventoryReorderPolicyUpsert(modelsCtx, tx, policy); err != nil {
+		return internalErr(err, "failed to set the reorder policy", tx)
+	}
+
+	policy, err = c.store.InventoryReorderPolicyGet(modelsCtx, tx, inventory.Id)
+	if err != nil {
+		return internalErr(err, "failed to get the reorder policy", tx)
+	}
+
+	if err := tx.Commit(modelsCtx.Context); err != nil {
+		return internalErr(err, "failed to commit transaction", tx)
+	}
+
+	ar.Success()
+	return sucBuilder(reorderPolicyData(inventory, policy))
+}
+
+// InventoryReorderPolicyGet gets the reorder policy of an item
+func (c *Controller) InventoryReorderPolicyGet(ctx context.Context, req *pb.InventoryReorderPolicyGetRequest) (*pb.InventoryReorderPolicyGetResponse, error) {
+	path := "inventory.controller.InventoryReorderPolicyGet"
+	errBuilder := func(e *models.AppError) (*pb.InventoryReorderPolicyGetResponse, error) {
+		return &pb.InventoryReorderPolicyGetResponse{Response: &pb.InventoryReorderPolicyGetResponse_Error{Error: models.AppErrorToProto(e)}}, nil
+	}
+
+	modelsCtx, ctxErr := models.ContextGet(ctx)
+	if ctxErr != nil {
+		return errBuilder(ctxErr)
+	}
+	internalErr := func(err error, details string) (*pb.InventoryReorderPolicyGetResponse, error) {
+		return errBuilder(models.NewAppError(modelsCtx, path, models.ErrMsgInternal, nil, details, int(codes.Internal), &models.AppErrorErrorsArgs{Err: err}))
+	}
+
+	rctx, cancel := context.WithTimeout(context.Background(), time.Second*12)
+	defer cancel()
+	modelsCtx.Context = rctx
+
+	sellerID, ok := sellerScope(ctx, req.GetSellerId())
+	if !ok {
+		return errBuilder(models.NewAppError(modelsCtx, path, "inventory.seller.forbidden", nil, "", int(codes.PermissionDenied), nil))
+	}
+
+	inventory, err := c.store.InventoryItemGetByProductVariant(modelsCtx, nil, sellerID, req.GetProductId(), req.GetVariantId(), req.GetLocationId())
+	if err != nil {
+		if appErr := itemAmbiguous(modelsCtx, path, err, fmt.Sprintf("%s.%s", req.GetProductId(), req.GetVariantId())); appErr != nil {
+			return errBuilder(appErr)
+		}
+		if err.ErrType == models.DBErrorTypeNoRows {
+			key := fmt.Sprintf("%s.%s", req.GetProductId(), req.GetVariantId())
+			ei := map[string]*models.AppErrorError{key: {ID: "orders.items.not_found_in_inventory"}}
+			return errBuilder(models.NewAppError(modelsCtx, path, "error.not_found", nil, "", int(codes.NotFound), &models.AppErrorErrorsArgs{Err: err, ErrorsInternal: ei}))
+		}
+		return internalErr(err, "failed to query inventory_items table")
+	}
+
+	policy, err := c.store.InventoryReorderPolicyGet(modelsCtx, nil, inventory.Id)
+	if err != nil {
+		if err.ErrType == models.DBErrorTypeNoRows {
+			return errBuilder(models.NewAppError(modelsCtx, path, "inventory.reorder_policy.not_found", nil, "", int(codes.NotFound), &models.AppErrorErrorsArgs{Err: err}))
+		}
+		return internalErr(err, "failed to get the reorder policy")
+	}
+
+	return &pb.InventoryReorderPolicyGetResponse{Response: &pb.InventoryReorderPolicyGetResponse_Data{Data: reorderPolicyData(inventory, policy)}}, nil
+}
+
+// reorderPolicyData converts the reorder policy of an inventory item to the response format
+func reorderPolicyData(inventory *pb.InventoryItem, policy *pb.InventoryReorderPolicy) *pb.InventoryReorderPolicyData {
+	data := &pb.InventoryReorderPolicyData{
+		ProductId:         inventory.ProductId,
+		VariantId:         inventory.VariantId,
+		Sku:               inventory.Sku,
+		LocationId:        inventory.LocationId,
+		SupplierReference: policy.SupplierReference,
+		LeadTimeDays:      uint32(policy.LeadTimeDays),
+		MinOrderQuantity:  uint32(policy.MinOrderQuantity),
+		CasePack:          uint32(policy.CasePack),
+		UpdatedAt:         policy.UpdatedAt,
+	}
+	if policy.ReorderPoint != nil {
+		reorderPoint := uint32(*policy.ReorderPoint)
+		data.ReorderPoint = &reorderPoint
+	}
+	if policy.OrderUpTo != nil {
+		orderUpTo := uint32(*policy.OrderUpTo)
+		data.OrderUpTo = &orderUpTo
+	}
+	return data
+}
@@ -0,0 +1,320 @@
+package controller
+
+import (
+	"context"
+	"slices"
+	"time"
+
+	intModels "github.com/ahmad-khatib0-org/megacommerce-inventory/pkg/models"
+	pb "github.com/ahmad-khatib0-org/megacommerce-proto/gen/go/inventory/v1"
+	"github.com/ahmad-khatib0-org/megacommerce-shared-go/pkg/models"
+	"github.com/ahmad-khatib0-org/megacommerce-shared-go/pkg/utils"
+	"github.com/jackc/pgx/v5"
+	"google.golang.org/grpc/codes"
+)
+
+// replenishmentLine is an item that a replenishment run orders, and the suggestion it's ordered by
+type replenishmentLine struct {
+	item       *pb.InventoryItem
+	suggestion *pb.InventoryReplenishmentSuggestion
+}
+
+// InventoryReplenishmentRun scans the items that have a reorder policy and drafts purchase orders for the ones whose
+// stock position is below their reorder point, one per seller, supplier and location. The drafts are reviewed and
+// submitted by buyers, items that are already on a draft are left out. A dry run only returns the suggestions
+func (c *Controller) InventoryReplenishmentRun(ctx context.Context, req *pb.InventoryReplenishmentRunRequest) (*pb.InventoryReplenishmentRunResponse, error) {
+	path := "inventory.controller.InventoryReplenishmentRun"
+	errBuilder := func(e *models.AppError) (*pb.InventoryReplenishmentRunResponse, error) {
+		return &pb.InventoryReplenishmentRunResponse{Response: &pb.InventoryReplenishmentRunResponse_Error{Error: models.AppErrorToProto(e)}}, nil
+	}
+
+	modelsCtx, ctxErr := models.ContextGet(ctx)
+	if ctxErr != nil {
+		return errBuilder(ctxErr)
+	}
+	internalErr := func(err error, details string) (*pb.InventoryReplenishmentRunResponse, error) {
+		return errBuilder(models.NewAppError(modelsCtx, path, models.ErrMsgInternal, nil, details, int(codes.Internal), &models.AppErrorErrorsArgs{Err: err}))
+	}
+
+	// a run scans every item of the seller, it takes longer than a single item request
+	rctx, cancel := context.WithTimeout(context.Background(), time.Minute*5)
+	defer cancel()
+	modelsCtx.Context = rctx
+
+	ar := models.AuditRecordNew(modelsCtx, intModels.EventNameInventoryReplenishmentRun, models.EventStatusFail)
+	defer func() {
+		ar.AuditEventDataPriorState(intModels.InventoryReplenishmentRunRequestAuditable(req))
+		c.ProcessAudit(ctx, ar)
+	}()
+
+	sellerID, ok := sellerScope(ctx, req.GetSellerId())
+	if !ok {
+		return errBuilder(models.NewAppError(modelsCtx, path, "inventory.seller.forbidden", nil, "", int(codes.PermissionDenied), nil))
+	}
+
+	data, err := c.replenish(modelsCtx, sellerID, req.GetLocationId(), req.GetDryRun())
+	if err != nil {
+		return internalErr(err, "failed to replenish the inventory items")
+	}
+
+	ar.Success()
+	return &pb.InventoryReplenishmentRunResponse{Response: &pb.InventoryReplenishmentRunResponse_Data{Data: data}}, nil
+}
+
+// InventoryReplenishmentSchedule drafts the purchase orders of every seller at the configured hour until ctx is
+// done. Running it twice a day drafts nothing new, the items of the first run's drafts are left out
+func (c *Controller) InventoryReplenishmentSchedule(ctx context.Context) {
+	runDaily(ctx, c.cfg.Replenishment.Hour, c.replenishmentRun)
+}
+
+// replenishmentRun drafts the purchase orders of every seller and location
+func (c *Controller) replenishmentRun(ctx context.Context) {
+	path := "inventory.controller.replenishmentRun"
+	rctx, cancel := context.WithTimeout(ctx, time.Minute*10)
+	defer cancel()
+	mctx := &models.Context{Context: rctx}
+
+	data, err := c.replenish(mctx, "", "", false)
+	if err != nil {
+		c.log.Errorf("%s: failed to replenish the inventory items, err: %v", path, err)
+		return
+	}
+	c.log.Infof("%s: suggested %d items, drafted %d purchase orders", path, len(data.Suggestions), len(data.PurchaseOrders))
+}
+
+// replenish suggests how many units to order of the items of a seller at a location (every seller or location if
+// they're empty), and drafts a purchase order for each seller, supplier and location unless dryRun is set. The
+// stock position of an item is its available units, its units in transit, and its units on open purchase orders.
+// The reorder point and the order up to level of a policy that doesn't fix them are the ones of the forecast
+func (c *Controller) replenish(mctx *models.Context, sellerID string, locationID string, dryRun bool) (*pb.InventoryReplenishmentRunResponseData, *models.DBError) {
+	params, _ := c.forecastParamsGet(pb.InventoryForecastMethod_INVENTORY_FORECAST_METHOD_UNSPECIFIED, nil, nil)
+	now := utils.TimeGetMillis()
+
+	groups := make(map[intModels.InventoryReplenishmentGroup][]*replenishmentLine)
+	keys := make([]intModels.InventoryReplenishmentGroup, 0)
+	data := &pb.InventoryReplenishmentRunResponseData{}
+
+	afterID := ""
+	for {
+		items, err := c.store.InventoryItemsList(mctx, sellerID, locationID, afterID, exportMaxBatchSize)
+		if err != nil {
+			return nil, err
+		}
+		if len(items) == 0 {
+			break
+		}
+
+		lines, err := c.replenishItems(mctx, items, params, now)
+		if err != nil {
+			return nil, err
+		}
+		for _, line := range lines {
+			key := intModels.InventoryReplenishmentGroup{
+				SellerID:          line.item.SellerId,
+				SupplierReference: line.suggestion.SupplierReference,
+				LocationID:        line.item.GetLocationId(),
+			}
+			if _, ok := groups[key]; !ok {
+				keys = append(keys, key)
+			}
+			groups[key] = append(groups[key], line)
+			data.Suggestions = append(data.Suggestions, line.suggestion)
+		}
+
+		if len(items) < exportMaxBatchSize {
+			break
+		}
+		afterID = items[len(items)-1].Id
+	}
+
+	if dryRun {
+		return data, nil
+	}
+
+	for _, key := range keys {
+		po, lines, err := c.replenishmentDraft(mctx, key, groups[key], now)
+		if err != nil {
+			return nil, err
+		}
+		if po == nil {
+			continue
+		}
+		data.PurchaseOrders = append(data.PurchaseOrders, purchaseOrderData(po, lines))
+	}
+
+	return data, nil
+}
+
+// replenishItems suggests the order of each item of a batch that has a reorder policy, a location to
+// be delivered to, and no draft purchase order yet. The items that don't need units are left out
+func (c *Controller) replenishItems(mctx *models.Context, items []*pb.InventoryItem, params forecastParams, now int64) ([]*replenishmentLine, *models.DBError) {
+	ids := make([]string, len(items))
+	for i, item := range items {
+		ids[i] = item.Id
+	}
+
+	policies, err := c.store.InventoryReorderPoliciesGetByItems(mctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	drafted, err := c.store.InventoryPurchaseOrderDraftedItems(mctx, nil, ids)
+	if err != nil {
+		return nil, err
+	}
+	incoming, err := c.store.InventoryPurchaseOrderIncomingByItems(mctx, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	candidates := make([]*pb.InventoryItem, 0, len(items))
+	forecasted := make([]*pb.InventoryItem, 0, len(items))
+	for _, item := range items {
+		policy, ok := policies[item.Id]
+		if !ok || item.LocationId == nil || drafted[item.Id] {
+			continue
+		}
+		candidates = append(candidates, item)
+		if policy.ReorderPoint == nil || policy.OrderUpTo == nil {
+			forecasted = append(forecasted, item)
+		}
+	}
+
+	forecasts := make(map[string]*pb.InventoryForecastData, len(forecasted))
+	if len(forecasted) > 0 {
+		result, err := c.forecastItems(mctx, forecasted, params)
+		if err != nil {
+			return nil, err
+		}
+		for i, f := range result {
+			forecasts[forecasted[i].Id] = f
+		}
+	}
+
+	lines := make([]*replenishmentLine, 0, len(candidates))
+	for _, item := range candidates {
+		policy := policies[item.Id]
+
+		var reorderPoint, orderUpTo int64
+		if f, ok := forecasts[item.Id]; ok {
+			reorderPoint, orderUpTo = int64(f.ReorderPoint), int64(f.OrderUpTo)
+		}
+		if policy.ReorderPoint != nil {
+			reorderPoint = int64(*policy.ReorderPoint)
+		}
+		if policy.OrderUpTo != nil {
+			orderUpTo = int64(*policy.OrderUpTo)
+		}
+
+		available := int64(max(item.QuantityAvailable, 0))
+		position := available + int64(item.QuantityInTransit) + incoming[item.Id]
+		quantity := intModels.InventoryReplenishmentQuantity(position, reorderPoint, orderUpTo, int64(policy.MinOrderQuantity), int64(policy.CasePack))
+		if quantity == 0 {
+			continue
+		}
+
+		lines = append(lines, &replenishmentLine{
+			item: item,
+			suggestion: &pb.InventoryReplenishmentSuggestion{
+				ProductId:         item.ProductId,
+				VariantId:         item.VariantId,
+				Sku:               item.Sku,
+				LocationId:        item.GetLocationId(),
+				SupplierReference: policy.SupplierReference,
+				QuantityAvailable: uint32(available),
+				QuantityInTransit: uint32(item.QuantityInTransit),
+				QuantityIncoming:  uint32(incoming[item.Id]),
+				ReorderPoint:      uint32(reorderPoint),
+				OrderUpTo:         uint32(orderUpTo),
+				OrderQuantity:     uint32(quantity),
+				LeadTimeDays:      uint32(policy.LeadTimeDays),
+				ExpectedAt:        now + int64(policy.LeadTimeDays)*intModels.InventoryDayMillis,
+			},
+		})
+	}
+
+	return lines, nil
+}
+
+// replenishmentDraft drafts the purchase order of a group of lines, each line is expected after the lead time of
+// its supplier and the purchase order at its earliest line. Every draft is created in its own transaction, so the
+// drafts of a run that fails part way are kept, and the next run leaves their items out. The drafts of concurrent
+// runs are serialized, the lines whose items were drafted since they were suggested are left out, and no
+// purchase order is returned if none is left
+func (c *Controller) replenishmentDraft(mctx *models.Context, key intModels.InventoryReplenishmentGroup, lines []*replenishmentLine, now int64) (*pb.InventoryPurchaseOrder, []*pb.InventoryPurchaseOrderItem, *models.DBError) {
+	path := "inventory.controller.replenishmentDraft"
+	rollback := func(tx pgx.Tx) {
+		if rbErr := tx.Rollback(mctx.Context); rbErr != nil {
+			c.log.Errorf("%s: an error rolling back a transaction, err: %s", path, rbErr.Error())
+		}
+	}
+
+	tx, err := c.store.GetTx(mctx.Context, pgx.TxOptions{})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// the scheduled run of every replica and the rpc can draft at the same time
+	if err := c.store.InventoryReplenishmentLock(mctx, tx); err != nil {
+		rollback(tx)
+		return nil, nil, err
+	}
+	ids := make([]string, len(lines))
+	for i, line := range lines {
+		ids[i] = line.item.Id
+	}
+	drafted, err := c.store.InventoryPurchaseOrderDraftedItems(mctx, tx, ids)
+	if err != nil {
+		rollback(tx)
+		return nil, nil, err
+	}
+	lines = slices.DeleteFunc(lines, func(line *replenishmentLine) bool { return drafted[line.item.Id] })
+	if len(lines) == 0 {
+		rollback(tx)
+		return nil, nil, nil
+	}
+
+	supplierReference := key.SupplierReference
+	po := &pb.InventoryPurchaseOrder{
+		Id:                utils.NewID(),
+		PoNumber:          "po_" + utils.NewID(),
+		SellerId:          key.SellerID,
+		LocationId:        key.LocationID,
+		SupplierReference: &supplierReference,
+		Status:            intModels.GetInventoryPurchaseOrderStatus(pb.InventoryPurchaseOrderStatus_INVENTORY_PURCHASE_ORDER_STATUS_DRAFT),
+		ExpectedAt:        lines[0].suggestion.ExpectedAt,
+		CreatedAt:         now,
+	}
+	for _, line := range lines {
+		po.ExpectedAt = min(po.ExpectedAt, line.suggestion.ExpectedAt)
+	}
+
+	if err := c.store.InventoryPurchaseOrderCreate(mctx, tx, po); err != nil {
+		rollback(tx)
+		return nil, nil, err
+	}
+
+	items := make([]*pb.InventoryPurchaseOrderItem, 0, len(lines))
+	for _, line := range lines {
+		item := &pb.InventoryPurchaseOrderItem{
+			Id:              utils.NewID(),
+			PurchaseOrderId: po.Id,
+			Sku:             line.item.Sku,
+			InventoryItemId: line.item.Id,
+			QuantityOrdered: int32(line.suggestion.OrderQuantity),
+			ExpectedAt:      line.suggestion.ExpectedAt,
+			CreatedAt:       now,
+		}
+		if err := c.store.InventoryPurchaseOrderItemCreate(mctx, tx, item); err != nil {
+			rollback(tx)
+			return nil, nil, err
+		}
+		items = append(items, item)
+	}
+
+	if err := tx.Commit(mctx.Context); err != nil {
+		rollback(tx)
+		return nil, nil, models.HandleDBError(mctx, err, path, nil)
+	}
+
+	return po, items, nil
+}
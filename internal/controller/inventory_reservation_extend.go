@@ -0,0 +1,115 @@
+package controller
+
+import (
+	"context"
+	"time"
+
+	intModels "github.com/ahmad-khatib0-org/megacommerce-inventory/pkg/models"
+	pb "github.com/ahmad-khatib0-org/megacommerce-proto/gen/go/inventory/v1"
+	"github.com/ahmad-khatib0-org/megacommerce-shared-go/pkg/models"
+	"github.com/ahmad-khatib0-org/megacommerce-shared-go/pkg/utils"
+	"github.com/jackc/pgx/v5"
+	"google.golang.org/grpc/codes"
+)
+
+// reservationDefaultMaxLifetime is used if reservations.max_lifetime_seconds is not configured
+const reservationDefaultMaxLifetime = 30 * time.Minute
+
+// InventoryReservationExtend pushes the expiry of an active reservation forward, up to
+// the configured maximum lifetime of a reservation
+func (c *Controller) InventoryReservationExtend(ctx context.Context, req *pb.InventoryReservationExtendRequest) (*pb.InventoryReservationExtendResponse, error) {
+	path := "inventory.controller.InventoryReservationExtend"
+	modelsCtx, ctxErr := models.ContextGet(ctx)
+	errBuilder := func(e *models.AppError, tx pgx.Tx) (*pb.InventoryReservationExtendResponse, error) {
+		if tx != nil {
+			if rbErr := tx.Rollback(modelsCtx.Context); rbErr != nil {
+				c.log.Errorf("%s: an error rolling back a transaction, err: %s", path, rbErr.Error())
+			}
+		}
+		return &pb.InventoryReservationExtendResponse{Response: &pb.InventoryReservationExtendResponse_Error{Error: models.AppErrorToProto(e)}}, nil
+	}
+
+	if ctxErr != nil {
+		return errBuilder(ctxErr, nil)
+	}
+	internalErr := func(err error, details string, tx pgx.Tx) (*pb.InventoryReservationExtendResponse, error) {
+		return errBuilder(models.NewAppError(modelsCtx, path, models.ErrMsgInternal, nil, details, int(codes.Internal), &models.AppErrorErrorsArgs{Err: err}), tx)
+	}
+	sucBuilder := func(data *pb.InventoryReservationExtendResponseData) (*pb.InventoryReservationExtendResponse, error) {
+		return &pb.InventoryReservationExtendResponse{Response: &pb.InventoryReservationExtendResponse_Data{Data: data}}, nil
+	}
+
+	rctx, cancel := context.WithTimeout(context.Background(), time.Second*12)
+	defer cancel()
+	modelsCtx.Context = rctx
+
+	auditable := map[string]any{"reservation_token": req.GetReservationToken(), "extend_seconds": req.GetExtendSeconds()}
+	ar := models.AuditRecordNew(modelsCtx, intModels.EventNameInventoryReservationExtend, models.EventStatusFail)
+	defer func() {
+		ar.AuditEventDataPriorState(auditable)
+		c.ProcessAudit(ctx, ar)
+	}()
+
+	if req.GetExtendSeconds() == 0 {
+		return errBuilder(models.NewAppError(modelsCtx, path, "inventory.reservation.extend_seconds_required", nil, "", int(codes.InvalidArgument), nil), nil)
+	}
+
+	tx, err := c.store.GetTx(modelsCtx.Context, pgx.TxOptions{})
+	if err != nil {
+		return internalErr(err, "failed to begin transaction", nil)
+	}
+
+	reservation, err := c.store.InventoryReservationGetByToken(modelsCtx, tx, req.GetReservationToken())
+	if err != nil {
+		if err.ErrType == models.DBErrorTypeNoRows {
+			return errBuilder(models.NewAppError(modelsCtx, path, "inventory.reservation.not_found", nil, err.Details, int(codes.NotFound), &models.AppErrorErrorsArgs{Err: err}), tx)
+		}
+		return internalErr(err, "failed to get reservation", tx)
+	}
+	auditable["expires_at_before"] = reservation.ExpiresAt
+
+	status := intModels.GetInventoryReservationStatusFromString(reservation.Status)
+	if status != pb.InventoryReservationStatus_INVENTORY_RESERVATION_STATUS_RESERVED &&
+		status != pb.InventoryReservationStatus_INVENTORY_RESERVATION_STATUS_PARTIALLY_RESERVED {
+		return errBuilder(models.NewAppError(modelsCtx, path, "inventory.reservation.already_processed", nil, "", int(codes.FailedPrecondition), nil), tx)
+	}
+
+	now := utils.TimeGetMillis()
+	if reservation.ExpiresAt <= now {
+		return errBuilder(models.NewAppError(modelsCtx, path, "inventory.reservation.expired", nil, "", int(codes.FailedPrecondition), nil), tx)
+	}
+
+	maxLifetime := reservationDefaultMaxLifetime
+	if c.cfg.Reservations.MaxLifetimeSeconds > 0 {
+		maxLifetime = time.Duration(c.cfg.Reservations.MaxLifetimeSeconds) * time.Second
+	}
+	deadline := reservation.CreatedAt + maxLifetime.Milliseconds()
+	if reservation.ExpiresAt >= deadline {
+		params := map[string]any{"Seconds": int(maxLifetime.Seconds())}
+		return errBuilder(models.NewAppError(modelsCtx, path, "inventory.reservation.max_lifetime_reached", params, "", int(codes.FailedPrecondition), nil), tx)
+	}
+
+	// an extension past the deadline is cut short rather than rejected, the caller gets the actual expiry back
+	expiresAt := min(reservation.ExpiresAt+int64(req.GetExtendSeconds())*1000, deadline)
+	extended, err := c.store.InventoryReservationExtend(modelsCtx, tx, reservation.Id, reservation.Status, expiresAt)
+	if err != nil {
+		return internalErr(err, "failed to extend the reservation", tx)
+	}
+	// the reservation was released, fulfilled or expired since it was read
+	if !extended {
+		return errBuilder(models.NewAppError(modelsCtx, path, "inventory.reservation.already_processed", nil, "", int(codes.FailedPrecondition), nil), tx)
+	}
+
+	if err := tx.Commit(modelsCtx.Context); err != nil {
+		return internalErr(err, "failed to commit transaction", tx)
+	}
+
+	auditable["expires_at_after"] = expiresAt
+	ar.Success()
+
+	return sucBuilder(&pb.InventoryReservationExtendResponseData{
+		ReservationToken: reservation.ReservationToken,
+		ExpiresAt:        expiresAt,
+		MaxExpiresAt:     deadline,
+	})
+}
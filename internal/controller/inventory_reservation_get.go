@@ -2,6 +2,7 @@ package controller
 
 import (
 	"context"
+	"math"
 	"time"
 
 	intMod "github.com/ahmad-khatib0-org/megacommerce-inventory/pkg/models"
@@ -43,42 +44,137 @@ func (c *Controller) InventoryReservationGet(ctx context.Context, req *pb.Invent
 		}
 	}
 
-	// Get reservation items
-	reservationItems, err := c.store.InventoryReservationItemsGetByReservationID(modelsCtx, nil, reservation.Id)
+	// Get reservation items, a seller only sees its own lines of the reservation
+	sellerID, ok := sellerScope(ctx, "")
+	if !ok {
+		return errBuilder(models.NewAppError(modelsCtx, path, "inventory.seller.forbidden", nil, "", int(codes.PermissionDenied), nil))
+	}
+	reservationItems, err := c.store.InventoryReservationItemsGetByReservationID(modelsCtx, nil, sellerID, reservation.Id)
 	if err != nil {
 		return internalErr(err, "failed to get reservation items")
 	}
 
-	ids := make([]string, 0, len(reservationItems))
-	for _, item := range reservationItems {
-		ids = append(ids, item.Id)
+	// don't reveal that a reservation exists to a seller that has no lines in it
+	if sellerID != "" && len(reservationItems) == 0 {
+		return errBuilder(models.NewAppError(modelsCtx, path, "inventory.reservation.not_found", nil, "", int(codes.NotFound), nil))
 	}
 
-	inventoryItems, err := c.store.InventoryItemGetByIDs(modelsCtx, ids)
+	data, err := c.reservationGetData(modelsCtx, sellerID, reservation, reservationItems)
 	if err != nil {
 		return internalErr(err, "failed to get inventory item")
 	}
 
-	// Convert to response format
+	return sucBuilder(data)
+}
+
+// reservationGetData builds the reservation details from its reservation items,
+// sellerID limits the inventory items to a single seller if it's not empty
+func (c *Controller) reservationGetData(ctx *models.Context, sellerID string, reservation *pb.InventoryReservation, reservationItems []*pb.InventoryReservationItem) (*pb.InventoryReservationGetResponseData, *models.DBError) {
+	ids := make([]string, 0, len(reservationItems))
+	kitIDs := make([]string, 0)
+	for _, item := range reservationItems {
+		ids = append(ids, item.InventoryItemId)
+		if item.KitId != nil {
+			kitIDs = append(kitIDs, *item.KitId)
+		}
+	}
+
+	inventoryItems := []*pb.InventoryItem{}
+	if len(ids) > 0 {
+		var err *models.DBError
+		inventoryItems, err = c.store.InventoryItemGetByIDs(ctx, sellerID, ids)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	kits := []*pb.InventoryKit{}
+	if len(kitIDs) > 0 {
+		var err *models.DBError
+		kits, err = c.store.InventoryKitGetByIDs(ctx, kitIDs)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return reservationData(reservation, reservationItems, inventoryItems, kits), nil
+}
+
+// reservationData converts a reservation to the response format, reservationItems are the lines of this
+// reservation, inventoryItems must contain the inventory item of every line, and kits the kit of every
+// kit component line. The component lines of a kit are reported as a single line of the kit
+func reservationData(reservation *pb.InventoryReservation, reservationItems []*pb.InventoryReservationItem, inventoryItems []*pb.InventoryItem, kits []*pb.InventoryKit) *pb.InventoryReservationGetResponseData {
 	items := make([]*pb.InventoryReservationListItem, 0, len(reservationItems))
-	for _, reservationItem := range inventoryItems {
-		item, _ := utils.Find(reservationItems, func(i *pb.InventoryReservationItem) bool { return i.InventoryItemId == reservationItem.Id })
+	reportedKits := make(map[string]bool)
+	for _, item := range reservationItems {
+		if item.KitId != nil {
+			if reportedKits[*item.KitId] {
+				continue
+			}
+			reportedKits[*item.KitId] = true
+
+			kit, found := utils.Find(kits, func(k *pb.InventoryKit) bool { return k.Id == *item.KitId })
+			if !found {
+				continue
+			}
+			items = append(items, kitLineData(kit, kitLines(reservationItems, kit.Id)))
+			continue
+		}
+
+		inventory, found := utils.Find(inventoryItems, func(i *pb.InventoryItem) bool { return i.Id == item.InventoryItemId })
+		if !found {
+			continue
+		}
 
-		// TODO: populate the status field
+		// a line keeps its released and fulfilled units in the requested quantity
+		status := pb.InventoryReservationItemStatus_INVENTORY_RESERVATION_ITEM_STATUS_RESERVED
+		switch {
+		case item.QuantityBackordered > 0:
+			status = pb.InventoryReservationItemStatus_INVENTORY_RESERVATION_ITEM_STATUS_BACKORDERED
+		case item.Quantity == 0:
+			status = pb.InventoryReservationItemStatus_INVENTORY_RESERVATION_ITEM_STATUS_NOT_RESERVED
+		}
 		items = append(items, &pb.InventoryReservationListItem{
-			ProductId:         reservationItem.ProductId,
-			VariantId:         reservationItem.VariantId,
-			Sku:               reservationItem.Sku,
-			QuantityRequested: uint32(item.Quantity),
-			QuantityReserved:  uint32(item.Quantity),
+			ProductId:           inventory.ProductId,
+			VariantId:           inventory.VariantId,
+			Sku:                 inventory.Sku,
+			QuantityRequested:   uint32(item.Quantity + item.QuantityBackordered + item.QuantityReleased + item.QuantityFulfilled),
+			QuantityReserved:    uint32(item.Quantity),
+			QuantityBackordered: uint32(item.QuantityBackordered),
+			Status:              status,
 		})
 	}
 
-	return sucBuilder(&pb.InventoryReservationGetResponseData{
+	return &pb.InventoryReservationGetResponseData{
 		ReservationToken: reservation.ReservationToken,
 		OrderId:          reservation.OrderId,
+		Channel:          reservation.Channel,
 		Status:           intMod.GetInventoryReservationStatusFromString(reservation.Status),
 		ExpiresAt:        reservation.ExpiresAt,
 		Items:            items,
-	})
+	}
+}
+
+// kitLineData reports the component lines of a kit as a single line, a kit counts as
+// reserved, released or fulfilled only as far as every one of its components is
+func kitLineData(kit *pb.InventoryKit, lines []*pb.InventoryReservationItem) *pb.InventoryReservationListItem {
+	requested, reserved := int32(math.MaxInt32), int32(math.MaxInt32)
+	for _, line := range lines {
+		requested = min(requested, (line.Quantity+line.QuantityReleased+line.QuantityFulfilled)/line.KitQuantity)
+		reserved = min(reserved, line.Quantity/line.KitQuantity)
+	}
+
+	status := pb.InventoryReservationItemStatus_INVENTORY_RESERVATION_ITEM_STATUS_RESERVED
+	if reserved == 0 {
+		status = pb.InventoryReservationItemStatus_INVENTORY_RESERVATION_ITEM_STATUS_NOT_RESERVED
+	}
+
+	return &pb.InventoryReservationListItem{
+		ProductId:         kit.ProductId,
+		VariantId:         kit.VariantId,
+		Sku:               kit.Sku,
+		QuantityRequested: uint32(requested),
+		QuantityReserved:  uint32(reserved),
+		Status:            status,
+	}
 }
@@ -0,0 +1,170 @@
+package controller
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	intModels "github.com/ahmad-khatib0-org/megacommerce-inventory/pkg/models"
+	pb "github.com/ahmad-khatib0-org/megacommerce-proto/gen/go/inventory/v1"
+	"github.com/ahmad-khatib0-org/megacommerce-shared-go/pkg/models"
+	"google.golang.org/grpc/codes"
+)
+
+const (
+	reservationListDefaultPageSize = 20
+	reservationListMaxPageSize     = 100
+)
+
+// InventoryReservationList searches the reservations by order, status, creation and expiry
+// windows and containing sku, newest first. A seller only finds the reservations that contain
+// its items, and only sees its own lines of them
+func (c *Controller) InventoryReservationList(ctx context.Context, req *pb.InventoryReservationListRequest) (*pb.InventoryReservationListResponse, error) {
+	path := "inventory.controller.InventoryReservationList"
+	errBuilder := func(e *models.AppError) (*pb.InventoryReservationListResponse, error) {
+		return &pb.InventoryReservationListResponse{Response: &pb.InventoryReservationListResponse_Error{Error: models.AppErrorToProto(e)}}, nil
+	}
+
+	modelsCtx, ctxErr := models.ContextGet(ctx)
+	if ctxErr != nil {
+		return errBuilder(ctxErr)
+	}
+	internalErr := func(err error, details string) (*pb.InventoryReservationListResponse, error) {
+		return errBuilder(models.NewAppError(modelsCtx, path, models.ErrMsgInternal, nil, details, int(codes.Internal), &models.AppErrorErrorsArgs{Err: err}))
+	}
+	invalidArg := func(id string) (*pb.InventoryReservationListResponse, error) {
+		return errBuilder(models.NewAppError(modelsCtx, path, id, nil, "", int(codes.InvalidArgument), nil))
+	}
+	sucBuilder := func(data *pb.InventoryReservationListResponseData) (*pb.InventoryReservationListResponse, error) {
+		return &pb.InventoryReservationListResponse{Response: &pb.InventoryReservationListResponse_Data{Data: data}}, nil
+	}
+
+	rctx, cancel := context.WithTimeout(context.Background(), time.Second*12)
+	defer cancel()
+	modelsCtx.Context = rctx
+
+	sellerID, ok := sellerScope(ctx, req.GetSellerId())
+	if !ok {
+		return errBuilder(models.NewAppError(modelsCtx, path, "inventory.seller.forbidden", nil, "", int(codes.PermissionDenied), nil))
+	}
+
+	if req.GetCreatedTo() != 0 && req.GetCreatedFrom() > req.GetCreatedTo() {
+		return invalidArg("inventory.reservation.invalid_created_window")
+	}
+	if req.GetExpiresTo() != 0 && req.GetExpiresFrom() > req.GetExpiresTo() {
+		return invalidArg("inventory.reservation.invalid_expires_window")
+	}
+
+	pageSize := int(req.GetPageSize())
+	if pageSize == 0 {
+		pageSize = reservationListDefaultPageSize
+	}
+	pageSize = min(pageSize, reservationListMaxPageSize)
+
+	filter := &intModels.InventoryReservationFilter{
+		OrderID:     req.GetOrderId(),
+		Sku:         req.GetSku(),
+		SellerID:    sellerID,
+		CreatedFrom: req.GetCreatedFrom(),
+		CreatedTo:   req.GetCreatedTo(),
+		ExpiresFrom: req.GetExpiresFrom(),
+		ExpiresTo:   req.GetExpiresTo(),
+		// one more than the page, to know if there is a next page
+		Limit: pageSize + 1,
+	}
+	if req.GetStatus() != pb.InventoryReservationStatus_INVENTORY_RESERVATION_STATUS_UNSPECIFIED {
+		filter.Status = intModels.GetInventoryReservationStatus(req.GetStatus())
+	}
+	if req.GetPageToken() != "" {
+		createdAt, id, err := reservationPageTokenDecode(req.GetPageToken())
+		if err != nil {
+			return invalidArg("inventory.reservation.invalid_page_token")
+		}
+		filter.AfterCreatedAt, filter.AfterID = createdAt, id
+	}
+
+	reservations, err := c.store.InventoryReservationsList(modelsCtx, filter)
+	if err != nil {
+		return internalErr(err, "failed to list the reservations")
+	}
+
+	data := &pb.InventoryReservationListResponseData{}
+	if len(reservations) > pageSize {
+		reservations = reservations[:pageSize]
+		last := reservations[pageSize-1]
+		data.NextPageToken = reservationPageTokenEncode(last.CreatedAt, last.Id)
+	}
+	if len(reservations) == 0 {
+		return sucBuilder(data)
+	}
+
+	ids := make([]string, 0, len(reservations))
+	for _, r := range reservations {
+		ids = append(ids, r.Id)
+	}
+	lines, err := c.store.InventoryReservationItemsGetByReservationIDs(modelsCtx, sellerID, ids)
+	if err != nil {
+		return internalErr(err, "failed to get reservation items")
+	}
+
+	inventoryIDs := make([]string, 0, len(lines))
+	kitIDs := make([]string, 0)
+	linesByReservation := make(map[string][]*pb.InventoryReservationItem, len(reservations))
+	for _, line := range lines {
+		inventoryIDs = append(inventoryIDs, line.InventoryItemId)
+		if line.KitId != nil {
+			kitIDs = append(kitIDs, *line.KitId)
+		}
+		linesByReservation[line.ReservationId] = append(linesByReservation[line.ReservationId], line)
+	}
+
+	inventoryItems := []*pb.InventoryItem{}
+	if len(inventoryIDs) > 0 {
+		inventoryItems, err = c.store.InventoryItemGetByIDs(modelsCtx, sellerID, inventoryIDs)
+		if err != nil {
+			return internalErr(err, "failed to get inventory item")
+		}
+	}
+
+	kits := []*pb.InventoryKit{}
+	if len(kitIDs) > 0 {
+		kits, err = c.store.InventoryKitGetByIDs(modelsCtx, kitIDs)
+		if err != nil {
+			return internalErr(err, "failed to get the kits")
+		}
+	}
+
+	data.Reservations = make([]*pb.InventoryReservationGetResponseData, 0, len(reservations))
+	for _, r := range reservations {
+		data.Reservations = append(data.Reservations, reservationData(r, linesByReservation[r.Id], inventoryItems, kits))
+	}
+
+	return sucBuilder(data)
+}
+
+// reservationPageTokenEncode builds an opaque page token from the sort key of the last listed reservation
+func reservationPageTokenEncode(createdAt int64, id string) string {
+	return base64.RawURLEncoding.EncodeToString(fmt.Appendf(nil, "%d|%s", createdAt, id))
+}
+
+func reservationPageTokenDecode(token string) (int64, string, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return 0, "", err
+	}
+
+	createdAt, id, found := strings.Cut(string(raw), "|")
+	if !found || id == "" {
+		return 0, "", fmt.Errorf("malformed page token")
+	}
+
+	ts, err := strconv.ParseInt(createdAt, 10, 64)
+	if err != nil {
+		return 0, "", err
+	}
+
+	return ts, id, nil
+}
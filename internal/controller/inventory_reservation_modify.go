@@ -0,0 +1,230 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	intModels "github.com/ahmad-khatib0-org/megacommerce-inventory/pkg/models"
+	pb "github.com/ahmad-khatib0-org/megacommerce-proto/gen/go/inventory/v1"
+	"github.com/ahmad-khatib0-org/megacommerce-shared-go/pkg/models"
+	"github.com/ahmad-khatib0-org/megacommerce-shared-go/pkg/utils"
+	"github.com/jackc/pgx/v5"
+	"google.golang.org/grpc/codes"
+)
+
+// InventoryReservationModify changes the lines of an active reservation in a single transaction,
+// the quantity of each item is the new quantity of its line: a new line is added, an existing one
+// is changed, and a quantity of 0 removes the line. Only the difference is reserved or released,
+// so the rest of the reserved stock is never exposed. Lines that aren't in the request are kept
+func (c *Controller) InventoryReservationModify(ctx context.Context, req *pb.InventoryReservationModifyRequest) (*pb.InventoryReservationModifyResponse, error) {
+	path := "inventory.controller.InventoryReservationModify"
+	modelsCtx, ctxErr := models.ContextGet(ctx)
+	errBuilder := func(e *models.AppError, tx pgx.Tx) (*pb.InventoryReservationModifyResponse, error) {
+		if tx != nil {
+			if rbErr := tx.Rollback(modelsCtx.Context); rbErr != nil {
+				c.log.Errorf("%s: an error rolling back a transaction, err: %s", path, rbErr.Error())
+			}
+		}
+		return &pb.InventoryReservationModifyResponse{Response: &pb.InventoryReservationModifyResponse_Error{Error: models.AppErrorToProto(e)}}, nil
+	}
+
+	if ctxErr != nil {
+		return errBuilder(ctxErr, nil)
+	}
+	internalErr := func(err error, details string, tx pgx.Tx) (*pb.InventoryReservationModifyResponse, error) {
+		return errBuilder(models.NewAppError(modelsCtx, path, models.ErrMsgInternal, nil, details, int(codes.Internal), &models.AppErrorErrorsArgs{Err: err}), tx)
+	}
+	sucBuilder := func(data *pb.InventoryReservationGetResponseData) (*pb.InventoryReservationModifyResponse, error) {
+		return &pb.InventoryReservationModifyResponse{Response: &pb.InventoryReservationModifyResponse_Data{Data: data}}, nil
+	}
+
+	rctx, cancel := context.WithTimeout(context.Background(), time.Second*12)
+	defer cancel()
+	modelsCtx.Context = rctx
+
+	ar := models.AuditRecordNew(modelsCtx, intModels.EventNameInventoryReservationModify, models.EventStatusFail)
+	defer func() {
+		ar.AuditEventDataPriorState(intModels.InventoryReservationModifyRequestAuditable(req))
+		c.ProcessAudit(ctx, ar)
+	}()
+
+	if len(req.GetItems()) == 0 {
+		return errBuilder(models.NewAppError(modelsCtx, path, "inventory.reservation.items_required", nil, "", int(codes.InvalidArgument), nil), nil)
+	}
+	seen := make(map[string]bool, len(req.GetItems()))
+	for _, item := range req.GetItems() {
+		key := fmt.Sprintf("%s.%s", item.GetProductId(), item.GetVariantId())
+		if seen[key] {
+			ei := map[string]*models.AppErrorError{key: {ID: "inventory.reservation.duplicate_item"}}
+			ai := models.NewAppError(modelsCtx, path, "inventory.reservation.duplicate_item", nil, "", int(codes.InvalidArgument), &models.AppErrorErrorsArgs{ErrorsInternal: ei})
+			return errBuilder(ai, nil)
+		}
+		seen[key] = true
+	}
+
+	tx, err := c.store.GetTx(modelsCtx.Context, pgx.TxOptions{})
+	if err != nil {
+		return internalErr(err, "failed to begin transaction", nil)
+	}
+
+	// locks the reservation, so concurrent changes to the same reservation are applied one after the other
+	reservation, err := c.store.InventoryReservationGetByToken(modelsCtx, tx, req.GetReservationToken())
+	if err != nil {
+		if err.ErrType == models.DBErrorTypeNoRows {
+			return errBuilder(models.NewAppError(modelsCtx, path, "inventory.reservation.not_found", nil, err.Details, int(codes.NotFound), &models.AppErrorErrorsArgs{Err: err}), tx)
+		}
+		return internalErr(err, "failed to get reservation", tx)
+	}
+
+	status := intModels.GetInventoryReservationStatusFromString(reservation.Status)
+	if status != pb.InventoryReservationStatus_INVENTORY_RESERVATION_STATUS_RESERVED &&
+		status != pb.InventoryReservationStatus_INVENTORY_RESERVATION_STATUS_PARTIALLY_RESERVED {
+		return errBuilder(models.NewAppError(modelsCtx, path, "inventory.reservation.already_processed", nil, "", int(codes.FailedPrecondition), nil), tx)
+	}
+	if reservation.ExpiresAt <= utils.TimeGetMillis() {
+		return errBuilder(models.NewAppError(modelsCtx, path, "inventory.reservation.expired", nil, "", int(codes.FailedPrecondition), nil), tx)
+	}
+
+	reservationItems, err := c.store.InventoryReservationItemsGetByReservationID(modelsCtx, tx, "", reservation.Id)
+	if err != nil {
+		return internalErr(err, "failed to get reservation items", tx)
+	}
+
+	for _, item := range req.GetItems() {
+		key := fmt.Sprintf("%s.%s", item.GetProductId(), item.GetVariantId())
+		sellerID, ok := sellerScope(ctx, item.GetSellerId())
+		if !ok {
+			return errBuilder(models.NewAppError(modelsCtx, path, "inventory.seller.forbidden", nil, "", int(codes.PermissionDenied), nil), tx)
+		}
+
+		inventory, errDB := c.store.InventoryItemGetByProductVariant(modelsCtx, tx, sellerID, item.GetProductId(), item.GetVariantId(), item.GetLocationId())
+		if errDB != nil {
+			if appErr := itemAmbiguous(modelsCtx, path, errDB, fmt.Sprintf("%s.%s", item.GetProductId(), item.GetVariantId())); appErr != nil {
+				return errBuilder(appErr, tx)
+			}
+			if errDB.ErrType == models.DBErrorTypeNoRows {
+				// the components of a kit are reserved together, a kit line is changed by releasing it and reserving again
+				_, kitErr := c.store.InventoryKitGetByProductVariant(modelsCtx, nil, sellerID, item.GetProductId(), item.GetVariantId())
+				if kitErr != nil && kitErr.ErrType != models.DBErrorTypeNoRows {
+					return internalErr(kitErr, "failed to query inventory_kits table", tx)
+				}
+				if kitErr == nil {
+					ei := map[string]*models.AppErrorError{key: {ID: "inventory.reservation.kit_not_modifiable"}}
+					return errBuilder(models.NewAppError(modelsCtx, path, "inventory.reservation.kit_not_modifiable", nil, "", int(codes.InvalidArgument), &models.AppErrorErrorsArgs{ErrorsInternal: ei}), tx)
+				}
+
+				errors := models.AppErrorErrorsArgs{
+					Err:            errDB,
+					ErrorsInternal: map[string]*models.AppErrorError{key: {ID: "orders.items.not_found_in_inventory"}},
+				}
+				return errBuilder(models.NewAppError(modelsCtx, path, "error.not_found", nil, "", int(codes.NotFound), &errors), tx)
+			}
+			return internalErr(errDB, "failed to query inventory_items table", tx)
+		}
+
+		line, found := utils.Find(reservationItems, func(i *pb.InventoryReservationItem) bool { return i.InventoryItemId == inventory.Id && i.KitId == nil })
+		reserved, backordered := int32(0), int32(0)
+		if found {
+			reserved, backordered = line.Quantity, line.QuantityBackordered
+		}
+
+		delta := int32(item.GetQuantity()) - (reserved + backordered)
+		lotsToReserve := int32(0)
+		switch {
+		case delta > 0:
+			// the line grows by what's still available, and by what the item lets it backorder
+			r, b, appErr := c.stockReserve(modelsCtx, path, tx, inventory, reservation.Channel, key, reserved+backordered, delta, 1)
+			if appErr != nil {
+				return errBuilder(appErr, tx)
+			}
+			reserved, backordered = reserved+r, backordered+b
+			lotsToReserve = r
+		case delta < 0:
+			// backordered units are given up before the units that hold stock
+			b := min(-delta, backordered)
+			if b > 0 {
+				added, errDB := c.store.InventoryBackorderPolicyBackorderedAdd(modelsCtx, tx, inventory.Id, -b)
+				if errDB != nil {
+					return internalErr(errDB, "failed to release backordered inventory", tx)
+				}
+				if !added {
+					return internalErr(nil, "failed to release backordered inventory, the quantity is bigger than the quantity_backordered value", tx)
+				}
+			}
+			if r := -delta - b; r > 0 {
+				released, errDB := c.store.InventoryItemRelease(modelsCtx, tx, inventory.Id, r)
+				if errDB != nil {
+					return internalErr(errDB, "failed to release inventory", tx)
+				}
+				if !released {
+					msg := "The requested quantity to be released is bigger than the quantity_reserved value"
+					return internalErr(nil, fmt.Sprintf("failed to release inventory, %s", msg), tx)
+				}
+				if _, err := c.lotsSettle(modelsCtx, tx, line.Id, r, false); err != nil {
+					return internalErr(err, "failed to release the lots of the inventory item", tx)
+				}
+				reserved -= r
+				if err := c.serialsTrim(modelsCtx, tx, line.Id, reserved, reservation.Id); err != nil {
+					return internalErr(err, "failed to release the serials of the reservation item", tx)
+				}
+			}
+			backordered -= b
+		}
+
+		switch {
+		case !found && item.GetQuantity() > 0:
+			line = &pb.InventoryReservationItem{
+				Id:                  utils.NewID(),
+				ReservationId:       reservation.Id,
+				InventoryItemId:     inventory.Id,
+				Quantity:            reserved,
+				QuantityBackordered: backordered,
+				CreatedAt:           utils.TimeGetMillis(),
+			}
+			errDB = c.store.InventoryReservationItemCreate(modelsCtx, tx, line)
+			reservationItems = append(reservationItems, line)
+		// a line that was partially released or fulfilled is kept for its history
+		case found && item.GetQuantity() == 0 && line.QuantityReleased == 0 && line.QuantityFulfilled == 0:
+			errDB = c.store.InventoryReservationItemDelete(modelsCtx, tx, line.Id)
+			reservationItems = slices.DeleteFunc(reservationItems, func(i *pb.InventoryReservationItem) bool { return i.Id == line.Id })
+		case found && delta != 0:
+			errDB = c.store.InventoryReservationItemUpdateQuantity(modelsCtx, tx, line.Id, reserved, backordered)
+			line.Quantity, line.QuantityBackordered = reserved, backordered
+		}
+		if errDB != nil {
+			return internalErr(errDB, "failed to update the reservation items", tx)
+		}
+		if lotsToReserve > 0 {
+			if _, err := c.lotsReserve(modelsCtx, tx, inventory.Id, line.Id, lotsToReserve); err != nil {
+				return internalErr(err, "failed to reserve the lots of the inventory item", tx)
+			}
+		}
+	}
+
+	// a reservation that holds nothing anymore is released
+	if status := reservationStatusFromLines(reservationItems); status != reservation.Status {
+		if err := c.store.InventoryReservationUpdateStatus(modelsCtx, tx, reservation.Id, status); err != nil {
+			return internalErr(err, "failed to update reservation status", tx)
+		}
+		reservation.Status = status
+	}
+
+	if err := tx.Commit(modelsCtx.Context); err != nil {
+		return internalErr(err, "failed to commit transaction", tx)
+	}
+
+	ar.Success()
+
+	reservationItems, err = c.store.InventoryReservationItemsGetByReservationID(modelsCtx, nil, "", reservation.Id)
+	if err != nil {
+		return internalErr(err, "failed to get reservation items", nil)
+	}
+	data, err := c.reservationGetData(modelsCtx, "", reservation, reservationItems)
+	if err != nil {
+		return internalErr(err, "failed to get inventory item", nil)
+	}
+
+	return sucBuilder(data)
+}
@@ -0,0 +1,257 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+
+	intModels "github.com/ahmad-khatib0-org/megacommerce-inventory/pkg/models"
+	pb "github.com/ahmad-khatib0-org/megacommerce-proto/gen/go/inventory/v1"
+	"github.com/ahmad-khatib0-org/megacommerce-shared-go/pkg/models"
+	"github.com/ahmad-khatib0-org/megacommerce-shared-go/pkg/utils"
+	"github.com/jackc/pgx/v5"
+	"google.golang.org/grpc/codes"
+)
+
+// reservationSettlement is the quantity of a reservation line that is being released or fulfilled
+type reservationSettlement struct {
+	line          *pb.InventoryReservationItem
+	quantity      int32
+	key           string
+	serialNumbers []string
+}
+
+// reservationSettle releases (or fulfils if fulfil is set) the requested quantities of the reservation lines,
+// every remaining unit of every line if items is empty, the quantity of a kit is a number of kits that is
+// settled from each of its component lines. Releasing gives up the backordered units of a line before the
+// units that hold stock, while only units that hold stock can be fulfilled. Each settled line is recorded
+// as a movement per lot that references the reservation, the fulfilled units of an item with serials are the
+// serials given with the line or the ones the line holds, and the reservation status is derived from the lines
+// afterwards
+func (c *Controller) reservationSettle(ctx context.Context, mctx *models.Context, path string, tx pgx.Tx, reservation *pb.InventoryReservation, items []*pb.InventoryReservationLineQuantity, fulfil bool) *models.AppError {
+	internalErr := func(err error, details string) *models.AppError {
+		return models.NewAppError(mctx, path, models.ErrMsgInternal, nil, details, int(codes.Internal), &models.AppErrorErrorsArgs{Err: err})
+	}
+
+	lines, err := c.store.InventoryReservationItemsGetByReservationID(mctx, tx, "", reservation.Id)
+	if err != nil {
+		return internalErr(err, "failed to get reservation items")
+	}
+
+	settlements := make([]reservationSettlement, 0, len(lines))
+	if len(items) == 0 {
+		for _, line := range lines {
+			if quantity := settleable(line, fulfil); quantity > 0 {
+				settlements = append(settlements, reservationSettlement{line: line, quantity: quantity, key: line.InventoryItemId})
+			}
+		}
+	}
+
+	seen := make(map[string]bool, len(items))
+	for _, item := range items {
+		key := fmt.Sprintf("%s.%s", item.GetProductId(), item.GetVariantId())
+		if seen[key] {
+			ei := map[string]*models.AppErrorError{key: {ID: "inventory.reservation.duplicate_item"}}
+			return models.NewAppError(mctx, path, "inventory.reservation.duplicate_item", nil, "", int(codes.InvalidArgument), &models.AppErrorErrorsArgs{ErrorsInternal: ei})
+		}
+		seen[key] = true
+
+		sellerID, ok := sellerScope(ctx, item.GetSellerId())
+		if !ok {
+			return models.NewAppError(mctx, path, "inventory.seller.forbidden", nil, "", int(codes.PermissionDenied), nil)
+		}
+
+		inventory, err := c.store.InventoryItemGetByProductVariant(mctx, tx, sellerID, item.GetProductId(), item.GetVariantId(), item.GetLocationId())
+		if appErr := itemAmbiguous(mctx, path, err, key); appErr != nil {
+			return appErr
+		}
+		if err != nil && err.ErrType != models.DBErrorTypeNoRows {
+			return internalErr(err, "failed to query inventory_items table")
+		}
+
+		var line *pb.InventoryReservationItem
+		if err == nil {
+			line, _ = utils.Find(lines, func(i *pb.InventoryReservationItem) bool { return i.InventoryItemId == inventory.Id && i.KitId == nil })
+		} else {
+			// a kit is settled by settling the same number of kits from every component line
+			kit, err := c.store.InventoryKitGetByProductVariant(mctx, nil, sellerID, item.GetProductId(), item.GetVariantId())
+			if err != nil && err.ErrType != models.DBErrorTypeNoRows {
+				return internalErr(err, "failed to query inventory_kits table")
+			}
+			if err == nil {
+				if components := kitLines(lines, kit.Id); len(components) > 0 {
+					// compared before the cast, a quantity above MaxInt32 would turn negative
+					kits := kitSettleable(components, fulfil)
+					if kits <= 0 || item.GetQuantity() > uint32(kits) {
+						ei := map[string]*models.AppErrorError{key: {ID: "inventory.reservation.quantity_exceeds_reserved", Params: map[string]any{"Quantity": kits}}}
+						return models.NewAppError(mctx, path, "inventory.reservation.quantity_exceeds_reserved", nil, "", int(codes.InvalidArgument), &models.AppErrorErrorsArgs{ErrorsInternal: ei})
+					}
+					if item.GetQuantity() > 0 {
+						kits = int32(item.GetQuantity())
+					}
+
+					for _, component := range components {
+						settlements = append(settlements, reservationSettlement{line: component, quantity: kits * component.KitQuantity, key: key})
+					}
+					continue
+				}
+			}
+		}
+		if line == nil {
+			ei := map[string]*models.AppErrorError{key: {ID: "inventory.reservation.item_not_found"}}
+			return models.NewAppError(mctx, path, "error.not_found", nil, "", int(codes.NotFound), &models.AppErrorErrorsArgs{ErrorsInternal: ei})
+		}
+
+		// a quantity of 0 settles whatever is left of the line, a quantity above MaxInt32 would turn
+		// negative if it was cast before the comparison
+		quantity := settleable(line, fulfil)
+		if quantity <= 0 || item.GetQuantity() > uint32(quantity) {
+			ei := map[string]*models.AppErrorError{key: {ID: "inventory.reservation.quantity_exceeds_reserved", Params: map[string]any{"Quantity": quantity}}}
+			return models.NewAppError(mctx, path, "inventory.reservation.quantity_exceeds_reserved", nil, "", int(codes.InvalidArgument), &models.AppErrorErrorsArgs{ErrorsInternal: ei})
+		}
+		if item.GetQuantity() > 0 {
+			quantity = int32(item.GetQuantity())
+		}
+
+		settlements = append(settlements, reservationSettlement{line: line, quantity: quantity, key: key, serialNumbers: item.GetSerialNumbers()})
+	}
+
+	movementType := intModels.GetInventoryMovementType(pb.InventoryMovementType_INVENTORY_MOVEMENT_TYPE_RELEASE)
+	if fulfil {
+		movementType = intModels.GetInventoryMovementType(pb.InventoryMovementType_INVENTORY_MOVEMENT_TYPE_OUT)
+	}
+
+	for _, s := range settlements {
+		// backordered units hold no stock, so giving them up only shrinks the backorders of the item
+		backordered := int32(0)
+		if !fulfil {
+			backordered = min(s.quantity, s.line.QuantityBackordered)
+		}
+		if backordered > 0 {
+			added, err := c.store.InventoryBackorderPolicyBackorderedAdd(mctx, tx, s.line.InventoryItemId, -backordered)
+			if err != nil {
+				return internalErr(err, "failed to release backordered inventory")
+			}
+			released, err := c.store.InventoryReservationItemBackorderRelease(mctx, tx, s.line.Id, backordered)
+			if err != nil {
+				return internalErr(err, "failed to update the reservation items")
+			}
+			// TODO: this should not happen, and should be added to DLQ to be reviewed
+			if !added || !released {
+				return internalErr(nil, "failed to release backordered inventory, the quantity is bigger than the quantity_backordered value")
+			}
+			s.line.QuantityBackordered -= backordered
+			s.line.QuantityReleased += backordered
+		}
+
+		quantity := s.quantity - backordered
+		if quantity == 0 {
+			continue
+		}
+
+		var released, fulfilled int32
+		var ok bool
+		if fulfil {
+			// the units of an item that a cycle count froze stay on the shelf until it's reviewed
+			if appErr := c.stockFrozen(mctx, path, tx, s.line.InventoryItemId, s.key); appErr != nil {
+				return appErr
+			}
+			fulfilled = quantity
+			ok, err = c.store.InventoryItemFulfill(mctx, tx, s.line.InventoryItemId, quantity)
+		} else {
+			released = quantity
+			ok, err = c.store.InventoryItemRelease(mctx, tx, s.line.InventoryItemId, quantity)
+		}
+		if err != nil {
+			return internalErr(err, "failed to settle inventory")
+		}
+		// TODO: this should not happen, and should be added to DLQ to be reviewed
+		if !ok {
+			return internalErr(nil, "failed to settle inventory, the quantity is bigger than the quantity_reserved value")
+		}
+
+		ok, err = c.store.InventoryReservationItemSettle(mctx, tx, s.line.Id, released, fulfilled)
+		if err != nil {
+			return internalErr(err, "failed to update the reservation items")
+		}
+		if !ok {
+			return internalErr(nil, "failed to settle a reservation item, the quantity is bigger than the reserved quantity")
+		}
+		s.line.Quantity -= quantity
+		s.line.QuantityReleased += released
+		s.line.QuantityFulfilled += fulfilled
+
+		lots, lotsErr := c.lotsSettle(mctx, tx, s.line.Id, quantity, fulfil)
+		if lotsErr != nil {
+			return internalErr(lotsErr, "failed to settle the lots of the inventory item")
+		}
+
+		movement := &pb.InventoryMovement{
+			Id:              utils.NewID(),
+			InventoryItemId: s.line.InventoryItemId,
+			MovementType:    movementType,
+			Quantity:        quantity,
+			ReferenceId:     &reservation.Id,
+			CreatedAt:       utils.TimeGetMillis(),
+		}
+		// fulfilled units leave the stock, what they cost is the cost of goods sold
+		if fulfil {
+			if _, err := c.stockCost(mctx, tx, movement, -quantity, nil, true); err != nil {
+				return internalErr(err, "failed to cost the inventory movement")
+			}
+		}
+		err = c.lotMovementsCreate(mctx, tx, movement, lots)
+		if err != nil {
+			return internalErr(err, "failed to create an inventory movement")
+		}
+
+		if fulfil {
+			if appErr := c.serialsFulfil(mctx, path, tx, s.line, s.key, s.serialNumbers, quantity, reservation.Id); appErr != nil {
+				return appErr
+			}
+		} else if err := c.serialsTrim(mctx, tx, s.line.Id, s.line.Quantity, reservation.Id); err != nil {
+			return internalErr(err, "failed to release the serials of the reservation item")
+		}
+	}
+
+	status := reservationStatusFromLines(lines)
+	if status != reservation.Status {
+		if err := c.store.InventoryReservationUpdateStatus(mctx, tx, reservation.Id, status); err != nil {
+			return internalErr(err, "failed to update reservation status")
+		}
+		reservation.Status = status
+	}
+
+	return nil
+}
+
+// settleable is the quantity of a reservation line that can be released, or fulfilled if fulfil is set
+func settleable(line *pb.InventoryReservationItem, fulfil bool) int32 {
+	if fulfil {
+		return line.Quantity
+	}
+	return line.Quantity + line.QuantityBackordered
+}
+
+// reservationStatusFromLines derives the status of a reservation from its lines: it's reserved while
+// no line was released or fulfilled, partially reserved while some units are still held, and fulfilled
+// or released once nothing is held, depending on whether any unit was fulfilled
+func reservationStatusFromLines(lines []*pb.InventoryReservationItem) string {
+	held, settled, fulfilled := false, false, false
+	for _, line := range lines {
+		held = held || line.Quantity > 0 || line.QuantityBackordered > 0
+		settled = settled || line.QuantityReleased > 0 || line.QuantityFulfilled > 0
+		fulfilled = fulfilled || line.QuantityFulfilled > 0
+	}
+
+	status := pb.InventoryReservationStatus_INVENTORY_RESERVATION_STATUS_RELEASED
+	switch {
+	case held && !settled:
+		status = pb.InventoryReservationStatus_INVENTORY_RESERVATION_STATUS_RESERVED
+	case held:
+		status = pb.InventoryReservationStatus_INVENTORY_RESERVATION_STATUS_PARTIALLY_RESERVED
+	case fulfilled:
+		status = pb.InventoryReservationStatus_INVENTORY_RESERVATION_STATUS_FULFILLED
+	}
+
+	return intModels.GetInventoryReservationStatus(status)
+}
@@ -20,8 +20,9 @@ func (c *Controller) InventoryReserve(ctx context.Context, req *pb.InventoryRese
 
 	errBuilder := func(e *models.AppError, tx pgx.Tx) (*pb.InventoryReserveResponse, error) {
 		if tx != nil {
-			rbErr := tx.Rollback(modelsCtx.Context)
-			c.log.Errorf("%s: an error rolling back a transaction, err: %s", path, rbErr.Error())
+			if rbErr := tx.Rollback(modelsCtx.Context); rbErr != nil {
+				c.log.Errorf("%s: an error rolling back a transaction, err: %s", path, rbErr.Error())
+			}
 		}
 		return &pb.InventoryReserveResponse{Response: &pb.InventoryReserveResponse_Error{Error: models.AppErrorToProto(e)}}, nil
 	}
@@ -42,7 +43,7 @@ func (c *Controller) InventoryReserve(ctx context.Context, req *pb.InventoryRese
 	ar := models.AuditRecordNew(modelsCtx, intModels.EventNameInventoryReserve, models.EventStatusFail)
 	defer func() {
 		ar.AuditEventDataPriorState(intModels.InventoryReserveRequestAuditable(req))
-		c.ProcessAudit(ar)
+		c.ProcessAudit(ctx, ar)
 	}()
 
 	tx, err := c.store.GetTx(modelsCtx.Context, pgx.TxOptions{})
@@ -63,6 +64,7 @@ func (c *Controller) InventoryReserve(ctx context.Context, req *pb.InventoryRese
 		Id:               reservationID,
 		ReservationToken: reservationToken,
 		OrderId:          req.GetOrderId(),
+		Channel:          salesChannel(ctx),
 		Status:           intModels.GetInventoryReservationStatus(pb.InventoryReservationStatus_INVENTORY_RESERVATION_STATUS_RESERVED),
 		ExpiresAt:        expiresAt,
 		CreatedAt:        utils.TimeGetMillis(),
@@ -71,24 +73,49 @@ func (c *Controller) InventoryReserve(ctx context.Context, req *pb.InventoryRese
 		return internalErr(err, "failed to create reservation", tx)
 	}
 
-	partiallyErr := func(proID, varID string, quantity uint32) (*pb.InventoryReserveResponse, error) {
-		key := fmt.Sprintf("%s.%s", proID, varID)
-		ei := map[string]*models.AppErrorError{
-			key: {ID: "orders.items.only_some_available", Params: map[string]any{"Quantity": quantity}},
-		}
-		errors := models.AppErrorErrorsArgs{Err: err, ErrorsInternal: ei}
-		ai := models.NewAppError(ctxErr.Ctx, path, "orders.items.partially_available", nil, "", int(codes.Aborted), &errors)
-		return errBuilder(ai, tx)
-	}
-
 	// Process each item
 	reservationItems := make([]*pb.InventoryReservationListItem, 0, len(req.GetItems()))
 	for _, item := range req.GetItems() {
+		sellerID, ok := sellerScope(ctx, item.GetSellerId())
+		if !ok {
+			return errBuilder(models.NewAppError(modelsCtx, path, "inventory.seller.forbidden", nil, "", int(codes.PermissionDenied), nil), tx)
+		}
+
 		// Check inventory availability
-		inventory, errDB := c.store.InventoryItemGetByProductVariant(modelsCtx, tx, item.GetProductId(), item.GetVariantId())
+		key := fmt.Sprintf("%s.%s", item.GetProductId(), item.GetVariantId())
+		inventory, errDB := c.store.InventoryItemGetByProductVariant(modelsCtx, tx, sellerID, item.GetProductId(), item.GetVariantId(), item.GetLocationId())
+		if appErr := itemAmbiguous(modelsCtx, path, errDB, key); appErr != nil {
+			return errBuilder(appErr, tx)
+		}
+		if errDB != nil && errDB.ErrType == models.DBErrorTypeNoRows {
+			// a product that isn't stocked itself may be a kit, that reserves the stock of its components
+			kit, kitErr := c.store.InventoryKitGetByProductVariant(modelsCtx, tx, sellerID, item.GetProductId(), item.GetVariantId())
+			if kitErr != nil && kitErr.ErrType != models.DBErrorTypeNoRows {
+				return internalErr(kitErr, "failed to query inventory_kits table", tx)
+			}
+			if kitErr == nil {
+				// a kit isn't stocked itself, so it has no units of measure
+				if item.GetUnit() != "" {
+					ei := map[string]*models.AppErrorError{key: {ID: "inventory.unit.unknown", Params: map[string]any{"Unit": item.GetUnit()}}}
+					return errBuilder(models.NewAppError(modelsCtx, path, "inventory.unit.unknown", nil, "", int(codes.InvalidArgument), &models.AppErrorErrorsArgs{ErrorsInternal: ei}), tx)
+				}
+				if appErr := c.kitReserve(modelsCtx, path, tx, reservationID, salesChannel(ctx), kit, key, item.GetQuantity()); appErr != nil {
+					return errBuilder(appErr, tx)
+				}
+
+				reservationItems = append(reservationItems, &pb.InventoryReservationListItem{
+					ProductId:         item.GetProductId(),
+					VariantId:         item.GetVariantId(),
+					Sku:               kit.Sku,
+					QuantityRequested: item.GetQuantity(),
+					QuantityReserved:  item.GetQuantity(),
+					Status:            pb.InventoryReservationItemStatus_INVENTORY_RESERVATION_ITEM_STATUS_RESERVED,
+				})
+				continue
+			}
+		}
 		if errDB != nil {
 			if errDB.ErrType == models.DBErrorTypeNoRows {
-				key := fmt.Sprintf("%s.%s", item.GetProductId(), item.GetVariantId())
 				errors := models.AppErrorErrorsArgs{
 					Err: errDB,
 					ErrorsInternal: map[string]*models.AppErrorError{
@@ -98,51 +125,70 @@ func (c *Controller) InventoryReserve(ctx context.Context, req *pb.InventoryRese
 				ai := models.NewAppError(ctxErr.Ctx, path, "error.not_found", nil, "", int(codes.NotFound), &errors)
 				return errBuilder(ai, tx)
 			}
-		} else {
 			return internalErr(errDB, "failed to query inventory_items table", tx)
 		}
 
-		if inventory.QuantityAvailable == 0 {
-			key := fmt.Sprintf("%s.%s", item.GetProductId(), item.GetVariantId())
-			ei := map[string]*models.AppErrorError{key: {ID: "orders.items.out_of_stock_for_variant"}}
-			errors := models.AppErrorErrorsArgs{ErrorsInternal: ei}
-			ai := models.NewAppError(ctxErr.Ctx, path, "orders.items.out_of_stock", nil, "", int(codes.Aborted), &errors)
-			return errBuilder(ai, tx)
-		}
-
-		if inventory.QuantityAvailable < int32(item.GetQuantity()) {
-			return partiallyErr(item.GetProductId(), item.GetVariantId(), uint32(inventory.QuantityAvailable))
+		// the stock is stored in base units, a line ordered in another unit is reserved in whole units of it
+		quantity, factor, appErr := c.unitQuantity(modelsCtx, path, tx, inventory.Id, item.GetUnit(), item.GetQuantity(), key)
+		if appErr != nil {
+			return errBuilder(appErr, tx)
 		}
 
-		// Reserve the inventory
-		reserved, errDB := c.store.InventoryItemReserve(modelsCtx, tx, inventory.Id, int(item.GetQuantity()))
-		if errDB != nil {
-			return internalErr(errDB, "failed to reserve inventory", tx)
+		// Reserve the inventory, backordering what isn't available if the item allows it
+		reserved, backordered, appErr := c.stockReserve(modelsCtx, path, tx, inventory, salesChannel(ctx), key, 0, quantity, factor)
+		if appErr != nil {
+			return errBuilder(appErr, tx)
 		}
-		if !reserved {
-			return partiallyErr(item.GetProductId(), item.GetVariantId(), uint32(inventory.QuantityAvailable))
+		reservedUnits, reservedOk := intModels.InventoryUnitFromBase(reserved, factor)
+		backorderedUnits, backorderedOk := intModels.InventoryUnitFromBase(backordered, factor)
+		if !reservedOk || !backorderedOk {
+			ei := map[string]*models.AppErrorError{key: {ID: "inventory.unit.not_whole_multiple", Params: map[string]any{"Unit": item.GetUnit()}}}
+			return errBuilder(models.NewAppError(modelsCtx, path, "inventory.unit.not_whole_multiple", nil, "", int(codes.FailedPrecondition), &models.AppErrorErrorsArgs{ErrorsInternal: ei}), tx)
 		}
 
 		// Create reservation item record
+		lineID := utils.NewID()
 		errDB = c.store.InventoryReservationItemCreate(modelsCtx, tx, &pb.InventoryReservationItem{
-			Id:              utils.NewID(),
-			ReservationId:   reservationID,
-			InventoryItemId: inventory.Id,
-			Quantity:        int32(item.GetQuantity()),
-			CreatedAt:       utils.TimeGetMillis(),
+			Id:                  lineID,
+			ReservationId:       reservationID,
+			InventoryItemId:     inventory.Id,
+			Quantity:            reserved,
+			QuantityBackordered: backordered,
+			CreatedAt:           utils.TimeGetMillis(),
 		})
 		if errDB != nil {
 			return internalErr(errDB, "failed to create a reservation item", tx)
 		}
+		if _, err := c.lotsReserve(modelsCtx, tx, inventory.Id, lineID, reserved); err != nil {
+			return internalErr(err, "failed to reserve the lots of the inventory item", tx)
+		}
+
+		// serials given with the item are assigned now, otherwise they're picked when the line is fulfilled
+		if serialNumbers := item.GetSerialNumbers(); len(serialNumbers) > 0 {
+			if int32(len(serialNumbers)) != reserved {
+				ei := map[string]*models.AppErrorError{key: {ID: "inventory.serial.count_mismatch", Params: map[string]any{"Quantity": reserved}}}
+				return errBuilder(models.NewAppError(modelsCtx, path, "inventory.serial.count_mismatch", nil, "", int(codes.InvalidArgument), &models.AppErrorErrorsArgs{ErrorsInternal: ei}), tx)
+			}
+			if appErr := c.serialsAssign(modelsCtx, path, tx, inventory.Id, lineID, reservationID, key, serialNumbers); appErr != nil {
+				return errBuilder(appErr, tx)
+			}
+		}
+
+		status := pb.InventoryReservationItemStatus_INVENTORY_RESERVATION_ITEM_STATUS_RESERVED
+		if backordered > 0 {
+			status = pb.InventoryReservationItemStatus_INVENTORY_RESERVATION_ITEM_STATUS_BACKORDERED
+		}
 
 		// Add successfully reserved item to response
 		reservationItems = append(reservationItems, &pb.InventoryReservationListItem{
-			ProductId:         item.GetProductId(),
-			VariantId:         item.GetVariantId(),
-			Sku:               item.GetSku(),
-			QuantityRequested: item.GetQuantity(),
-			QuantityReserved:  item.GetQuantity(),
-			Status:            pb.InventoryReservationItemStatus_INVENTORY_RESERVATION_ITEM_STATUS_RESERVED,
+			ProductId:           item.GetProductId(),
+			VariantId:           item.GetVariantId(),
+			Sku:                 item.GetSku(),
+			QuantityRequested:   item.GetQuantity(),
+			QuantityReserved:    reservedUnits,
+			QuantityBackordered: backorderedUnits,
+			Unit:                item.GetUnit(),
+			Status:              status,
 		})
 	}
 
@@ -0,0 +1,154 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	intModels "github.com/ahmad-khatib0-org/megacommerce-inventory/pkg/models"
+	pb "github.com/ahmad-khatib0-org/megacommerce-proto/gen/go/inventory/v1"
+	"github.com/ahmad-khatib0-org/megacommerce-shared-go/pkg/models"
+	"github.com/ahmad-khatib0-org/megacommerce-shared-go/pkg/utils"
+	"github.com/jackc/pgx/v5"
+	"google.golang.org/grpc/codes"
+)
+
+// InventoryReturnCreate registers a return (RMA) against the fulfilled lines of a reservation,
+// a line can't be authorized for more units than were fulfilled, over all of its returns
+func (c *Controller) InventoryReturnCreate(ctx context.Context, req *pb.InventoryReturnCreateRequest) (*pb.InventoryReturnCreateResponse, error) {
+	path := "inventory.controller.InventoryReturnCreate"
+	modelsCtx, ctxErr := models.ContextGet(ctx)
+	errBuilder := func(e *models.AppError, tx pgx.Tx) (*pb.InventoryReturnCreateResponse, error) {
+		if tx != nil {
+			if rbErr := tx.Rollback(modelsCtx.Context); rbErr != nil {
+				c.log.Errorf("%s: an error rolling back a transaction, err: %s", path, rbErr.Error())
+			}
+		}
+		return &pb.InventoryReturnCreateResponse{Response: &pb.InventoryReturnCreateResponse_Error{Error: models.AppErrorToProto(e)}}, nil
+	}
+
+	if ctxErr != nil {
+		return errBuilder(ctxErr, nil)
+	}
+	internalErr := func(err error, details string, tx pgx.Tx) (*pb.InventoryReturnCreateResponse, error) {
+		return errBuilder(models.NewAppError(modelsCtx, path, models.ErrMsgInternal, nil, details, int(codes.Internal), &models.AppErrorErrorsArgs{Err: err}), tx)
+	}
+	sucBuilder := func(data *pb.InventoryReturnGetResponseData) (*pb.InventoryReturnCreateResponse, error) {
+		return &pb.InventoryReturnCreateResponse{Response: &pb.InventoryReturnCreateResponse_Data{Data: data}}, nil
+	}
+
+	rctx, cancel := context.WithTimeout(context.Background(), time.Second*12)
+	defer cancel()
+	modelsCtx.Context = rctx
+
+	auditable := intModels.InventoryReturnCreateRequestAuditable(req)
+	ar := models.AuditRecordNew(modelsCtx, intModels.EventNameInventoryReturnCreate, models.EventStatusFail)
+	defer func() {
+		ar.AuditEventDataPriorState(auditable)
+		c.ProcessAudit(ctx, ar)
+	}()
+
+	if len(req.GetItems()) == 0 {
+		return errBuilder(models.NewAppError(modelsCtx, path, "inventory.return.items_required", nil, "", int(codes.InvalidArgument), nil), nil)
+	}
+
+	tx, err := c.store.GetTx(modelsCtx.Context, pgx.TxOptions{})
+	if err != nil {
+		return internalErr(err, "failed to begin transaction", nil)
+	}
+
+	// locks the reservation, so concurrent returns can't authorize the same units twice
+	reservation, err := c.store.InventoryReservationGetByToken(modelsCtx, tx, req.GetReservationToken())
+	if err != nil {
+		if err.ErrType == models.DBErrorTypeNoRows {
+			return errBuilder(models.NewAppError(modelsCtx, path, "inventory.reservation.not_found", nil, err.Details, int(codes.NotFound), &models.AppErrorErrorsArgs{Err: err}), tx)
+		}
+		return internalErr(err, "failed to get reservation", tx)
+	}
+
+	lines, err := c.store.InventoryReservationItemsGetByReservationID(modelsCtx, tx, "", reservation.Id)
+	if err != nil {
+		return internalErr(err, "failed to get reservation items", tx)
+	}
+	authorized, err := c.store.InventoryReturnItemsAuthorized(modelsCtx, tx, reservation.Id)
+	if err != nil {
+		return internalErr(err, "failed to get the returned quantities", tx)
+	}
+
+	now := utils.TimeGetMillis()
+	ret := &pb.InventoryReturn{
+		Id:            utils.NewID(),
+		RmaNumber:     "rma_" + utils.NewID(),
+		ReservationId: reservation.Id,
+		OrderId:       reservation.OrderId,
+		Status:        intModels.GetInventoryReturnStatus(pb.InventoryReturnStatus_INVENTORY_RETURN_STATUS_OPEN),
+		Reason:        req.Reason,
+		CreatedAt:     now,
+	}
+	auditable["rma_number"] = ret.RmaNumber
+
+	returnItems := make([]*pb.InventoryReturnItem, 0, len(req.GetItems()))
+	for _, item := range req.GetItems() {
+		key := fmt.Sprintf("%s.%s", item.GetProductId(), item.GetVariantId())
+		sellerID, ok := sellerScope(ctx, item.GetSellerId())
+		if !ok {
+			return errBuilder(models.NewAppError(modelsCtx, path, "inventory.seller.forbidden", nil, "", int(codes.PermissionDenied), nil), tx)
+		}
+
+		inventory, errDB := c.store.InventoryItemGetByProductVariant(modelsCtx, tx, sellerID, item.GetProductId(), item.GetVariantId(), item.GetLocationId())
+		if appErr := itemAmbiguous(modelsCtx, path, errDB, key); appErr != nil {
+			return errBuilder(appErr, tx)
+		}
+		if errDB != nil && errDB.ErrType != models.DBErrorTypeNoRows {
+			return internalErr(errDB, "failed to query inventory_items table", tx)
+		}
+
+		var line *pb.InventoryReservationItem
+		if errDB == nil {
+			line, _ = utils.Find(lines, func(i *pb.InventoryReservationItem) bool { return i.InventoryItemId == inventory.Id && i.KitId == nil })
+		}
+		if line == nil {
+			ei := map[string]*models.AppErrorError{key: {ID: "inventory.reservation.item_not_found"}}
+			return errBuilder(models.NewAppError(modelsCtx, path, "error.not_found", nil, "", int(codes.NotFound), &models.AppErrorErrorsArgs{ErrorsInternal: ei}), tx)
+		}
+
+		// the units of this line that aren't authorized for return yet, this also covers duplicated items.
+		// It's compared before the cast, a quantity above MaxInt32 would turn negative
+		returnable := line.QuantityFulfilled - authorized[line.InventoryItemId]
+		if item.GetQuantity() == 0 || returnable <= 0 || item.GetQuantity() > uint32(returnable) {
+			ei := map[string]*models.AppErrorError{key: {ID: "inventory.return.quantity_exceeds_fulfilled", Params: map[string]any{"Quantity": max(returnable, 0)}}}
+			return errBuilder(models.NewAppError(modelsCtx, path, "inventory.return.quantity_exceeds_fulfilled", nil, "", int(codes.InvalidArgument), &models.AppErrorErrorsArgs{ErrorsInternal: ei}), tx)
+		}
+		authorized[line.InventoryItemId] += int32(item.GetQuantity())
+
+		returnItems = append(returnItems, &pb.InventoryReturnItem{
+			Id:              utils.NewID(),
+			ReturnId:        ret.Id,
+			InventoryItemId: line.InventoryItemId,
+			Quantity:        int32(item.GetQuantity()),
+			CreatedAt:       now,
+		})
+	}
+
+	if err := c.store.InventoryReturnCreate(modelsCtx, tx, ret); err != nil {
+		return internalErr(err, "failed to create the return", tx)
+	}
+	for _, item := range returnItems {
+		if err := c.store.InventoryReturnItemCreate(modelsCtx, tx, item); err != nil {
+			return internalErr(err, "failed to create a return item", tx)
+		}
+	}
+
+	if err := tx.Commit(modelsCtx.Context); err != nil {
+		return internalErr(err, "failed to commit transaction", tx)
+	}
+
+	ar.Success()
+
+	data, err := c.returnGetData(modelsCtx, "", ret, returnItems)
+	if err != nil {
+		return internalErr(err, "failed to get inventory item", nil)
+	}
+
+	return sucBuilder(data)
+}
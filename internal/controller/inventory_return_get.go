@@ -0,0 +1,109 @@
+package controller
+
+import (
+	"context"
+	"time"
+
+	intModels "github.com/ahmad-khatib0-org/megacommerce-inventory/pkg/models"
+	pb "github.com/ahmad-khatib0-org/megacommerce-proto/gen/go/inventory/v1"
+	"github.com/ahmad-khatib0-org/megacommerce-shared-go/pkg/models"
+	"github.com/ahmad-khatib0-org/megacommerce-shared-go/pkg/utils"
+	"google.golang.org/grpc/codes"
+)
+
+// InventoryReturnGet gets the details of a return by its rma number
+func (c *Controller) InventoryReturnGet(ctx context.Context, req *pb.InventoryReturnGetRequest) (*pb.InventoryReturnGetResponse, error) {
+	path := "inventory.controller.InventoryReturnGet"
+	errBuilder := func(e *models.AppError) (*pb.InventoryReturnGetResponse, error) {
+		return &pb.InventoryReturnGetResponse{Response: &pb.InventoryReturnGetResponse_Error{Error: models.AppErrorToProto(e)}}, nil
+	}
+
+	modelsCtx, ctxErr := models.ContextGet(ctx)
+	if ctxErr != nil {
+		return errBuilder(ctxErr)
+	}
+	internalErr := func(err error, details string) (*pb.InventoryReturnGetResponse, error) {
+		return errBuilder(models.NewAppError(modelsCtx, path, models.ErrMsgInternal, nil, details, int(codes.Internal), &models.AppErrorErrorsArgs{Err: err}))
+	}
+	sucBuilder := func(data *pb.InventoryReturnGetResponseData) (*pb.InventoryReturnGetResponse, error) {
+		return &pb.InventoryReturnGetResponse{Response: &pb.InventoryReturnGetResponse_Data{Data: data}}, nil
+	}
+
+	rctx, cancel := context.WithTimeout(context.Background(), time.Second*12)
+	defer cancel()
+	modelsCtx.Context = rctx
+
+	ret, err := c.store.InventoryReturnGetByRmaNumber(modelsCtx, nil, req.GetRmaNumber())
+	if err != nil {
+		if err.ErrType == models.DBErrorTypeNoRows {
+			return errBuilder(models.NewAppError(modelsCtx, path, "inventory.return.not_found", nil, err.Details, int(codes.NotFound), &models.AppErrorErrorsArgs{Err: err}))
+		}
+		return internalErr(err, "failed to get the return")
+	}
+
+	// a seller only sees its own lines of the return
+	sellerID, ok := sellerScope(ctx, "")
+	if !ok {
+		return errBuilder(models.NewAppError(modelsCtx, path, "inventory.seller.forbidden", nil, "", int(codes.PermissionDenied), nil))
+	}
+	items, err := c.store.InventoryReturnItemsGetByReturnID(modelsCtx, nil, sellerID, ret.Id)
+	if err != nil {
+		return internalErr(err, "failed to get the return items")
+	}
+	if sellerID != "" && len(items) == 0 {
+		return errBuilder(models.NewAppError(modelsCtx, path, "inventory.return.not_found", nil, "", int(codes.NotFound), nil))
+	}
+
+	data, err := c.returnGetData(modelsCtx, sellerID, ret, items)
+	if err != nil {
+		return internalErr(err, "failed to get inventory item")
+	}
+
+	return sucBuilder(data)
+}
+
+// returnGetData builds the return details from its return items,
+// sellerID limits the inventory items to a single seller if it's not empty
+func (c *Controller) returnGetData(ctx *models.Context, sellerID string, ret *pb.InventoryReturn, returnItems []*pb.InventoryReturnItem) (*pb.InventoryReturnGetResponseData, *models.DBError) {
+	ids := make([]string, 0, len(returnItems))
+	for _, item := range returnItems {
+		ids = append(ids, item.InventoryItemId)
+	}
+
+	inventoryItems := []*pb.InventoryItem{}
+	if len(ids) > 0 {
+		var err *models.DBError
+		inventoryItems, err = c.store.InventoryItemGetByIDs(ctx, sellerID, ids)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	items := make([]*pb.InventoryReturnListItem, 0, len(returnItems))
+	for _, item := range returnItems {
+		inventory, found := utils.Find(inventoryItems, func(i *pb.InventoryItem) bool { return i.Id == item.InventoryItemId })
+		if !found {
+			continue
+		}
+
+		items = append(items, &pb.InventoryReturnListItem{
+			ProductId:           inventory.ProductId,
+			VariantId:           inventory.VariantId,
+			Sku:                 inventory.Sku,
+			QuantityAuthorized:  uint32(item.Quantity),
+			QuantityReceived:    uint32(intModels.InventoryReturnItemReceived(item)),
+			QuantityRestocked:   uint32(item.QuantityRestocked),
+			QuantityQuarantined: uint32(item.QuantityQuarantined),
+			QuantityWrittenOff:  uint32(item.QuantityWrittenOff),
+		})
+	}
+
+	return &pb.InventoryReturnGetResponseData{
+		RmaNumber: ret.RmaNumber,
+		OrderId:   ret.OrderId,
+		Status:    intModels.GetInventoryReturnStatusFromString(ret.Status),
+		Reason:    ret.Reason,
+		CreatedAt: ret.CreatedAt,
+		Items:     items,
+	}, nil
+}
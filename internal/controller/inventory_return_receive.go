@@ -0,0 +1,249 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	intModels "github.com/ahmad-khatib0-org/megacommerce-inventory/pkg/models"
+	pb "github.com/ahmad-khatib0-org/megacommerce-proto/gen/go/inventory/v1"
+	"github.com/ahmad-khatib0-org/megacommerce-shared-go/pkg/models"
+	"github.com/ahmad-khatib0-org/megacommerce-shared-go/pkg/utils"
+	"github.com/jackc/pgx/v5"
+	"google.golang.org/grpc/codes"
+)
+
+// InventoryReturnReceive receives returned units of a return with a disposition: restocked units
+// re-enter the sellable stock with an IN movement, quarantined and written off units stay out of it
+// and are recorded as ADJUSTMENT movements. Every movement references the original order. The serials of
+// the returned units go back on the shelf as returned if they're restocked, and are marked defective otherwise
+func (c *Controller) InventoryReturnReceive(ctx context.Context, req *pb.InventoryReturnReceiveRequest) (*pb.InventoryReturnReceiveResponse, error) {
+	path := "inventory.controller.InventoryReturnReceive"
+	modelsCtx, ctxErr := models.ContextGet(ctx)
+	errBuilder := func(e *models.AppError, tx pgx.Tx) (*pb.InventoryReturnReceiveResponse, error) {
+		if tx != nil {
+			if rbErr := tx.Rollback(modelsCtx.Context); rbErr != nil {
+				c.log.Errorf("%s: an error rolling back a transaction, err: %s", path, rbErr.Error())
+			}
+		}
+		return &pb.InventoryReturnReceiveResponse{Response: &pb.InventoryReturnReceiveResponse_Error{Error: models.AppErrorToProto(e)}}, nil
+	}
+
+	if ctxErr != nil {
+		return errBuilder(ctxErr, nil)
+	}
+	internalErr := func(err error, details string, tx pgx.Tx) (*pb.InventoryReturnReceiveResponse, error) {
+		return errBuilder(models.NewAppError(modelsCtx, path, models.ErrMsgInternal, nil, details, int(codes.Internal), &models.AppErrorErrorsArgs{Err: err}), tx)
+	}
+	sucBuilder := func(data *pb.InventoryReturnGetResponseData) (*pb.InventoryReturnReceiveResponse, error) {
+		return &pb.InventoryReturnReceiveResponse{Response: &pb.InventoryReturnReceiveResponse_Data{Data: data}}, nil
+	}
+
+	rctx, cancel := context.WithTimeout(context.Background(), time.Second*12)
+	defer cancel()
+	modelsCtx.Context = rctx
+
+	ar := models.AuditRecordNew(modelsCtx, intModels.EventNameInventoryReturnReceive, models.EventStatusFail)
+	defer func() {
+		ar.AuditEventDataPriorState(intModels.InventoryReturnReceiveRequestAuditable(req))
+		c.ProcessAudit(ctx, ar)
+	}()
+
+	if len(req.GetItems()) == 0 {
+		return errBuilder(models.NewAppError(modelsCtx, path, "inventory.return.items_required", nil, "", int(codes.InvalidArgument), nil), nil)
+	}
+
+	tx, err := c.store.GetTx(modelsCtx.Context, pgx.TxOptions{})
+	if err != nil {
+		return internalErr(err, "failed to begin transaction", nil)
+	}
+
+	ret, err := c.store.InventoryReturnGetByRmaNumber(modelsCtx, tx, req.GetRmaNumber())
+	if err != nil {
+		if err.ErrType == models.DBErrorTypeNoRows {
+			return errBuilder(models.NewAppError(modelsCtx, path, "inventory.return.not_found", nil, err.Details, int(codes.NotFound), &models.AppErrorErrorsArgs{Err: err}), tx)
+		}
+		return internalErr(err, "failed to get the return", tx)
+	}
+	if intModels.GetInventoryReturnStatusFromString(ret.Status) == pb.InventoryReturnStatus_INVENTORY_RETURN_STATUS_RECEIVED {
+		return errBuilder(models.NewAppError(modelsCtx, path, "inventory.return.already_received", nil, "", int(codes.FailedPrecondition), nil), tx)
+	}
+
+	lines, err := c.store.InventoryReturnItemsGetByReturnID(modelsCtx, tx, "", ret.Id)
+	if err != nil {
+		return internalErr(err, "failed to get the return items", tx)
+	}
+
+	for _, item := range req.GetItems() {
+		key := fmt.Sprintf("%s.%s", item.GetProductId(), item.GetVariantId())
+		sellerID, ok := sellerScope(ctx, item.GetSellerId())
+		if !ok {
+			return errBuilder(models.NewAppError(modelsCtx, path, "inventory.seller.forbidden", nil, "", int(codes.PermissionDenied), nil), tx)
+		}
+
+		inventory, errDB := c.store.InventoryItemGetByProductVariant(modelsCtx, tx, sellerID, item.GetProductId(), item.GetVariantId(), item.GetLocationId())
+		if appErr := itemAmbiguous(modelsCtx, path, errDB, key); appErr != nil {
+			return errBuilder(appErr, tx)
+		}
+		if errDB != nil && errDB.ErrType != models.DBErrorTypeNoRows {
+			return internalErr(errDB, "failed to query inventory_items table", tx)
+		}
+
+		var line *pb.InventoryReturnItem
+		if errDB == nil {
+			line, _ = utils.Find(lines, func(i *pb.InventoryReturnItem) bool { return i.InventoryItemId == inventory.Id })
+		}
+		if line == nil {
+			ei := map[string]*models.AppErrorError{key: {ID: "inventory.return.item_not_found"}}
+			return errBuilder(models.NewAppError(modelsCtx, path, "error.not_found", nil, "", int(codes.NotFound), &models.AppErrorErrorsArgs{ErrorsInternal: ei}), tx)
+		}
+
+		// compared before the cast, a quantity above MaxInt32 would turn negative
+		remaining := line.Quantity - intModels.InventoryReturnItemReceived(line)
+		if item.GetQuantity() == 0 || remaining <= 0 || item.GetQuantity() > uint32(remaining) {
+			ei := map[string]*models.AppErrorError{key: {ID: "inventory.return.quantity_exceeds_authorized", Params: map[string]any{"Quantity": max(remaining, 0)}}}
+			return errBuilder(models.NewAppError(modelsCtx, path, "inventory.return.quantity_exceeds_authorized", nil, "", int(codes.InvalidArgument), &models.AppErrorErrorsArgs{ErrorsInternal: ei}), tx)
+		}
+
+		quantity := int32(item.GetQuantity())
+		var restocked, quarantined, writtenOff int32
+		var movementType pb.InventoryMovementType
+		serialStatus := pb.InventorySerialStatus_INVENTORY_SERIAL_STATUS_DEFECTIVE
+		switch item.GetDisposition() {
+		case pb.InventoryReturnDisposition_INVENTORY_RETURN_DISPOSITION_RESTOCK:
+			restocked = quantity
+			movementType = pb.InventoryMovementType_INVENTORY_MOVEMENT_TYPE_IN
+			serialStatus = pb.InventorySerialStatus_INVENTORY_SERIAL_STATUS_RETURNED
+		case pb.InventoryReturnDisposition_INVENTORY_RETURN_DISPOSITION_QUARANTINE:
+			quarantined = quantity
+			movementType = intModels.InventoryStockBucketMovementType(pb.InventoryStockBucket_INVENTORY_STOCK_BUCKET_QUARANTINED)
+		case pb.InventoryReturnDisposition_INVENTORY_RETURN_DISPOSITION_WRITE_OFF:
+			writtenOff = quantity
+			movementType = intModels.InventoryStockBucketMovementType(pb.InventoryStockBucket_INVENTORY_STOCK_BUCKET_DAMAGED)
+		default:
+			ei := map[string]*models.AppErrorError{key: {ID: "inventory.return.invalid_disposition"}}
+			return errBuilder(models.NewAppError(modelsCtx, path, "inventory.return.invalid_disposition", nil, "", int(codes.InvalidArgument), &models.AppErrorErrorsArgs{ErrorsInternal: ei}), tx)
+		}
+
+		received, errDB := c.store.InventoryReturnItemReceive(modelsCtx, tx, line.Id, restocked, quarantined, writtenOff)
+		if errDB != nil {
+			return internalErr(errDB, "failed to update the return items", tx)
+		}
+		if !received {
+			ei := map[string]*models.AppErrorError{key: {ID: "inventory.return.quantity_exceeds_authorized", Params: map[string]any{"Quantity": remaining}}}
+			return errBuilder(models.NewAppError(modelsCtx, path, "inventory.return.quantity_exceeds_authorized", nil, "", int(codes.InvalidArgument), &models.AppErrorErrorsArgs{ErrorsInternal: ei}), tx)
+		}
+		line.QuantityRestocked += restocked
+		line.QuantityQuarantined += quarantined
+		line.QuantityWrittenOff += writtenOff
+
+		// the returned units of an item with lots came from the lot the item names, restocked units go back into it
+		var lot *pb.InventoryLot
+		if restocked > 0 || item.GetLotNumber() != "" {
+			var appErr *models.AppError
+			if lot, appErr = c.lotByNumber(modelsCtx, path, tx, inventory.Id, item.GetLotNumber(), key); appErr != nil {
+				return errBuilder(appErr, tx)
+			}
+		}
+
+		if restocked > 0 {
+			if appErr := c.stockFrozen(modelsCtx, path, tx, inventory.Id, key); appErr != nil {
+				return errBuilder(appErr, tx)
+			}
+			if lot != nil {
+				if err := c.store.InventoryLotReceive(modelsCtx, tx, lot.Id, restocked); err != nil {
+					return internalErr(err, "failed to update the lot", tx)
+				}
+			}
+			total := int(inventory.QuantityTotal + restocked)
+			available := int(inventory.QuantityAvailable + restocked)
+			if err := c.store.InventoryItemUpdate(modelsCtx, tx, inventory.Id, total, inventory.QuantityReserved, available); err != nil {
+				return internalErr(err, "failed to update inventory", tx)
+			}
+			inventory.QuantityTotal, inventory.QuantityAvailable = int32(total), int32(available)
+
+			// restocked units go to the orders that wait for them first
+			if err := c.backorderAllocate(modelsCtx, tx, inventory.Id, int32(available)); err != nil {
+				return internalErr(err, "failed to allocate backordered inventory", tx)
+			}
+		}
+		// units that can't be sold again stay on hand, in the quarantined or the damaged bucket. The cost layers only
+		// value the sellable bucket, so they get one when they're moved back into it
+		if quarantined > 0 || writtenOff > 0 {
+			inventory.QuantityQuarantined += quarantined
+			inventory.QuantityDamaged += writtenOff
+			err := c.store.InventoryItemBucketsUpdate(modelsCtx, tx, inventory.Id, inventory.QuantityTotal, inventory.QuantityAvailable, inventory.QuantityQuarantined, inventory.QuantityDamaged, inventory.QuantityOnHold)
+			if err != nil {
+				return internalErr(err, "failed to update inventory", tx)
+			}
+		}
+
+		movement := &pb.InventoryMovement{
+			Id:              utils.NewID(),
+			InventoryItemId: inventory.Id,
+			MovementType:    intModels.GetInventoryMovementType(movementType),
+			Quantity:        quantity,
+			ReferenceId:     &ret.OrderId,
+			Reason:          req.Note,
+			Metadata: map[string]string{
+				"rma_number":  ret.RmaNumber,
+				"disposition": intModels.GetInventoryReturnDisposition(item.GetDisposition()),
+			},
+			CreatedAt: utils.TimeGetMillis(),
+		}
+		if lot != nil {
+			movement.LotId = &lot.Id
+		}
+		if _, err := c.stockCost(modelsCtx, tx, movement, restocked, nil, false); err != nil {
+			return internalErr(err, "failed to cost the inventory movement", tx)
+		}
+		if err := c.store.InventoryMovementCreate(modelsCtx, tx, movement); err != nil {
+			return internalErr(err, "failed to create inventory movement", tx)
+		}
+
+		if serialNumbers := item.GetSerialNumbers(); len(serialNumbers) > 0 {
+			if int32(len(serialNumbers)) != quantity {
+				ei := map[string]*models.AppErrorError{key: {ID: "inventory.serial.count_mismatch", Params: map[string]any{"Quantity": quantity}}}
+				return errBuilder(models.NewAppError(modelsCtx, path, "inventory.serial.count_mismatch", nil, "", int(codes.InvalidArgument), &models.AppErrorErrorsArgs{ErrorsInternal: ei}), tx)
+			}
+
+			sold := func(s *pb.InventorySerial) bool {
+				return intModels.GetInventorySerialStatusFromString(s.Status) == pb.InventorySerialStatus_INVENTORY_SERIAL_STATUS_SOLD
+			}
+			serials, appErr := c.serialsFind(modelsCtx, path, tx, inventory.Id, key, serialNumbers, sold)
+			if appErr != nil {
+				return errBuilder(appErr, tx)
+			}
+			for _, serial := range serials {
+				if err := c.serialMove(modelsCtx, tx, serial, serialStatus, movementType, nil, &ret.OrderId, req.Note); err != nil {
+					return internalErr(err, "failed to update a returned serial", tx)
+				}
+			}
+		}
+	}
+
+	status := pb.InventoryReturnStatus_INVENTORY_RETURN_STATUS_RECEIVED
+	for _, line := range lines {
+		if intModels.InventoryReturnItemReceived(line) < line.Quantity {
+			status = pb.InventoryReturnStatus_INVENTORY_RETURN_STATUS_PARTIALLY_RECEIVED
+			break
+		}
+	}
+	ret.Status = intModels.GetInventoryReturnStatus(status)
+	if err := c.store.InventoryReturnUpdateStatus(modelsCtx, tx, ret.Id, ret.Status); err != nil {
+		return internalErr(err, "failed to update the return status", tx)
+	}
+
+	if err := tx.Commit(modelsCtx.Context); err != nil {
+		return internalErr(err, "failed to commit transaction", tx)
+	}
+
+	ar.Success()
+
+	data, err := c.returnGetData(modelsCtx, "", ret, lines)
+	if err != nil {
+		return internalErr(err, "failed to get inventory item", nil)
+	}
+
+	return sucBuilder(data)
+}
@@ -0,0 +1,185 @@
+package controller
+
+import (
+	intModels "github.com/ahmad-khatib0-org/megacommerce-inventory/pkg/models"
+	pb "github.com/ahmad-khatib0-org/megacommerce-proto/gen/go/inventory/v1"
+	"github.com/ahmad-khatib0-org/megacommerce-shared-go/pkg/models"
+	"github.com/ahmad-khatib0-org/megacommerce-shared-go/pkg/utils"
+	"github.com/jackc/pgx/v5"
+	"google.golang.org/grpc/codes"
+)
+
+// serialMove moves a serial to status, held by reservationItemID if it's not nil, and records the change
+// as a serial movement, referenceID is the reservation or order that caused it
+func (c *Controller) serialMove(mctx *models.Context, tx pgx.Tx, serial *pb.InventorySerial, status pb.InventorySerialStatus, movementType pb.InventoryMovementType, reservationItemID *string, referenceID *string, reason *string) *models.DBError {
+	serial.Status = intModels.GetInventorySerialStatus(status)
+	serial.ReservationItemId = reservationItemID
+	if err := c.store.InventorySerialUpdateStatus(mctx, tx, serial.Id, serial.Status, reservationItemID); err != nil {
+		return err
+	}
+
+	return c.store.InventorySerialMovementCreate(mctx, tx, &pb.InventorySerialMovement{
+		Id:           utils.NewID(),
+		SerialId:     serial.Id,
+		MovementType: intModels.GetInventoryMovementType(movementType),
+		Status:       serial.Status,
+		ReferenceId:  referenceID,
+		Reason:       reason,
+		CreatedAt:    utils.TimeGetMillis(),
+	})
+}
+
+// serialsFind locks the serials of an inventory item by their numbers, and fails if a number is repeated,
+// isn't registered for the item, or if valid rejects the serial. key identifies the item in the errors
+func (c *Controller) serialsFind(mctx *models.Context, path string, tx pgx.Tx, inventoryItemID string, key string, serialNumbers []string, valid func(*pb.InventorySerial) bool) ([]*pb.InventorySerial, *models.AppError) {
+	serials := make([]*pb.InventorySerial, 0, len(serialNumbers))
+	seen := make(map[string]bool, len(serialNumbers))
+	for _, number := range serialNumbers {
+		params := map[string]any{"SerialNumber": number}
+		if seen[number] {
+			ei := map[string]*models.AppErrorError{key: {ID: "inventory.serial.duplicate_serial", Params: params}}
+			return nil, models.NewAppError(mctx, path, "inventory.serial.duplicate_serial", nil, "", int(codes.InvalidArgument), &models.AppErrorErrorsArgs{ErrorsInternal: ei})
+		}
+		seen[number] = true
+
+		serial, err := c.store.InventorySerialGetByNumber(mctx, tx, inventoryItemID, number)
+		if err != nil {
+			if err.ErrType == models.DBErrorTypeNoRows {
+				ei := map[string]*models.AppErrorError{key: {ID: "inventory.serial.not_found", Params: params}}
+				return nil, models.NewAppError(mctx, path, "error.not_found", nil, "", int(codes.NotFound), &models.AppErrorErrorsArgs{Err: err, ErrorsInternal: ei})
+			}
+			return nil, models.NewAppError(mctx, path, models.ErrMsgInternal, nil, "failed to query inventory_serials table", int(codes.Internal), &models.AppErrorErrorsArgs{Err: err})
+		}
+		if !valid(serial) {
+			params["Status"] = serial.Status
+			ei := map[string]*models.AppErrorError{key: {ID: "inventory.serial.invalid_status", Params: params}}
+			return nil, models.NewAppError(mctx, path, "inventory.serial.invalid_status", nil, "", int(codes.FailedPrecondition), &models.AppErrorErrorsArgs{ErrorsInternal: ei})
+		}
+
+		serials = append(serials, serial)
+	}
+
+	return serials, nil
+}
+
+// serialsAssign reserves the serials with the given numbers for a reservation line, one per reserved unit,
+// they must be on the shelf, i.e. available or returned and restocked
+func (c *Controller) serialsAssign(mctx *models.Context, path string, tx pgx.Tx, inventoryItemID string, reservationItemID string, reservationID string, key string, serialNumbers []string) *models.AppError {
+	serials, appErr := c.serialsFind(mctx, path, tx, inventoryItemID, key, serialNumbers, intModels.InventorySerialAssignable)
+	if appErr != nil {
+		return appErr
+	}
+
+	for _, serial := range serials {
+		err := c.serialMove(mctx, tx, serial, pb.InventorySerialStatus_INVENTORY_SERIAL_STATUS_RESERVED,
+			pb.InventoryMovementType_INVENTORY_MOVEMENT_TYPE_RESERVATION, &reservationItemID, &reservationID, nil)
+		if err != nil {
+			return models.NewAppError(mctx, path, models.ErrMsgInternal, nil, "failed to reserve a serial", int(codes.Internal), &models.AppErrorErrorsArgs{Err: err})
+		}
+	}
+
+	return nil
+}
+
+// serialsFulfil marks quantity serials of a reservation line as sold, the serials with the given numbers if any,
+// which are either held by the line or still on the shelf, otherwise the serials that the line holds. An item
+// without serials is fulfilled without them. The serials that the line holds beyond its remaining quantity
+// are put back on the shelf afterwards, so line.Quantity must already exclude the fulfilled units
+func (c *Controller) serialsFulfil(mctx *models.Context, path string, tx pgx.Tx, line *pb.InventoryReservationItem, key string, serialNumbers []string, quantity int32, referenceID string) *models.AppError {
+	internalErr := func(err error, details string) *models.AppError {
+		return models.NewAppError(mctx, path, models.ErrMsgInternal, nil, details, int(codes.Internal), &models.AppErrorErrorsArgs{Err: err})
+	}
+
+	held, err := c.store.InventorySerialsGetByReservationItem(mctx, tx, line.Id)
+	if err != nil {
+		return internalErr(err, "failed to query inventory_serials table")
+	}
+
+	var sold []*pb.InventorySerial
+	if len(serialNumbers) > 0 {
+		if int32(len(serialNumbers)) != quantity {
+			ei := map[string]*models.AppErrorError{key: {ID: "inventory.serial.count_mismatch", Params: map[string]any{"Quantity": quantity}}}
+			return models.NewAppError(mctx, path, "inventory.serial.count_mismatch", nil, "", int(codes.InvalidArgument), &models.AppErrorErrorsArgs{ErrorsInternal: ei})
+		}
+
+		valid := func(s *pb.InventorySerial) bool {
+			return intModels.InventorySerialAssignable(s) || (s.ReservationItemId != nil && *s.ReservationItemId == line.Id)
+		}
+		var appErr *models.AppError
+		sold, appErr = c.serialsFind(mctx, path, tx, line.InventoryItemId, key, serialNumbers, valid)
+		if appErr != nil {
+			return appErr
+		}
+	} else {
+		tracked, err := c.store.InventorySerialsCount(mctx, tx, line.InventoryItemId, nil)
+		if err != nil {
+			return internalErr(err, "failed to count the serials of the inventory item")
+		}
+		if tracked == 0 {
+			return nil
+		}
+		if int32(len(held)) < quantity {
+			ei := map[string]*models.AppErrorError{key: {ID: "inventory.serial.serials_required", Params: map[string]any{"Quantity": quantity}}}
+			return models.NewAppError(mctx, path, "inventory.serial.serials_required", nil, "", int(codes.InvalidArgument), &models.AppErrorErrorsArgs{ErrorsInternal: ei})
+		}
+		sold = held[:quantity]
+	}
+
+	for _, serial := range sold {
+		err := c.serialMove(mctx, tx, serial, pb.InventorySerialStatus_INVENTORY_SERIAL_STATUS_SOLD,
+			pb.InventoryMovementType_INVENTORY_MOVEMENT_TYPE_OUT, nil, &referenceID, nil)
+		if err != nil {
+			return internalErr(err, "failed to sell a serial")
+		}
+	}
+
+	if err := c.serialsTrim(mctx, tx, line.Id, line.Quantity, referenceID); err != nil {
+		return internalErr(err, "failed to release the serials of the reservation item")
+	}
+
+	return nil
+}
+
+// serialsTrim puts the serials that a reservation line holds beyond keep back on the shelf, it's called
+// once units of the line were released, so the line never holds more serials than reserved units
+func (c *Controller) serialsTrim(mctx *models.Context, tx pgx.Tx, reservationItemID string, keep int32, referenceID string) *models.DBError {
+	held, err := c.store.InventorySerialsGetByReservationItem(mctx, tx, reservationItemID)
+	if err != nil {
+		return err
+	}
+
+	for i := max(keep, 0); i < int32(len(held)); i++ {
+		err := c.serialMove(mctx, tx, held[i], pb.InventorySerialStatus_INVENTORY_SERIAL_STATUS_AVAILABLE,
+			pb.InventoryMovementType_INVENTORY_MOVEMENT_TYPE_RELEASE, nil, &referenceID, nil)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// serialData converts a serial of an inventory item to the response format, with its movements if any
+func serialData(inventory *pb.InventoryItem, serial *pb.InventorySerial, movements []*pb.InventorySerialMovement) *pb.InventorySerialData {
+	data := &pb.InventorySerialData{
+		ProductId:    inventory.ProductId,
+		VariantId:    inventory.VariantId,
+		Sku:          inventory.Sku,
+		LocationId:   serial.LocationId,
+		SerialNumber: serial.SerialNumber,
+		Status:       intModels.GetInventorySerialStatusFromString(serial.Status),
+		CreatedAt:    serial.CreatedAt,
+		UpdatedAt:    serial.UpdatedAt,
+	}
+	for _, m := range movements {
+		data.Movements = append(data.Movements, &pb.InventorySerialMovementData{
+			MovementType: intModels.GetInventoryMovementTypeFromString(m.MovementType),
+			Status:       intModels.GetInventorySerialStatusFromString(m.Status),
+			ReferenceId:  m.ReferenceId,
+			Reason:       m.Reason,
+			CreatedAt:    m.CreatedAt,
+		})
+	}
+
+	return data
+}
@@ -0,0 +1,73 @@
+package controller
+
+import (
+	"context"
+	"time"
+
+	pb "github.com/ahmad-khatib0-org/megacommerce-proto/gen/go/inventory/v1"
+	"github.com/ahmad-khatib0-org/megacommerce-shared-go/pkg/models"
+	"github.com/ahmad-khatib0-org/megacommerce-shared-go/pkg/utils"
+	"google.golang.org/grpc/codes"
+)
+
+// InventorySerialGet looks up a serial number with its full movement history, a serial number is only unique
+// per item, so every item of the seller that has a unit with that number is returned
+func (c *Controller) InventorySerialGet(ctx context.Context, req *pb.InventorySerialGetRequest) (*pb.InventorySerialGetResponse, error) {
+	path := "inventory.controller.InventorySerialGet"
+	errBuilder := func(e *models.AppError) (*pb.InventorySerialGetResponse, error) {
+		return &pb.InventorySerialGetResponse{Response: &pb.InventorySerialGetResponse_Error{Error: models.AppErrorToProto(e)}}, nil
+	}
+
+	modelsCtx, ctxErr := models.ContextGet(ctx)
+	if ctxErr != nil {
+		return errBuilder(ctxErr)
+	}
+	internalErr := func(err error, details string) (*pb.InventorySerialGetResponse, error) {
+		return errBuilder(models.NewAppError(modelsCtx, path, models.ErrMsgInternal, nil, details, int(codes.Internal), &models.AppErrorErrorsArgs{Err: err}))
+	}
+
+	rctx, cancel := context.WithTimeout(context.Background(), time.Second*12)
+	defer cancel()
+	modelsCtx.Context = rctx
+
+	sellerID, ok := sellerScope(ctx, req.GetSellerId())
+	if !ok {
+		return errBuilder(models.NewAppError(modelsCtx, path, "inventory.seller.forbidden", nil, "", int(codes.PermissionDenied), nil))
+	}
+	if req.GetSerialNumber() == "" {
+		return errBuilder(models.NewAppError(modelsCtx, path, "inventory.serial.serial_number_required", nil, "", int(codes.InvalidArgument), nil))
+	}
+
+	serials, err := c.store.InventorySerialsGetByNumber(modelsCtx, sellerID, req.GetSerialNumber())
+	if err != nil {
+		return internalErr(err, "failed to query inventory_serials table")
+	}
+	if len(serials) == 0 {
+		return errBuilder(models.NewAppError(modelsCtx, path, "inventory.serial.not_found", map[string]any{"SerialNumber": req.GetSerialNumber()}, "", int(codes.NotFound), nil))
+	}
+
+	ids := make([]string, 0, len(serials))
+	for _, serial := range serials {
+		ids = append(ids, serial.InventoryItemId)
+	}
+	items, err := c.store.InventoryItemGetByIDs(modelsCtx, sellerID, ids)
+	if err != nil {
+		return internalErr(err, "failed to query inventory_items table")
+	}
+
+	data := &pb.InventorySerialGetResponseData{Serials: make([]*pb.InventorySerialData, 0, len(serials))}
+	for _, serial := range serials {
+		inventory, found := utils.Find(items, func(i *pb.InventoryItem) bool { return i.Id == serial.InventoryItemId })
+		if !found {
+			continue
+		}
+
+		movements, err := c.store.InventorySerialMovementsGet(modelsCtx, serial.Id)
+		if err != nil {
+			return internalErr(err, "failed to query inventory_serial_movements table")
+		}
+		data.Serials = append(data.Serials, serialData(inventory, serial, movements))
+	}
+
+	return &pb.InventorySerialGetResponse{Response: &pb.InventorySerialGetResponse_Data{Data: data}}, nil
+}
@@ -0,0 +1,147 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	intModels "github.com/ahmad-khatib0-org/megacommerce-inventory/pkg/models"
+	pb "github.com/ahmad-khatib0-org/megacommerce-proto/gen/go/inventory/v1"
+	"github.com/ahmad-khatib0-org/megacommerce-shared-go/pkg/models"
+	"github.com/ahmad-khatib0-org/megacommerce-shared-go/pkg/utils"
+	"github.com/jackc/pgx/v5"
+	"google.golang.org/grpc/codes"
+)
+
+// InventorySerialRegister registers the serial numbers of units that are on hand of an item, at the location
+// of the item, each serial starts available with an IN serial movement. The quantities of the item don't
+// change, since the units were already counted, but an item can't have more serials in stock than units
+// on hand. Once an item has serials, each of its fulfilled units must be matched with one of them
+func (c *Controller) InventorySerialRegister(ctx context.Context, req *pb.InventorySerialRegisterRequest) (*pb.InventorySerialRegisterResponse, error) {
+	path := "inventory.controller.InventorySerialRegister"
+	modelsCtx, ctxErr := models.ContextGet(ctx)
+	errBuilder := func(e *models.AppError, tx pgx.Tx) (*pb.InventorySerialRegisterResponse, error) {
+		if tx != nil {
+			if rbErr := tx.Rollback(modelsCtx.Context); rbErr != nil {
+				c.log.Errorf("%s: an error rolling back a transaction, err: %s", path, rbErr.Error())
+			}
+		}
+		return &pb.InventorySerialRegisterResponse{Response: &pb.InventorySerialRegisterResponse_Error{Error: models.AppErrorToProto(e)}}, nil
+	}
+
+	if ctxErr != nil {
+		return errBuilder(ctxErr, nil)
+	}
+	internalErr := func(err error, details string, tx pgx.Tx) (*pb.InventorySerialRegisterResponse, error) {
+		return errBuilder(models.NewAppError(modelsCtx, path, models.ErrMsgInternal, nil, details, int(codes.Internal), &models.AppErrorErrorsArgs{Err: err}), tx)
+	}
+	sucBuilder := func(data *pb.InventorySerialRegisterResponseData) (*pb.InventorySerialRegisterResponse, error) {
+		return &pb.InventorySerialRegisterResponse{Response: &pb.InventorySerialRegisterResponse_Data{Data: data}}, nil
+	}
+
+	rctx, cancel := context.WithTimeout(context.Background(), time.Second*12)
+	defer cancel()
+	modelsCtx.Context = rctx
+
+	ar := models.AuditRecordNew(modelsCtx, intModels.EventNameInventorySerialRegister, models.EventStatusFail)
+	defer func() {
+		ar.AuditEventDataPriorState(intModels.InventorySerialRegisterRequestAuditable(req))
+		c.ProcessAudit(ctx, ar)
+	}()
+
+	sellerID, ok := sellerScope(ctx, req.GetSellerId())
+	if !ok {
+		return errBuilder(models.NewAppError(modelsCtx, path, "inventory.seller.forbidden", nil, "", int(codes.PermissionDenied), nil), nil)
+	}
+	if len(req.GetSerialNumbers()) == 0 {
+		return errBuilder(models.NewAppError(modelsCtx, path, "inventory.serial.serial_numbers_required", nil, "", int(codes.InvalidArgument), nil), nil)
+	}
+	seen := make(map[string]bool, len(req.GetSerialNumbers()))
+	for _, number := range req.GetSerialNumbers() {
+		if number == "" {
+			return errBuilder(models.NewAppError(modelsCtx, path, "inventory.serial.serial_numbers_required", nil, "", int(codes.InvalidArgument), nil), nil)
+		}
+		if seen[number] {
+			ei := map[string]*models.AppErrorError{number: {ID: "inventory.serial.duplicate_serial", Params: map[string]any{"SerialNumber": number}}}
+			return errBuilder(models.NewAppError(modelsCtx, path, "inventory.serial.duplicate_serial", nil, "", int(codes.InvalidArgument), &models.AppErrorErrorsArgs{ErrorsInternal: ei}), nil)
+		}
+		seen[number] = true
+	}
+
+	tx, err := c.store.GetTx(modelsCtx.Context, pgx.TxOptions{})
+	if err != nil {
+		return internalErr(err, "failed to begin transaction", nil)
+	}
+
+	inventory, err := c.store.InventoryItemGetByProductVariant(modelsCtx, tx, sellerID, req.GetProductId(), req.GetVariantId(), req.GetLocationId())
+	if err != nil {
+		if appErr := itemAmbiguous(modelsCtx, path, err, fmt.Sprintf("%s.%s", req.GetProductId(), req.GetVariantId())); appErr != nil {
+			return errBuilder(appErr, tx)
+		}
+		if err.ErrType == models.DBErrorTypeNoRows {
+			key := fmt.Sprintf("%s.%s", req.GetProductId(), req.GetVariantId())
+			ei := map[string]*models.AppErrorError{key: {ID: "orders.items.not_found_in_inventory"}}
+			return errBuilder(models.NewAppError(modelsCtx, path, "error.not_found", nil, "", int(codes.NotFound), &models.AppErrorErrorsArgs{Err: err, ErrorsInternal: ei}), tx)
+		}
+		return internalErr(err, "failed to query inventory_items table", tx)
+	}
+
+	inStock := []string{
+		intModels.GetInventorySerialStatus(pb.InventorySerialStatus_INVENTORY_SERIAL_STATUS_AVAILABLE),
+		intModels.GetInventorySerialStatus(pb.InventorySerialStatus_INVENTORY_SERIAL_STATUS_RESERVED),
+		intModels.GetInventorySerialStatus(pb.InventorySerialStatus_INVENTORY_SERIAL_STATUS_RETURNED),
+	}
+	count, err := c.store.InventorySerialsCount(modelsCtx, tx, inventory.Id, inStock)
+	if err != nil {
+		return internalErr(err, "failed to count the serials of the inventory item", tx)
+	}
+	if count+int32(len(req.GetSerialNumbers())) > inventory.QuantityTotal {
+		params := map[string]any{"Quantity": max(inventory.QuantityTotal-count, 0)}
+		return errBuilder(models.NewAppError(modelsCtx, path, "inventory.serial.exceeds_on_hand", params, "", int(codes.FailedPrecondition), nil), tx)
+	}
+
+	data := &pb.InventorySerialRegisterResponseData{Serials: make([]*pb.InventorySerialData, 0, len(req.GetSerialNumbers()))}
+	for _, number := range req.GetSerialNumbers() {
+		_, err := c.store.InventorySerialGetByNumber(modelsCtx, tx, inventory.Id, number)
+		if err != nil && err.ErrType != models.DBErrorTypeNoRows {
+			return internalErr(err, "failed to query inventory_serials table", tx)
+		}
+		if err == nil {
+			ei := map[string]*models.AppErrorError{number: {ID: "inventory.serial.already_registered", Params: map[string]any{"SerialNumber": number}}}
+			return errBuilder(models.NewAppError(modelsCtx, path, "inventory.serial.already_registered", nil, "", int(codes.AlreadyExists), &models.AppErrorErrorsArgs{ErrorsInternal: ei}), tx)
+		}
+
+		serial := &pb.InventorySerial{
+			Id:              utils.NewID(),
+			InventoryItemId: inventory.Id,
+			LocationId:      inventory.LocationId,
+			SerialNumber:    number,
+			Status:          intModels.GetInventorySerialStatus(pb.InventorySerialStatus_INVENTORY_SERIAL_STATUS_AVAILABLE),
+			CreatedAt:       utils.TimeGetMillis(),
+		}
+		if err := c.store.InventorySerialCreate(modelsCtx, tx, serial); err != nil {
+			return internalErr(err, "failed to create the serial", tx)
+		}
+
+		movement := &pb.InventorySerialMovement{
+			Id:           utils.NewID(),
+			SerialId:     serial.Id,
+			MovementType: intModels.GetInventoryMovementType(pb.InventoryMovementType_INVENTORY_MOVEMENT_TYPE_IN),
+			Status:       serial.Status,
+			Reason:       req.Reason,
+			CreatedAt:    serial.CreatedAt,
+		}
+		if err := c.store.InventorySerialMovementCreate(modelsCtx, tx, movement); err != nil {
+			return internalErr(err, "failed to create the serial movement", tx)
+		}
+
+		data.Serials = append(data.Serials, serialData(inventory, serial, []*pb.InventorySerialMovement{movement}))
+	}
+
+	if err := tx.Commit(modelsCtx.Context); err != nil {
+		return internalErr(err, "failed to commit transaction", tx)
+	}
+
+	ar.Success()
+	return sucBuilder(data)
+}
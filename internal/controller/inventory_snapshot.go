@@ -0,0 +1,40 @@
+package controller
+
+import (
+	"context"
+	"time"
+
+	"github.com/ahmad-khatib0-org/megacommerce-shared-go/pkg/models"
+)
+
+// InventorySnapshotsSchedule takes the daily snapshot of the inventory items at the configured hour until ctx is
+// done. If the service wasn't running at that hour today, the snapshot of today is taken once it starts
+func (c *Controller) InventorySnapshotsSchedule(ctx context.Context) {
+	runDaily(ctx, c.cfg.Snapshots.Hour, c.snapshotsTake)
+}
+
+// snapshotsTake takes the snapshot of the day, and deletes the snapshots that are older than the retention period.
+// The snapshots of the day are taken once, so taking them again only adds the items that were created since
+func (c *Controller) snapshotsTake(ctx context.Context) {
+	path := "inventory.controller.snapshotsTake"
+	rctx, cancel := context.WithTimeout(ctx, time.Minute*5)
+	defer cancel()
+	mctx := &models.Context{Context: rctx}
+
+	now := time.Now().UTC()
+	taken, err := c.store.InventorySnapshotsTake(mctx, now.UnixMilli())
+	if err != nil {
+		c.log.Errorf("%s: failed to take the inventory snapshots, err: %v", path, err)
+		return
+	}
+	c.log.Infof("%s: took %d inventory snapshots", path, taken)
+
+	if days := c.cfg.Snapshots.RetentionDays; days > 0 {
+		deleted, err := c.store.InventorySnapshotsDelete(mctx, now.AddDate(0, 0, -int(days)).UnixMilli())
+		if err != nil {
+			c.log.Errorf("%s: failed to delete the expired inventory snapshots, err: %v", path, err)
+			return
+		}
+		c.log.Infof("%s: deleted %d expired inventory snapshots", path, deleted)
+	}
+}
@@ -0,0 +1,130 @@
+package controller
+
+import (
+	intModels "github.com/ahmad-khatib0-org/megacommerce-inventory/pkg/models"
+	pb "github.com/ahmad-khatib0-org/megacommerce-proto/gen/go/inventory/v1"
+	"github.com/ahmad-khatib0-org/megacommerce-shared-go/pkg/models"
+	"github.com/ahmad-khatib0-org/megacommerce-shared-go/pkg/utils"
+	"github.com/jackc/pgx/v5"
+	"google.golang.org/grpc/codes"
+)
+
+// stockChange computes the quantities of an inventory item after applying an update operation,
+// errID is set to a translation id if the operation is unknown or can't be applied
+func stockChange(inventory *pb.InventoryItem, op pb.InventoryUpdateOperation, quantity uint32) (total int, available int, movementType string, errID string) {
+	switch op {
+	case pb.InventoryUpdateOperation_INVENTORY_UPDATE_OPERATION_SET:
+		total = int(quantity)
+		available = total - int(inventory.QuantityReserved)
+		movementType = intModels.GetInventoryMovementType(pb.InventoryMovementType_INVENTORY_MOVEMENT_TYPE_ADJUSTMENT)
+	case pb.InventoryUpdateOperation_INVENTORY_UPDATE_OPERATION_ADD:
+		total = int(inventory.QuantityTotal + int32(quantity))
+		available = int(inventory.QuantityAvailable + int32(quantity))
+		movementType = intModels.GetInventoryMovementType(pb.InventoryMovementType_INVENTORY_MOVEMENT_TYPE_IN)
+	case pb.InventoryUpdateOperation_INVENTORY_UPDATE_OPERATION_SUBTRACT:
+		// Check if we have enough available inventory for subtraction
+		if int32(quantity) > inventory.QuantityAvailable {
+			return 0, 0, "", "inventory.update.insufficient_available"
+		}
+		total = int(inventory.QuantityTotal - int32(quantity))
+		available = int(inventory.QuantityAvailable - int32(quantity))
+		movementType = intModels.GetInventoryMovementType(pb.InventoryMovementType_INVENTORY_MOVEMENT_TYPE_OUT)
+	default:
+		return 0, 0, "", "inventory.update.invalid_operation"
+	}
+
+	return total, available, movementType, ""
+}
+
+// stockUpdateGuard computes the quantities of a locked inventory item after a manual update, which can't change
+// an item with lots, nor an item that a cycle count froze. errID is set to a translation id if the update can't
+// be applied, and err if the checks failed
+func (c *Controller) stockUpdateGuard(mctx *models.Context, tx pgx.Tx, inventory *pb.InventoryItem, op pb.InventoryUpdateOperation, quantity uint32) (total int, available int, movementType string, errID string, err *models.DBError) {
+	// the stock of an item with lots changes through its lots, so every unit keeps a lot
+	_, tracked, err := c.lotsSellable(mctx, tx, inventory.Id)
+	if err != nil {
+		return 0, 0, "", "", err
+	}
+	if tracked {
+		return 0, 0, "", "inventory.lot.item_is_lot_tracked", nil
+	}
+	frozen, err := c.store.InventoryCycleCountItemActive(mctx, tx, inventory.Id, true)
+	if err != nil {
+		return 0, 0, "", "", err
+	}
+	if frozen {
+		return 0, 0, "", "inventory.cycle_count.item_frozen", nil
+	}
+
+	total, available, movementType, errID = stockChange(inventory, op, quantity)
+	return total, available, movementType, errID, nil
+}
+
+// stockUpdateCheck is stockUpdateGuard for the rpcs, its failures are returned as errors. key identifies the item in the errors
+func (c *Controller) stockUpdateCheck(mctx *models.Context, path string, tx pgx.Tx, inventory *pb.InventoryItem, op pb.InventoryUpdateOperation, quantity uint32, key string) (total int, available int, movementType string, appErr *models.AppError) {
+	total, available, movementType, errID, err := c.stockUpdateGuard(mctx, tx, inventory, op, quantity)
+	if err != nil {
+		return 0, 0, "", models.NewAppError(mctx, path, models.ErrMsgInternal, nil, "failed to check the inventory item", int(codes.Internal), &models.AppErrorErrorsArgs{Err: err})
+	}
+	if errID != "" {
+		code := codes.InvalidArgument
+		if errID == "inventory.lot.item_is_lot_tracked" || errID == "inventory.cycle_count.item_frozen" {
+			code = codes.FailedPrecondition
+		}
+		ei := map[string]*models.AppErrorError{key: {ID: errID}}
+		return 0, 0, "", models.NewAppError(mctx, path, errID, nil, "", int(code), &models.AppErrorErrorsArgs{ErrorsInternal: ei})
+	}
+
+	return total, available, movementType, nil
+}
+
+// stockUpdate applies a manual update to a locked inventory item and records it as a movement, the units that it
+// adds are costed at unitCost, or at the average unit cost of the item if it's nil, and the units that it
+// makes available go to the orders that wait for them first
+func (c *Controller) stockUpdate(mctx *models.Context, path string, tx pgx.Tx, inventory *pb.InventoryItem, op pb.InventoryUpdateOperation, quantity uint32, key string, unitCost *int64, reason *string, metadata map[string]string) *models.AppError {
+	total, available, movementType, appErr := c.stockUpdateCheck(mctx, path, tx, inventory, op, quantity, key)
+	if appErr != nil {
+		return appErr
+	}
+
+	movement := &pb.InventoryMovement{
+		Id:              utils.NewID(),
+		InventoryItemId: inventory.Id,
+		MovementType:    movementType,
+		Quantity:        int32(quantity),
+		Reason:          reason,
+		Metadata:        metadata,
+		CreatedAt:       utils.TimeGetMillis(),
+	}
+	if err := c.stockApply(mctx, tx, inventory, total, available, movement, unitCost); err != nil {
+		return models.NewAppError(mctx, path, models.ErrMsgInternal, nil, "failed to update inventory", int(codes.Internal), &models.AppErrorErrorsArgs{Err: err})
+	}
+
+	return nil
+}
+
+// stockApply sets the quantities that stockUpdateGuard computed on a locked inventory item, and records the change
+// as movement, the units that it adds are costed at unitCost, or at the average unit cost of the item if it's nil,
+// and the units that it takes out are issued as non sale issues
+func (c *Controller) stockApply(mctx *models.Context, tx pgx.Tx, inventory *pb.InventoryItem, total int, available int, movement *pb.InventoryMovement, unitCost *int64) error {
+	if err := c.store.InventoryItemUpdate(mctx, tx, inventory.Id, total, inventory.QuantityReserved, available); err != nil {
+		return err
+	}
+
+	// a manual write down isn't a sale, only fulfilled reservations are booked as cost of goods sold
+	if _, err := c.stockCost(mctx, tx, movement, int32(total)-inventory.QuantityTotal, unitCost, false); err != nil {
+		return err
+	}
+	if err := c.store.InventoryMovementCreate(mctx, tx, movement); err != nil {
+		return err
+	}
+
+	// new stock goes to the orders that wait for it first
+	if available > int(inventory.QuantityAvailable) {
+		if err := c.backorderAllocate(mctx, tx, inventory.Id, int32(available)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
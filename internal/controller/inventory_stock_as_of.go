@@ -0,0 +1,113 @@
+package controller
+
+import (
+	"context"
+	"time"
+
+	pb "github.com/ahmad-khatib0-org/megacommerce-proto/gen/go/inventory/v1"
+	"github.com/ahmad-khatib0-org/megacommerce-shared-go/pkg/models"
+	"github.com/ahmad-khatib0-org/megacommerce-shared-go/pkg/utils"
+	"google.golang.org/grpc/codes"
+)
+
+// InventoryStockAsOf tells how many units of a sku were on hand at a point in time, at a single location or
+// across all locations. The stock of each item is the one of its nearest snapshot, rolled forward or back
+// by the movements made between the snapshot and that time, items that didn't exist yet are left out.
+// Times before the first snapshot that is kept are rejected
+func (c *Controller) InventoryStockAsOf(ctx context.Context, req *pb.InventoryStockAsOfRequest) (*pb.InventoryStockAsOfResponse, error) {
+	path := "inventory.controller.InventoryStockAsOf"
+	errBuilder := func(e *models.AppError) (*pb.InventoryStockAsOfResponse, error) {
+		return &pb.InventoryStockAsOfResponse{Response: &pb.InventoryStockAsOfResponse_Error{Error: models.AppErrorToProto(e)}}, nil
+	}
+
+	modelsCtx, ctxErr := models.ContextGet(ctx)
+	if ctxErr != nil {
+		return errBuilder(ctxErr)
+	}
+	internalErr := func(err error, details string) (*pb.InventoryStockAsOfResponse, error) {
+		return errBuilder(models.NewAppError(modelsCtx, path, models.ErrMsgInternal, nil, details, int(codes.Internal), &models.AppErrorErrorsArgs{Err: err}))
+	}
+	invalidArg := func(id string) (*pb.InventoryStockAsOfResponse, error) {
+		return errBuilder(models.NewAppError(modelsCtx, path, id, nil, "", int(codes.InvalidArgument), nil))
+	}
+
+	rctx, cancel := context.WithTimeout(context.Background(), time.Second*12)
+	defer cancel()
+	modelsCtx.Context = rctx
+
+	sellerID, ok := sellerScope(ctx, req.GetSellerId())
+	if !ok {
+		return errBuilder(models.NewAppError(modelsCtx, path, "inventory.seller.forbidden", nil, "", int(codes.PermissionDenied), nil))
+	}
+	if req.GetSku() == "" {
+		return invalidArg("inventory.stock_as_of.sku_required")
+	}
+	now, at := utils.TimeGetMillis(), req.GetAt()
+	if at <= 0 || at > now {
+		return invalidArg("inventory.stock_as_of.invalid_at")
+	}
+
+	// the movements made before the snapshots began don't record how they changed the on hand units, and the
+	// snapshots older than the retention are deleted, so the stock can only be rebuilt since the first snapshot kept
+	first, err := c.store.InventorySnapshotsFirstTakenAt(modelsCtx)
+	if err != nil && err.ErrType != models.DBErrorTypeNoRows {
+		return internalErr(err, "failed to query inventory_snapshots table")
+	}
+	if err != nil || at < first {
+		return invalidArg("inventory.stock_as_of.before_history")
+	}
+
+	items, err := c.store.InventoryItemsGetBySku(modelsCtx, sellerID, req.GetSku(), req.GetLocationId())
+	if err != nil {
+		return internalErr(err, "failed to query inventory_items table")
+	}
+	if len(items) == 0 {
+		return errBuilder(models.NewAppError(modelsCtx, path, "inventory.stock_as_of.sku_not_found", nil, "", int(codes.NotFound), nil))
+	}
+
+	data := &pb.InventoryStockAsOfData{Sku: req.GetSku(), LocationId: req.LocationId, At: at}
+	for _, item := range items {
+		if item.CreatedAt > at {
+			continue
+		}
+
+		// an item without snapshots is rolled back from its current stock
+		quantity, takenAt := int64(item.QuantityTotal), now
+		snapshot, err := c.store.InventorySnapshotNearest(modelsCtx, item.Id, at)
+		if err != nil && err.ErrType != models.DBErrorTypeNoRows {
+			return internalErr(err, "failed to query inventory_snapshots table")
+		}
+		if err == nil {
+			quantity, takenAt = int64(snapshot.QuantityTotal), snapshot.TakenAt
+		}
+
+		if takenAt <= at {
+			change, err := c.store.InventoryMovementsQuantityChange(modelsCtx, item.Id, takenAt, at)
+			if err != nil {
+				return internalErr(err, "failed to query inventory_movements table")
+			}
+			quantity += change
+		} else {
+			change, err := c.store.InventoryMovementsQuantityChange(modelsCtx, item.Id, at, takenAt)
+			if err != nil {
+				return internalErr(err, "failed to query inventory_movements table")
+			}
+			quantity -= change
+		}
+
+		var snapshotAt *int64
+		if snapshot != nil {
+			snapshotAt = &snapshot.TakenAt
+		}
+		data.Items = append(data.Items, &pb.InventoryStockAsOfItem{
+			ProductId:       item.ProductId,
+			VariantId:       item.VariantId,
+			LocationId:      item.LocationId,
+			QuantityOnHand:  uint32(max(quantity, 0)),
+			SnapshotTakenAt: snapshotAt,
+		})
+		data.QuantityOnHand += uint32(max(quantity, 0))
+	}
+
+	return &pb.InventoryStockAsOfResponse{Response: &pb.InventoryStockAsOfResponse_Data{Data: data}}, nil
+}
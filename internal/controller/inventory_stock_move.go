@@ -0,0 +1,171 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	intModels "github.com/ahmad-khatib0-org/megacommerce-inventory/pkg/models"
+	pb "github.com/ahmad-khatib0-org/megacommerce-proto/gen/go/inventory/v1"
+	"github.com/ahmad-khatib0-org/megacommerce-shared-go/pkg/models"
+	"github.com/ahmad-khatib0-org/megacommerce-shared-go/pkg/utils"
+	"github.com/jackc/pgx/v5"
+	"google.golang.org/grpc/codes"
+)
+
+// InventoryStockMove moves units of an item between its stock buckets, only the sellable bucket can be reserved and
+// sold, the quarantined, damaged and on hold buckets keep units on hand that can't be. Units that leave the sellable
+// bucket must be available, units that enter it go to backordered reservation lines first, and every move is
+// recorded as a movement typed by the bucket it moves units into, which issues or receives the cost of the units
+// that leave or enter the sellable bucket
+func (c *Controller) InventoryStockMove(ctx context.Context, req *pb.InventoryStockMoveRequest) (*pb.InventoryStockMoveResponse, error) {
+	path := "inventory.controller.InventoryStockMove"
+	modelsCtx, ctxErr := models.ContextGet(ctx)
+	errBuilder := func(e *models.AppError, tx pgx.Tx) (*pb.InventoryStockMoveResponse, error) {
+		if tx != nil {
+			if rbErr := tx.Rollback(modelsCtx.Context); rbErr != nil {
+				c.log.Errorf("%s: an error rolling back a transaction, err: %s", path, rbErr.Error())
+			}
+		}
+		return &pb.InventoryStockMoveResponse{Response: &pb.InventoryStockMoveResponse_Error{Error: models.AppErrorToProto(e)}}, nil
+	}
+
+	if ctxErr != nil {
+		return errBuilder(ctxErr, nil)
+	}
+	internalErr := func(err error, details string, tx pgx.Tx) (*pb.InventoryStockMoveResponse, error) {
+		return errBuilder(models.NewAppError(modelsCtx, path, models.ErrMsgInternal, nil, details, int(codes.Internal), &models.AppErrorErrorsArgs{Err: err}), tx)
+	}
+	invalidArg := func(id string, tx pgx.Tx) (*pb.InventoryStockMoveResponse, error) {
+		return errBuilder(models.NewAppError(modelsCtx, path, id, nil, "", int(codes.InvalidArgument), nil), tx)
+	}
+	sucBuilder := func(data *pb.InventoryStockBucketsData) (*pb.InventoryStockMoveResponse, error) {
+		return &pb.InventoryStockMoveResponse{Response: &pb.InventoryStockMoveResponse_Data{Data: data}}, nil
+	}
+
+	rctx, cancel := context.WithTimeout(context.Background(), time.Second*12)
+	defer cancel()
+	modelsCtx.Context = rctx
+
+	ar := models.AuditRecordNew(modelsCtx, intModels.EventNameInventoryStockMove, models.EventStatusFail)
+	defer func() {
+		ar.AuditEventDataPriorState(intModels.InventoryStockMoveRequestAuditable(req))
+		c.ProcessAudit(ctx, ar)
+	}()
+
+	sellerID, ok := sellerScope(ctx, req.GetSellerId())
+	if !ok {
+		return errBuilder(models.NewAppError(modelsCtx, path, "inventory.seller.forbidden", nil, "", int(codes.PermissionDenied), nil), nil)
+	}
+	if req.GetQuantity() == 0 {
+		return invalidArg("inventory.stock_move.quantity_required", nil)
+	}
+
+	tx, err := c.store.GetTx(modelsCtx.Context, pgx.TxOptions{})
+	if err != nil {
+		return internalErr(err, "failed to begin transaction", nil)
+	}
+
+	key := fmt.Sprintf("%s.%s", req.GetProductId(), req.GetVariantId())
+	inventory, err := c.store.InventoryItemGetByProductVariant(modelsCtx, tx, sellerID, req.GetProductId(), req.GetVariantId(), req.GetLocationId())
+	if err != nil {
+		if appErr := itemAmbiguous(modelsCtx, path, err, key); appErr != nil {
+			return errBuilder(appErr, tx)
+		}
+		if err.ErrType == models.DBErrorTypeNoRows {
+			ei := map[string]*models.AppErrorError{key: {ID: "orders.items.not_found_in_inventory"}}
+			return errBuilder(models.NewAppError(modelsCtx, path, "error.not_found", nil, "", int(codes.NotFound), &models.AppErrorErrorsArgs{Err: err, ErrorsInternal: ei}), tx)
+		}
+		return internalErr(err, "failed to query inventory_items table", tx)
+	}
+
+	// the sellable units of an item with lots are its lots, a unit that left them would have no lot to return to
+	_, tracked, err := c.lotsSellable(modelsCtx, tx, inventory.Id)
+	if err != nil {
+		return internalErr(err, "failed to get the lots of the inventory item", tx)
+	}
+	if tracked {
+		return errBuilder(models.NewAppError(modelsCtx, path, "inventory.lot.item_is_lot_tracked", nil, "", int(codes.FailedPrecondition), nil), tx)
+	}
+	if appErr := c.stockFrozen(modelsCtx, path, tx, inventory.Id, key); appErr != nil {
+		return errBuilder(appErr, tx)
+	}
+
+	total, available := inventory.QuantityTotal, inventory.QuantityAvailable
+	quantity := int32(req.GetQuantity())
+	if errID := intModels.InventoryStockBucketMove(inventory, req.GetFrom(), req.GetTo(), quantity); errID != "" {
+		ei := map[string]*models.AppErrorError{key: {ID: errID}}
+		return errBuilder(models.NewAppError(modelsCtx, path, errID, nil, "", int(codes.InvalidArgument), &models.AppErrorErrorsArgs{ErrorsInternal: ei}), tx)
+	}
+
+	err = c.store.InventoryItemBucketsUpdate(modelsCtx, tx, inventory.Id, inventory.QuantityTotal, inventory.QuantityAvailable, inventory.QuantityQuarantined, inventory.QuantityDamaged, inventory.QuantityOnHold)
+	if err != nil {
+		return internalErr(err, "failed to update the inventory item", tx)
+	}
+
+	movement := &pb.InventoryMovement{
+		Id:              utils.NewID(),
+		InventoryItemId: inventory.Id,
+		MovementType:    intModels.GetInventoryMovementType(intModels.InventoryStockBucketMovementType(req.GetTo())),
+		Quantity:        quantity,
+		Reason:          req.Reason,
+		Metadata: map[string]string{
+			"from_bucket": intModels.GetInventoryStockBucket(req.GetFrom()),
+			"to_bucket":   intModels.GetInventoryStockBucket(req.GetTo()),
+		},
+		CreatedAt: utils.TimeGetMillis(),
+	}
+
+	// the cost layers value the sellable bucket, units that leave it are issued out of them and units that enter it
+	// are received back at the cost they left at
+	var unitCost *int64
+	if inventory.QuantityTotal > total {
+		unitCost, err = c.stockCostBucket(modelsCtx, tx, inventory.Id, req.GetFrom())
+		if err != nil {
+			return internalErr(err, "failed to get the cost of the stock bucket", tx)
+		}
+	}
+	if _, err := c.stockCost(modelsCtx, tx, movement, inventory.QuantityTotal-total, unitCost, false); err != nil {
+		return internalErr(err, "failed to cost the inventory movement", tx)
+	}
+	if err := c.store.InventoryMovementCreate(modelsCtx, tx, movement); err != nil {
+		return internalErr(err, "failed to create inventory movement", tx)
+	}
+
+	// units that are sellable again go to the orders that wait for them first
+	if inventory.QuantityAvailable > available {
+		if err := c.backorderAllocate(modelsCtx, tx, inventory.Id, inventory.QuantityAvailable); err != nil {
+			return internalErr(err, "failed to allocate backordered inventory", tx)
+		}
+	}
+
+	// backordered lines may have reserved some of the units
+	inventory, err = c.store.InventoryItemGetByProductVariant(modelsCtx, tx, sellerID, req.GetProductId(), req.GetVariantId(), req.GetLocationId())
+	if err != nil {
+		return internalErr(err, "failed to query inventory_items table", tx)
+	}
+
+	if err := tx.Commit(modelsCtx.Context); err != nil {
+		return internalErr(err, "failed to commit transaction", tx)
+	}
+
+	ar.Success()
+	return sucBuilder(stockBucketsData(inventory))
+}
+
+// stockBucketsData converts the stock buckets of an inventory item to the response format. quantity_total only
+// holds the sellable bucket, so it's the sellable quantity on purpose, it includes the reserved units that are
+// reported apart, and the units that can still be reserved are the available quantity
+func stockBucketsData(inventory *pb.InventoryItem) *pb.InventoryStockBucketsData {
+	return &pb.InventoryStockBucketsData{
+		ProductId:           inventory.ProductId,
+		VariantId:           inventory.VariantId,
+		Sku:                 inventory.Sku,
+		QuantitySellable:    uint32(inventory.QuantityTotal),
+		QuantityReserved:    uint32(inventory.QuantityReserved),
+		QuantityAvailable:   uint32(max(inventory.QuantityAvailable, 0)),
+		QuantityQuarantined: uint32(inventory.QuantityQuarantined),
+		QuantityDamaged:     uint32(inventory.QuantityDamaged),
+		QuantityOnHold:      uint32(inventory.QuantityOnHold),
+	}
+}
@@ -0,0 +1,198 @@
+package controller
+
+import (
+	"context"
+
+	intModels "github.com/ahmad-khatib0-org/megacommerce-inventory/pkg/models"
+	pb "github.com/ahmad-khatib0-org/megacommerce-proto/gen/go/inventory/v1"
+	"github.com/ahmad-khatib0-org/megacommerce-shared-go/pkg/models"
+	"github.com/ahmad-khatib0-org/megacommerce-shared-go/pkg/utils"
+	"github.com/jackc/pgx/v5"
+	"google.golang.org/grpc/codes"
+)
+
+// transferGet gets a transfer and its items, the transfer is locked if tx is not nil.
+// A seller can only get its own transfers, other transfers are reported as not found
+func (c *Controller) transferGet(ctx context.Context, mctx *models.Context, path string, tx pgx.Tx, transferNumber string) (*pb.InventoryTransfer, []*pb.InventoryTransferItem, *models.AppError) {
+	transfer, err := c.store.InventoryTransferGetByNumber(mctx, tx, transferNumber)
+	if err != nil {
+		if err.ErrType == models.DBErrorTypeNoRows {
+			return nil, nil, models.NewAppError(mctx, path, "inventory.transfer.not_found", nil, err.Details, int(codes.NotFound), &models.AppErrorErrorsArgs{Err: err})
+		}
+		return nil, nil, models.NewAppError(mctx, path, models.ErrMsgInternal, nil, "failed to get the transfer", int(codes.Internal), &models.AppErrorErrorsArgs{Err: err})
+	}
+
+	if _, ok := sellerScope(ctx, transfer.SellerId); !ok {
+		return nil, nil, models.NewAppError(mctx, path, "inventory.transfer.not_found", nil, "", int(codes.NotFound), nil)
+	}
+
+	lines, err := c.store.InventoryTransferItemsGetByTransferID(mctx, tx, transfer.Id)
+	if err != nil {
+		return nil, nil, models.NewAppError(mctx, path, models.ErrMsgInternal, nil, "failed to get the transfer items", int(codes.Internal), &models.AppErrorErrorsArgs{Err: err})
+	}
+
+	return transfer, lines, nil
+}
+
+// transferSerialsCheck validates the serial numbers of a transfer line against its source item, a serialized
+// item is transferred by naming one available serial per unit, and an item without serials takes none
+func (c *Controller) transferSerialsCheck(mctx *models.Context, path string, tx pgx.Tx, sourceItemID string, key string, serialNumbers []string, quantity int32) *models.AppError {
+	tracked, err := c.store.InventorySerialsCount(mctx, tx, sourceItemID, nil)
+	if err != nil {
+		return models.NewAppError(mctx, path, models.ErrMsgInternal, nil, "failed to count the serials of the inventory item", int(codes.Internal), &models.AppErrorErrorsArgs{Err: err})
+	}
+	if tracked == 0 && len(serialNumbers) == 0 {
+		return nil
+	}
+	if tracked > 0 && len(serialNumbers) == 0 {
+		ei := map[string]*models.AppErrorError{key: {ID: "inventory.serial.serials_required", Params: map[string]any{"Quantity": quantity}}}
+		return models.NewAppError(mctx, path, "inventory.serial.serials_required", nil, "", int(codes.InvalidArgument), &models.AppErrorErrorsArgs{ErrorsInternal: ei})
+	}
+	if int32(len(serialNumbers)) != quantity {
+		ei := map[string]*models.AppErrorError{key: {ID: "inventory.serial.count_mismatch", Params: map[string]any{"Quantity": quantity}}}
+		return models.NewAppError(mctx, path, "inventory.serial.count_mismatch", nil, "", int(codes.InvalidArgument), &models.AppErrorErrorsArgs{ErrorsInternal: ei})
+	}
+
+	_, appErr := c.serialsFind(mctx, path, tx, sourceItemID, key, serialNumbers, intModels.InventorySerialAssignable)
+	return appErr
+}
+
+// transferSerialsMove moves the serials with the given numbers from one inventory item to another one and its location,
+// valid checks every serial at the item it leaves, and each move is recorded as a serial movement of the transfer
+func (c *Controller) transferSerialsMove(mctx *models.Context, path string, tx pgx.Tx, transfer *pb.InventoryTransfer, key string, serialNumbers []string, fromItemID string, to *pb.InventoryItem, status pb.InventorySerialStatus, movementType pb.InventoryMovementType, reason *string, valid func(*pb.InventorySerial) bool) *models.AppError {
+	serials, appErr := c.serialsFind(mctx, path, tx, fromItemID, key, serialNumbers, valid)
+	if appErr != nil {
+		return appErr
+	}
+
+	for _, serial := range serials {
+		serial.Status = intModels.GetInventorySerialStatus(status)
+		err := c.store.InventorySerialRelocate(mctx, tx, serial.Id, to.Id, to.LocationId, serial.Status)
+		if err == nil {
+			err = c.store.InventorySerialMovementCreate(mctx, tx, &pb.InventorySerialMovement{
+				Id:           utils.NewID(),
+				SerialId:     serial.Id,
+				MovementType: intModels.GetInventoryMovementType(movementType),
+				Status:       serial.Status,
+				ReferenceId:  &transfer.Id,
+				Reason:       reason,
+				CreatedAt:    utils.TimeGetMillis(),
+			})
+		}
+		if err != nil {
+			return models.NewAppError(mctx, path, models.ErrMsgInternal, nil, "failed to move a serial of the transfer", int(codes.Internal), &models.AppErrorErrorsArgs{Err: err})
+		}
+	}
+
+	return nil
+}
+
+// transferSerialsInTransit returns up to quantity serial numbers of a shipped transfer line that are still
+// in transit at its destination item, in the order of the line
+func (c *Controller) transferSerialsInTransit(mctx *models.Context, tx pgx.Tx, line *pb.InventoryTransferItem, quantity int32) ([]string, *models.DBError) {
+	numbers := make([]string, 0, quantity)
+	for _, number := range line.SerialNumbers {
+		if int32(len(numbers)) == quantity {
+			break
+		}
+
+		// a received serial may have moved on to another item since
+		serial, err := c.store.InventorySerialGetByNumber(mctx, tx, *line.DestinationItemId, number)
+		if err != nil && err.ErrType != models.DBErrorTypeNoRows {
+			return nil, err
+		}
+		if err == nil && intModels.InventorySerialInTransit(serial) {
+			numbers = append(numbers, number)
+		}
+	}
+
+	return numbers, nil
+}
+
+// transferStatusInvalid is returned when a transfer can't go through an operation in its current status
+func transferStatusInvalid(mctx *models.Context, path string, transfer *pb.InventoryTransfer) *models.AppError {
+	params := map[string]any{"Status": transfer.Status}
+	return models.NewAppError(mctx, path, "inventory.transfer.invalid_status", params, "", int(codes.FailedPrecondition), nil)
+}
+
+// transferMovement records a movement of a transfer line, movements of the same transfer share its id as reference,
+// and lotID is the lot the units move out of or into, nil if the item has no lots. The units of an IN movement are
+// costed at unitCost, or at the average unit cost of the item if it's nil, the first in first out cost of the
+// units of an OUT movement is returned
+func (c *Controller) transferMovement(mctx *models.Context, tx pgx.Tx, transfer *pb.InventoryTransfer, inventoryItemID string, lotID *string, movementType pb.InventoryMovementType, quantity int32, unitCost *int64, reason *string) (int64, *models.DBError) {
+	movement := &pb.InventoryMovement{
+		Id:              utils.NewID(),
+		InventoryItemId: inventoryItemID,
+		LotId:           lotID,
+		MovementType:    intModels.GetInventoryMovementType(movementType),
+		Quantity:        quantity,
+		ReferenceId:     &transfer.Id,
+		Reason:          reason,
+		Metadata:        map[string]string{"transfer_number": transfer.TransferNumber},
+		CreatedAt:       utils.TimeGetMillis(),
+	}
+
+	delta := quantity
+	if movementType == pb.InventoryMovementType_INVENTORY_MOVEMENT_TYPE_OUT {
+		delta = -quantity
+	}
+	cost, err := c.stockCost(mctx, tx, movement, delta, unitCost, false)
+	if err != nil {
+		return 0, err
+	}
+	return cost, c.store.InventoryMovementCreate(mctx, tx, movement)
+}
+
+// transferUnitCost is the cost per unit that the units of a transfer line left the source at, nil for
+// the lines that were shipped before the cost was recorded
+func transferUnitCost(line *pb.InventoryTransferItem) *int64 {
+	if line.CostShipped == nil || line.QuantityShipped <= 0 {
+		return nil
+	}
+	unitCost := *line.CostShipped / int64(line.QuantityShipped)
+	return &unitCost
+}
+
+// transferStatusFromLines derives the status of a shipped transfer from what its lines received
+func transferStatusFromLines(lines []*pb.InventoryTransferItem) pb.InventoryTransferStatus {
+	received, complete := false, true
+	for _, line := range lines {
+		received = received || line.QuantityReceived > 0
+		complete = complete && line.QuantityReceived >= line.QuantityShipped
+	}
+
+	switch {
+	case complete:
+		return pb.InventoryTransferStatus_INVENTORY_TRANSFER_STATUS_RECEIVED
+	case received:
+		return pb.InventoryTransferStatus_INVENTORY_TRANSFER_STATUS_PARTIALLY_RECEIVED
+	default:
+		return pb.InventoryTransferStatus_INVENTORY_TRANSFER_STATUS_SHIPPED
+	}
+}
+
+// transferData converts a transfer to the response format
+func transferData(transfer *pb.InventoryTransfer, lines []*pb.InventoryTransferItem) *pb.InventoryTransferGetResponseData {
+	items := make([]*pb.InventoryTransferListItem, 0, len(lines))
+	for _, line := range lines {
+		items = append(items, &pb.InventoryTransferListItem{
+			Sku:               line.Sku,
+			LotNumber:         line.LotNumber,
+			Quantity:          uint32(line.Quantity),
+			QuantityShipped:   uint32(line.QuantityShipped),
+			QuantityReceived:  uint32(line.QuantityReceived),
+			QuantityInTransit: uint32(line.QuantityShipped - line.QuantityReceived),
+		})
+	}
+
+	return &pb.InventoryTransferGetResponseData{
+		TransferNumber: transfer.TransferNumber,
+		SellerId:       transfer.SellerId,
+		FromLocationId: transfer.FromLocationId,
+		ToLocationId:   transfer.ToLocationId,
+		Status:         intModels.GetInventoryTransferStatusFromString(transfer.Status),
+		Note:           transfer.Note,
+		CreatedAt:      transfer.CreatedAt,
+		Items:          items,
+	}
+}
@@ -0,0 +1,136 @@
+package controller
+
+import (
+	"context"
+	"time"
+
+	intModels "github.com/ahmad-khatib0-org/megacommerce-inventory/pkg/models"
+	pb "github.com/ahmad-khatib0-org/megacommerce-proto/gen/go/inventory/v1"
+	"github.com/ahmad-khatib0-org/megacommerce-shared-go/pkg/models"
+	"github.com/jackc/pgx/v5"
+	"google.golang.org/grpc/codes"
+)
+
+// InventoryTransferCancel cancels a transfer that isn't fully received, the units that are still
+// in transit go back to the source location with an IN movement, received units stay where they are
+func (c *Controller) InventoryTransferCancel(ctx context.Context, req *pb.InventoryTransferCancelRequest) (*pb.InventoryTransferCancelResponse, error) {
+	path := "inventory.controller.InventoryTransferCancel"
+	modelsCtx, ctxErr := models.ContextGet(ctx)
+	errBuilder := func(e *models.AppError, tx pgx.Tx) (*pb.InventoryTransferCancelResponse, error) {
+		if tx != nil {
+			if rbErr := tx.Rollback(modelsCtx.Context); rbErr != nil {
+				c.log.Errorf("%s: an error rolling back a transaction, err: %s", path, rbErr.Error())
+			}
+		}
+		return &pb.InventoryTransferCancelResponse{Response: &pb.InventoryTransferCancelResponse_Error{Error: models.AppErrorToProto(e)}}, nil
+	}
+
+	if ctxErr != nil {
+		return errBuilder(ctxErr, nil)
+	}
+	internalErr := func(err error, details string, tx pgx.Tx) (*pb.InventoryTransferCancelResponse, error) {
+		return errBuilder(models.NewAppError(modelsCtx, path, models.ErrMsgInternal, nil, details, int(codes.Internal), &models.AppErrorErrorsArgs{Err: err}), tx)
+	}
+	sucBuilder := func(data *pb.InventoryTransferGetResponseData) (*pb.InventoryTransferCancelResponse, error) {
+		return &pb.InventoryTransferCancelResponse{Response: &pb.InventoryTransferCancelResponse_Data{Data: data}}, nil
+	}
+
+	rctx, cancel := context.WithTimeout(context.Background(), time.Second*12)
+	defer cancel()
+	modelsCtx.Context = rctx
+
+	ar := models.AuditRecordNew(modelsCtx, intModels.EventNameInventoryTransferCancel, models.EventStatusFail)
+	defer func() {
+		ar.AuditEventDataPriorState(map[string]any{"transfer_number": req.GetTransferNumber(), "reason": req.Reason})
+		c.ProcessAudit(ctx, ar)
+	}()
+
+	tx, err := c.store.GetTx(modelsCtx.Context, pgx.TxOptions{})
+	if err != nil {
+		return internalErr(err, "failed to begin transaction", nil)
+	}
+
+	transfer, lines, appErr := c.transferGet(ctx, modelsCtx, path, tx, req.GetTransferNumber())
+	if appErr != nil {
+		return errBuilder(appErr, tx)
+	}
+	switch intModels.GetInventoryTransferStatusFromString(transfer.Status) {
+	case pb.InventoryTransferStatus_INVENTORY_TRANSFER_STATUS_DRAFT,
+		pb.InventoryTransferStatus_INVENTORY_TRANSFER_STATUS_SHIPPED,
+		pb.InventoryTransferStatus_INVENTORY_TRANSFER_STATUS_PARTIALLY_RECEIVED:
+	default:
+		return errBuilder(transferStatusInvalid(modelsCtx, path, transfer), tx)
+	}
+
+	// a drafted transfer has nothing shipped, so nothing is in transit
+	for _, line := range lines {
+		outstanding := line.QuantityShipped - line.QuantityReceived
+		if outstanding <= 0 || line.DestinationItemId == nil {
+			continue
+		}
+
+		source, err := c.store.InventoryItemGetBySku(modelsCtx, tx, transfer.SellerId, line.Sku, transfer.FromLocationId)
+		if err != nil {
+			return internalErr(err, "failed to get the source inventory item", tx)
+		}
+		if appErr := c.stockFrozen(modelsCtx, path, tx, source.Id, line.Sku); appErr != nil {
+			return errBuilder(appErr, tx)
+		}
+
+		// the units go back into the lot they were shipped from
+		lot, appErr := c.lotByNumber(modelsCtx, path, tx, source.Id, line.GetLotNumber(), line.Sku)
+		if appErr != nil {
+			return errBuilder(appErr, tx)
+		}
+		var lotID *string
+		if lot != nil {
+			if err := c.store.InventoryLotReceive(modelsCtx, tx, lot.Id, outstanding); err != nil {
+				return internalErr(err, "failed to update the lot", tx)
+			}
+			lotID = &lot.Id
+		}
+
+		// the serials that are still in transit go back to the source item
+		if len(line.SerialNumbers) > 0 {
+			serialNumbers, err := c.transferSerialsInTransit(modelsCtx, tx, line, outstanding)
+			if err != nil {
+				return internalErr(err, "failed to get the serials in transit", tx)
+			}
+			appErr := c.transferSerialsMove(modelsCtx, path, tx, transfer, line.Sku, serialNumbers, *line.DestinationItemId, source,
+				pb.InventorySerialStatus_INVENTORY_SERIAL_STATUS_AVAILABLE, pb.InventoryMovementType_INVENTORY_MOVEMENT_TYPE_IN, req.Reason, intModels.InventorySerialInTransit)
+			if appErr != nil {
+				return errBuilder(appErr, tx)
+			}
+		}
+		added, err := c.store.InventoryItemInTransitAdd(modelsCtx, tx, *line.DestinationItemId, -outstanding)
+		if err != nil {
+			return internalErr(err, "failed to update the destination inventory item", tx)
+		}
+		// TODO: this should not happen, and should be added to DLQ to be reviewed
+		if !added {
+			return internalErr(nil, "failed to cancel a transfer, the quantity is bigger than the quantity_in_transit value", tx)
+		}
+
+		total := int(source.QuantityTotal + outstanding)
+		available := int(source.QuantityAvailable + outstanding)
+		if err := c.store.InventoryItemUpdate(modelsCtx, tx, source.Id, total, source.QuantityReserved, available); err != nil {
+			return internalErr(err, "failed to update the source inventory item", tx)
+		}
+		// the units come back at the cost they left at
+		if _, err := c.transferMovement(modelsCtx, tx, transfer, source.Id, lotID, pb.InventoryMovementType_INVENTORY_MOVEMENT_TYPE_IN, outstanding, transferUnitCost(line), req.Reason); err != nil {
+			return internalErr(err, "failed to create inventory movement", tx)
+		}
+	}
+
+	transfer.Status = intModels.GetInventoryTransferStatus(pb.InventoryTransferStatus_INVENTORY_TRANSFER_STATUS_CANCELLED)
+	if err := c.store.InventoryTransferUpdateStatus(modelsCtx, tx, transfer.Id, transfer.Status); err != nil {
+		return internalErr(err, "failed to update the transfer status", tx)
+	}
+
+	if err := tx.Commit(modelsCtx.Context); err != nil {
+		return internalErr(err, "failed to commit transaction", tx)
+	}
+
+	ar.Success()
+	return sucBuilder(transferData(transfer, lines))
+}
@@ -0,0 +1,148 @@
+package controller
+
+import (
+	"context"
+	"time"
+
+	intModels "github.com/ahmad-khatib0-org/megacommerce-inventory/pkg/models"
+	pb "github.com/ahmad-khatib0-org/megacommerce-proto/gen/go/inventory/v1"
+	"github.com/ahmad-khatib0-org/megacommerce-shared-go/pkg/models"
+	"github.com/ahmad-khatib0-org/megacommerce-shared-go/pkg/utils"
+	"github.com/jackc/pgx/v5"
+	"google.golang.org/grpc/codes"
+)
+
+// InventoryTransferCreate drafts a transfer of a seller's stock from one location to another,
+// nothing moves until the transfer is shipped. A line of an item with lots names the lot it ships from
+func (c *Controller) InventoryTransferCreate(ctx context.Context, req *pb.InventoryTransferCreateRequest) (*pb.InventoryTransferCreateResponse, error) {
+	path := "inventory.controller.InventoryTransferCreate"
+	modelsCtx, ctxErr := models.ContextGet(ctx)
+	errBuilder := func(e *models.AppError, tx pgx.Tx) (*pb.InventoryTransferCreateResponse, error) {
+		if tx != nil {
+			if rbErr := tx.Rollback(modelsCtx.Context); rbErr != nil {
+				c.log.Errorf("%s: an error rolling back a transaction, err: %s", path, rbErr.Error())
+			}
+		}
+		return &pb.InventoryTransferCreateResponse{Response: &pb.InventoryTransferCreateResponse_Error{Error: models.AppErrorToProto(e)}}, nil
+	}
+
+	if ctxErr != nil {
+		return errBuilder(ctxErr, nil)
+	}
+	internalErr := func(err error, details string, tx pgx.Tx) (*pb.InventoryTransferCreateResponse, error) {
+		return errBuilder(models.NewAppError(modelsCtx, path, models.ErrMsgInternal, nil, details, int(codes.Internal), &models.AppErrorErrorsArgs{Err: err}), tx)
+	}
+	invalidArg := func(id string) (*pb.InventoryTransferCreateResponse, error) {
+		return errBuilder(models.NewAppError(modelsCtx, path, id, nil, "", int(codes.InvalidArgument), nil), nil)
+	}
+	sucBuilder := func(data *pb.InventoryTransferGetResponseData) (*pb.InventoryTransferCreateResponse, error) {
+		return &pb.InventoryTransferCreateResponse{Response: &pb.InventoryTransferCreateResponse_Data{Data: data}}, nil
+	}
+
+	rctx, cancel := context.WithTimeout(context.Background(), time.Second*12)
+	defer cancel()
+	modelsCtx.Context = rctx
+
+	auditable := intModels.InventoryTransferCreateRequestAuditable(req)
+	ar := models.AuditRecordNew(modelsCtx, intModels.EventNameInventoryTransferCreate, models.EventStatusFail)
+	defer func() {
+		ar.AuditEventDataPriorState(auditable)
+		c.ProcessAudit(ctx, ar)
+	}()
+
+	sellerID, ok := sellerScope(ctx, req.GetSellerId())
+	if !ok {
+		return errBuilder(models.NewAppError(modelsCtx, path, "inventory.seller.forbidden", nil, "", int(codes.PermissionDenied), nil), nil)
+	}
+	if sellerID == "" {
+		return invalidArg("inventory.transfer.seller_required")
+	}
+	if req.GetFromLocationId() == "" || req.GetToLocationId() == "" {
+		return invalidArg("inventory.transfer.locations_required")
+	}
+	if req.GetFromLocationId() == req.GetToLocationId() {
+		return invalidArg("inventory.transfer.same_location")
+	}
+	if len(req.GetItems()) == 0 {
+		return invalidArg("inventory.transfer.items_required")
+	}
+
+	seen := make(map[string]bool, len(req.GetItems()))
+	for _, item := range req.GetItems() {
+		if item.GetSku() == "" || item.GetQuantity() == 0 {
+			return invalidArg("inventory.transfer.invalid_item")
+		}
+		if seen[item.GetSku()] {
+			return invalidArg("inventory.transfer.duplicate_item")
+		}
+		seen[item.GetSku()] = true
+	}
+
+	tx, err := c.store.GetTx(modelsCtx.Context, pgx.TxOptions{})
+	if err != nil {
+		return internalErr(err, "failed to begin transaction", nil)
+	}
+
+	now := utils.TimeGetMillis()
+	transfer := &pb.InventoryTransfer{
+		Id:             utils.NewID(),
+		TransferNumber: "trf_" + utils.NewID(),
+		SellerId:       sellerID,
+		FromLocationId: req.GetFromLocationId(),
+		ToLocationId:   req.GetToLocationId(),
+		Status:         intModels.GetInventoryTransferStatus(pb.InventoryTransferStatus_INVENTORY_TRANSFER_STATUS_DRAFT),
+		Note:           req.Note,
+		CreatedAt:      now,
+	}
+	auditable["transfer_number"] = transfer.TransferNumber
+
+	if err := c.store.InventoryTransferCreate(modelsCtx, tx, transfer); err != nil {
+		return internalErr(err, "failed to create the transfer", tx)
+	}
+
+	lines := make([]*pb.InventoryTransferItem, 0, len(req.GetItems()))
+	for _, item := range req.GetItems() {
+		source, err := c.store.InventoryItemGetBySku(modelsCtx, tx, sellerID, item.GetSku(), req.GetFromLocationId())
+		if err != nil {
+			if appErr := itemAmbiguous(modelsCtx, path, err, item.GetSku()); appErr != nil {
+				return errBuilder(appErr, tx)
+			}
+			if err.ErrType == models.DBErrorTypeNoRows {
+				ei := map[string]*models.AppErrorError{item.GetSku(): {ID: "inventory.transfer.sku_not_found_at_source"}}
+				return errBuilder(models.NewAppError(modelsCtx, path, "error.not_found", nil, "", int(codes.NotFound), &models.AppErrorErrorsArgs{Err: err, ErrorsInternal: ei}), tx)
+			}
+			return internalErr(err, "failed to query inventory_items table", tx)
+		}
+		// the units of an item with lots travel with the lot they're shipped from, a line names one
+		if _, appErr := c.lotByNumber(modelsCtx, path, tx, source.Id, item.GetLotNumber(), item.GetSku()); appErr != nil {
+			return errBuilder(appErr, tx)
+		}
+		if appErr := c.transferSerialsCheck(modelsCtx, path, tx, source.Id, item.GetSku(), item.GetSerialNumbers(), int32(item.GetQuantity())); appErr != nil {
+			return errBuilder(appErr, tx)
+		}
+
+		line := &pb.InventoryTransferItem{
+			Id:            utils.NewID(),
+			TransferId:    transfer.Id,
+			Sku:           item.GetSku(),
+			SourceItemId:  source.Id,
+			Quantity:      int32(item.GetQuantity()),
+			SerialNumbers: item.GetSerialNumbers(),
+			CreatedAt:     now,
+		}
+		if item.GetLotNumber() != "" {
+			line.LotNumber = item.LotNumber
+		}
+		if err := c.store.InventoryTransferItemCreate(modelsCtx, tx, line); err != nil {
+			return internalErr(err, "failed to create a transfer item", tx)
+		}
+		lines = append(lines, line)
+	}
+
+	if err := tx.Commit(modelsCtx.Context); err != nil {
+		return internalErr(err, "failed to commit transaction", tx)
+	}
+
+	ar.Success()
+	return sucBuilder(transferData(transfer, lines))
+}
@@ -0,0 +1,33 @@
+package controller
+
+import (
+	"context"
+	"time"
+
+	pb "github.com/ahmad-khatib0-org/megacommerce-proto/gen/go/inventory/v1"
+	"github.com/ahmad-khatib0-org/megacommerce-shared-go/pkg/models"
+)
+
+// InventoryTransferGet gets the details of a transfer by its number
+func (c *Controller) InventoryTransferGet(ctx context.Context, req *pb.InventoryTransferGetRequest) (*pb.InventoryTransferGetResponse, error) {
+	path := "inventory.controller.InventoryTransferGet"
+	errBuilder := func(e *models.AppError) (*pb.InventoryTransferGetResponse, error) {
+		return &pb.InventoryTransferGetResponse{Response: &pb.InventoryTransferGetResponse_Error{Error: models.AppErrorToProto(e)}}, nil
+	}
+
+	modelsCtx, ctxErr := models.ContextGet(ctx)
+	if ctxErr != nil {
+		return errBuilder(ctxErr)
+	}
+
+	rctx, cancel := context.WithTimeout(context.Background(), time.Second*12)
+	defer cancel()
+	modelsCtx.Context = rctx
+
+	transfer, lines, appErr := c.transferGet(ctx, modelsCtx, path, nil, req.GetTransferNumber())
+	if appErr != nil {
+		return errBuilder(appErr)
+	}
+
+	return &pb.InventoryTransferGetResponse{Response: &pb.InventoryTransferGetResponse_Data{Data: transferData(transfer, lines)}}, nil
+}
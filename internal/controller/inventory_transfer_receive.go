@@ -0,0 +1,202 @@
+package controller
+
+import (
+	"context"
+	"time"
+
+	intModels "github.com/ahmad-khatib0-org/megacommerce-inventory/pkg/models"
+	pb "github.com/ahmad-khatib0-org/megacommerce-proto/gen/go/inventory/v1"
+	"github.com/ahmad-khatib0-org/megacommerce-shared-go/pkg/models"
+	"github.com/ahmad-khatib0-org/megacommerce-shared-go/pkg/utils"
+	"github.com/jackc/pgx/v5"
+	"google.golang.org/grpc/codes"
+)
+
+// InventoryTransferReceive receives units of a shipped transfer at the destination location, the given
+// quantities of the given skus, or everything still in transit if no items are given. The units move
+// from in transit into the destination stock with an IN movement, and go to backordered reservation lines first
+func (c *Controller) InventoryTransferReceive(ctx context.Context, req *pb.InventoryTransferReceiveRequest) (*pb.InventoryTransferReceiveResponse, error) {
+	path := "inventory.controller.InventoryTransferReceive"
+	modelsCtx, ctxErr := models.ContextGet(ctx)
+	errBuilder := func(e *models.AppError, tx pgx.Tx) (*pb.InventoryTransferReceiveResponse, error) {
+		if tx != nil {
+			if rbErr := tx.Rollback(modelsCtx.Context); rbErr != nil {
+				c.log.Errorf("%s: an error rolling back a transaction, err: %s", path, rbErr.Error())
+			}
+		}
+		return &pb.InventoryTransferReceiveResponse{Response: &pb.InventoryTransferReceiveResponse_Error{Error: models.AppErrorToProto(e)}}, nil
+	}
+
+	if ctxErr != nil {
+		return errBuilder(ctxErr, nil)
+	}
+	internalErr := func(err error, details string, tx pgx.Tx) (*pb.InventoryTransferReceiveResponse, error) {
+		return errBuilder(models.NewAppError(modelsCtx, path, models.ErrMsgInternal, nil, details, int(codes.Internal), &models.AppErrorErrorsArgs{Err: err}), tx)
+	}
+	sucBuilder := func(data *pb.InventoryTransferGetResponseData) (*pb.InventoryTransferReceiveResponse, error) {
+		return &pb.InventoryTransferReceiveResponse{Response: &pb.InventoryTransferReceiveResponse_Data{Data: data}}, nil
+	}
+
+	rctx, cancel := context.WithTimeout(context.Background(), time.Second*12)
+	defer cancel()
+	modelsCtx.Context = rctx
+
+	ar := models.AuditRecordNew(modelsCtx, intModels.EventNameInventoryTransferReceive, models.EventStatusFail)
+	defer func() {
+		ar.AuditEventDataPriorState(intModels.InventoryTransferReceiveRequestAuditable(req))
+		c.ProcessAudit(ctx, ar)
+	}()
+
+	tx, err := c.store.GetTx(modelsCtx.Context, pgx.TxOptions{})
+	if err != nil {
+		return internalErr(err, "failed to begin transaction", nil)
+	}
+
+	transfer, lines, appErr := c.transferGet(ctx, modelsCtx, path, tx, req.GetTransferNumber())
+	if appErr != nil {
+		return errBuilder(appErr, tx)
+	}
+	status := intModels.GetInventoryTransferStatusFromString(transfer.Status)
+	if status != pb.InventoryTransferStatus_INVENTORY_TRANSFER_STATUS_SHIPPED &&
+		status != pb.InventoryTransferStatus_INVENTORY_TRANSFER_STATUS_PARTIALLY_RECEIVED {
+		return errBuilder(transferStatusInvalid(modelsCtx, path, transfer), tx)
+	}
+
+	receipts := make(map[string]int32, len(lines))
+	receiptSerials := make(map[string][]string, len(lines))
+	if len(req.GetItems()) == 0 {
+		for _, line := range lines {
+			if outstanding := line.QuantityShipped - line.QuantityReceived; outstanding > 0 {
+				receipts[line.Sku] = outstanding
+			}
+		}
+	}
+	for _, item := range req.GetItems() {
+		line, found := utils.Find(lines, func(i *pb.InventoryTransferItem) bool { return i.Sku == item.GetSku() })
+		if !found {
+			ei := map[string]*models.AppErrorError{item.GetSku(): {ID: "inventory.transfer.item_not_found"}}
+			return errBuilder(models.NewAppError(modelsCtx, path, "error.not_found", nil, "", int(codes.NotFound), &models.AppErrorErrorsArgs{ErrorsInternal: ei}), tx)
+		}
+
+		// a quantity of 0 receives whatever is still in transit, duplicated skus add up
+		quantity := int32(item.GetQuantity())
+		if quantity == 0 {
+			quantity = line.QuantityShipped - line.QuantityReceived
+		}
+		receipts[line.Sku] += quantity
+		receiptSerials[line.Sku] = append(receiptSerials[line.Sku], item.GetSerialNumbers()...)
+	}
+
+	for _, line := range lines {
+		quantity, ok := receipts[line.Sku]
+		if !ok {
+			continue
+		}
+
+		received, err := c.store.InventoryTransferItemReceive(modelsCtx, tx, line.Id, quantity)
+		if err != nil {
+			return internalErr(err, "failed to update the transfer items", tx)
+		}
+		if quantity <= 0 || !received {
+			ei := map[string]*models.AppErrorError{
+				line.Sku: {ID: "inventory.transfer.quantity_exceeds_in_transit", Params: map[string]any{"Quantity": line.QuantityShipped - line.QuantityReceived}},
+			}
+			return errBuilder(models.NewAppError(modelsCtx, path, "inventory.transfer.quantity_exceeds_in_transit", nil, "", int(codes.InvalidArgument), &models.AppErrorErrorsArgs{ErrorsInternal: ei}), tx)
+		}
+		line.QuantityReceived += quantity
+
+		destination, err := c.store.InventoryItemGetBySku(modelsCtx, tx, transfer.SellerId, line.Sku, transfer.ToLocationId)
+		if err != nil {
+			return internalErr(err, "failed to get the destination inventory item", tx)
+		}
+		if appErr := c.stockFrozen(modelsCtx, path, tx, destination.Id, line.Sku); appErr != nil {
+			return errBuilder(appErr, tx)
+		}
+
+		// the units go into the lot of the same number at the destination, which keeps the expiry date of the source lot
+		var lotID *string
+		if line.GetLotNumber() != "" {
+			source, appErr := c.lotByNumber(modelsCtx, path, tx, line.SourceItemId, line.GetLotNumber(), line.Sku)
+			if appErr != nil {
+				return errBuilder(appErr, tx)
+			}
+			lot, errID, err := c.lotReceive(modelsCtx, tx, destination.Id, line.GetLotNumber(), source.ExpiresAt, quantity)
+			if err != nil {
+				return internalErr(err, "failed to receive the lot", tx)
+			}
+			if errID != "" {
+				ei := map[string]*models.AppErrorError{line.Sku: {ID: errID}}
+				return errBuilder(models.NewAppError(modelsCtx, path, errID, nil, "", int(codes.FailedPrecondition), &models.AppErrorErrorsArgs{ErrorsInternal: ei}), tx)
+			}
+			lotID = &lot.Id
+		} else if _, appErr := c.lotByNumber(modelsCtx, path, tx, destination.Id, "", line.Sku); appErr != nil {
+			return errBuilder(appErr, tx)
+		}
+
+		// the received serials are the given ones, or the serials of the line that are still in transit
+		serialNumbers := receiptSerials[line.Sku]
+		if len(serialNumbers) == 0 && len(line.SerialNumbers) > 0 {
+			serialNumbers, err = c.transferSerialsInTransit(modelsCtx, tx, line, quantity)
+			if err != nil {
+				return internalErr(err, "failed to get the serials in transit", tx)
+			}
+		}
+		if len(serialNumbers) > 0 {
+			if int32(len(serialNumbers)) != quantity {
+				ei := map[string]*models.AppErrorError{line.Sku: {ID: "inventory.serial.count_mismatch", Params: map[string]any{"Quantity": quantity}}}
+				return errBuilder(models.NewAppError(modelsCtx, path, "inventory.serial.count_mismatch", nil, "", int(codes.InvalidArgument), &models.AppErrorErrorsArgs{ErrorsInternal: ei}), tx)
+			}
+			appErr := c.transferSerialsMove(modelsCtx, path, tx, transfer, line.Sku, serialNumbers, destination.Id, destination,
+				pb.InventorySerialStatus_INVENTORY_SERIAL_STATUS_AVAILABLE, pb.InventoryMovementType_INVENTORY_MOVEMENT_TYPE_IN, transfer.Note, intModels.InventorySerialInTransit)
+			if appErr != nil {
+				return errBuilder(appErr, tx)
+			}
+		}
+		added, err := c.store.InventoryItemInTransitAdd(modelsCtx, tx, destination.Id, -quantity)
+		if err != nil {
+			return internalErr(err, "failed to update the destination inventory item", tx)
+		}
+		// TODO: this should not happen, and should be added to DLQ to be reviewed
+		if !added {
+			return internalErr(nil, "failed to receive a transfer, the quantity is bigger than the quantity_in_transit value", tx)
+		}
+
+		total := int(destination.QuantityTotal + quantity)
+		available := int(destination.QuantityAvailable + quantity)
+		if err := c.store.InventoryItemUpdate(modelsCtx, tx, destination.Id, total, destination.QuantityReserved, available); err != nil {
+			return internalErr(err, "failed to update the destination inventory item", tx)
+		}
+
+		// the received units carry the cost they left the source location at
+		unitCost := transferUnitCost(line)
+		if unitCost == nil {
+			source, err := c.store.InventoryItemGetBySku(modelsCtx, tx, transfer.SellerId, line.Sku, transfer.FromLocationId)
+			if err != nil {
+				return internalErr(err, "failed to get the source inventory item", tx)
+			}
+			average, err := c.stockCostAverage(modelsCtx, tx, source.Id)
+			if err != nil {
+				return internalErr(err, "failed to get the unit cost of the source inventory item", tx)
+			}
+			unitCost = &average
+		}
+		if _, err := c.transferMovement(modelsCtx, tx, transfer, destination.Id, lotID, pb.InventoryMovementType_INVENTORY_MOVEMENT_TYPE_IN, quantity, unitCost, transfer.Note); err != nil {
+			return internalErr(err, "failed to create inventory movement", tx)
+		}
+		if err := c.backorderAllocate(modelsCtx, tx, destination.Id, int32(available)); err != nil {
+			return internalErr(err, "failed to allocate backordered inventory", tx)
+		}
+	}
+
+	transfer.Status = intModels.GetInventoryTransferStatus(transferStatusFromLines(lines))
+	if err := c.store.InventoryTransferUpdateStatus(modelsCtx, tx, transfer.Id, transfer.Status); err != nil {
+		return internalErr(err, "failed to update the transfer status", tx)
+	}
+
+	if err := tx.Commit(modelsCtx.Context); err != nil {
+		return internalErr(err, "failed to commit transaction", tx)
+	}
+
+	ar.Success()
+	return sucBuilder(transferData(transfer, lines))
+}
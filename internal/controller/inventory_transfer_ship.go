@@ -0,0 +1,170 @@
+package controller
+
+import (
+	"context"
+	"time"
+
+	intModels "github.com/ahmad-khatib0-org/megacommerce-inventory/pkg/models"
+	pb "github.com/ahmad-khatib0-org/megacommerce-proto/gen/go/inventory/v1"
+	"github.com/ahmad-khatib0-org/megacommerce-shared-go/pkg/models"
+	"github.com/ahmad-khatib0-org/megacommerce-shared-go/pkg/utils"
+	"github.com/jackc/pgx/v5"
+	"google.golang.org/grpc/codes"
+)
+
+// InventoryTransferShip ships a drafted transfer: the units leave the source location with an OUT
+// movement and are held in transit on the destination inventory item until they are received
+func (c *Controller) InventoryTransferShip(ctx context.Context, req *pb.InventoryTransferShipRequest) (*pb.InventoryTransferShipResponse, error) {
+	path := "inventory.controller.InventoryTransferShip"
+	modelsCtx, ctxErr := models.ContextGet(ctx)
+	errBuilder := func(e *models.AppError, tx pgx.Tx) (*pb.InventoryTransferShipResponse, error) {
+		if tx != nil {
+			if rbErr := tx.Rollback(modelsCtx.Context); rbErr != nil {
+				c.log.Errorf("%s: an error rolling back a transaction, err: %s", path, rbErr.Error())
+			}
+		}
+		return &pb.InventoryTransferShipResponse{Response: &pb.InventoryTransferShipResponse_Error{Error: models.AppErrorToProto(e)}}, nil
+	}
+
+	if ctxErr != nil {
+		return errBuilder(ctxErr, nil)
+	}
+	internalErr := func(err error, details string, tx pgx.Tx) (*pb.InventoryTransferShipResponse, error) {
+		return errBuilder(models.NewAppError(modelsCtx, path, models.ErrMsgInternal, nil, details, int(codes.Internal), &models.AppErrorErrorsArgs{Err: err}), tx)
+	}
+	sucBuilder := func(data *pb.InventoryTransferGetResponseData) (*pb.InventoryTransferShipResponse, error) {
+		return &pb.InventoryTransferShipResponse{Response: &pb.InventoryTransferShipResponse_Data{Data: data}}, nil
+	}
+
+	rctx, cancel := context.WithTimeout(context.Background(), time.Second*12)
+	defer cancel()
+	modelsCtx.Context = rctx
+
+	ar := models.AuditRecordNew(modelsCtx, intModels.EventNameInventoryTransferShip, models.EventStatusFail)
+	defer func() {
+		ar.AuditEventDataPriorState(map[string]any{"transfer_number": req.GetTransferNumber()})
+		c.ProcessAudit(ctx, ar)
+	}()
+
+	tx, err := c.store.GetTx(modelsCtx.Context, pgx.TxOptions{})
+	if err != nil {
+		return internalErr(err, "failed to begin transaction", nil)
+	}
+
+	transfer, lines, appErr := c.transferGet(ctx, modelsCtx, path, tx, req.GetTransferNumber())
+	if appErr != nil {
+		return errBuilder(appErr, tx)
+	}
+	if intModels.GetInventoryTransferStatusFromString(transfer.Status) != pb.InventoryTransferStatus_INVENTORY_TRANSFER_STATUS_DRAFT {
+		return errBuilder(transferStatusInvalid(modelsCtx, path, transfer), tx)
+	}
+
+	for _, line := range lines {
+		source, err := c.store.InventoryItemGetBySku(modelsCtx, tx, transfer.SellerId, line.Sku, transfer.FromLocationId)
+		if err != nil {
+			return internalErr(err, "failed to get the source inventory item", tx)
+		}
+		lot, appErr := c.lotByNumber(modelsCtx, path, tx, source.Id, line.GetLotNumber(), line.Sku)
+		if appErr != nil {
+			return errBuilder(appErr, tx)
+		}
+		if appErr := c.stockFrozen(modelsCtx, path, tx, source.Id, line.Sku); appErr != nil {
+			return errBuilder(appErr, tx)
+		}
+		// serials may have been registered or sold since the transfer was drafted
+		if appErr := c.transferSerialsCheck(modelsCtx, path, tx, source.Id, line.Sku, line.SerialNumbers, line.Quantity); appErr != nil {
+			return errBuilder(appErr, tx)
+		}
+		if source.QuantityAvailable < line.Quantity {
+			ei := map[string]*models.AppErrorError{
+				line.Sku: {ID: "inventory.transfer.insufficient_available", Params: map[string]any{"Quantity": source.QuantityAvailable}},
+			}
+			return errBuilder(models.NewAppError(modelsCtx, path, "inventory.transfer.insufficient_available", nil, "", int(codes.Aborted), &models.AppErrorErrorsArgs{ErrorsInternal: ei}), tx)
+		}
+
+		// the units leave the lot they travel with, its reserved units stay for the orders that hold them
+		var lotID *string
+		if lot != nil {
+			issued, err := c.store.InventoryLotIssue(modelsCtx, tx, lot.Id, line.Quantity)
+			if err != nil {
+				return internalErr(err, "failed to update the lot", tx)
+			}
+			if !issued {
+				ei := map[string]*models.AppErrorError{
+					line.Sku: {ID: "inventory.transfer.insufficient_available", Params: map[string]any{"Quantity": max(lot.Quantity-lot.QuantityReserved, 0)}},
+				}
+				return errBuilder(models.NewAppError(modelsCtx, path, "inventory.transfer.insufficient_available", nil, "", int(codes.Aborted), &models.AppErrorErrorsArgs{ErrorsInternal: ei}), tx)
+			}
+			lotID = &lot.Id
+		}
+
+		total := int(source.QuantityTotal - line.Quantity)
+		available := int(source.QuantityAvailable - line.Quantity)
+		if err := c.store.InventoryItemUpdate(modelsCtx, tx, source.Id, total, source.QuantityReserved, available); err != nil {
+			return internalErr(err, "failed to update the source inventory item", tx)
+		}
+		// the units travel at the cost they leave the source at, the destination and a cancel receive them at it
+		cost, err := c.transferMovement(modelsCtx, tx, transfer, source.Id, lotID, pb.InventoryMovementType_INVENTORY_MOVEMENT_TYPE_OUT, line.Quantity, nil, transfer.Note)
+		if err != nil {
+			return internalErr(err, "failed to create inventory movement", tx)
+		}
+
+		// the first transfer of a sku to a location creates its inventory item there
+		destination, err := c.store.InventoryItemGetBySku(modelsCtx, tx, transfer.SellerId, line.Sku, transfer.ToLocationId)
+		if err != nil && err.ErrType != models.DBErrorTypeNoRows {
+			return internalErr(err, "failed to get the destination inventory item", tx)
+		}
+		if err == nil {
+			// units without a lot can't be received by an item that has lots
+			if lot == nil {
+				if _, appErr := c.lotByNumber(modelsCtx, path, tx, destination.Id, "", line.Sku); appErr != nil {
+					return errBuilder(appErr, tx)
+				}
+			}
+		} else {
+			destination = &pb.InventoryItem{
+				Id:         utils.NewID(),
+				SellerId:   source.SellerId,
+				ProductId:  source.ProductId,
+				VariantId:  source.VariantId,
+				Sku:        source.Sku,
+				LocationId: &transfer.ToLocationId,
+				CreatedAt:  utils.TimeGetMillis(),
+			}
+			if err := c.store.InventoryItemCreate(modelsCtx, tx, destination); err != nil {
+				return internalErr(err, "failed to create the destination inventory item", tx)
+			}
+		}
+
+		// the serials travel with the units, they belong to the destination item from now on
+		if len(line.SerialNumbers) > 0 {
+			appErr := c.transferSerialsMove(modelsCtx, path, tx, transfer, line.Sku, line.SerialNumbers, source.Id, destination,
+				pb.InventorySerialStatus_INVENTORY_SERIAL_STATUS_IN_TRANSIT, pb.InventoryMovementType_INVENTORY_MOVEMENT_TYPE_OUT, transfer.Note, intModels.InventorySerialAssignable)
+			if appErr != nil {
+				return errBuilder(appErr, tx)
+			}
+		}
+
+		if _, err := c.store.InventoryItemInTransitAdd(modelsCtx, tx, destination.Id, line.Quantity); err != nil {
+			return internalErr(err, "failed to update the destination inventory item", tx)
+		}
+		if err := c.store.InventoryTransferItemShip(modelsCtx, tx, line.Id, destination.Id, line.Quantity, cost); err != nil {
+			return internalErr(err, "failed to update the transfer items", tx)
+		}
+		line.DestinationItemId = &destination.Id
+		line.QuantityShipped = line.Quantity
+		line.CostShipped = &cost
+	}
+
+	transfer.Status = intModels.GetInventoryTransferStatus(pb.InventoryTransferStatus_INVENTORY_TRANSFER_STATUS_SHIPPED)
+	if err := c.store.InventoryTransferUpdateStatus(modelsCtx, tx, transfer.Id, transfer.Status); err != nil {
+		return internalErr(err, "failed to update the transfer status", tx)
+	}
+
+	if err := tx.Commit(modelsCtx.Context); err != nil {
+		return internalErr(err, "failed to commit transaction", tx)
+	}
+
+	ar.Success()
+	return sucBuilder(transferData(transfer, lines))
+}
@@ -0,0 +1,57 @@
+package controller
+
+import (
+	intModels "github.com/ahmad-khatib0-org/megacommerce-inventory/pkg/models"
+	pb "github.com/ahmad-khatib0-org/megacommerce-proto/gen/go/inventory/v1"
+	"github.com/ahmad-khatib0-org/megacommerce-shared-go/pkg/models"
+	"github.com/jackc/pgx/v5"
+	"google.golang.org/grpc/codes"
+)
+
+// unitQuantity converts a quantity of a unit of measure of an inventory item to the base units that the stock is
+// stored in, along with the factor of the unit. An empty unit is the base unit, key identifies the item in the errors
+func (c *Controller) unitQuantity(mctx *models.Context, path string, tx pgx.Tx, inventoryItemID string, unit string, quantity uint32, key string) (base int32, factor int32, appErr *models.AppError) {
+	if unit == "" {
+		return int32(quantity), 1, nil
+	}
+
+	units, err := c.store.InventoryUnitsGet(mctx, tx, inventoryItemID)
+	if err != nil {
+		return 0, 0, models.NewAppError(mctx, path, models.ErrMsgInternal, nil, "failed to get the units of the inventory item", int(codes.Internal), &models.AppErrorErrorsArgs{Err: err})
+	}
+
+	factor, ok := intModels.InventoryUnitFactor(units, unit)
+	if !ok {
+		ei := map[string]*models.AppErrorError{key: {ID: "inventory.unit.unknown", Params: map[string]any{"Unit": unit}}}
+		return 0, 0, models.NewAppError(mctx, path, "inventory.unit.unknown", nil, "", int(codes.InvalidArgument), &models.AppErrorErrorsArgs{ErrorsInternal: ei})
+	}
+	base, ok = intModels.InventoryUnitToBase(quantity, factor)
+	if !ok {
+		ei := map[string]*models.AppErrorError{key: {ID: "inventory.unit.quantity_too_large"}}
+		return 0, 0, models.NewAppError(mctx, path, "inventory.unit.quantity_too_large", nil, "", int(codes.InvalidArgument), &models.AppErrorErrorsArgs{ErrorsInternal: ei})
+	}
+
+	return base, factor, nil
+}
+
+// unitsData converts the units of measure of an inventory item to the response format, with the whole
+// number of each unit that its on hand and available base units make
+func unitsData(inventory *pb.InventoryItem, units []*pb.InventoryUnit) *pb.InventoryUnitsData {
+	data := &pb.InventoryUnitsData{
+		ProductId: inventory.ProductId,
+		VariantId: inventory.VariantId,
+		Sku:       inventory.Sku,
+		BaseUnit:  intModels.InventoryUnitBase(units),
+		Units:     make([]*pb.InventoryUnitData, 0, len(units)),
+	}
+	for _, unit := range units {
+		data.Units = append(data.Units, &pb.InventoryUnitData{
+			Unit:              unit.Unit,
+			Factor:            uint32(unit.Factor),
+			QuantityTotal:     uint32(max(inventory.QuantityTotal, 0) / unit.Factor),
+			QuantityAvailable: uint32(max(inventory.QuantityAvailable, 0) / unit.Factor),
+		})
+	}
+
+	return data
+}
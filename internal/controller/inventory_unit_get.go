@@ -0,0 +1,56 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	pb "github.com/ahmad-khatib0-org/megacommerce-proto/gen/go/inventory/v1"
+	"github.com/ahmad-khatib0-org/megacommerce-shared-go/pkg/models"
+	"google.golang.org/grpc/codes"
+)
+
+// InventoryUnitGet gets the units of measure of an item, with the stock it has in each of them
+func (c *Controller) InventoryUnitGet(ctx context.Context, req *pb.InventoryUnitGetRequest) (*pb.InventoryUnitGetResponse, error) {
+	path := "inventory.controller.InventoryUnitGet"
+	errBuilder := func(e *models.AppError) (*pb.InventoryUnitGetResponse, error) {
+		return &pb.InventoryUnitGetResponse{Response: &pb.InventoryUnitGetResponse_Error{Error: models.AppErrorToProto(e)}}, nil
+	}
+
+	modelsCtx, ctxErr := models.ContextGet(ctx)
+	if ctxErr != nil {
+		return errBuilder(ctxErr)
+	}
+	internalErr := func(err error, details string) (*pb.InventoryUnitGetResponse, error) {
+		return errBuilder(models.NewAppError(modelsCtx, path, models.ErrMsgInternal, nil, details, int(codes.Internal), &models.AppErrorErrorsArgs{Err: err}))
+	}
+
+	rctx, cancel := context.WithTimeout(context.Background(), time.Second*12)
+	defer cancel()
+	modelsCtx.Context = rctx
+
+	sellerID, ok := sellerScope(ctx, req.GetSellerId())
+	if !ok {
+		return errBuilder(models.NewAppError(modelsCtx, path, "inventory.seller.forbidden", nil, "", int(codes.PermissionDenied), nil))
+	}
+
+	inventory, err := c.store.InventoryItemGetByProductVariant(modelsCtx, nil, sellerID, req.GetProductId(), req.GetVariantId(), req.GetLocationId())
+	if err != nil {
+		if appErr := itemAmbiguous(modelsCtx, path, err, fmt.Sprintf("%s.%s", req.GetProductId(), req.GetVariantId())); appErr != nil {
+			return errBuilder(appErr)
+		}
+		if err.ErrType == models.DBErrorTypeNoRows {
+			key := fmt.Sprintf("%s.%s", req.GetProductId(), req.GetVariantId())
+			ei := map[string]*models.AppErrorError{key: {ID: "orders.items.not_found_in_inventory"}}
+			return errBuilder(models.NewAppError(modelsCtx, path, "error.not_found", nil, "", int(codes.NotFound), &models.AppErrorErrorsArgs{Err: err, ErrorsInternal: ei}))
+		}
+		return internalErr(err, "failed to query inventory_items table")
+	}
+
+	units, err := c.store.InventoryUnitsGet(modelsCtx, nil, inventory.Id)
+	if err != nil {
+		return internalErr(err, "failed to get the units of the inventory item")
+	}
+
+	return &pb.InventoryUnitGetResponse{Response: &pb.InventoryUnitGetResponse_Data{Data: unitsData(inventory, units)}}, nil
+}
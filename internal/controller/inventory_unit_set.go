@@ -0,0 +1,129 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	intModels "github.com/ahmad-khatib0-org/megacommerce-inventory/pkg/models"
+	pb "github.com/ahmad-khatib0-org/megacommerce-proto/gen/go/inventory/v1"
+	"github.com/ahmad-khatib0-org/megacommerce-shared-go/pkg/models"
+	"github.com/ahmad-khatib0-org/megacommerce-shared-go/pkg/utils"
+	"github.com/jackc/pgx/v5"
+	"google.golang.org/grpc/codes"
+)
+
+// InventoryUnitSet defines the units of measure of an item, replacing the ones it had. The stock is stored in the
+// base unit, and every other unit is a whole number of base units, such as a case of 12, so updates and
+// reservations can be given in any of them. Setting no base unit and no units removes them all
+func (c *Controller) InventoryUnitSet(ctx context.Context, req *pb.InventoryUnitSetRequest) (*pb.InventoryUnitSetResponse, error) {
+	path := "inventory.controller.InventoryUnitSet"
+	modelsCtx, ctxErr := models.ContextGet(ctx)
+	errBuilder := func(e *models.AppError, tx pgx.Tx) (*pb.InventoryUnitSetResponse, error) {
+		if tx != nil {
+			if rbErr := tx.Rollback(modelsCtx.Context); rbErr != nil {
+				c.log.Errorf("%s: an error rolling back a transaction, err: %s", path, rbErr.Error())
+			}
+		}
+		return &pb.InventoryUnitSetResponse{Response: &pb.InventoryUnitSetResponse_Error{Error: models.AppErrorToProto(e)}}, nil
+	}
+
+	if ctxErr != nil {
+		return errBuilder(ctxErr, nil)
+	}
+	internalErr := func(err error, details string, tx pgx.Tx) (*pb.InventoryUnitSetResponse, error) {
+		return errBuilder(models.NewAppError(modelsCtx, path, models.ErrMsgInternal, nil, details, int(codes.Internal), &models.AppErrorErrorsArgs{Err: err}), tx)
+	}
+	invalidArg := func(id string) (*pb.InventoryUnitSetResponse, error) {
+		return errBuilder(models.NewAppError(modelsCtx, path, id, nil, "", int(codes.InvalidArgument), nil), nil)
+	}
+	sucBuilder := func(data *pb.InventoryUnitsData) (*pb.InventoryUnitSetResponse, error) {
+		return &pb.InventoryUnitSetResponse{Response: &pb.InventoryUnitSetResponse_Data{Data: data}}, nil
+	}
+
+	rctx, cancel := context.WithTimeout(context.Background(), time.Second*12)
+	defer cancel()
+	modelsCtx.Context = rctx
+
+	ar := models.AuditRecordNew(modelsCtx, intModels.EventNameInventoryUnitSet, models.EventStatusFail)
+	defer func() {
+		ar.AuditEventDataPriorState(intModels.InventoryUnitSetRequestAuditable(req))
+		c.ProcessAudit(ctx, ar)
+	}()
+
+	sellerID, ok := sellerScope(ctx, req.GetSellerId())
+	if !ok {
+		return errBuilder(models.NewAppError(modelsCtx, path, "inventory.seller.forbidden", nil, "", int(codes.PermissionDenied), nil), nil)
+	}
+	if req.GetBaseUnit() == "" && len(req.GetUnits()) > 0 {
+		return invalidArg("inventory.unit.base_unit_required")
+	}
+
+	seen := map[string]bool{strings.ToLower(req.GetBaseUnit()): true}
+	for _, unit := range req.GetUnits() {
+		if unit.GetUnit() == "" {
+			return invalidArg("inventory.unit.unit_required")
+		}
+		if seen[strings.ToLower(unit.GetUnit())] {
+			ei := map[string]*models.AppErrorError{unit.GetUnit(): {ID: "inventory.unit.duplicate_unit"}}
+			return errBuilder(models.NewAppError(modelsCtx, path, "inventory.unit.duplicate_unit", nil, "", int(codes.InvalidArgument), &models.AppErrorErrorsArgs{ErrorsInternal: ei}), nil)
+		}
+		seen[strings.ToLower(unit.GetUnit())] = true
+
+		// the stock is stored in whole base units, so a unit can't be smaller than the base unit
+		if unit.GetFactor() < 2 || unit.GetFactor() > math.MaxInt32 {
+			ei := map[string]*models.AppErrorError{unit.GetUnit(): {ID: "inventory.unit.invalid_factor"}}
+			return errBuilder(models.NewAppError(modelsCtx, path, "inventory.unit.invalid_factor", nil, "", int(codes.InvalidArgument), &models.AppErrorErrorsArgs{ErrorsInternal: ei}), nil)
+		}
+	}
+
+	tx, err := c.store.GetTx(modelsCtx.Context, pgx.TxOptions{})
+	if err != nil {
+		return internalErr(err, "failed to begin transaction", nil)
+	}
+
+	inventory, err := c.store.InventoryItemGetByProductVariant(modelsCtx, tx, sellerID, req.GetProductId(), req.GetVariantId(), req.GetLocationId())
+	if err != nil {
+		if appErr := itemAmbiguous(modelsCtx, path, err, fmt.Sprintf("%s.%s", req.GetProductId(), req.GetVariantId())); appErr != nil {
+			return errBuilder(appErr, tx)
+		}
+		if err.ErrType == models.DBErrorTypeNoRows {
+			key := fmt.Sprintf("%s.%s", req.GetProductId(), req.GetVariantId())
+			ei := map[string]*models.AppErrorError{key: {ID: "orders.items.not_found_in_inventory"}}
+			return errBuilder(models.NewAppError(modelsCtx, path, "error.not_found", nil, "", int(codes.NotFound), &models.AppErrorErrorsArgs{Err: err, ErrorsInternal: ei}), tx)
+		}
+		return internalErr(err, "failed to query inventory_items table", tx)
+	}
+
+	if err := c.store.InventoryUnitsDelete(modelsCtx, tx, inventory.Id); err != nil {
+		return internalErr(err, "failed to remove the units of the inventory item", tx)
+	}
+
+	units := make([]*pb.InventoryUnit, 0, len(req.GetUnits())+1)
+	if req.GetBaseUnit() != "" {
+		units = append(units, &pb.InventoryUnit{Unit: req.GetBaseUnit(), Factor: 1})
+	}
+	for _, unit := range req.GetUnits() {
+		units = append(units, &pb.InventoryUnit{Unit: unit.GetUnit(), Factor: int32(unit.GetFactor())})
+	}
+	for _, unit := range units {
+		unit.Id, unit.InventoryItemId, unit.CreatedAt = utils.NewID(), inventory.Id, utils.TimeGetMillis()
+		if err := c.store.InventoryUnitCreate(modelsCtx, tx, unit); err != nil {
+			return internalErr(err, "failed to create a unit of the inventory item", tx)
+		}
+	}
+
+	units, err = c.store.InventoryUnitsGet(modelsCtx, tx, inventory.Id)
+	if err != nil {
+		return internalErr(err, "failed to get the units of the inventory item", tx)
+	}
+
+	if err := tx.Commit(modelsCtx.Context); err != nil {
+		return internalErr(err, "failed to commit transaction", tx)
+	}
+
+	ar.Success()
+	return sucBuilder(unitsData(inventory, units))
+}
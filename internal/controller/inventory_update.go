@@ -2,25 +2,27 @@ package controller
 
 import (
 	"context"
+	"strconv"
 	"time"
 
 	intModels "github.com/ahmad-khatib0-org/megacommerce-inventory/pkg/models"
 	pb "github.com/ahmad-khatib0-org/megacommerce-proto/gen/go/inventory/v1"
 	pbSh "github.com/ahmad-khatib0-org/megacommerce-proto/gen/go/shared/v1"
 	"github.com/ahmad-khatib0-org/megacommerce-shared-go/pkg/models"
-	"github.com/ahmad-khatib0-org/megacommerce-shared-go/pkg/utils"
 	"github.com/jackc/pgx/v5"
 	"google.golang.org/grpc/codes"
 )
 
-// InventoryUpdate updates inventory levels
+// InventoryUpdate updates inventory levels, an update that changes the on hand units of an item by more than the
+// configured thresholds isn't applied, it's held as an adjustment request until another caller approves it
 func (c *Controller) InventoryUpdate(ctx context.Context, req *pb.InventoryUpdateRequest) (*pb.InventoryUpdateResponse, error) {
 	path := "inventory.controller.InventoryUpdate"
 	modelsCtx, ctxErr := models.ContextGet(ctx)
 	errBuilder := func(e *models.AppError, tx pgx.Tx) (*pb.InventoryUpdateResponse, error) {
 		if tx != nil {
-			rbErr := tx.Rollback(modelsCtx.Context)
-			c.log.Errorf("%s: an error rolling back a transaction, err: %s", path, rbErr.Error())
+			if rbErr := tx.Rollback(modelsCtx.Context); rbErr != nil {
+				c.log.Errorf("%s: an error rolling back a transaction, err: %s", path, rbErr.Error())
+			}
 		}
 		return &pb.InventoryUpdateResponse{Response: &pb.InventoryUpdateResponse_Error{Error: models.AppErrorToProto(e)}}, nil
 	}
@@ -42,7 +44,7 @@ func (c *Controller) InventoryUpdate(ctx context.Context, req *pb.InventoryUpdat
 	ar := models.AuditRecordNew(modelsCtx, intModels.EventNameInventoryUpdate, models.EventStatusFail)
 	defer func() {
 		ar.AuditEventDataPriorState(intModels.InventoryUpdateRequestAuditable(req))
-		c.ProcessAudit(ar)
+		c.ProcessAudit(ctx, ar)
 	}()
 
 	// if err := models.InventoryUpdateRequestIsValid(modelsCtx, req); err != nil {
@@ -55,9 +57,19 @@ func (c *Controller) InventoryUpdate(ctx context.Context, req *pb.InventoryUpdat
 	}
 
 	// Process each item
+	pending := make([]*pb.InventoryAdjustmentRequest, 0)
 	for _, item := range req.GetItems() {
-		inventory, err := c.store.InventoryItemGetByProductVariant(modelsCtx, tx, item.GetProductId(), item.GetVariantId())
+		// sellers can only update their own stock, another seller's item is rejected
+		sellerID, ok := sellerScope(ctx, item.GetSellerId())
+		if !ok {
+			return errBuilder(models.NewAppError(modelsCtx, path, "inventory.seller.forbidden", nil, "", int(codes.PermissionDenied), nil), tx)
+		}
+
+		inventory, err := c.store.InventoryItemGetByProductVariant(modelsCtx, tx, sellerID, item.GetProductId(), item.GetVariantId(), item.GetLocationId())
 		if err != nil {
+			if appErr := itemAmbiguous(modelsCtx, path, err, item.GetVariantId()); appErr != nil {
+				return errBuilder(appErr, tx)
+			}
 			if err.ErrType == models.DBErrorTypeNoRows {
 				errors := models.AppErrorErrorsArgs{
 					Err: err,
@@ -71,53 +83,32 @@ func (c *Controller) InventoryUpdate(ctx context.Context, req *pb.InventoryUpdat
 				return internalErr(err, "failed to update inventory", tx)
 			}
 		}
-		var newQuantityTotal int
-		var newQuantityAvailable int
-		var movementType string
-
-		// Update inventory based on operation
-		switch item.GetOperation() {
-		case pb.InventoryUpdateOperation_INVENTORY_UPDATE_OPERATION_SET:
-			newQuantityTotal = int(item.GetQuantity())
-			newQuantityAvailable = newQuantityTotal - int(inventory.QuantityReserved)
-			movementType = intModels.GetInventoryMovementType(pb.InventoryMovementType_INVENTORY_MOVEMENT_TYPE_ADJUSTMENT)
-		case pb.InventoryUpdateOperation_INVENTORY_UPDATE_OPERATION_ADD:
-			newQuantityTotal = int(inventory.QuantityTotal + int32(item.GetQuantity()))
-			newQuantityAvailable = int(inventory.QuantityAvailable + int32(item.GetQuantity()))
-			movementType = intModels.GetInventoryMovementType(pb.InventoryMovementType_INVENTORY_MOVEMENT_TYPE_IN)
-		case pb.InventoryUpdateOperation_INVENTORY_UPDATE_OPERATION_SUBTRACT:
-			newQuantityTotal = int(inventory.QuantityTotal - int32(item.GetQuantity()))
-			newQuantityAvailable = int(inventory.QuantityAvailable - int32(item.GetQuantity()))
-			movementType = intModels.GetInventoryMovementType(pb.InventoryMovementType_INVENTORY_MOVEMENT_TYPE_OUT)
-		default:
-			return errBuilder(models.NewAppError(modelsCtx, path, "inventory.update.invalid_operation", nil, "", int(codes.InvalidArgument), nil), tx)
+		// the stock is stored in base units, a quantity of another unit is converted to them
+		quantity, _, appErr := c.unitQuantity(modelsCtx, path, tx, inventory.Id, item.GetUnit(), item.GetQuantity(), item.GetVariantId())
+		if appErr != nil {
+			return errBuilder(appErr, tx)
 		}
-
-		// Check if we have enough available inventory for subtraction
-		if item.GetOperation() == pb.InventoryUpdateOperation_INVENTORY_UPDATE_OPERATION_SUBTRACT &&
-			int32(item.GetQuantity()) > inventory.QuantityAvailable {
-			id := "inventory.update.insufficient_available"
-			errors := models.AppErrorErrorsArgs{
-				ErrorsInternal: map[string]*models.AppErrorError{item.GetVariantId(): {ID: id}},
-			}
-			return errBuilder(models.NewAppError(modelsCtx, path, id, nil, "", int(codes.InvalidArgument), &errors), tx)
+		var metadata map[string]string
+		if item.GetUnit() != "" {
+			metadata = map[string]string{"unit": item.GetUnit(), "unit_quantity": strconv.Itoa(int(item.GetQuantity()))}
 		}
 
-		err = c.store.InventoryItemUpdate(modelsCtx, tx, inventory.Id, newQuantityTotal, inventory.QuantityReserved, newQuantityAvailable)
-		if err != nil {
-			return internalErr(err, "failed to update inventory", tx)
+		// changes above the configured thresholds are held until another caller approves them
+		total, _, _, appErr := c.stockUpdateCheck(modelsCtx, path, tx, inventory, item.GetOperation(), uint32(quantity), item.GetVariantId())
+		if appErr != nil {
+			return errBuilder(appErr, tx)
+		}
+		if intModels.InventoryAdjustmentNeedsApproval(inventory.QuantityTotal, int32(total), &c.cfg.Adjustments) {
+			adjustment := c.adjustmentRequestNew(ctx, inventory, item.GetOperation(), uint32(quantity), item.UnitCost, int32(total), req.Reason)
+			if err := c.store.InventoryAdjustmentRequestCreate(modelsCtx, tx, adjustment); err != nil {
+				return internalErr(err, "failed to create the adjustment request", tx)
+			}
+			pending = append(pending, adjustment)
+			continue
 		}
 
-		err = c.store.InventoryMovementCreate(modelsCtx, tx, &pb.InventoryMovement{
-			Id:              utils.NewID(),
-			InventoryItemId: inventory.Id,
-			MovementType:    movementType,
-			Quantity:        int32(item.GetQuantity()),
-			Reason:          req.Reason,
-			CreatedAt:       utils.TimeGetMillis(),
-		})
-		if err != nil {
-			return internalErr(err, "failed to create inventory movement", tx)
+		if appErr := c.stockUpdate(modelsCtx, path, tx, inventory, item.GetOperation(), uint32(quantity), item.GetVariantId(), item.UnitCost, req.Reason, metadata); appErr != nil {
+			return errBuilder(appErr, tx)
 		}
 	}
 
@@ -126,6 +117,14 @@ func (c *Controller) InventoryUpdate(ctx context.Context, req *pb.InventoryUpdat
 	}
 
 	ar.Success()
+	for _, adjustment := range pending {
+		c.adjustmentAudit(ctx, modelsCtx, intModels.EventNameInventoryAdjustmentRequest, adjustment)
+	}
+
+	if len(pending) > 0 {
+		msg := models.Tr(modelsCtx.AcceptLanguage, "inventory.update.pending_approval", map[string]any{"Count": len(pending)})
+		return sucBuilder(&pbSh.SuccessResponseData{Message: &msg})
+	}
 
 	msg := models.Tr(modelsCtx.AcceptLanguage, "inventory.update.success", nil)
 	return sucBuilder(&pbSh.SuccessResponseData{Message: &msg})
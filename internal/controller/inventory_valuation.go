@@ -0,0 +1,110 @@
+package controller
+
+import (
+	"context"
+	"time"
+
+	intModels "github.com/ahmad-khatib0-org/megacommerce-inventory/pkg/models"
+	pb "github.com/ahmad-khatib0-org/megacommerce-proto/gen/go/inventory/v1"
+	"github.com/ahmad-khatib0-org/megacommerce-shared-go/pkg/models"
+	"github.com/ahmad-khatib0-org/megacommerce-shared-go/pkg/utils"
+	"google.golang.org/grpc/codes"
+)
+
+// InventoryValuation reports the value of the stock as of a date, by item, by location and in total, under the
+// requested valuation method or the configured one, with the cost of the units sold from a date to it. Values are
+// in the minor unit of the configured currency
+func (c *Controller) InventoryValuation(ctx context.Context, req *pb.InventoryValuationRequest) (*pb.InventoryValuationResponse, error) {
+	path := "inventory.controller.InventoryValuation"
+	errBuilder := func(e *models.AppError) (*pb.InventoryValuationResponse, error) {
+		return &pb.InventoryValuationResponse{Response: &pb.InventoryValuationResponse_Error{Error: models.AppErrorToProto(e)}}, nil
+	}
+
+	modelsCtx, ctxErr := models.ContextGet(ctx)
+	if ctxErr != nil {
+		return errBuilder(ctxErr)
+	}
+	invalidArg := func(id string) (*pb.InventoryValuationResponse, error) {
+		return errBuilder(models.NewAppError(modelsCtx, path, id, nil, "", int(codes.InvalidArgument), nil))
+	}
+
+	rctx, cancel := context.WithTimeout(context.Background(), time.Second*12)
+	defer cancel()
+	modelsCtx.Context = rctx
+
+	sellerID, ok := sellerScope(ctx, req.GetSellerId())
+	if !ok {
+		return errBuilder(models.NewAppError(modelsCtx, path, "inventory.seller.forbidden", nil, "", int(codes.PermissionDenied), nil))
+	}
+
+	method := req.GetMethod()
+	if method == pb.InventoryValuationMethod_INVENTORY_VALUATION_METHOD_UNSPECIFIED {
+		method = intModels.GetInventoryValuationMethodFromString(c.cfg.Valuation.Method)
+	}
+	if method == pb.InventoryValuationMethod_INVENTORY_VALUATION_METHOD_UNSPECIFIED {
+		method = pb.InventoryValuationMethod_INVENTORY_VALUATION_METHOD_FIFO
+	}
+
+	at := req.GetAt()
+	if req.At == nil {
+		at = utils.TimeGetMillis()
+	}
+	if req.GetFrom() > at {
+		return invalidArg("inventory.valuation.invalid_window")
+	}
+
+	filter := &intModels.InventoryValuationFilter{
+		SellerID:   sellerID,
+		LocationID: req.GetLocationId(),
+		Sku:        req.GetSku(),
+		From:       req.GetFrom(),
+		At:         at,
+	}
+	rows, err := c.store.InventoryValuation(modelsCtx, filter)
+	if err != nil {
+		return errBuilder(models.NewAppError(modelsCtx, path, models.ErrMsgInternal, nil, "failed to value the inventory", int(codes.Internal), &models.AppErrorErrorsArgs{Err: err}))
+	}
+
+	return &pb.InventoryValuationResponse{Response: &pb.InventoryValuationResponse_Data{Data: valuationData(rows, method, c.cfg.Valuation.Currency, filter)}}, nil
+}
+
+// valuationData converts the valuation rows of a report to the response format, the rows are ordered by location,
+// so the total of a location is complete once the rows move on to the next one
+func valuationData(rows []*intModels.InventoryValuationRow, method pb.InventoryValuationMethod, currency string, filter *intModels.InventoryValuationFilter) *pb.InventoryValuationData {
+	data := &pb.InventoryValuationData{
+		Method:   method,
+		Currency: currency,
+		From:     filter.From,
+		At:       filter.At,
+		Items:    make([]*pb.InventoryValuationItem, 0, len(rows)),
+	}
+
+	var location *pb.InventoryValuationLocation
+	for _, row := range rows {
+		quantity, value, costOfGoodsSold := intModels.InventoryValuationOf(row, method)
+		data.Items = append(data.Items, &pb.InventoryValuationItem{
+			ProductId:       row.ProductID,
+			VariantId:       row.VariantID,
+			Sku:             row.Sku,
+			LocationId:      row.LocationID,
+			Quantity:        quantity,
+			Value:           value,
+			UnitCost:        intModels.InventoryCostAverage(quantity, value),
+			CostOfGoodsSold: costOfGoodsSold,
+		})
+
+		if location == nil || location.LocationId != row.LocationID {
+			location = &pb.InventoryValuationLocation{LocationId: row.LocationID}
+			data.Locations = append(data.Locations, location)
+		}
+		location.Quantity += quantity
+		location.Value += value
+		location.CostOfGoodsSold += costOfGoodsSold
+
+		data.Quantity += quantity
+		data.Value += value
+		data.CostOfGoodsSold += costOfGoodsSold
+	}
+
+	return data
+}
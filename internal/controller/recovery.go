@@ -0,0 +1,14 @@
+package controller
+
+import (
+	"runtime/debug"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// recoverPanic turns a panic in a handler into an internal error, so a single call can't take the server down
+func (c *Controller) recoverPanic(p any) error {
+	c.log.Errorf("inventory.controller.recoverPanic: a handler panicked: %v\n%s", p, debug.Stack())
+	return status.Error(codes.Internal, "internal server error")
+}
@@ -0,0 +1,25 @@
+package controller
+
+import (
+	"context"
+	"testing"
+
+	"github.com/grpc-ecosystem/go-grpc-middleware/v2/interceptors/recovery"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestRecoverPanic(t *testing.T) {
+	c := newTestController(t, nil)
+	interceptor := recovery.UnaryServerInterceptor(recovery.WithRecoveryHandler(c.recoverPanic))
+
+	handler := func(ctx context.Context, req any) (any, error) {
+		var tx interface{ Rollback() error }
+		return nil, tx.Rollback()
+	}
+	_, err := interceptor(context.Background(), nil, &grpc.UnaryServerInfo{FullMethod: "/inventory.v1.InventoryService/InventoryUpdate"}, handler)
+	if got := status.Code(err); got != codes.Internal {
+		t.Fatalf("the panic was returned as %v, want %v", got, codes.Internal)
+	}
+}
@@ -0,0 +1,38 @@
+package controller
+
+import (
+	"context"
+	"time"
+)
+
+// runDaily runs job every day at hour (UTC) until ctx is done, and once when it starts if that hour has already
+// passed today, so a day that the service wasn't running at that hour isn't skipped. The jobs must be safe to
+// run twice a day
+func runDaily(ctx context.Context, hour uint32, job func(ctx context.Context)) {
+	now := time.Now().UTC()
+	if !now.Before(dailyAt(now, hour)) {
+		job(ctx)
+	}
+
+	for {
+		now = time.Now().UTC()
+		next := dailyAt(now, hour)
+		if !next.After(now) {
+			next = next.AddDate(0, 0, 1)
+		}
+
+		timer := time.NewTimer(next.Sub(now))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+			job(ctx)
+		}
+	}
+}
+
+// dailyAt is the time of the day of now at hour (UTC)
+func dailyAt(now time.Time, hour uint32) time.Time {
+	return time.Date(now.Year(), now.Month(), now.Day(), int(hour%24), 0, 0, 0, time.UTC)
+}
@@ -0,0 +1,40 @@
+package controller
+
+import (
+	"context"
+	"errors"
+
+	"github.com/ahmad-khatib0-org/megacommerce-inventory/internal/auth"
+	intModels "github.com/ahmad-khatib0-org/megacommerce-inventory/pkg/models"
+	"github.com/ahmad-khatib0-org/megacommerce-shared-go/pkg/models"
+	"google.golang.org/grpc/codes"
+)
+
+// sellerScope returns the seller that the queries of this request must be limited to,
+// sellers are always limited to themselves, and asking for another seller returns ok = false,
+// while platform callers act on the requested seller, or on every seller if it's empty.
+// A seller without a seller id is never ok, an empty seller would match every seller
+func sellerScope(ctx context.Context, requested string) (sellerID string, ok bool) {
+	caller, _ := auth.CallerFromContext(ctx)
+	own, scoped := caller.SellerScope()
+	if !scoped {
+		return requested, true
+	}
+
+	if own == "" || (requested != "" && requested != own) {
+		return "", false
+	}
+	return own, true
+}
+
+// itemAmbiguous returns an invalid argument error if an inventory item lookup matched more than one item,
+// which happens when the seller or the location of a product that many items stock isn't given,
+// it returns nil for any other error
+func itemAmbiguous(mctx *models.Context, path string, err *models.DBError, key string) *models.AppError {
+	if err == nil || !errors.Is(err.Err, intModels.ErrInventoryItemAmbiguous) {
+		return nil
+	}
+
+	ei := map[string]*models.AppErrorError{key: {ID: "inventory.item.ambiguous"}}
+	return models.NewAppError(mctx, path, "inventory.item.ambiguous", nil, err.Msg, int(codes.InvalidArgument), &models.AppErrorErrorsArgs{Err: err, ErrorsInternal: ei})
+}
@@ -0,0 +1,335 @@
+package controller
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"slices"
+	"testing"
+
+	"github.com/ahmad-khatib0-org/megacommerce-inventory/internal/auth"
+	"github.com/ahmad-khatib0-org/megacommerce-inventory/internal/store"
+	pb "github.com/ahmad-khatib0-org/megacommerce-proto/gen/go/inventory/v1"
+	"github.com/ahmad-khatib0-org/megacommerce-shared-go/pkg/models"
+	"github.com/jackc/pgx/v5"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+func TestSellerScope(t *testing.T) {
+	admin := &auth.Caller{Subject: "admin", Roles: []string{auth.RoleAdmin}}
+	seller := &auth.Caller{Subject: "seller", Roles: []string{auth.RoleSeller}, SellerID: "s1"}
+
+	tests := []struct {
+		name      string
+		caller    *auth.Caller
+		requested string
+		want      string
+		wantOK    bool
+	}{
+		{name: "no caller acts on the requested seller", requested: "s2", want: "s2", wantOK: true},
+		{name: "platform caller acts on the requested seller", caller: admin, requested: "s2", want: "s2", wantOK: true},
+		{name: "platform caller without a seller acts on every seller", caller: admin, want: "", wantOK: true},
+		{name: "seller without a requested seller is limited to itself", caller: seller, want: "s1", wantOK: true},
+		{name: "seller asking for itself", caller: seller, requested: "s1", want: "s1", wantOK: true},
+		{name: "seller asking for another seller", caller: seller, requested: "s2", want: "", wantOK: false},
+		{name: "seller without a seller id", caller: &auth.Caller{Subject: "cert", Roles: []string{auth.RoleSeller}}, want: "", wantOK: false},
+		{name: "seller without a seller id asking for a seller", caller: &auth.Caller{Subject: "cert", Roles: []string{auth.RoleSeller}}, requested: "s2", want: "", wantOK: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			if tt.caller != nil {
+				ctx = auth.ContextWithCaller(ctx, tt.caller)
+			}
+
+			got, ok := sellerScope(ctx, tt.requested)
+			if got != tt.want || ok != tt.wantOK {
+				t.Fatalf("sellerScope(%q) = (%q, %v), want (%q, %v)", tt.requested, got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
+// transferStore serves the transfers that transferGet reads, any other store call panics
+type transferStore struct {
+	store.InventoryDBStore
+	transfers map[string]*pb.InventoryTransfer
+}
+
+func (s *transferStore) InventoryTransferGetByNumber(ctx *models.Context, tx pgx.Tx, transferNumber string) (*pb.InventoryTransfer, *models.DBError) {
+	transfer, ok := s.transfers[transferNumber]
+	if !ok {
+		return nil, &models.DBError{ErrType: models.DBErrorTypeNoRows, Err: pgx.ErrNoRows}
+	}
+	return transfer, nil
+}
+
+func (s *transferStore) InventoryTransferItemsGetByTransferID(ctx *models.Context, tx pgx.Tx, transferID string) ([]*pb.InventoryTransferItem, *models.DBError) {
+	return []*pb.InventoryTransferItem{{Id: "line-" + transferID, TransferId: transferID}}, nil
+}
+
+func TestSellerTokenCantGetAnotherSellersTransfer(t *testing.T) {
+	a, key := newTestAuthenticator(t)
+	c := newTestController(t, a)
+	c.store = &transferStore{transfers: map[string]*pb.InventoryTransfer{
+		"TR-1": {Id: "t1", TransferNumber: "TR-1", SellerId: "s1"},
+	}}
+	method := pb.InventoryService_InventoryTransferGet_FullMethodName
+	mctx := &models.Context{Context: context.Background()}
+
+	tests := []struct {
+		name   string
+		claims serviceTokenClaims
+		found  bool
+	}{
+		{name: "owner", claims: serviceTokenClaims{Subject: "seller-1", Roles: []string{auth.RoleSeller}, SellerID: "s1"}, found: true},
+		{name: "another seller", claims: serviceTokenClaims{Subject: "seller-2", Roles: []string{auth.RoleSeller}, SellerID: "s2"}, found: false},
+		{name: "platform caller", claims: serviceTokenClaims{Subject: "admin", Roles: []string{auth.RoleAdmin}}, found: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, err := c.AuthFuncOverride(bearerContext(t, key, tt.claims), method)
+			if err != nil {
+				t.Fatalf("AuthFuncOverride failed: %v", err)
+			}
+
+			transfer, lines, appErr := c.transferGet(ctx, mctx, "inventory.controller.InventoryTransferGet", nil, "TR-1")
+			if !tt.found {
+				if appErr == nil || transfer != nil || lines != nil {
+					t.Fatal("expected the transfer of another seller to be reported as not found")
+				}
+				return
+			}
+			if appErr != nil {
+				t.Fatalf("transferGet failed: %v", appErr)
+			}
+			if transfer.GetId() != "t1" || len(lines) != 1 {
+				t.Fatalf("got transfer %q with %d lines, want t1 with 1 line", transfer.GetId(), len(lines))
+			}
+		})
+	}
+}
+
+// tenantTx is a transaction that nothing is written to, the handlers under test are denied before they write
+type tenantTx struct {
+	pgx.Tx
+}
+
+func (tenantTx) Commit(ctx context.Context) error   { return nil }
+func (tenantTx) Rollback(ctx context.Context) error { return nil }
+
+// tenantStore serves the items and reservations of two sellers, filtered by seller like the db store,
+// and remembers the sellers that it was asked for. Any other store call panics
+type tenantStore struct {
+	store.InventoryDBStore
+	items        []*pb.InventoryItem
+	reservations map[string]*pb.InventoryReservation
+	lines        []*pb.InventoryReservationItem
+	sellers      []string
+}
+
+func (s *tenantStore) GetTx(ctx context.Context, opts pgx.TxOptions) (pgx.Tx, *models.DBError) {
+	return tenantTx{}, nil
+}
+
+func (s *tenantStore) InventoryItemGetByProductVariant(ctx *models.Context, tx pgx.Tx, sellerID string, productID string, variantID string, locationID string) (*pb.InventoryItem, *models.DBError) {
+	s.sellers = append(s.sellers, sellerID)
+	for _, item := range s.items {
+		if item.ProductId == productID && item.VariantId == variantID && (sellerID == "" || item.SellerId == sellerID) {
+			return item, nil
+		}
+	}
+	return nil, &models.DBError{ErrType: models.DBErrorTypeNoRows, Err: pgx.ErrNoRows}
+}
+
+func (s *tenantStore) InventoryKitGetByProductVariant(ctx *models.Context, tx pgx.Tx, sellerID string, productID string, variantID string) (*pb.InventoryKit, *models.DBError) {
+	return nil, &models.DBError{ErrType: models.DBErrorTypeNoRows, Err: pgx.ErrNoRows}
+}
+
+func (s *tenantStore) InventoryReservationCreate(ctx *models.Context, tx pgx.Tx, params *pb.InventoryReservation) *models.DBError {
+	return nil
+}
+
+func (s *tenantStore) InventoryReservationGetByToken(ctx *models.Context, tx pgx.Tx, token string) (*pb.InventoryReservation, *models.DBError) {
+	reservation, ok := s.reservations[token]
+	if !ok {
+		return nil, &models.DBError{ErrType: models.DBErrorTypeNoRows, Err: pgx.ErrNoRows}
+	}
+	return reservation, nil
+}
+
+func (s *tenantStore) InventoryReservationItemsGetByReservationID(ctx *models.Context, tx pgx.Tx, sellerID string, reservationID string) ([]*pb.InventoryReservationItem, *models.DBError) {
+	s.sellers = append(s.sellers, sellerID)
+	lines := []*pb.InventoryReservationItem{}
+	for _, line := range s.lines {
+		item, _ := s.item(line.InventoryItemId)
+		if line.ReservationId == reservationID && (sellerID == "" || item.GetSellerId() == sellerID) {
+			lines = append(lines, line)
+		}
+	}
+	return lines, nil
+}
+
+func (s *tenantStore) InventoryItemGetByIDs(ctx *models.Context, sellerID string, ids []string) ([]*pb.InventoryItem, *models.DBError) {
+	items := []*pb.InventoryItem{}
+	for _, id := range ids {
+		if item, ok := s.item(id); ok && (sellerID == "" || item.SellerId == sellerID) {
+			items = append(items, item)
+		}
+	}
+	return items, nil
+}
+
+func (s *tenantStore) item(id string) (*pb.InventoryItem, bool) {
+	for _, item := range s.items {
+		if item.Id == id {
+			return item, true
+		}
+	}
+	return nil, false
+}
+
+func newTenantStore() *tenantStore {
+	return &tenantStore{
+		items: []*pb.InventoryItem{
+			{Id: "i1", SellerId: "s1", ProductId: "p1", VariantId: "v1", Sku: "SKU-1", QuantityTotal: 10, QuantityAvailable: 10},
+			{Id: "i2", SellerId: "s2", ProductId: "p2", VariantId: "v2", Sku: "SKU-2", QuantityTotal: 10, QuantityAvailable: 10},
+		},
+		reservations: map[string]*pb.InventoryReservation{
+			"res_s2": {Id: "r2", ReservationToken: "res_s2", OrderId: "o2", Status: "RESERVED"},
+		},
+		lines: []*pb.InventoryReservationItem{
+			{Id: "l2", ReservationId: "r2", InventoryItemId: "i2", Quantity: 2},
+		},
+	}
+}
+
+// callAs authenticates claims for method and runs handler behind the metadata interceptor, like the server does
+func callAs[T any](t *testing.T, c *Controller, key *ecdsa.PrivateKey, claims serviceTokenClaims, method string, handler func(ctx context.Context) (T, error)) T {
+	t.Helper()
+	ctx := bearerContext(t, key, claims)
+	md, _ := metadata.FromIncomingContext(ctx)
+	ctx = metadata.NewIncomingContext(ctx, metadata.Join(md, metadata.Pairs("accept-language", "en")))
+
+	ctx, err := c.AuthFuncOverride(ctx, method)
+	if err != nil {
+		t.Fatalf("AuthFuncOverride failed: %v", err)
+	}
+
+	interceptor := models.UnaryMetadataInterceptor("en", []string{"en"})
+	res, err := interceptor(ctx, nil, &grpc.UnaryServerInfo{FullMethod: method}, func(ctx context.Context, req any) (any, error) {
+		return handler(ctx)
+	})
+	if err != nil {
+		t.Fatalf("%s failed: %v", method, err)
+	}
+	return res.(T)
+}
+
+func TestSellerTokenCantUpdateAnotherSellersStock(t *testing.T) {
+	a, key := newTestAuthenticator(t)
+	c := newTestController(t, a)
+	method := pb.InventoryService_InventoryUpdate_FullMethodName
+	seller := serviceTokenClaims{Subject: "seller-1", Roles: []string{auth.RoleSeller}, SellerID: "s1"}
+	add := pb.InventoryUpdateOperation_INVENTORY_UPDATE_OPERATION_ADD
+
+	tests := []struct {
+		name    string
+		item    *pb.InventoryUpdateItem
+		sellers []string
+	}{
+		{name: "asking for another seller", item: &pb.InventoryUpdateItem{SellerId: "s2", ProductId: "p2", VariantId: "v2", Operation: add, Quantity: 5}},
+		{name: "another seller's product", item: &pb.InventoryUpdateItem{ProductId: "p2", VariantId: "v2", Operation: add, Quantity: 5}, sellers: []string{"s1"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTenantStore()
+			c.store = s
+			req := &pb.InventoryUpdateRequest{Items: []*pb.InventoryUpdateItem{tt.item}}
+
+			res := callAs(t, c, key, seller, method, func(ctx context.Context) (*pb.InventoryUpdateResponse, error) {
+				return c.InventoryUpdate(ctx, req)
+			})
+			if res.GetError() == nil || res.GetData() != nil {
+				t.Fatal("expected the update of another seller's stock to be rejected")
+			}
+			if !slices.Equal(s.sellers, tt.sellers) {
+				t.Fatalf("the items were looked up for the sellers %v, want %v", s.sellers, tt.sellers)
+			}
+			if s.items[1].QuantityTotal != 10 {
+				t.Fatalf("the stock of the other seller changed to %d", s.items[1].QuantityTotal)
+			}
+		})
+	}
+}
+
+func TestSellerCantReserveAnotherSellersStock(t *testing.T) {
+	a, key := newTestAuthenticator(t)
+	c := newTestController(t, a)
+	c.store = newTenantStore()
+	method := pb.InventoryService_InventoryReserve_FullMethodName
+	seller := serviceTokenClaims{Subject: "seller-1", Roles: []string{auth.RoleSeller}, SellerID: "s1"}
+
+	// reservations are made by the order service, a seller token isn't allowed to call it at all
+	if _, err := c.AuthFuncOverride(bearerContext(t, key, seller), method); status.Code(err) != codes.PermissionDenied {
+		t.Fatalf("AuthFuncOverride returned %v, want %v", status.Code(err), codes.PermissionDenied)
+	}
+
+	// and a seller that reaches the handler is still limited to its own stock
+	ctx := auth.ContextWithCaller(context.Background(), &auth.Caller{Subject: "seller-1", Roles: []string{auth.RoleSeller}, SellerID: "s1"})
+	ctx = metadata.NewIncomingContext(ctx, metadata.Pairs("accept-language", "en"))
+	interceptor := models.UnaryMetadataInterceptor("en", []string{"en"})
+	req := &pb.InventoryReserveRequest{OrderId: "o1", Items: []*pb.InventoryReserveItem{{SellerId: "s2", ProductId: "p2", VariantId: "v2", Quantity: 1}}}
+	res, err := interceptor(ctx, req, &grpc.UnaryServerInfo{FullMethod: method}, func(ctx context.Context, req any) (any, error) {
+		return c.InventoryReserve(ctx, req.(*pb.InventoryReserveRequest))
+	})
+	if err != nil {
+		t.Fatalf("InventoryReserve failed: %v", err)
+	}
+	if reserve := res.(*pb.InventoryReserveResponse); reserve.GetError() == nil || reserve.GetData() != nil {
+		t.Fatal("expected the reservation of another seller's stock to be rejected")
+	}
+}
+
+func TestSellerTokenCantGetAnotherSellersReservation(t *testing.T) {
+	a, key := newTestAuthenticator(t)
+	c := newTestController(t, a)
+	method := pb.InventoryService_InventoryReservationGet_FullMethodName
+	req := &pb.InventoryReservationGetRequest{ReservationToken: "res_s2"}
+
+	tests := []struct {
+		name   string
+		claims serviceTokenClaims
+		found  bool
+	}{
+		{name: "owner of the lines", claims: serviceTokenClaims{Subject: "seller-2", Roles: []string{auth.RoleSeller}, SellerID: "s2"}, found: true},
+		{name: "another seller", claims: serviceTokenClaims{Subject: "seller-1", Roles: []string{auth.RoleSeller}, SellerID: "s1"}, found: false},
+		{name: "order service", claims: serviceTokenClaims{Subject: "orders", Roles: []string{auth.RoleOrderService}}, found: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c.store = newTenantStore()
+			res := callAs(t, c, key, tt.claims, method, func(ctx context.Context) (*pb.InventoryReservationGetResponse, error) {
+				return c.InventoryReservationGet(ctx, req)
+			})
+			if !tt.found {
+				if res.GetError() == nil || res.GetData() != nil {
+					t.Fatal("expected the reservation of another seller to be reported as not found")
+				}
+				return
+			}
+			if res.GetError() != nil {
+				t.Fatalf("InventoryReservationGet failed: %v", res.GetError().GetMessage())
+			}
+			if items := res.GetData().GetItems(); len(items) != 1 || items[0].GetSku() != "SKU-2" {
+				t.Fatalf("got the lines %v, want the line of SKU-2", items)
+			}
+		})
+	}
+}
@@ -1,24 +1,33 @@
 package server
 
 import (
-	"github.com/ahmad-khatib0-org/megacommerce-inventory/pkg/models"
+	intModels "github.com/ahmad-khatib0-org/megacommerce-inventory/pkg/models"
 	com "github.com/ahmad-khatib0-org/megacommerce-proto/gen/go/common/v1"
+	"github.com/ahmad-khatib0-org/megacommerce-shared-go/pkg/models"
 	"github.com/spf13/viper"
 )
 
-func (s *Server) initSharedConfig() {
+func (s *Server) initSharedConfig() *models.InternalError {
 	config, err := s.commonClient.ConfigGet()
 	if err != nil {
-		s.errors <- err
+		return err
 	}
 
-	s.configFn = func() *com.Config { return config }
+	// the config is read through configFn, so a config that's fetched again replaces it everywhere
+	if s.configFn == nil {
+		s.configFn = func() *com.Config {
+			s.configMux.RLock()
+			defer s.configMux.RUnlock()
+			return s.config
+		}
+	}
 	s.configMux.Lock()
 	s.config = config
 	s.configMux.Unlock()
+	return nil
 }
 
-func LoadServiceConfig(fileName string) (*models.Config, error) {
+func LoadServiceConfig(fileName string) (*intModels.Config, error) {
 	viper.AddConfigPath(".")
 	viper.SetConfigFile(fileName)
 	viper.SetConfigType("yaml")
@@ -27,7 +36,7 @@ func LoadServiceConfig(fileName string) (*models.Config, error) {
 		return nil, err
 	}
 
-	var c models.Config
+	var c intModels.Config
 	if err := viper.Unmarshal(&c); err != nil {
 		return nil, err
 	}
@@ -6,30 +6,77 @@ import (
 	com "github.com/ahmad-khatib0-org/megacommerce-proto/gen/go/common/v1"
 	"github.com/ahmad-khatib0-org/megacommerce-shared-go/pkg/models"
 	"github.com/jackc/pgx/v5/pgxpool"
+	"google.golang.org/grpc/connectivity"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
 )
 
-func (s *Server) initTrans() map[string]*com.TranslationElements {
+// HealthServiceCommon is the health service name that reports the common service connection
+const HealthServiceCommon = "megacommerce.common"
+
+func (s *Server) initTrans() (map[string]*com.TranslationElements, *models.InternalError) {
 	trans, err := s.commonClient.TranslationsGet()
 	if err != nil {
-		s.errors <- err
+		return nil, err
 	}
 
 	lang := s.config.Localization.GetDefaultClientLocale()
 	if err := models.TranslationsInit(trans, lang); err != nil {
 		path := "inventory.server.initTrans"
-		err := &models.InternalError{Err: err, Msg: "failed to init translations", Path: path}
-		s.errors <- err
+		return nil, &models.InternalError{Err: err, Msg: "failed to init translations", Path: path}
 	}
 
-	return trans
+	return trans, nil
 }
 
-func (s *Server) initDB() {
+func (s *Server) initDB() *models.InternalError {
 	pool, err := pgxpool.New(context.Background(), s.config.Sql.GetDataSource())
 	if err != nil {
 		path := "inventory.server.initDB"
-		err := &models.InternalError{Err: err, Msg: "failed to init db pool", Path: path}
-		s.errors <- err
+		return &models.InternalError{Err: err, Msg: "failed to init db pool", Path: path}
 	}
 	s.dbConn = pool
+	return nil
+}
+
+// initHealth reports the inventory service as serving, and keeps the
+// HealthServiceCommon status in sync with the common service connection
+func (s *Server) initHealth() {
+	s.health = health.NewServer()
+	s.health.SetServingStatus("", healthpb.HealthCheckResponse_SERVING)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	s.stopHealth = cancel
+
+	go s.commonClient.WatchState(ctx, func(state connectivity.State) {
+		status := healthpb.HealthCheckResponse_NOT_SERVING
+		if s.commonClient.Healthy() {
+			status = healthpb.HealthCheckResponse_SERVING
+		}
+		s.health.SetServingStatus(HealthServiceCommon, status)
+
+		if s.commonClient.Degraded() {
+			s.log.Infof("the common service connection is %s, running on cached config and translations", state)
+			if state == connectivity.Ready {
+				s.refreshShared()
+			}
+		}
+	})
+}
+
+// refreshShared fetches the config and the translations again once the common service is back, to replace the
+// cached copies that the service started with, the common client is no longer degraded once both are fetched
+func (s *Server) refreshShared() {
+	if err := s.initSharedConfig(); err != nil {
+		s.log.Errorf("inventory.server.refreshShared: failed to fetch the configurations again, err: %v", err)
+		return
+	}
+	if _, err := s.initTrans(); err != nil {
+		s.log.Errorf("inventory.server.refreshShared: failed to fetch the translations again, err: %v", err)
+		return
+	}
+
+	if !s.commonClient.Degraded() {
+		s.log.Infof("the common service is back, the config and the translations were fetched from it again")
+	}
 }
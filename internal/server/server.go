@@ -6,10 +6,12 @@ import (
 	"context"
 	"sync"
 
+	"github.com/ahmad-khatib0-org/megacommerce-inventory/internal/auth"
 	"github.com/ahmad-khatib0-org/megacommerce-inventory/internal/common"
 	"github.com/ahmad-khatib0-org/megacommerce-inventory/internal/controller"
 	"github.com/ahmad-khatib0-org/megacommerce-inventory/internal/store"
 	"github.com/ahmad-khatib0-org/megacommerce-inventory/internal/store/dbstore"
+	"github.com/ahmad-khatib0-org/megacommerce-inventory/internal/tlsutil"
 	intModels "github.com/ahmad-khatib0-org/megacommerce-inventory/pkg/models"
 	com "github.com/ahmad-khatib0-org/megacommerce-proto/gen/go/common/v1"
 	"github.com/ahmad-khatib0-org/megacommerce-shared-go/pkg/logger"
@@ -17,19 +19,27 @@ import (
 	grpcprom "github.com/grpc-ecosystem/go-grpc-middleware/providers/prometheus"
 	"github.com/jackc/pgx/v5/pgxpool"
 	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/health"
 )
 
 type Server struct {
-	commonClient   *common.CommonClient
-	configMux      sync.RWMutex
-	configFn       func() *com.Config
-	config         *com.Config
-	errors         chan *models.InternalError
-	tracerProvider *sdktrace.TracerProvider
-	metrics        *grpcprom.ServerMetrics
-	log            *logger.Logger
-	dbConn         *pgxpool.Pool
-	dbStore        store.InventoryDBStore
+	commonClient      *common.CommonClient
+	configMux         sync.RWMutex
+	configFn          func() *com.Config
+	config            *com.Config
+	errors            chan *models.InternalError
+	tracerProvider    *sdktrace.TracerProvider
+	metrics           *grpcprom.ServerMetrics
+	log               *logger.Logger
+	dbConn            *pgxpool.Pool
+	dbStore           store.InventoryDBStore
+	serverCerts       *tlsutil.Reloader
+	health            *health.Server
+	stopHealth        context.CancelFunc
+	stopSnapshots     context.CancelFunc
+	stopReplenishment context.CancelFunc
+	cfg               *intModels.Config
 }
 
 type ServerArgs struct {
@@ -37,39 +47,100 @@ type ServerArgs struct {
 	Cfg *intModels.Config
 }
 
-// TODO: cleanup on bootstrap errors
 func RunServer(s *ServerArgs) error {
-	com, err := common.NewCommonClient(&common.CommonArgs{Config: s.Cfg, Log: s.Log})
 	srv := &Server{
-		commonClient: com,
-		errors:       make(chan *models.InternalError, 1),
-		log:          s.Log,
+		errors: make(chan *models.InternalError, 1),
+		log:    s.Log,
+		cfg:    s.Cfg,
 	}
+
+	if err := srv.bootstrap(); err != nil {
+		s.Log.Infof("an error occurred while bootstrapping %v ", err)
+		srv.shutdown()
+		return err
+	}
+
+	err := <-srv.errors
 	if err != nil {
-		srv.errors <- err
+		s.Log.Infof("an error occurred %v ", err)
 	}
+	srv.shutdown()
+	return err
+}
 
-	srv.initSharedConfig()
-	srv.initTrans()
-	srv.initDB()
-	srv.dbStore = dbstore.NewInventoryStore(srv.dbConn)
+// bootstrap stops at the first failing step, leaving the cleanup of whatever was
+// already initialized to shutdown
+func (s *Server) bootstrap() *models.InternalError {
+	com, err := common.NewCommonClient(&common.CommonArgs{Config: s.cfg, Log: s.log})
+	if err != nil {
+		return err
+	}
+	s.commonClient = com
 
-	_, err = controller.NewController(&controller.ControllerArgs{
-		Config:         srv.configFn,
-		TracerProvider: srv.tracerProvider,
-		Metrics:        srv.metrics,
-		Log:            srv.log,
+	if err := s.initSharedConfig(); err != nil {
+		return err
+	}
+	if _, err := s.initTrans(); err != nil {
+		return err
+	}
+	if err := s.initDB(); err != nil {
+		return err
+	}
+	s.dbStore = dbstore.NewInventoryStore(s.dbConn)
+
+	authenticator, err := auth.NewAuthenticator(&s.cfg.Auth)
+	if err != nil {
+		return err
+	}
+
+	serverCreds, err := s.initServerCreds(&s.cfg.TLS.Server)
+	if err != nil {
+		return err
+	}
+
+	s.initHealth()
+
+	ctrl, err := controller.NewController(&controller.ControllerArgs{
+		Config:         s.configFn,
+		Cfg:            s.cfg,
+		TracerProvider: s.tracerProvider,
+		Metrics:        s.metrics,
+		Log:            s.log,
+		DBStore:        s.dbStore,
+		Authenticator:  authenticator,
+		ServerCreds:    serverCreds,
+		Health:         s.health,
 	})
 	if err != nil {
-		srv.errors <- err
+		return err
 	}
 
-	err = <-srv.errors
+	ctx, cancel := context.WithCancel(context.Background())
+	s.stopSnapshots = cancel
+	go ctrl.InventorySnapshotsSchedule(ctx)
+
+	if s.cfg.Replenishment.Enabled {
+		ctx, cancel := context.WithCancel(context.Background())
+		s.stopReplenishment = cancel
+		go ctrl.InventoryReplenishmentSchedule(ctx)
+	}
+
+	return nil
+}
+
+// initServerCreds returns nil credentials (plaintext) if tls is disabled
+func (s *Server) initServerCreds(cfg *intModels.TLSServer) (credentials.TransportCredentials, *models.InternalError) {
+	if !cfg.Enabled {
+		return nil, nil
+	}
+
+	certs, err := tlsutil.NewReloader(cfg.CertFile, cfg.KeyFile, cfg.ClientCAFile, s.log)
 	if err != nil {
-		s.Log.Infof("an error occurred %v ", err)
+		return nil, err
 	}
-	srv.shutdown()
-	return err
+	s.serverCerts = certs
+
+	return credentials.NewTLS(certs.ServerConfig(cfg.RequireClientCert)), nil
 }
 
 func (s *Server) shutdown() {
@@ -78,6 +149,26 @@ func (s *Server) shutdown() {
 		s.dbConn.Close()
 	}
 
+	if s.stopHealth != nil {
+		s.stopHealth()
+	}
+
+	if s.stopSnapshots != nil {
+		s.stopSnapshots()
+	}
+
+	if s.stopReplenishment != nil {
+		s.stopReplenishment()
+	}
+
+	if s.serverCerts != nil {
+		s.serverCerts.Close()
+	}
+
+	if s.commonClient != nil {
+		s.commonClient.Close()
+	}
+
 	if s.tracerProvider != nil {
 		if err := s.tracerProvider.Shutdown(ctx); err != nil {
 			s.log.Errorf("failed to shutdown tracer provider %v", err)
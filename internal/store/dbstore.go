@@ -4,6 +4,7 @@ package store
 import (
 	"context"
 
+	intModels "github.com/ahmad-khatib0-org/megacommerce-inventory/pkg/models"
 	pb "github.com/ahmad-khatib0-org/megacommerce-proto/gen/go/inventory/v1"
 	"github.com/ahmad-khatib0-org/megacommerce-shared-go/pkg/models"
 	"github.com/jackc/pgx/v5"
@@ -11,12 +12,20 @@ import (
 
 type InventoryDBStore interface {
 	GetTx(ctx context.Context, opts pgx.TxOptions) (pgx.Tx, *models.DBError)
-	// InventoryReservationGetByToken gets a reservation by its token,
+	// InventoryReservationGetByToken gets a reservation by its token and locks it until the end of tx,
 	// you can pass nil for the tx argument, and a normal db query will be used
 	InventoryReservationGetByToken(ctx *models.Context, tx pgx.Tx, token string) (*pb.InventoryReservation, *models.DBError)
 	InventoryReservationCreate(ctx *models.Context, tx pgx.Tx, params *pb.InventoryReservation) *models.DBError
 	InventoryReservationUpdateStatus(ctx *models.Context, tx pgx.Tx, id string, status string) *models.DBError
-	InventoryItemGetByProductVariant(ctx *models.Context, tx pgx.Tx, productID string, variantID string) (*pb.InventoryItem, *models.DBError)
+	// InventoryReservationsList lists the reservations matching filter, newest first, a reservation
+	// matches the sku and seller filters if any of its items does
+	InventoryReservationsList(ctx *models.Context, filter *intModels.InventoryReservationFilter) ([]*pb.InventoryReservation, *models.DBError)
+	// InventoryReservationExtend moves the expiry of a reservation to expiresAt, it returns
+	// extended = false if the reservation is no longer in the given status or has already expired
+	InventoryReservationExtend(ctx *models.Context, tx pgx.Tx, id string, status string, expiresAt int64) (bool, *models.DBError)
+	// InventoryItemGetByProductVariant locks and returns an inventory item, or just returns it if tx is nil,
+	// an empty sellerID or locationID matches every seller or location, and more than one match fails with intModels.ErrInventoryItemAmbiguous
+	InventoryItemGetByProductVariant(ctx *models.Context, tx pgx.Tx, sellerID string, productID string, variantID string, locationID string) (*pb.InventoryItem, *models.DBError)
 	InventoryItemCreate(ctx *models.Context, tx pgx.Tx, params *pb.InventoryItem) *models.DBError
 	// InventoryItemReserve reserves inventory for an item, and returns sufficient = false
 	//
@@ -28,15 +37,267 @@ type InventoryDBStore interface {
 	//
 	// quantity we are about to release is bigger than the current quantity_reserved value!
 	InventoryItemRelease(ctx *models.Context, tx pgx.Tx, id string, quantity int32) (bool, *models.DBError)
+	// InventoryItemFulfill ships reserved inventory of an item, the units leave both quantity_reserved
+	// and quantity_total, it returns fulfilled = false if quantity_reserved is less than quantity
+	InventoryItemFulfill(ctx *models.Context, tx pgx.Tx, id string, quantity int32) (bool, *models.DBError)
 	// InventoryReservationItemCreate creates a new reservation item
 	InventoryReservationItemCreate(ctx *models.Context, tx pgx.Tx, params *pb.InventoryReservationItem) *models.DBError
-	// InventoryReservationItemsGetByReservationID gets all items for a reservation
+	// InventoryReservationItemsGetByReservationID gets all items for a reservation, limited to the
+	// items of sellerID if it's not empty, you can pass nil for the tx argument, and a normal db query will be used
+	InventoryReservationItemsGetByReservationID(ctx *models.Context, tx pgx.Tx, sellerID string, reservationID string) ([]*pb.InventoryReservationItem, *models.DBError)
+	// InventoryReservationItemsGetByReservationIDs gets the items of several reservations at once,
+	// limited to the items of sellerID if it's not empty
+	InventoryReservationItemsGetByReservationIDs(ctx *models.Context, sellerID string, reservationIDs []string) ([]*pb.InventoryReservationItem, *models.DBError)
+	// InventoryReservationItemUpdateQuantity sets the reserved and backordered quantities of a reservation item
+	InventoryReservationItemUpdateQuantity(ctx *models.Context, tx pgx.Tx, id string, quantity int32, backordered int32) *models.DBError
+	// InventoryReservationItemSettle moves released and fulfilled units out of the reserved quantity of a
+	// reservation item, it returns settled = false if the item has fewer units reserved than released + fulfilled
+	InventoryReservationItemSettle(ctx *models.Context, tx pgx.Tx, id string, released int32, fulfilled int32) (bool, *models.DBError)
+	// InventoryReservationItemAllocate moves backordered units of a reservation item into its reserved quantity,
+	// it returns allocated = false if the item has fewer units backordered than quantity
+	InventoryReservationItemAllocate(ctx *models.Context, tx pgx.Tx, id string, quantity int32) (bool, *models.DBError)
+	// InventoryReservationItemBackorderRelease releases backordered units of a reservation item, it returns
+	// released = false if the item has fewer units backordered than quantity
+	InventoryReservationItemBackorderRelease(ctx *models.Context, tx pgx.Tx, id string, quantity int32) (bool, *models.DBError)
+	// InventoryReservationItemsBackordered locks and returns the lines of active reservations that wait
+	// for backordered units of an inventory item, oldest first
+	InventoryReservationItemsBackordered(ctx *models.Context, tx pgx.Tx, inventoryItemID string, now int64) ([]*pb.InventoryReservationItem, *models.DBError)
+	// InventoryReservationItemDelete removes an item from its reservation, along with the lots it held
+	InventoryReservationItemDelete(ctx *models.Context, tx pgx.Tx, id string) *models.DBError
+	InventoryReturnCreate(ctx *models.Context, tx pgx.Tx, params *pb.InventoryReturn) *models.DBError
+	// InventoryReturnGetByRmaNumber gets a return by its rma number and locks it until the end of tx,
 	// you can pass nil for the tx argument, and a normal db query will be used
-	InventoryReservationItemsGetByReservationID(ctx *models.Context, tx pgx.Tx, reservationID string) ([]*pb.InventoryReservationItem, *models.DBError)
+	InventoryReturnGetByRmaNumber(ctx *models.Context, tx pgx.Tx, rmaNumber string) (*pb.InventoryReturn, *models.DBError)
+	InventoryReturnUpdateStatus(ctx *models.Context, tx pgx.Tx, id string, status string) *models.DBError
+	InventoryReturnItemCreate(ctx *models.Context, tx pgx.Tx, params *pb.InventoryReturnItem) *models.DBError
+	// InventoryReturnItemsGetByReturnID gets all items for a return, limited to the items of sellerID
+	// if it's not empty, you can pass nil for the tx argument, and a normal db query will be used
+	InventoryReturnItemsGetByReturnID(ctx *models.Context, tx pgx.Tx, sellerID string, returnID string) ([]*pb.InventoryReturnItem, *models.DBError)
+	// InventoryReturnItemsAuthorized sums the units that were already authorized for return
+	// per inventory item, over every return of a reservation
+	InventoryReturnItemsAuthorized(ctx *models.Context, tx pgx.Tx, reservationID string) (map[string]int32, *models.DBError)
+	// InventoryReturnItemReceive adds received units to a return item by disposition, it returns
+	// received = false if that would receive more units than were authorized for the item
+	InventoryReturnItemReceive(ctx *models.Context, tx pgx.Tx, id string, restocked int32, quarantined int32, writtenOff int32) (bool, *models.DBError)
+	// InventoryItemInTransitAdd adds quantity (which may be negative) to the units on their way to an item,
+	// it returns added = false if that would make quantity_in_transit negative
+	InventoryItemInTransitAdd(ctx *models.Context, tx pgx.Tx, id string, quantity int32) (bool, *models.DBError)
+	InventoryTransferCreate(ctx *models.Context, tx pgx.Tx, params *pb.InventoryTransfer) *models.DBError
+	// InventoryTransferGetByNumber gets a transfer by its number and locks it until the end of tx,
+	// you can pass nil for the tx argument, and a normal db query will be used
+	InventoryTransferGetByNumber(ctx *models.Context, tx pgx.Tx, transferNumber string) (*pb.InventoryTransfer, *models.DBError)
+	InventoryTransferUpdateStatus(ctx *models.Context, tx pgx.Tx, id string, status string) *models.DBError
+	InventoryTransferItemCreate(ctx *models.Context, tx pgx.Tx, params *pb.InventoryTransferItem) *models.DBError
+	// InventoryTransferItemsGetByTransferID gets all items for a transfer,
+	// you can pass nil for the tx argument, and a normal db query will be used
+	InventoryTransferItemsGetByTransferID(ctx *models.Context, tx pgx.Tx, transferID string) ([]*pb.InventoryTransferItem, *models.DBError)
+	// InventoryTransferItemShip records the shipped quantity of a transfer item, what the shipped units cost at
+	// the source, and the inventory item at the destination location that will receive it
+	InventoryTransferItemShip(ctx *models.Context, tx pgx.Tx, id string, destinationItemID string, quantity int32, cost int64) *models.DBError
+	// InventoryTransferItemReceive adds received units to a transfer item, it returns received = false
+	// if that would receive more units than were shipped
+	InventoryTransferItemReceive(ctx *models.Context, tx pgx.Tx, id string, quantity int32) (bool, *models.DBError)
+	InventoryPurchaseOrderCreate(ctx *models.Context, tx pgx.Tx, params *pb.InventoryPurchaseOrder) *models.DBError
+	// InventoryPurchaseOrderGetByNumber gets a purchase order by its number and locks it until the end of tx,
+	// you can pass nil for the tx argument, and a normal db query will be used
+	InventoryPurchaseOrderGetByNumber(ctx *models.Context, tx pgx.Tx, poNumber string) (*pb.InventoryPurchaseOrder, *models.DBError)
+	InventoryPurchaseOrderUpdateStatus(ctx *models.Context, tx pgx.Tx, id string, status string) *models.DBError
+	// InventoryPurchaseOrderUpdate updates the status, the expected date and the note of a purchase order
+	InventoryPurchaseOrderUpdate(ctx *models.Context, tx pgx.Tx, params *pb.InventoryPurchaseOrder) *models.DBError
+	// InventoryPurchaseOrdersList lists the purchase orders newest first, an empty sellerID or locationID
+	// matches every seller or location, and an empty statuses matches every status
+	InventoryPurchaseOrdersList(ctx *models.Context, sellerID string, locationID string, statuses []string, limit int) ([]*pb.InventoryPurchaseOrder, *models.DBError)
+	InventoryPurchaseOrderItemCreate(ctx *models.Context, tx pgx.Tx, params *pb.InventoryPurchaseOrderItem) *models.DBError
+	// InventoryPurchaseOrderItemUpdate updates the ordered units and the expected date of a purchase order item
+	InventoryPurchaseOrderItemUpdate(ctx *models.Context, tx pgx.Tx, id string, quantityOrdered int32, expectedAt int64) *models.DBError
+	InventoryPurchaseOrderItemDelete(ctx *models.Context, tx pgx.Tx, id string) *models.DBError
+	// InventoryPurchaseOrderDraftedItems tells which of the inventory items are on a draft purchase order,
+	// you can pass nil for the tx argument, and a normal db query will be used
+	InventoryPurchaseOrderDraftedItems(ctx *models.Context, tx pgx.Tx, inventoryItemIDs []string) (map[string]bool, *models.DBError)
+	// InventoryPurchaseOrderItemsGetByPurchaseOrderID gets all items for a purchase order,
+	// you can pass nil for the tx argument, and a normal db query will be used
+	InventoryPurchaseOrderItemsGetByPurchaseOrderID(ctx *models.Context, tx pgx.Tx, purchaseOrderID string) ([]*pb.InventoryPurchaseOrderItem, *models.DBError)
+	// InventoryPurchaseOrderItemReceive adds received units to a purchase order item, it returns
+	// received = false if that would receive more units than were ordered
+	InventoryPurchaseOrderItemReceive(ctx *models.Context, tx pgx.Tx, id string, quantity int32) (bool, *models.DBError)
+	// InventoryPurchaseOrderIncoming sums the units of a sku that are still expected on open purchase orders,
+	// per expected date in ascending order, an empty sellerID or locationID matches every seller or location
+	InventoryPurchaseOrderIncoming(ctx *models.Context, sellerID string, sku string, locationID string) ([]*intModels.InventoryIncoming, *models.DBError)
+	// InventoryPurchaseOrderIncomingByItems sums the units of inventory items that are still expected
+	// on open purchase orders of their seller and location, the items that have none are left out
+	InventoryPurchaseOrderIncomingByItems(ctx *models.Context, inventoryItemIDs []string) (map[string]int64, *models.DBError)
+	// InventoryBackorderPolicyUpsert creates or replaces the backorder policy of an inventory item
+	InventoryBackorderPolicyUpsert(ctx *models.Context, tx pgx.Tx, params *pb.InventoryBackorderPolicy) *models.DBError
+	// InventoryBackorderPolicyGet gets the backorder policy of an inventory item and locks it until the end of tx,
+	// you can pass nil for the tx argument, and a normal db query will be used
+	InventoryBackorderPolicyGet(ctx *models.Context, tx pgx.Tx, inventoryItemID string) (*pb.InventoryBackorderPolicy, *models.DBError)
+	// InventoryBackorderPolicyBackorderedAdd adds quantity (which may be negative) to the backordered units of an
+	// inventory item, it returns added = false if that would make quantity_backordered negative, or would
+	// backorder more units than the limit of a BACKORDER policy
+	InventoryBackorderPolicyBackorderedAdd(ctx *models.Context, tx pgx.Tx, inventoryItemID string, quantity int32) (bool, *models.DBError)
+	// InventoryAllocationPolicyUpsert creates the allocation policy of an inventory item, or replaces its safety stock
+	InventoryAllocationPolicyUpsert(ctx *models.Context, tx pgx.Tx, params *pb.InventoryAllocationPolicy) *models.DBError
+	// InventoryAllocationPolicyGet gets the allocation policy of an inventory item,
+	// you can pass nil for the tx argument, and a normal db query will be used
+	InventoryAllocationPolicyGet(ctx *models.Context, tx pgx.Tx, inventoryItemID string) (*pb.InventoryAllocationPolicy, *models.DBError)
+	// InventoryChannelAllocationCreate creates a new channel allocation rule
+	InventoryChannelAllocationCreate(ctx *models.Context, tx pgx.Tx, params *pb.InventoryChannelAllocation) *models.DBError
+	// InventoryChannelAllocationsDelete removes every channel allocation rule of an inventory item
+	InventoryChannelAllocationsDelete(ctx *models.Context, tx pgx.Tx, inventoryItemID string) *models.DBError
+	// InventoryChannelAllocationsGet gets the channel allocation rules of an inventory item in the order they were created,
+	// you can pass nil for the tx argument, and a normal db query will be used
+	InventoryChannelAllocationsGet(ctx *models.Context, tx pgx.Tx, inventoryItemID string) ([]*pb.InventoryChannelAllocation, *models.DBError)
+	// InventoryReservationItemsReservedByChannel sums the units of an inventory item held by the active reservations
+	// of every sales channel, the reservations without a channel are summed under an empty channel
+	InventoryReservationItemsReservedByChannel(ctx *models.Context, tx pgx.Tx, inventoryItemID string) (map[string]int32, *models.DBError)
+	InventoryKitCreate(ctx *models.Context, tx pgx.Tx, params *pb.InventoryKit) *models.DBError
+	// InventoryKitUpdateSku sets the sku of a kit
+	InventoryKitUpdateSku(ctx *models.Context, tx pgx.Tx, id string, sku string) *models.DBError
+	// InventoryKitGetByProductVariant gets a kit and locks it until the end of tx, you can pass nil
+	// for the tx argument, and a normal db query will be used. An empty sellerID matches every seller
+	InventoryKitGetByProductVariant(ctx *models.Context, tx pgx.Tx, sellerID string, productID string, variantID string) (*pb.InventoryKit, *models.DBError)
+	// InventoryKitGetByIDs gets the kits for the given ids
+	InventoryKitGetByIDs(ctx *models.Context, ids []string) ([]*pb.InventoryKit, *models.DBError)
+	InventoryKitComponentCreate(ctx *models.Context, tx pgx.Tx, params *pb.InventoryKitComponent) *models.DBError
+	// InventoryKitComponentsDelete removes every component of a kit
+	InventoryKitComponentsDelete(ctx *models.Context, tx pgx.Tx, kitID string) *models.DBError
+	// InventoryKitComponentsGet gets the components of a kit ordered by inventory item,
+	// you can pass nil for the tx argument, and a normal db query will be used
+	InventoryKitComponentsGet(ctx *models.Context, tx pgx.Tx, kitID string) ([]*pb.InventoryKitComponent, *models.DBError)
+	// InventoryItemsLockByIDs locks and returns the inventory items for the given ids, in the order of their ids
+	InventoryItemsLockByIDs(ctx *models.Context, tx pgx.Tx, ids []string) ([]*pb.InventoryItem, *models.DBError)
+	InventoryLotCreate(ctx *models.Context, tx pgx.Tx, params *pb.InventoryLot) *models.DBError
+	// InventoryLotGetByNumber locks and returns a lot of an inventory item by its number
+	InventoryLotGetByNumber(ctx *models.Context, tx pgx.Tx, inventoryItemID string, lotNumber string) (*pb.InventoryLot, *models.DBError)
+	// InventoryLotsGet gets the lots of an inventory item first expired first out and locks them until the end of tx,
+	// you can pass nil for the tx argument, and a normal db query will be used
+	InventoryLotsGet(ctx *models.Context, tx pgx.Tx, inventoryItemID string) ([]*pb.InventoryLot, *models.DBError)
+	// InventoryLotReceive adds received units to the on hand quantity of a lot
+	InventoryLotReceive(ctx *models.Context, tx pgx.Tx, id string, quantity int32) *models.DBError
+	// InventoryLotReserve reserves units of a lot, it returns reserved = false if
+	// the lot has fewer units that aren't reserved yet than quantity
+	InventoryLotReserve(ctx *models.Context, tx pgx.Tx, id string, quantity int32) (bool, *models.DBError)
+	// InventoryLotRelease releases reserved units of a lot, it returns released = false
+	// if the lot has fewer units reserved than quantity
+	InventoryLotRelease(ctx *models.Context, tx pgx.Tx, id string, quantity int32) (bool, *models.DBError)
+	// InventoryLotFulfill ships reserved units of a lot, it returns fulfilled = false
+	// if the lot has fewer units reserved than quantity
+	InventoryLotFulfill(ctx *models.Context, tx pgx.Tx, id string, quantity int32) (bool, *models.DBError)
+	// InventoryLotIssue takes units that aren't reserved out of a lot, it returns issued = false
+	// if the lot has fewer units that aren't reserved than quantity
+	InventoryLotIssue(ctx *models.Context, tx pgx.Tx, id string, quantity int32) (bool, *models.DBError)
+	// InventoryReservationLotAdd adds units of a lot to what a reservation item holds of that lot
+	InventoryReservationLotAdd(ctx *models.Context, tx pgx.Tx, reservationItemID string, lotID string, quantity int32) *models.DBError
+	// InventoryReservationLotsGet gets the lots that a reservation item holds units of, first expired first out,
+	// you can pass nil for the tx argument, and a normal db query will be used
+	InventoryReservationLotsGet(ctx *models.Context, tx pgx.Tx, reservationItemID string) ([]*pb.InventoryReservationLot, *models.DBError)
+	// InventoryReservationLotSettle takes released or fulfilled units out of what a reservation item holds
+	// of a lot, it returns settled = false if it holds fewer units of the lot than quantity
+	InventoryReservationLotSettle(ctx *models.Context, tx pgx.Tx, id string, quantity int32) (bool, *models.DBError)
+	InventorySerialCreate(ctx *models.Context, tx pgx.Tx, params *pb.InventorySerial) *models.DBError
+	// InventorySerialGetByNumber locks and returns a serial of an inventory item by its number
+	InventorySerialGetByNumber(ctx *models.Context, tx pgx.Tx, inventoryItemID string, serialNumber string) (*pb.InventorySerial, *models.DBError)
+	// InventorySerialsGetByNumber gets the serials with the given number across the inventory items of a seller,
+	// an empty sellerID matches every seller
+	InventorySerialsGetByNumber(ctx *models.Context, sellerID string, serialNumber string) ([]*pb.InventorySerial, *models.DBError)
+	// InventorySerialsGetByReservationItem locks and returns the serials that a reservation item holds
+	InventorySerialsGetByReservationItem(ctx *models.Context, tx pgx.Tx, reservationItemID string) ([]*pb.InventorySerial, *models.DBError)
+	// InventorySerialsCount counts the serials of an inventory item that are in one of statuses, or every serial
+	// if statuses is empty, you can pass nil for the tx argument, and a normal db query will be used
+	InventorySerialsCount(ctx *models.Context, tx pgx.Tx, inventoryItemID string, statuses []string) (int32, *models.DBError)
+	// InventorySerialUpdateStatus sets the status of a serial and the reservation item that holds it
+	InventorySerialUpdateStatus(ctx *models.Context, tx pgx.Tx, id string, status string, reservationItemID *string) *models.DBError
+	// InventorySerialRelocate moves a serial to another inventory item and location with the given status
+	InventorySerialRelocate(ctx *models.Context, tx pgx.Tx, id string, inventoryItemID string, locationID *string, status string) *models.DBError
+	// InventorySerialMovementCreate records a change of the status of a serial
+	InventorySerialMovementCreate(ctx *models.Context, tx pgx.Tx, params *pb.InventorySerialMovement) *models.DBError
+	// InventorySerialMovementsGet gets the movements of a serial, oldest first
+	InventorySerialMovementsGet(ctx *models.Context, serialID string) ([]*pb.InventorySerialMovement, *models.DBError)
 	// InventoryItemUpdate updates an inventory item
 	InventoryItemUpdate(ctx *models.Context, tx pgx.Tx, id string, quantityTotal int, quantityReserved int32, quantityAvailable int) *models.DBError
+	// InventoryItemBucketsUpdate updates the stock buckets of an inventory item, quantityTotal is the sellable bucket
+	InventoryItemBucketsUpdate(ctx *models.Context, tx pgx.Tx, id string, quantityTotal int32, quantityAvailable int32, quantityQuarantined int32, quantityDamaged int32, quantityOnHold int32) *models.DBError
+	InventoryAdjustmentRequestCreate(ctx *models.Context, tx pgx.Tx, params *pb.InventoryAdjustmentRequest) *models.DBError
+	// InventoryAdjustmentRequestGetByNumber gets an adjustment request by its number and locks it until the end of tx,
+	// you can pass nil for the tx argument, and a normal db query will be used
+	InventoryAdjustmentRequestGetByNumber(ctx *models.Context, tx pgx.Tx, requestNumber string) (*pb.InventoryAdjustmentRequest, *models.DBError)
+	// InventoryAdjustmentRequestsList lists the adjustment requests of a seller that have one of the given statuses
+	// newest first, an empty sellerID matches every seller and no statuses match every status
+	InventoryAdjustmentRequestsList(ctx *models.Context, sellerID string, statuses []string, limit int) ([]*pb.InventoryAdjustmentRequest, *models.DBError)
+	// InventoryAdjustmentRequestReview sets the status that the review of an adjustment request ended with
+	InventoryAdjustmentRequestReview(ctx *models.Context, tx pgx.Tx, id string, status string, reviewedBy *string, reviewNote *string) *models.DBError
+	InventoryUnitCreate(ctx *models.Context, tx pgx.Tx, params *pb.InventoryUnit) *models.DBError
+	// InventoryUnitsDelete removes every unit of measure of an inventory item
+	InventoryUnitsDelete(ctx *models.Context, tx pgx.Tx, inventoryItemID string) *models.DBError
+	// InventoryUnitsGet gets the units of measure of an inventory item smallest first,
+	// you can pass nil for the tx argument, and a normal db query will be used
+	InventoryUnitsGet(ctx *models.Context, tx pgx.Tx, inventoryItemID string) ([]*pb.InventoryUnit, *models.DBError)
+	// InventoryCostLayerCreate creates a new cost layer, the units of an item received at the same unit cost
+	InventoryCostLayerCreate(ctx *models.Context, tx pgx.Tx, params *pb.InventoryCostLayer) *models.DBError
+	// InventoryCostLayersOpen locks and returns the cost layers of an item that have units remaining, first in first out
+	InventoryCostLayersOpen(ctx *models.Context, tx pgx.Tx, inventoryItemID string) ([]*pb.InventoryCostLayer, *models.DBError)
+	// InventoryCostLayerConsume takes units out of a cost layer, consumed is false if it doesn't have them
+	InventoryCostLayerConsume(ctx *models.Context, tx pgx.Tx, id string, quantity int32) (bool, *models.DBError)
+	InventoryCostIssueCreate(ctx *models.Context, tx pgx.Tx, params *pb.InventoryCostIssue) *models.DBError
+	// InventoryCostIssued is the units of an item that the issues of a movement type took, and their first in first out cost
+	InventoryCostIssued(ctx *models.Context, tx pgx.Tx, inventoryItemID string, movementType string) (quantity int64, value int64, errDB *models.DBError)
+	// InventoryCostBalance is the costed units of an item that are on hand, and their weighted average value
+	InventoryCostBalance(ctx *models.Context, tx pgx.Tx, inventoryItemID string) (quantity int64, value int64, errDB *models.DBError)
+	// InventoryValuation adds up the cost layers and issues of the items that match the filter as of its date
+	InventoryValuation(ctx *models.Context, filter *intModels.InventoryValuationFilter) ([]*intModels.InventoryValuationRow, *models.DBError)
+	InventoryCycleCountCreate(ctx *models.Context, tx pgx.Tx, params *pb.InventoryCycleCount) *models.DBError
+	// InventoryCycleCountGetByNumber gets a cycle count by its number and locks it until the end of tx,
+	// you can pass nil for the tx argument, and a normal db query will be used
+	InventoryCycleCountGetByNumber(ctx *models.Context, tx pgx.Tx, countNumber string) (*pb.InventoryCycleCount, *models.DBError)
+	// InventoryCycleCountUpdateStatus updates the status of a cycle count, and the note of its review if it's not nil
+	InventoryCycleCountUpdateStatus(ctx *models.Context, tx pgx.Tx, id string, status string, reviewNote *string) *models.DBError
+	InventoryCycleCountItemCreate(ctx *models.Context, tx pgx.Tx, params *pb.InventoryCycleCountItem) *models.DBError
+	// InventoryCycleCountItemsGetByCycleCountID gets all items of a cycle count,
+	// you can pass nil for the tx argument, and a normal db query will be used
+	InventoryCycleCountItemsGetByCycleCountID(ctx *models.Context, tx pgx.Tx, cycleCountID string) ([]*pb.InventoryCycleCountItem, *models.DBError)
+	// InventoryCycleCountItemCount records the counted units of a cycle count item, with the on hand
+	// and the reserved units that the system had for the item when it was counted
+	InventoryCycleCountItemCount(ctx *models.Context, tx pgx.Tx, id string, expected int32, reserved int32, counted int32) *models.DBError
+	// InventoryCycleCountItemActive tells whether an inventory item is part of a cycle count that isn't reviewed yet,
+	// only the cycle counts that freeze their items are considered if frozenOnly is set
+	InventoryCycleCountItemActive(ctx *models.Context, tx pgx.Tx, inventoryItemID string, frozenOnly bool) (bool, *models.DBError)
 	// InventoryMovementCreate creates a new inventory movement
 	InventoryMovementCreate(ctx *models.Context, tx pgx.Tx, params *pb.InventoryMovement) *models.DBError
-	// InventoryItemGetByIDs gets the inventory items for the given ids
-	InventoryItemGetByIDs(ctx *models.Context, ids []string) ([]*pb.InventoryItem, *models.DBError)
+	// InventoryMovementsQuantityChange adds up the changes of the on hand units of an inventory
+	// item that the movements created after a date, and up to another one, have made
+	InventoryMovementsQuantityChange(ctx *models.Context, inventoryItemID string, after int64, upTo int64) (int64, *models.DBError)
+	// InventorySnapshotsTake records the quantities of every inventory item once per day (UTC), and returns
+	// the number of snapshots taken, the items that already have a snapshot of that day are skipped
+	InventorySnapshotsTake(ctx *models.Context, takenAt int64) (int64, *models.DBError)
+	// InventorySnapshotsDelete deletes the snapshots taken before a date
+	InventorySnapshotsDelete(ctx *models.Context, before int64) (int64, *models.DBError)
+	// InventorySnapshotsFirstTakenAt gets the date of the earliest snapshot that is kept
+	InventorySnapshotsFirstTakenAt(ctx *models.Context) (int64, *models.DBError)
+	// InventorySnapshotNearest gets the latest snapshot of an inventory item taken at
+	// or before a date, or the earliest one taken after it if there's none
+	InventorySnapshotNearest(ctx *models.Context, inventoryItemID string, at int64) (*pb.InventorySnapshot, *models.DBError)
+	// InventoryMovementsDaily sums the units that inventory items moved per day (UTC) since a date, by movement type
+	// and by whether the movements settled a reservation, ordered by item and day
+	InventoryMovementsDaily(ctx *models.Context, inventoryItemIDs []string, from int64) ([]*intModels.InventoryMovementDay, *models.DBError)
+	// InventoryReorderPolicyUpsert creates the reorder policy of an inventory item, or replaces an existing one
+	InventoryReorderPolicyUpsert(ctx *models.Context, tx pgx.Tx, params *pb.InventoryReorderPolicy) *models.DBError
+	// InventoryReorderPolicyGet gets the reorder policy of an inventory item and locks it until the end of tx,
+	// you can pass nil for the tx argument, and a normal db query will be used
+	InventoryReorderPolicyGet(ctx *models.Context, tx pgx.Tx, inventoryItemID string) (*pb.InventoryReorderPolicy, *models.DBError)
+	// InventoryReplenishmentLock waits until no other transaction drafts replenishment purchase orders, and
+	// holds the lock until the end of tx
+	InventoryReplenishmentLock(ctx *models.Context, tx pgx.Tx) *models.DBError
+	// InventoryReorderPoliciesGetByItems gets the reorder policies of inventory items by item id,
+	// the items that have none are left out
+	InventoryReorderPoliciesGetByItems(ctx *models.Context, inventoryItemIDs []string) (map[string]*pb.InventoryReorderPolicy, *models.DBError)
+	// InventoryItemGetByIDs gets the inventory items for the given ids, an empty sellerID matches every seller
+	InventoryItemGetByIDs(ctx *models.Context, sellerID string, ids []string) ([]*pb.InventoryItem, *models.DBError)
+	// InventoryItemGetBySku locks and returns the inventory item of a sku at a location,
+	// an empty sellerID or locationID matches every seller or location, and more than one match fails with intModels.ErrInventoryItemAmbiguous
+	InventoryItemGetBySku(ctx *models.Context, tx pgx.Tx, sellerID string, sku string, locationID string) (*pb.InventoryItem, *models.DBError)
+	// InventoryItemsList lists the inventory items ordered by id, starting after afterID (keyset pagination)
+	InventoryItemsList(ctx *models.Context, sellerID string, locationID string, afterID string, limit int) ([]*pb.InventoryItem, *models.DBError)
+	// InventoryItemsGetBySku gets the inventory items of a sku at every location, or at locationID if it's not empty,
+	// an empty sellerID matches every seller, the items aren't locked
+	InventoryItemsGetBySku(ctx *models.Context, sellerID string, sku string, locationID string) ([]*pb.InventoryItem, *models.DBError)
 }
@@ -0,0 +1,209 @@
+package dbstore
+
+import (
+	pb "github.com/ahmad-khatib0-org/megacommerce-proto/gen/go/inventory/v1"
+	"github.com/ahmad-khatib0-org/megacommerce-shared-go/pkg/models"
+	"github.com/ahmad-khatib0-org/megacommerce-shared-go/pkg/utils"
+	"github.com/jackc/pgx/v5"
+)
+
+// InventoryAdjustmentRequestCreate creates a new adjustment request
+func (is *InventoryStore) InventoryAdjustmentRequestCreate(ctx *models.Context, tx pgx.Tx, params *pb.InventoryAdjustmentRequest) *models.DBError {
+	stmt := `
+		INSERT INTO inventory_adjustment_requests (
+			id,
+			request_number,
+			seller_id,
+			inventory_item_id,
+			operation,
+			quantity,
+			unit_cost,
+			quantity_total_before,
+			quantity_total_after,
+			reason,
+			status,
+			requested_by,
+			reviewed_by,
+			review_note,
+			expires_at,
+			created_at,
+			updated_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17)
+  `
+
+	_, err := tx.Exec(
+		ctx.Ctx(),
+		stmt,
+		params.Id,
+		params.RequestNumber,
+		params.SellerId,
+		params.InventoryItemId,
+		params.Operation,
+		params.Quantity,
+		params.UnitCost,
+		params.QuantityTotalBefore,
+		params.QuantityTotalAfter,
+		params.Reason,
+		params.Status,
+		params.RequestedBy,
+		params.ReviewedBy,
+		params.ReviewNote,
+		params.ExpiresAt,
+		params.CreatedAt,
+		params.UpdatedAt,
+	)
+
+	return models.HandleDBError(ctx, err, "inventory.store.InventoryAdjustmentRequestCreate", tx)
+}
+
+// InventoryAdjustmentRequestGetByNumber gets an adjustment request by its number, the row
+// is locked until the end of tx if tx is not nil
+func (is *InventoryStore) InventoryAdjustmentRequestGetByNumber(ctx *models.Context, tx pgx.Tx, requestNumber string) (*pb.InventoryAdjustmentRequest, *models.DBError) {
+	stmt := `
+		SELECT
+			id,
+			request_number,
+			seller_id,
+			inventory_item_id,
+			operation,
+			quantity,
+			unit_cost,
+			quantity_total_before,
+			quantity_total_after,
+			reason,
+			status,
+			requested_by,
+			reviewed_by,
+			review_note,
+			expires_at,
+			created_at,
+			updated_at
+		FROM inventory_adjustment_requests
+		WHERE request_number = $1
+  `
+
+	var ar pb.InventoryAdjustmentRequest
+	var updatedAt int64
+	var row pgx.Row
+	if tx != nil {
+		row = tx.QueryRow(ctx.Context, stmt+" FOR UPDATE", requestNumber)
+	} else {
+		row = is.db.QueryRow(ctx.Context, stmt, requestNumber)
+	}
+	err := row.Scan(
+		&ar.Id,
+		&ar.RequestNumber,
+		&ar.SellerId,
+		&ar.InventoryItemId,
+		&ar.Operation,
+		&ar.Quantity,
+		&ar.UnitCost,
+		&ar.QuantityTotalBefore,
+		&ar.QuantityTotalAfter,
+		&ar.Reason,
+		&ar.Status,
+		&ar.RequestedBy,
+		&ar.ReviewedBy,
+		&ar.ReviewNote,
+		&ar.ExpiresAt,
+		&ar.CreatedAt,
+		&updatedAt,
+	)
+	if err != nil {
+		return nil, models.HandleDBError(ctx, err, "inventory.store.InventoryAdjustmentRequestGetByNumber", tx)
+	}
+
+	if updatedAt > 0 {
+		ar.UpdatedAt = &updatedAt
+	}
+
+	return &ar, nil
+}
+
+// InventoryAdjustmentRequestsList lists the adjustment requests of a seller that have one of the given
+// statuses, newest first, an empty sellerID matches every seller and no statuses match every status
+func (is *InventoryStore) InventoryAdjustmentRequestsList(ctx *models.Context, sellerID string, statuses []string, limit int) ([]*pb.InventoryAdjustmentRequest, *models.DBError) {
+	stmt := `
+		SELECT
+			id,
+			request_number,
+			seller_id,
+			inventory_item_id,
+			operation,
+			quantity,
+			unit_cost,
+			quantity_total_before,
+			quantity_total_after,
+			reason,
+			status,
+			requested_by,
+			reviewed_by,
+			review_note,
+			expires_at,
+			created_at,
+			updated_at
+		FROM inventory_adjustment_requests
+		WHERE ($1 = '' OR seller_id = $1) AND (cardinality($2::text[]) = 0 OR status = ANY($2))
+		ORDER BY created_at DESC, id DESC
+		LIMIT $3
+  `
+
+	rows, err := is.db.Query(ctx.Ctx(), stmt, sellerID, statuses, limit)
+	if err != nil {
+		return nil, models.HandleDBError(ctx, err, "inventory.store.InventoryAdjustmentRequestsList", nil)
+	}
+	defer rows.Close()
+
+	result := make([]*pb.InventoryAdjustmentRequest, 0, limit)
+	for rows.Next() {
+		var ar pb.InventoryAdjustmentRequest
+		var updatedAt int64
+		err := rows.Scan(
+			&ar.Id,
+			&ar.RequestNumber,
+			&ar.SellerId,
+			&ar.InventoryItemId,
+			&ar.Operation,
+			&ar.Quantity,
+			&ar.UnitCost,
+			&ar.QuantityTotalBefore,
+			&ar.QuantityTotalAfter,
+			&ar.Reason,
+			&ar.Status,
+			&ar.RequestedBy,
+			&ar.ReviewedBy,
+			&ar.ReviewNote,
+			&ar.ExpiresAt,
+			&ar.CreatedAt,
+			&updatedAt,
+		)
+		if err != nil {
+			return nil, models.HandleDBError(ctx, err, "inventory.store.InventoryAdjustmentRequestsList", nil)
+		}
+
+		if updatedAt > 0 {
+			ar.UpdatedAt = &updatedAt
+		}
+		result = append(result, &ar)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, models.HandleDBError(ctx, err, "inventory.store.InventoryAdjustmentRequestsList", nil)
+	}
+
+	return result, nil
+}
+
+// InventoryAdjustmentRequestReview sets the status that the review of an adjustment request ended with,
+// along with who reviewed it and why, reviewedBy is nil for requests that expired without a review
+func (is *InventoryStore) InventoryAdjustmentRequestReview(ctx *models.Context, tx pgx.Tx, id string, status string, reviewedBy *string, reviewNote *string) *models.DBError {
+	stmt := `
+		UPDATE inventory_adjustment_requests
+		SET status = $1, reviewed_by = $2, review_note = $3, updated_at = $4
+		WHERE id = $5
+  `
+
+	_, err := tx.Exec(ctx.Ctx(), stmt, status, reviewedBy, reviewNote, utils.TimeGetMillis(), id)
+
+	return models.HandleDBError(ctx, err, "inventory.store.InventoryAdjustmentRequestReview", tx)
+}
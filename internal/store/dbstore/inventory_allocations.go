@@ -0,0 +1,199 @@
+package dbstore
+
+import (
+	pb "github.com/ahmad-khatib0-org/megacommerce-proto/gen/go/inventory/v1"
+	"github.com/ahmad-khatib0-org/megacommerce-shared-go/pkg/models"
+	"github.com/jackc/pgx/v5"
+)
+
+// InventoryAllocationPolicyUpsert creates the allocation policy of an inventory item, or replaces the safety stock of an existing one
+func (is *InventoryStore) InventoryAllocationPolicyUpsert(ctx *models.Context, tx pgx.Tx, params *pb.InventoryAllocationPolicy) *models.DBError {
+	stmt := `
+		INSERT INTO inventory_allocation_policies (
+			inventory_item_id,
+			safety_stock,
+			created_at,
+			updated_at
+		) VALUES ($1, $2, $3, $4)
+		ON CONFLICT (inventory_item_id) DO UPDATE SET
+			safety_stock = EXCLUDED.safety_stock,
+			updated_at = EXCLUDED.created_at
+  `
+
+	_, err := tx.Exec(
+		ctx.Ctx(),
+		stmt,
+		params.InventoryItemId,
+		params.SafetyStock,
+		params.CreatedAt,
+		params.UpdatedAt,
+	)
+
+	return models.HandleDBError(ctx, err, "inventory.store.InventoryAllocationPolicyUpsert", tx)
+}
+
+// InventoryAllocationPolicyGet gets the allocation policy of an inventory item,
+// you can pass nil for the tx argument, and a normal db query will be used
+func (is *InventoryStore) InventoryAllocationPolicyGet(ctx *models.Context, tx pgx.Tx, inventoryItemID string) (*pb.InventoryAllocationPolicy, *models.DBError) {
+	stmt := `
+		SELECT
+			inventory_item_id,
+			safety_stock,
+			created_at,
+			updated_at
+		FROM inventory_allocation_policies
+		WHERE inventory_item_id = $1
+  `
+
+	var ap pb.InventoryAllocationPolicy
+	var updatedAt int64
+	var row pgx.Row
+	if tx != nil {
+		row = tx.QueryRow(ctx.Context, stmt, inventoryItemID)
+	} else {
+		row = is.db.QueryRow(ctx.Context, stmt, inventoryItemID)
+	}
+	err := row.Scan(
+		&ap.InventoryItemId,
+		&ap.SafetyStock,
+		&ap.CreatedAt,
+		&updatedAt,
+	)
+	if err != nil {
+		return nil, models.HandleDBError(ctx, err, "inventory.store.InventoryAllocationPolicyGet", tx)
+	}
+
+	if updatedAt > 0 {
+		ap.UpdatedAt = &updatedAt
+	}
+
+	return &ap, nil
+}
+
+// InventoryChannelAllocationCreate creates a new channel allocation rule
+func (is *InventoryStore) InventoryChannelAllocationCreate(ctx *models.Context, tx pgx.Tx, params *pb.InventoryChannelAllocation) *models.DBError {
+	stmt := `
+		INSERT INTO inventory_channel_allocations (
+			id,
+			inventory_item_id,
+			channel,
+			allocation_type,
+			value,
+			created_at
+		) VALUES ($1, $2, $3, $4, $5, $6)
+  `
+
+	_, err := tx.Exec(
+		ctx.Ctx(),
+		stmt,
+		params.Id,
+		params.InventoryItemId,
+		params.Channel,
+		params.AllocationType,
+		params.Value,
+		params.CreatedAt,
+	)
+
+	return models.HandleDBError(ctx, err, "inventory.store.InventoryChannelAllocationCreate", tx)
+}
+
+// InventoryChannelAllocationsDelete removes every channel allocation rule of an inventory item
+func (is *InventoryStore) InventoryChannelAllocationsDelete(ctx *models.Context, tx pgx.Tx, inventoryItemID string) *models.DBError {
+	stmt := `DELETE FROM inventory_channel_allocations WHERE inventory_item_id = $1`
+
+	_, err := tx.Exec(ctx.Ctx(), stmt, inventoryItemID)
+
+	return models.HandleDBError(ctx, err, "inventory.store.InventoryChannelAllocationsDelete", tx)
+}
+
+// InventoryChannelAllocationsGet gets the channel allocation rules of an inventory item in the order they were created,
+// you can pass nil for the tx argument, and a normal db query will be used
+func (is *InventoryStore) InventoryChannelAllocationsGet(ctx *models.Context, tx pgx.Tx, inventoryItemID string) ([]*pb.InventoryChannelAllocation, *models.DBError) {
+	stmt := `
+		SELECT
+			id,
+			inventory_item_id,
+			channel,
+			allocation_type,
+			value,
+			created_at
+		FROM inventory_channel_allocations
+		WHERE inventory_item_id = $1
+		ORDER BY created_at, id
+  `
+
+	var rows pgx.Rows
+	var err error
+	if tx != nil {
+		rows, err = tx.Query(ctx.Context, stmt, inventoryItemID)
+	} else {
+		rows, err = is.db.Query(ctx.Context, stmt, inventoryItemID)
+	}
+	if err != nil {
+		return nil, models.HandleDBError(ctx, err, "inventory.store.InventoryChannelAllocationsGet", tx)
+	}
+	defer rows.Close()
+
+	result := make([]*pb.InventoryChannelAllocation, 0)
+	for rows.Next() {
+		var ca pb.InventoryChannelAllocation
+		err := rows.Scan(
+			&ca.Id,
+			&ca.InventoryItemId,
+			&ca.Channel,
+			&ca.AllocationType,
+			&ca.Value,
+			&ca.CreatedAt,
+		)
+		if err != nil {
+			return nil, models.HandleDBError(ctx, err, "inventory.store.InventoryChannelAllocationsGet", tx)
+		}
+		result = append(result, &ca)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, models.HandleDBError(ctx, err, "inventory.store.InventoryChannelAllocationsGet", tx)
+	}
+
+	return result, nil
+}
+
+// InventoryReservationItemsReservedByChannel sums the units of an inventory item that are held by the active
+// reservations of every sales channel, the reservations without a channel are summed under an empty channel
+func (is *InventoryStore) InventoryReservationItemsReservedByChannel(ctx *models.Context, tx pgx.Tx, inventoryItemID string) (map[string]int32, *models.DBError) {
+	stmt := `
+		SELECT r.channel, SUM(ri.quantity)
+		FROM inventory_reservation_items ri
+		JOIN inventory_reservations r ON r.id = ri.reservation_id
+		WHERE ri.inventory_item_id = $1 AND r.status IN ('RESERVED', 'PARTIALLY_RESERVED')
+		GROUP BY r.channel
+  `
+
+	var rows pgx.Rows
+	var err error
+	if tx != nil {
+		rows, err = tx.Query(ctx.Context, stmt, inventoryItemID)
+	} else {
+		rows, err = is.db.Query(ctx.Context, stmt, inventoryItemID)
+	}
+	if err != nil {
+		return nil, models.HandleDBError(ctx, err, "inventory.store.InventoryReservationItemsReservedByChannel", tx)
+	}
+	defer rows.Close()
+
+	result := make(map[string]int32)
+	for rows.Next() {
+		var channel string
+		var quantity int32
+		if err := rows.Scan(&channel, &quantity); err != nil {
+			return nil, models.HandleDBError(ctx, err, "inventory.store.InventoryReservationItemsReservedByChannel", tx)
+		}
+		result[channel] = quantity
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, models.HandleDBError(ctx, err, "inventory.store.InventoryReservationItemsReservedByChannel", tx)
+	}
+
+	return result, nil
+}
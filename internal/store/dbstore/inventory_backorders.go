@@ -0,0 +1,196 @@
+package dbstore
+
+import (
+	pb "github.com/ahmad-khatib0-org/megacommerce-proto/gen/go/inventory/v1"
+	"github.com/ahmad-khatib0-org/megacommerce-shared-go/pkg/models"
+	"github.com/ahmad-khatib0-org/megacommerce-shared-go/pkg/utils"
+	"github.com/jackc/pgx/v5"
+)
+
+// InventoryBackorderPolicyUpsert creates the backorder policy of an inventory item, or replaces the
+// policy, limit and release date of an existing one, the backordered quantity is kept
+func (is *InventoryStore) InventoryBackorderPolicyUpsert(ctx *models.Context, tx pgx.Tx, params *pb.InventoryBackorderPolicy) *models.DBError {
+	stmt := `
+		INSERT INTO inventory_backorder_policies (
+			inventory_item_id,
+			policy,
+			backorder_limit,
+			preorder_release_at,
+			quantity_backordered,
+			created_at,
+			updated_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7)
+		ON CONFLICT (inventory_item_id) DO UPDATE SET
+			policy = EXCLUDED.policy,
+			backorder_limit = EXCLUDED.backorder_limit,
+			preorder_release_at = EXCLUDED.preorder_release_at,
+			updated_at = EXCLUDED.created_at
+  `
+
+	_, err := tx.Exec(
+		ctx.Ctx(),
+		stmt,
+		params.InventoryItemId,
+		params.Policy,
+		params.BackorderLimit,
+		params.PreorderReleaseAt,
+		params.QuantityBackordered,
+		params.CreatedAt,
+		params.UpdatedAt,
+	)
+
+	return models.HandleDBError(ctx, err, "inventory.store.InventoryBackorderPolicyUpsert", tx)
+}
+
+// InventoryBackorderPolicyGet gets the backorder policy of an inventory item, the policy
+// row is locked until the end of tx if tx is not nil
+func (is *InventoryStore) InventoryBackorderPolicyGet(ctx *models.Context, tx pgx.Tx, inventoryItemID string) (*pb.InventoryBackorderPolicy, *models.DBError) {
+	stmt := `
+		SELECT
+			inventory_item_id,
+			policy,
+			backorder_limit,
+			preorder_release_at,
+			quantity_backordered,
+			created_at,
+			updated_at
+		FROM inventory_backorder_policies
+		WHERE inventory_item_id = $1
+  `
+
+	var bp pb.InventoryBackorderPolicy
+	var updatedAt int64
+	var row pgx.Row
+	if tx != nil {
+		row = tx.QueryRow(ctx.Context, stmt+" FOR UPDATE", inventoryItemID)
+	} else {
+		row = is.db.QueryRow(ctx.Context, stmt, inventoryItemID)
+	}
+	err := row.Scan(
+		&bp.InventoryItemId,
+		&bp.Policy,
+		&bp.BackorderLimit,
+		&bp.PreorderReleaseAt,
+		&bp.QuantityBackordered,
+		&bp.CreatedAt,
+		&updatedAt,
+	)
+	if err != nil {
+		return nil, models.HandleDBError(ctx, err, "inventory.store.InventoryBackorderPolicyGet", tx)
+	}
+
+	if updatedAt > 0 {
+		bp.UpdatedAt = &updatedAt
+	}
+
+	return &bp, nil
+}
+
+// InventoryBackorderPolicyBackorderedAdd adds quantity (which may be negative) to the backordered units of an
+// inventory item, it returns added = false if that would make quantity_backordered negative, or would
+// backorder more units than the limit of a BACKORDER policy
+func (is *InventoryStore) InventoryBackorderPolicyBackorderedAdd(ctx *models.Context, tx pgx.Tx, inventoryItemID string, quantity int32) (bool, *models.DBError) {
+	stmt := `
+		UPDATE inventory_backorder_policies
+		SET quantity_backordered = quantity_backordered + $1, updated_at = $2
+		WHERE inventory_item_id = $3
+			AND quantity_backordered + $1 >= 0
+			AND ($1 <= 0 OR policy <> 'BACKORDER' OR quantity_backordered + $1 <= backorder_limit)
+  `
+
+	result, err := tx.Exec(ctx.Ctx(), stmt, quantity, utils.TimeGetMillis(), inventoryItemID)
+	if err != nil {
+		return false, models.HandleDBError(ctx, err, "inventory.store.InventoryBackorderPolicyBackorderedAdd", tx)
+	}
+
+	return result.RowsAffected() > 0, nil
+}
+
+// InventoryReservationItemsBackordered gets the reservation lines of an inventory item that wait for backordered
+// units, oldest first, only the lines of reservations that are still active at now are returned, and they are locked
+func (is *InventoryStore) InventoryReservationItemsBackordered(ctx *models.Context, tx pgx.Tx, inventoryItemID string, now int64) ([]*pb.InventoryReservationItem, *models.DBError) {
+	stmt := `
+		SELECT
+			ri.id,
+			ri.reservation_id,
+			ri.inventory_item_id,
+			ri.quantity,
+			ri.quantity_backordered,
+			ri.quantity_released,
+			ri.quantity_fulfilled,
+			ri.created_at
+		FROM inventory_reservation_items ri
+		JOIN inventory_reservations r ON r.id = ri.reservation_id
+		WHERE ri.inventory_item_id = $1
+			AND ri.quantity_backordered > 0
+			AND r.status IN ('RESERVED', 'PARTIALLY_RESERVED')
+			AND r.expires_at > $2
+		ORDER BY ri.created_at, ri.id
+		FOR UPDATE OF ri
+  `
+
+	rows, err := tx.Query(ctx.Ctx(), stmt, inventoryItemID, now)
+	if err != nil {
+		return nil, models.HandleDBError(ctx, err, "inventory.store.InventoryReservationItemsBackordered", tx)
+	}
+	defer rows.Close()
+
+	var items []*pb.InventoryReservationItem
+	for rows.Next() {
+		var item pb.InventoryReservationItem
+		err := rows.Scan(
+			&item.Id,
+			&item.ReservationId,
+			&item.InventoryItemId,
+			&item.Quantity,
+			&item.QuantityBackordered,
+			&item.QuantityReleased,
+			&item.QuantityFulfilled,
+			&item.CreatedAt,
+		)
+		if err != nil {
+			return nil, models.HandleDBError(ctx, err, "inventory.store.InventoryReservationItemsBackordered", tx)
+		}
+		items = append(items, &item)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, models.HandleDBError(ctx, err, "inventory.store.InventoryReservationItemsBackordered", tx)
+	}
+
+	return items, nil
+}
+
+// InventoryReservationItemAllocate moves backordered units of a reservation item into its reserved quantity,
+// it returns allocated = false if the item has fewer units backordered than quantity
+func (is *InventoryStore) InventoryReservationItemAllocate(ctx *models.Context, tx pgx.Tx, id string, quantity int32) (bool, *models.DBError) {
+	stmt := `
+		UPDATE inventory_reservation_items
+		SET quantity = quantity + $1, quantity_backordered = quantity_backordered - $1
+		WHERE id = $2 AND quantity_backordered >= $1
+  `
+
+	result, err := tx.Exec(ctx.Ctx(), stmt, quantity, id)
+	if err != nil {
+		return false, models.HandleDBError(ctx, err, "inventory.store.InventoryReservationItemAllocate", tx)
+	}
+
+	return result.RowsAffected() > 0, nil
+}
+
+// InventoryReservationItemBackorderRelease releases backordered units of a reservation item, it returns
+// released = false if the item has fewer units backordered than quantity
+func (is *InventoryStore) InventoryReservationItemBackorderRelease(ctx *models.Context, tx pgx.Tx, id string, quantity int32) (bool, *models.DBError) {
+	stmt := `
+		UPDATE inventory_reservation_items
+		SET quantity_backordered = quantity_backordered - $1, quantity_released = quantity_released + $1
+		WHERE id = $2 AND quantity_backordered >= $1
+  `
+
+	result, err := tx.Exec(ctx.Ctx(), stmt, quantity, id)
+	if err != nil {
+		return false, models.HandleDBError(ctx, err, "inventory.store.InventoryReservationItemBackorderRelease", tx)
+	}
+
+	return result.RowsAffected() > 0, nil
+}
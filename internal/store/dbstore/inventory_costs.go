@@ -0,0 +1,249 @@
+package dbstore
+
+import (
+	intModels "github.com/ahmad-khatib0-org/megacommerce-inventory/pkg/models"
+	pb "github.com/ahmad-khatib0-org/megacommerce-proto/gen/go/inventory/v1"
+	"github.com/ahmad-khatib0-org/megacommerce-shared-go/pkg/models"
+	"github.com/jackc/pgx/v5"
+)
+
+// InventoryCostLayerCreate creates a new cost layer, the units of an item received at the same unit cost
+func (is *InventoryStore) InventoryCostLayerCreate(ctx *models.Context, tx pgx.Tx, params *pb.InventoryCostLayer) *models.DBError {
+	stmt := `
+		INSERT INTO inventory_cost_layers (
+			id,
+			inventory_item_id,
+			movement_id,
+			quantity,
+			quantity_remaining,
+			unit_cost,
+			created_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7)
+  `
+
+	_, err := tx.Exec(
+		ctx.Ctx(),
+		stmt,
+		params.Id,
+		params.InventoryItemId,
+		params.MovementId,
+		params.Quantity,
+		params.QuantityRemaining,
+		params.UnitCost,
+		params.CreatedAt,
+	)
+
+	return models.HandleDBError(ctx, err, "inventory.store.InventoryCostLayerCreate", tx)
+}
+
+// InventoryCostLayersOpen locks and returns the cost layers of an inventory item that still have
+// units remaining, first in first out
+func (is *InventoryStore) InventoryCostLayersOpen(ctx *models.Context, tx pgx.Tx, inventoryItemID string) ([]*pb.InventoryCostLayer, *models.DBError) {
+	stmt := `
+		SELECT
+			id,
+			inventory_item_id,
+			movement_id,
+			quantity,
+			quantity_remaining,
+			unit_cost,
+			created_at
+		FROM inventory_cost_layers
+		WHERE inventory_item_id = $1 AND quantity_remaining > 0
+		ORDER BY created_at, id
+		FOR UPDATE
+  `
+
+	rows, err := tx.Query(ctx.Ctx(), stmt, inventoryItemID)
+	if err != nil {
+		return nil, models.HandleDBError(ctx, err, "inventory.store.InventoryCostLayersOpen", tx)
+	}
+	defer rows.Close()
+
+	result := make([]*pb.InventoryCostLayer, 0)
+	for rows.Next() {
+		var cl pb.InventoryCostLayer
+		err := rows.Scan(
+			&cl.Id,
+			&cl.InventoryItemId,
+			&cl.MovementId,
+			&cl.Quantity,
+			&cl.QuantityRemaining,
+			&cl.UnitCost,
+			&cl.CreatedAt,
+		)
+		if err != nil {
+			return nil, models.HandleDBError(ctx, err, "inventory.store.InventoryCostLayersOpen", tx)
+		}
+		result = append(result, &cl)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, models.HandleDBError(ctx, err, "inventory.store.InventoryCostLayersOpen", tx)
+	}
+
+	return result, nil
+}
+
+// InventoryCostLayerConsume takes quantity units out of a cost layer, it returns
+// consumed = false if the layer doesn't have that many units remaining
+func (is *InventoryStore) InventoryCostLayerConsume(ctx *models.Context, tx pgx.Tx, id string, quantity int32) (bool, *models.DBError) {
+	stmt := `
+		UPDATE inventory_cost_layers
+		SET quantity_remaining = quantity_remaining - $1
+		WHERE id = $2 AND quantity_remaining >= $1
+  `
+
+	result, err := tx.Exec(ctx.Ctx(), stmt, quantity, id)
+	if err != nil {
+		return false, models.HandleDBError(ctx, err, "inventory.store.InventoryCostLayerConsume", tx)
+	}
+
+	return result.RowsAffected() > 0, nil
+}
+
+// InventoryCostIssueCreate records the cost of units that left an inventory item
+func (is *InventoryStore) InventoryCostIssueCreate(ctx *models.Context, tx pgx.Tx, params *pb.InventoryCostIssue) *models.DBError {
+	stmt := `
+		INSERT INTO inventory_cost_issues (
+			id,
+			inventory_item_id,
+			reference_id,
+			movement_type,
+			quantity,
+			cost_fifo,
+			cost_average,
+			sold,
+			created_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+  `
+
+	_, err := tx.Exec(
+		ctx.Ctx(),
+		stmt,
+		params.Id,
+		params.InventoryItemId,
+		params.ReferenceId,
+		params.MovementType,
+		params.Quantity,
+		params.CostFifo,
+		params.CostAverage,
+		params.Sold,
+		params.CreatedAt,
+	)
+
+	return models.HandleDBError(ctx, err, "inventory.store.InventoryCostIssueCreate", tx)
+}
+
+// InventoryCostIssued is the units of an inventory item that the issues of a movement type took, and
+// what they cost first in first out
+func (is *InventoryStore) InventoryCostIssued(ctx *models.Context, tx pgx.Tx, inventoryItemID string, movementType string) (quantity int64, value int64, errDB *models.DBError) {
+	stmt := `
+		SELECT COALESCE(SUM(quantity), 0), COALESCE(SUM(cost_fifo), 0)
+		FROM inventory_cost_issues
+		WHERE inventory_item_id = $1 AND movement_type = $2
+  `
+
+	if err := tx.QueryRow(ctx.Ctx(), stmt, inventoryItemID, movementType).Scan(&quantity, &value); err != nil {
+		return 0, 0, models.HandleDBError(ctx, err, "inventory.store.InventoryCostIssued", tx)
+	}
+
+	return quantity, value, nil
+}
+
+// InventoryCostBalance is the units of an inventory item that the cost layers received and the issues
+// didn't take, and what they're worth under the weighted average method
+func (is *InventoryStore) InventoryCostBalance(ctx *models.Context, tx pgx.Tx, inventoryItemID string) (quantity int64, value int64, errDB *models.DBError) {
+	stmt := `
+		SELECT
+			COALESCE((SELECT SUM(quantity) FROM inventory_cost_layers WHERE inventory_item_id = $1), 0)
+			- COALESCE((SELECT SUM(quantity) FROM inventory_cost_issues WHERE inventory_item_id = $1), 0),
+			COALESCE((SELECT SUM(quantity::bigint * unit_cost) FROM inventory_cost_layers WHERE inventory_item_id = $1), 0)
+			- COALESCE((SELECT SUM(cost_average) FROM inventory_cost_issues WHERE inventory_item_id = $1), 0)
+  `
+
+	if err := tx.QueryRow(ctx.Ctx(), stmt, inventoryItemID).Scan(&quantity, &value); err != nil {
+		return 0, 0, models.HandleDBError(ctx, err, "inventory.store.InventoryCostBalance", tx)
+	}
+
+	return quantity, value, nil
+}
+
+// InventoryValuation adds up the cost layers and the issues of the inventory items that match the filter as of
+// its date, the items that have never been costed are left out. The rows are ordered by location and sku
+func (is *InventoryStore) InventoryValuation(ctx *models.Context, filter *intModels.InventoryValuationFilter) ([]*intModels.InventoryValuationRow, *models.DBError) {
+	stmt := `
+		SELECT
+			ii.id,
+			ii.sku,
+			ii.product_id,
+			ii.variant_id,
+			COALESCE(ii.location_id, ''),
+			COALESCE(r.quantity, 0),
+			COALESCE(r.value, 0),
+			COALESCE(s.quantity, 0),
+			COALESCE(s.cost_fifo, 0),
+			COALESCE(s.cost_average, 0),
+			COALESCE(s.sold_fifo, 0),
+			COALESCE(s.sold_average, 0)
+		FROM inventory_items ii
+		LEFT JOIN (
+			SELECT inventory_item_id, SUM(quantity) AS quantity, SUM(quantity::bigint * unit_cost) AS value
+			FROM inventory_cost_layers
+			WHERE created_at <= $4
+			GROUP BY inventory_item_id
+		) r ON r.inventory_item_id = ii.id
+		LEFT JOIN (
+			SELECT
+				inventory_item_id,
+				SUM(quantity) AS quantity,
+				SUM(cost_fifo) AS cost_fifo,
+				SUM(cost_average) AS cost_average,
+				SUM(cost_fifo) FILTER (WHERE sold AND created_at >= $5) AS sold_fifo,
+				SUM(cost_average) FILTER (WHERE sold AND created_at >= $5) AS sold_average
+			FROM inventory_cost_issues
+			WHERE created_at <= $4
+			GROUP BY inventory_item_id
+		) s ON s.inventory_item_id = ii.id
+		WHERE ($1 = '' OR ii.seller_id = $1)
+			AND ($2 = '' OR ii.location_id = $2)
+			AND ($3 = '' OR ii.sku = $3)
+			AND (r.inventory_item_id IS NOT NULL OR s.inventory_item_id IS NOT NULL)
+		ORDER BY ii.location_id, ii.sku, ii.id
+  `
+
+	rows, err := is.db.Query(ctx.Ctx(), stmt, filter.SellerID, filter.LocationID, filter.Sku, filter.At, filter.From)
+	if err != nil {
+		return nil, models.HandleDBError(ctx, err, "inventory.store.InventoryValuation", nil)
+	}
+	defer rows.Close()
+
+	result := make([]*intModels.InventoryValuationRow, 0)
+	for rows.Next() {
+		var row intModels.InventoryValuationRow
+		err := rows.Scan(
+			&row.InventoryItemID,
+			&row.Sku,
+			&row.ProductID,
+			&row.VariantID,
+			&row.LocationID,
+			&row.QuantityReceived,
+			&row.ValueReceived,
+			&row.QuantityIssued,
+			&row.CostFifoIssued,
+			&row.CostAverageIssued,
+			&row.SoldCostFifo,
+			&row.SoldCostAverage,
+		)
+		if err != nil {
+			return nil, models.HandleDBError(ctx, err, "inventory.store.InventoryValuation", nil)
+		}
+		result = append(result, &row)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, models.HandleDBError(ctx, err, "inventory.store.InventoryValuation", nil)
+	}
+
+	return result, nil
+}
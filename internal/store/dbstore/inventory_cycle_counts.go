@@ -0,0 +1,237 @@
+package dbstore
+
+import (
+	pb "github.com/ahmad-khatib0-org/megacommerce-proto/gen/go/inventory/v1"
+	"github.com/ahmad-khatib0-org/megacommerce-shared-go/pkg/models"
+	"github.com/ahmad-khatib0-org/megacommerce-shared-go/pkg/utils"
+	"github.com/jackc/pgx/v5"
+)
+
+// InventoryCycleCountCreate creates a new cycle count
+func (is *InventoryStore) InventoryCycleCountCreate(ctx *models.Context, tx pgx.Tx, params *pb.InventoryCycleCount) *models.DBError {
+	stmt := `
+		INSERT INTO inventory_cycle_counts (
+			id,
+			count_number,
+			seller_id,
+			location_id,
+			status,
+			freeze,
+			note,
+			review_note,
+			created_at,
+			updated_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+  `
+
+	_, err := tx.Exec(
+		ctx.Ctx(),
+		stmt,
+		params.Id,
+		params.CountNumber,
+		params.SellerId,
+		params.LocationId,
+		params.Status,
+		params.Freeze,
+		params.Note,
+		params.ReviewNote,
+		params.CreatedAt,
+		params.UpdatedAt,
+	)
+
+	return models.HandleDBError(ctx, err, "inventory.store.InventoryCycleCountCreate", tx)
+}
+
+// InventoryCycleCountGetByNumber gets a cycle count by its number, the cycle count row
+// is locked until the end of tx if tx is not nil
+func (is *InventoryStore) InventoryCycleCountGetByNumber(ctx *models.Context, tx pgx.Tx, countNumber string) (*pb.InventoryCycleCount, *models.DBError) {
+	stmt := `
+		SELECT
+			id,
+			count_number,
+			seller_id,
+			location_id,
+			status,
+			freeze,
+			note,
+			review_note,
+			created_at,
+			updated_at
+		FROM inventory_cycle_counts
+		WHERE count_number = $1
+  `
+
+	var cc pb.InventoryCycleCount
+	var updatedAt int64
+	var row pgx.Row
+	if tx != nil {
+		row = tx.QueryRow(ctx.Context, stmt+" FOR UPDATE", countNumber)
+	} else {
+		row = is.db.QueryRow(ctx.Context, stmt, countNumber)
+	}
+	err := row.Scan(
+		&cc.Id,
+		&cc.CountNumber,
+		&cc.SellerId,
+		&cc.LocationId,
+		&cc.Status,
+		&cc.Freeze,
+		&cc.Note,
+		&cc.ReviewNote,
+		&cc.CreatedAt,
+		&updatedAt,
+	)
+	if err != nil {
+		return nil, models.HandleDBError(ctx, err, "inventory.store.InventoryCycleCountGetByNumber", tx)
+	}
+
+	if updatedAt > 0 {
+		cc.UpdatedAt = &updatedAt
+	}
+
+	return &cc, nil
+}
+
+// InventoryCycleCountUpdateStatus updates the status of a cycle count, and the note of its review if it's not nil
+func (is *InventoryStore) InventoryCycleCountUpdateStatus(ctx *models.Context, tx pgx.Tx, id string, status string, reviewNote *string) *models.DBError {
+	stmt := `
+		UPDATE inventory_cycle_counts
+		SET status = $1, review_note = COALESCE($2, review_note), updated_at = $3
+		WHERE id = $4
+  `
+
+	_, err := tx.Exec(ctx.Ctx(), stmt, status, reviewNote, utils.TimeGetMillis(), id)
+
+	return models.HandleDBError(ctx, err, "inventory.store.InventoryCycleCountUpdateStatus", tx)
+}
+
+// InventoryCycleCountItemCreate creates a new cycle count item
+func (is *InventoryStore) InventoryCycleCountItemCreate(ctx *models.Context, tx pgx.Tx, params *pb.InventoryCycleCountItem) *models.DBError {
+	stmt := `
+		INSERT INTO inventory_cycle_count_items (
+			id,
+			cycle_count_id,
+			inventory_item_id,
+			sku,
+			quantity_expected,
+			quantity_reserved,
+			quantity_counted,
+			counted_at,
+			created_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+  `
+
+	_, err := tx.Exec(
+		ctx.Ctx(),
+		stmt,
+		params.Id,
+		params.CycleCountId,
+		params.InventoryItemId,
+		params.Sku,
+		params.QuantityExpected,
+		params.QuantityReserved,
+		params.QuantityCounted,
+		params.CountedAt,
+		params.CreatedAt,
+	)
+
+	return models.HandleDBError(ctx, err, "inventory.store.InventoryCycleCountItemCreate", tx)
+}
+
+// InventoryCycleCountItemsGetByCycleCountID gets all items of a cycle count,
+// you can pass nil for the tx argument, and a normal db query will be used
+func (is *InventoryStore) InventoryCycleCountItemsGetByCycleCountID(ctx *models.Context, tx pgx.Tx, cycleCountID string) ([]*pb.InventoryCycleCountItem, *models.DBError) {
+	stmt := `
+		SELECT
+			id,
+			cycle_count_id,
+			inventory_item_id,
+			sku,
+			quantity_expected,
+			quantity_reserved,
+			quantity_counted,
+			counted_at,
+			created_at
+		FROM inventory_cycle_count_items
+		WHERE cycle_count_id = $1
+		ORDER BY sku
+  `
+
+	var rows pgx.Rows
+	var err error
+	if tx != nil {
+		rows, err = tx.Query(ctx.Context, stmt, cycleCountID)
+	} else {
+		rows, err = is.db.Query(ctx.Context, stmt, cycleCountID)
+	}
+	if err != nil {
+		return nil, models.HandleDBError(ctx, err, "inventory.store.InventoryCycleCountItemsGetByCycleCountID", tx)
+	}
+	defer rows.Close()
+
+	var items []*pb.InventoryCycleCountItem
+	for rows.Next() {
+		var item pb.InventoryCycleCountItem
+		err := rows.Scan(
+			&item.Id,
+			&item.CycleCountId,
+			&item.InventoryItemId,
+			&item.Sku,
+			&item.QuantityExpected,
+			&item.QuantityReserved,
+			&item.QuantityCounted,
+			&item.CountedAt,
+			&item.CreatedAt,
+		)
+		if err != nil {
+			return nil, models.HandleDBError(ctx, err, "inventory.store.InventoryCycleCountItemsGetByCycleCountID", tx)
+		}
+		items = append(items, &item)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, models.HandleDBError(ctx, err, "inventory.store.InventoryCycleCountItemsGetByCycleCountID", tx)
+	}
+
+	return items, nil
+}
+
+// InventoryCycleCountItemCount records the counted units of a cycle count item, along with the on hand
+// and the reserved units that the system had for the item when it was counted
+func (is *InventoryStore) InventoryCycleCountItemCount(ctx *models.Context, tx pgx.Tx, id string, expected int32, reserved int32, counted int32) *models.DBError {
+	stmt := `
+		UPDATE inventory_cycle_count_items
+		SET quantity_expected = $1, quantity_reserved = $2, quantity_counted = $3, counted_at = $4
+		WHERE id = $5
+  `
+
+	_, err := tx.Exec(ctx.Ctx(), stmt, expected, reserved, counted, utils.TimeGetMillis(), id)
+
+	return models.HandleDBError(ctx, err, "inventory.store.InventoryCycleCountItemCount", tx)
+}
+
+// InventoryCycleCountItemActive tells whether an inventory item is part of a cycle count that is still open or
+// waiting for its review, only the cycle counts that freeze their items are considered if frozenOnly is set
+func (is *InventoryStore) InventoryCycleCountItemActive(ctx *models.Context, tx pgx.Tx, inventoryItemID string, frozenOnly bool) (bool, *models.DBError) {
+	stmt := `
+		SELECT EXISTS (
+			SELECT 1
+			FROM inventory_cycle_count_items i
+			JOIN inventory_cycle_counts c ON c.id = i.cycle_count_id
+			WHERE i.inventory_item_id = $1 AND c.status IN ('OPEN', 'COUNTED') AND (c.freeze OR NOT $2)
+		)
+  `
+
+	var active bool
+	var err error
+	if tx != nil {
+		err = tx.QueryRow(ctx.Ctx(), stmt, inventoryItemID, frozenOnly).Scan(&active)
+	} else {
+		err = is.db.QueryRow(ctx.Ctx(), stmt, inventoryItemID, frozenOnly).Scan(&active)
+	}
+	if err != nil {
+		return false, models.HandleDBError(ctx, err, "inventory.store.InventoryCycleCountItemActive", tx)
+	}
+
+	return active, nil
+}
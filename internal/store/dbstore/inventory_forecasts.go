@@ -0,0 +1,44 @@
+package dbstore
+
+import (
+	intModels "github.com/ahmad-khatib0-org/megacommerce-inventory/pkg/models"
+	"github.com/ahmad-khatib0-org/megacommerce-shared-go/pkg/models"
+)
+
+// InventoryMovementsDaily sums the units that inventory items moved per day (UTC) since a date, by movement type and by
+// whether the movements settled a reservation, ordered by item and day
+func (is *InventoryStore) InventoryMovementsDaily(ctx *models.Context, inventoryItemIDs []string, from int64) ([]*intModels.InventoryMovementDay, *models.DBError) {
+	stmt := `
+		SELECT
+			m.inventory_item_id,
+			(m.created_at / $3) * $3 AS day,
+			m.movement_type,
+			EXISTS (SELECT 1 FROM inventory_reservations r WHERE r.id = m.reference_id) AS fulfilment,
+			SUM(m.quantity)
+		FROM inventory_movements m
+		WHERE m.inventory_item_id = ANY($1) AND m.created_at >= $2
+		GROUP BY 1, 2, 3, 4
+		ORDER BY 1, 2
+  `
+
+	rows, err := is.db.Query(ctx.Ctx(), stmt, inventoryItemIDs, from, intModels.InventoryDayMillis)
+	if err != nil {
+		return nil, models.HandleDBError(ctx, err, "inventory.store.InventoryMovementsDaily", nil)
+	}
+	defer rows.Close()
+
+	result := make([]*intModels.InventoryMovementDay, 0)
+	for rows.Next() {
+		var d intModels.InventoryMovementDay
+		if err := rows.Scan(&d.InventoryItemID, &d.Day, &d.MovementType, &d.Fulfilment, &d.Quantity); err != nil {
+			return nil, models.HandleDBError(ctx, err, "inventory.store.InventoryMovementsDaily", nil)
+		}
+		result = append(result, &d)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, models.HandleDBError(ctx, err, "inventory.store.InventoryMovementsDaily", nil)
+	}
+
+	return result, nil
+}
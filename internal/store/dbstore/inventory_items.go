@@ -1,56 +1,103 @@
 package dbstore
 
 import (
+	intModels "github.com/ahmad-khatib0-org/megacommerce-inventory/pkg/models"
 	pb "github.com/ahmad-khatib0-org/megacommerce-proto/gen/go/inventory/v1"
 	"github.com/ahmad-khatib0-org/megacommerce-shared-go/pkg/models"
 	"github.com/ahmad-khatib0-org/megacommerce-shared-go/pkg/utils"
 	"github.com/jackc/pgx/v5"
 )
 
-// InventoryItemGetByProductVariant gets an inventory item by product and variant
-func (is *InventoryStore) InventoryItemGetByProductVariant(ctx *models.Context, tx pgx.Tx, productID string, variantID string) (*pb.InventoryItem, *models.DBError) {
+// InventoryItemGetByProductVariant gets an inventory item by product and variant, limited to the given seller
+// and location unless sellerID or locationID are empty, the item is locked until the end of tx, or a normal
+// db query is used if tx is nil. It fails with intModels.ErrInventoryItemAmbiguous if the product is stocked
+// by more than one item, as it is by every location that it was transferred or purchased to
+func (is *InventoryStore) InventoryItemGetByProductVariant(ctx *models.Context, tx pgx.Tx, sellerID string, productID string, variantID string, locationID string) (*pb.InventoryItem, *models.DBError) {
 	stmt := `
 		SELECT 
 			id, 
+			seller_id,
 			product_id, 
 			variant_id, 
 			sku, 
 			quantity_available, 
 			quantity_reserved, 
-			quantity_total, 
+			quantity_total,
+			quantity_in_transit,
+			quantity_quarantined,
+			quantity_damaged,
+			quantity_on_hold,
 			location_id, 
 			metadata, 
 			created_at, 
 			updated_at
 		FROM inventory_items 
-		WHERE product_id = $1 AND variant_id = $2
-		FOR UPDATE;
+		WHERE product_id = $1 AND variant_id = $2 AND ($3 = '' OR seller_id = $3) AND ($4 = '' OR location_id = $4)
+		LIMIT 2
   `
 
-	var ii pb.InventoryItem
-	var updatedAt int64
-	err := tx.QueryRow(ctx.Ctx(), stmt, productID, variantID).Scan(
-		&ii.Id,
-		&ii.ProductId,
-		&ii.VariantId,
-		&ii.Sku,
-		&ii.QuantityAvailable,
-		&ii.QuantityReserved,
-		&ii.QuantityTotal,
-		&ii.LocationId,
-		&ii.Metadata,
-		&ii.CreatedAt,
-		&updatedAt,
-	)
+	return is.inventoryItemGetOne(ctx, tx, "inventory.store.InventoryItemGetByProductVariant", stmt, productID, variantID, sellerID, locationID)
+}
+
+// inventoryItemGetOne runs an inventory item lookup that must match a single item, stmt must select
+// at most 2 rows so that a second match can be detected, the item is locked if tx is not nil
+func (is *InventoryStore) inventoryItemGetOne(ctx *models.Context, tx pgx.Tx, path string, stmt string, args ...any) (*pb.InventoryItem, *models.DBError) {
+	var rows pgx.Rows
+	var err error
+	if tx != nil {
+		rows, err = tx.Query(ctx.Ctx(), stmt+" FOR UPDATE", args...)
+	} else {
+		rows, err = is.db.Query(ctx.Ctx(), stmt, args...)
+	}
 	if err != nil {
-		return nil, models.HandleDBError(ctx, err, "inventory.store.InventoryItemGetByProductVariant", tx)
+		return nil, models.HandleDBError(ctx, err, path, tx)
 	}
+	defer rows.Close()
+
+	result := make([]*pb.InventoryItem, 0, 2)
+	for rows.Next() {
+		var ii pb.InventoryItem
+		var updatedAt int64
+		err := rows.Scan(
+			&ii.Id,
+			&ii.SellerId,
+			&ii.ProductId,
+			&ii.VariantId,
+			&ii.Sku,
+			&ii.QuantityAvailable,
+			&ii.QuantityReserved,
+			&ii.QuantityTotal,
+			&ii.QuantityInTransit,
+			&ii.QuantityQuarantined,
+			&ii.QuantityDamaged,
+			&ii.QuantityOnHold,
+			&ii.LocationId,
+			&ii.Metadata,
+			&ii.CreatedAt,
+			&updatedAt,
+		)
+		if err != nil {
+			return nil, models.HandleDBError(ctx, err, path, tx)
+		}
 
-	if updatedAt > 0 {
-		ii.UpdatedAt = &updatedAt
+		if updatedAt > 0 {
+			ii.UpdatedAt = &updatedAt
+		}
+		result = append(result, &ii)
 	}
 
-	return &ii, nil
+	if err := rows.Err(); err != nil {
+		return nil, models.HandleDBError(ctx, err, path, tx)
+	}
+
+	switch len(result) {
+	case 0:
+		return nil, models.HandleDBError(ctx, pgx.ErrNoRows, path, tx)
+	case 1:
+		return result[0], nil
+	default:
+		return nil, &models.DBError{Err: intModels.ErrInventoryItemAmbiguous, Msg: "more than one inventory item matches, a seller or a location is required"}
+	}
 }
 
 // InventoryItemCreate creates a new inventory item
@@ -58,29 +105,33 @@ func (is *InventoryStore) InventoryItemCreate(ctx *models.Context, tx pgx.Tx, pa
 	stmt := `
 		INSERT INTO inventory_items (
 			id, 
+			seller_id,
 			product_id, 
 			variant_id, 
 			sku, 
 			quantity_available, 
 			quantity_reserved, 
-			quantity_total, 
+			quantity_total,
+			quantity_in_transit,
 			location_id, 
 			metadata, 
 			created_at, 
 			updated_at
-		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
   `
 
 	_, err := tx.Exec(
 		ctx.Ctx(),
 		stmt,
 		params.Id,
+		params.SellerId,
 		params.ProductId,
 		params.VariantId,
 		params.Sku,
 		params.QuantityAvailable,
 		params.QuantityReserved,
 		params.QuantityTotal,
+		params.QuantityInTransit,
 		params.LocationId,
 		params.Metadata,
 		params.CreatedAt,
@@ -91,7 +142,7 @@ func (is *InventoryStore) InventoryItemCreate(ctx *models.Context, tx pgx.Tx, pa
 }
 
 // InventoryItemReserve reserves inventory for an item, and returns sufficient = false
-// if the requested quantity can't be reserved (quantity_available is not enough)
+// if the requested quantity can't be reserved (quantity_available is not enough, or quantity isn't positive)
 func (is *InventoryStore) InventoryItemReserve(ctx *models.Context, tx pgx.Tx, id string, quantity int) (bool, *models.DBError) {
 	stmt := `
 			UPDATE inventory_items 
@@ -99,7 +150,7 @@ func (is *InventoryStore) InventoryItemReserve(ctx *models.Context, tx pgx.Tx, i
 					quantity_reserved = quantity_reserved + $1,
 					quantity_available = quantity_available - $1,
 					updated_at = $2
-			WHERE id = $3 AND quantity_available >= $1
+			WHERE id = $3 AND $1 > 0 AND quantity_available >= $1
     `
 
 	result, err := tx.Exec(
@@ -126,14 +177,15 @@ func (is *InventoryStore) InventoryItemReserve(ctx *models.Context, tx pgx.Tx, i
 //
 // quantity we are about to release is bigger than the current quantity_reserved value!
 func (is *InventoryStore) InventoryItemRelease(ctx *models.Context, tx pgx.Tx, id string, quantity int32) (bool, *models.DBError) {
-	// AND quantity_reserved >= $1 to prevent making quantity_reserved less than 0
+	// AND quantity_reserved >= $1 to prevent making quantity_reserved less than 0, and $1 > 0 so a
+	// negative quantity can't add units back
 	stmt := `
 			UPDATE inventory_items 
 			SET 
 				quantity_reserved = quantity_reserved - $1,
 				quantity_available = quantity_available + $1,
 				updated_at = $2
-			WHERE id = $3 AND quantity_reserved >= $1
+			WHERE id = $3 AND $1 > 0 AND quantity_reserved >= $1
     `
 
 	result, err := tx.Exec(
@@ -154,6 +206,44 @@ func (is *InventoryStore) InventoryItemRelease(ctx *models.Context, tx pgx.Tx, i
 	return true, nil
 }
 
+// InventoryItemFulfill ships reserved inventory of an item, the units leave both quantity_reserved
+// and quantity_total, it returns fulfilled = false if quantity_reserved is less than quantity, or if
+// quantity isn't positive
+func (is *InventoryStore) InventoryItemFulfill(ctx *models.Context, tx pgx.Tx, id string, quantity int32) (bool, *models.DBError) {
+	stmt := `
+			UPDATE inventory_items 
+			SET 
+				quantity_reserved = quantity_reserved - $1,
+				quantity_total = quantity_total - $1,
+				updated_at = $2
+			WHERE id = $3 AND $1 > 0 AND quantity_reserved >= $1
+    `
+
+	result, err := tx.Exec(ctx.Ctx(), stmt, quantity, utils.TimeGetMillis(), id)
+	if err != nil {
+		return false, models.HandleDBError(ctx, err, "inventory.store.InventoryItemFulfill", tx)
+	}
+
+	return result.RowsAffected() > 0, nil
+}
+
+// InventoryItemInTransitAdd adds quantity (which may be negative) to the units on their way to an item,
+// it returns added = false if that would make quantity_in_transit negative
+func (is *InventoryStore) InventoryItemInTransitAdd(ctx *models.Context, tx pgx.Tx, id string, quantity int32) (bool, *models.DBError) {
+	stmt := `
+			UPDATE inventory_items 
+			SET quantity_in_transit = quantity_in_transit + $1, updated_at = $2
+			WHERE id = $3 AND quantity_in_transit + $1 >= 0
+    `
+
+	result, err := tx.Exec(ctx.Ctx(), stmt, quantity, utils.TimeGetMillis(), id)
+	if err != nil {
+		return false, models.HandleDBError(ctx, err, "inventory.store.InventoryItemInTransitAdd", tx)
+	}
+
+	return result.RowsAffected() > 0, nil
+}
+
 // InventoryItemUpdate updates an inventory item
 func (is *InventoryStore) InventoryItemUpdate(ctx *models.Context, tx pgx.Tx, id string, quantityTotal int, quantityReserved int32, quantityAvailable int) *models.DBError {
 	stmt := `
@@ -179,26 +269,62 @@ func (is *InventoryStore) InventoryItemUpdate(ctx *models.Context, tx pgx.Tx, id
 	return models.HandleDBError(ctx, err, "inventory.store.InventoryItemUpdate", tx)
 }
 
-// InventoryItemGetByIDs gets the inventory items for the given ids
-func (is *InventoryStore) InventoryItemGetByIDs(ctx *models.Context, ids []string) ([]*pb.InventoryItem, *models.DBError) {
+// InventoryItemBucketsUpdate updates the stock buckets of an inventory item, quantity_total is the sellable
+// bucket that quantity_available is computed from, the other buckets are never sold
+func (is *InventoryStore) InventoryItemBucketsUpdate(ctx *models.Context, tx pgx.Tx, id string, quantityTotal int32, quantityAvailable int32, quantityQuarantined int32, quantityDamaged int32, quantityOnHold int32) *models.DBError {
+	stmt := `
+		UPDATE inventory_items 
+		SET 
+			quantity_total = $1,
+			quantity_available = $2,
+			quantity_quarantined = $3,
+			quantity_damaged = $4,
+			quantity_on_hold = $5,
+			updated_at = $6
+		WHERE id = $7
+  `
+
+	_, err := tx.Exec(
+		ctx.Ctx(),
+		stmt,
+		quantityTotal,
+		quantityAvailable,
+		quantityQuarantined,
+		quantityDamaged,
+		quantityOnHold,
+		utils.TimeGetMillis(),
+		id,
+	)
+
+	return models.HandleDBError(ctx, err, "inventory.store.InventoryItemBucketsUpdate", tx)
+}
+
+// InventoryItemGetByIDs gets the inventory items for the given ids,
+// limited to the given seller unless sellerID is empty
+func (is *InventoryStore) InventoryItemGetByIDs(ctx *models.Context, sellerID string, ids []string) ([]*pb.InventoryItem, *models.DBError) {
 	stmt := `
 		SELECT 
 			id, 
+			seller_id,
 			product_id,
 			variant_id, 
 			sku,
 			quantity_available, 
 			quantity_reserved, 
 			quantity_total,
+			quantity_in_transit,
+			quantity_quarantined,
+			quantity_damaged,
+			quantity_on_hold,
 			location_id,
 			metadata,
 			created_at,
 			updated_at
 		FROM inventory_items 
-		WHERE id = ANY($1)
+		WHERE id = ANY($1) AND ($2 = '' OR seller_id = $2)
   `
 
-	rows, err := is.db.Query(ctx.Ctx(), stmt, ids)
+	rows, err := is.db.Query(ctx.Ctx(), stmt, ids, sellerID)
 	if err != nil {
 		return nil, models.HandleDBError(ctx, err, "inventory.store.InventoryItemGetByIDs", nil)
 	}
@@ -210,12 +336,17 @@ func (is *InventoryStore) InventoryItemGetByIDs(ctx *models.Context, ids []strin
 		var updatedAt int64
 		err := rows.Scan(
 			&ii.Id,
+			&ii.SellerId,
 			&ii.ProductId,
 			&ii.VariantId,
 			&ii.Sku,
 			&ii.QuantityAvailable,
 			&ii.QuantityReserved,
 			&ii.QuantityTotal,
+			&ii.QuantityInTransit,
+			&ii.QuantityQuarantined,
+			&ii.QuantityDamaged,
+			&ii.QuantityOnHold,
 			&ii.LocationId,
 			&ii.Metadata,
 			&ii.CreatedAt,
@@ -233,3 +364,248 @@ func (is *InventoryStore) InventoryItemGetByIDs(ctx *models.Context, ids []strin
 
 	return result, nil
 }
+
+// InventoryItemsLockByIDs locks and returns the inventory items for the given ids, the rows
+// are locked in the order of their ids, so concurrent callers can't deadlock on each other
+func (is *InventoryStore) InventoryItemsLockByIDs(ctx *models.Context, tx pgx.Tx, ids []string) ([]*pb.InventoryItem, *models.DBError) {
+	stmt := `
+		SELECT
+			id,
+			seller_id,
+			product_id,
+			variant_id,
+			sku,
+			quantity_available,
+			quantity_reserved,
+			quantity_total,
+			quantity_in_transit,
+			quantity_quarantined,
+			quantity_damaged,
+			quantity_on_hold,
+			location_id,
+			metadata,
+			created_at,
+			updated_at
+		FROM inventory_items
+		WHERE id = ANY($1)
+		ORDER BY id
+		FOR UPDATE
+  `
+
+	rows, err := tx.Query(ctx.Ctx(), stmt, ids)
+	if err != nil {
+		return nil, models.HandleDBError(ctx, err, "inventory.store.InventoryItemsLockByIDs", tx)
+	}
+	defer rows.Close()
+
+	result := make([]*pb.InventoryItem, 0, len(ids))
+	for rows.Next() {
+		var ii pb.InventoryItem
+		var updatedAt int64
+		err := rows.Scan(
+			&ii.Id,
+			&ii.SellerId,
+			&ii.ProductId,
+			&ii.VariantId,
+			&ii.Sku,
+			&ii.QuantityAvailable,
+			&ii.QuantityReserved,
+			&ii.QuantityTotal,
+			&ii.QuantityInTransit,
+			&ii.QuantityQuarantined,
+			&ii.QuantityDamaged,
+			&ii.QuantityOnHold,
+			&ii.LocationId,
+			&ii.Metadata,
+			&ii.CreatedAt,
+			&updatedAt,
+		)
+		if err != nil {
+			return nil, models.HandleDBError(ctx, err, "inventory.store.InventoryItemsLockByIDs", tx)
+		}
+
+		if updatedAt > 0 {
+			ii.UpdatedAt = &updatedAt
+		}
+		result = append(result, &ii)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, models.HandleDBError(ctx, err, "inventory.store.InventoryItemsLockByIDs", tx)
+	}
+
+	return result, nil
+}
+
+// InventoryItemGetBySku locks and returns the inventory item of a sku at a location,
+// an empty sellerID or locationID matches every seller or location, but the lookup fails
+// with intModels.ErrInventoryItemAmbiguous if the sku is stocked by more than one item
+func (is *InventoryStore) InventoryItemGetBySku(ctx *models.Context, tx pgx.Tx, sellerID string, sku string, locationID string) (*pb.InventoryItem, *models.DBError) {
+	stmt := `
+		SELECT 
+			id, 
+			seller_id,
+			product_id, 
+			variant_id, 
+			sku, 
+			quantity_available, 
+			quantity_reserved, 
+			quantity_total,
+			quantity_in_transit,
+			quantity_quarantined,
+			quantity_damaged,
+			quantity_on_hold,
+			location_id, 
+			metadata, 
+			created_at, 
+			updated_at
+		FROM inventory_items 
+		WHERE sku = $1 AND ($2 = '' OR location_id = $2) AND ($3 = '' OR seller_id = $3)
+		LIMIT 2
+  `
+
+	return is.inventoryItemGetOne(ctx, tx, "inventory.store.InventoryItemGetBySku", stmt, sku, locationID, sellerID)
+}
+
+// InventoryItemsList lists the inventory items ordered by id, starting after afterID,
+// an empty sellerID or locationID matches every seller or location
+func (is *InventoryStore) InventoryItemsList(ctx *models.Context, sellerID string, locationID string, afterID string, limit int) ([]*pb.InventoryItem, *models.DBError) {
+	stmt := `
+		SELECT 
+			id, 
+			seller_id,
+			product_id,
+			variant_id, 
+			sku,
+			quantity_available, 
+			quantity_reserved, 
+			quantity_total,
+			quantity_in_transit,
+			quantity_quarantined,
+			quantity_damaged,
+			quantity_on_hold,
+			location_id,
+			metadata,
+			created_at,
+			updated_at
+		FROM inventory_items 
+		WHERE id > $1 AND ($2 = '' OR seller_id = $2) AND ($3 = '' OR location_id = $3)
+		ORDER BY id
+		LIMIT $4
+  `
+
+	rows, err := is.db.Query(ctx.Ctx(), stmt, afterID, sellerID, locationID, limit)
+	if err != nil {
+		return nil, models.HandleDBError(ctx, err, "inventory.store.InventoryItemsList", nil)
+	}
+	defer rows.Close()
+
+	result := make([]*pb.InventoryItem, 0, limit)
+	for rows.Next() {
+		var ii pb.InventoryItem
+		var updatedAt int64
+		err := rows.Scan(
+			&ii.Id,
+			&ii.SellerId,
+			&ii.ProductId,
+			&ii.VariantId,
+			&ii.Sku,
+			&ii.QuantityAvailable,
+			&ii.QuantityReserved,
+			&ii.QuantityTotal,
+			&ii.QuantityInTransit,
+			&ii.QuantityQuarantined,
+			&ii.QuantityDamaged,
+			&ii.QuantityOnHold,
+			&ii.LocationId,
+			&ii.Metadata,
+			&ii.CreatedAt,
+			&updatedAt,
+		)
+		if err != nil {
+			return nil, models.HandleDBError(ctx, err, "inventory.store.InventoryItemsList", nil)
+		}
+
+		if updatedAt > 0 {
+			ii.UpdatedAt = &updatedAt
+		}
+		result = append(result, &ii)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, models.HandleDBError(ctx, err, "inventory.store.InventoryItemsList", nil)
+	}
+
+	return result, nil
+}
+
+// InventoryItemsGetBySku gets the inventory items of a sku at every location, or at a single location if
+// locationID is not empty, limited to the given seller unless sellerID is empty. The items aren't locked
+func (is *InventoryStore) InventoryItemsGetBySku(ctx *models.Context, sellerID string, sku string, locationID string) ([]*pb.InventoryItem, *models.DBError) {
+	stmt := `
+		SELECT 
+			id, 
+			seller_id,
+			product_id,
+			variant_id, 
+			sku,
+			quantity_available, 
+			quantity_reserved, 
+			quantity_total,
+			quantity_in_transit,
+			quantity_quarantined,
+			quantity_damaged,
+			quantity_on_hold,
+			location_id,
+			metadata,
+			created_at,
+			updated_at
+		FROM inventory_items 
+		WHERE sku = $1 AND ($2 = '' OR location_id = $2) AND ($3 = '' OR seller_id = $3)
+		ORDER BY location_id
+  `
+
+	rows, err := is.db.Query(ctx.Ctx(), stmt, sku, locationID, sellerID)
+	if err != nil {
+		return nil, models.HandleDBError(ctx, err, "inventory.store.InventoryItemsGetBySku", nil)
+	}
+	defer rows.Close()
+
+	result := make([]*pb.InventoryItem, 0)
+	for rows.Next() {
+		var ii pb.InventoryItem
+		var updatedAt int64
+		err := rows.Scan(
+			&ii.Id,
+			&ii.SellerId,
+			&ii.ProductId,
+			&ii.VariantId,
+			&ii.Sku,
+			&ii.QuantityAvailable,
+			&ii.QuantityReserved,
+			&ii.QuantityTotal,
+			&ii.QuantityInTransit,
+			&ii.QuantityQuarantined,
+			&ii.QuantityDamaged,
+			&ii.QuantityOnHold,
+			&ii.LocationId,
+			&ii.Metadata,
+			&ii.CreatedAt,
+			&updatedAt,
+		)
+		if err != nil {
+			return nil, models.HandleDBError(ctx, err, "inventory.store.InventoryItemsGetBySku", nil)
+		}
+
+		if updatedAt > 0 {
+			ii.UpdatedAt = &updatedAt
+		}
+		result = append(result, &ii)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, models.HandleDBError(ctx, err, "inventory.store.InventoryItemsGetBySku", nil)
+	}
+
+	return result, nil
+}
@@ -0,0 +1,225 @@
+package dbstore
+
+import (
+	pb "github.com/ahmad-khatib0-org/megacommerce-proto/gen/go/inventory/v1"
+	"github.com/ahmad-khatib0-org/megacommerce-shared-go/pkg/models"
+	"github.com/ahmad-khatib0-org/megacommerce-shared-go/pkg/utils"
+	"github.com/jackc/pgx/v5"
+)
+
+// InventoryKitCreate creates a new kit
+func (is *InventoryStore) InventoryKitCreate(ctx *models.Context, tx pgx.Tx, params *pb.InventoryKit) *models.DBError {
+	stmt := `
+		INSERT INTO inventory_kits (
+			id,
+			seller_id,
+			product_id,
+			variant_id,
+			sku,
+			created_at,
+			updated_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7)
+  `
+
+	_, err := tx.Exec(
+		ctx.Ctx(),
+		stmt,
+		params.Id,
+		params.SellerId,
+		params.ProductId,
+		params.VariantId,
+		params.Sku,
+		params.CreatedAt,
+		params.UpdatedAt,
+	)
+
+	return models.HandleDBError(ctx, err, "inventory.store.InventoryKitCreate", tx)
+}
+
+// InventoryKitUpdateSku sets the sku of a kit
+func (is *InventoryStore) InventoryKitUpdateSku(ctx *models.Context, tx pgx.Tx, id string, sku string) *models.DBError {
+	stmt := `UPDATE inventory_kits SET sku = $1, updated_at = $2 WHERE id = $3`
+
+	_, err := tx.Exec(ctx.Ctx(), stmt, sku, utils.TimeGetMillis(), id)
+
+	return models.HandleDBError(ctx, err, "inventory.store.InventoryKitUpdateSku", tx)
+}
+
+// InventoryKitGetByProductVariant gets a kit by product and variant, limited to the given seller unless
+// sellerID is empty, the kit is locked until the end of tx, or a normal db query is used if tx is nil
+func (is *InventoryStore) InventoryKitGetByProductVariant(ctx *models.Context, tx pgx.Tx, sellerID string, productID string, variantID string) (*pb.InventoryKit, *models.DBError) {
+	stmt := `
+		SELECT
+			id,
+			seller_id,
+			product_id,
+			variant_id,
+			sku,
+			created_at,
+			updated_at
+		FROM inventory_kits
+		WHERE product_id = $1 AND variant_id = $2 AND ($3 = '' OR seller_id = $3)
+  `
+
+	var kit pb.InventoryKit
+	var updatedAt int64
+	var row pgx.Row
+	if tx != nil {
+		row = tx.QueryRow(ctx.Ctx(), stmt+" FOR UPDATE", productID, variantID, sellerID)
+	} else {
+		row = is.db.QueryRow(ctx.Ctx(), stmt, productID, variantID, sellerID)
+	}
+	err := row.Scan(
+		&kit.Id,
+		&kit.SellerId,
+		&kit.ProductId,
+		&kit.VariantId,
+		&kit.Sku,
+		&kit.CreatedAt,
+		&updatedAt,
+	)
+	if err != nil {
+		return nil, models.HandleDBError(ctx, err, "inventory.store.InventoryKitGetByProductVariant", tx)
+	}
+
+	if updatedAt > 0 {
+		kit.UpdatedAt = &updatedAt
+	}
+
+	return &kit, nil
+}
+
+// InventoryKitGetByIDs gets the kits for the given ids
+func (is *InventoryStore) InventoryKitGetByIDs(ctx *models.Context, ids []string) ([]*pb.InventoryKit, *models.DBError) {
+	stmt := `
+		SELECT
+			id,
+			seller_id,
+			product_id,
+			variant_id,
+			sku,
+			created_at,
+			updated_at
+		FROM inventory_kits
+		WHERE id = ANY($1)
+  `
+
+	rows, err := is.db.Query(ctx.Ctx(), stmt, ids)
+	if err != nil {
+		return nil, models.HandleDBError(ctx, err, "inventory.store.InventoryKitGetByIDs", nil)
+	}
+	defer rows.Close()
+
+	result := make([]*pb.InventoryKit, 0, len(ids))
+	for rows.Next() {
+		var kit pb.InventoryKit
+		var updatedAt int64
+		err := rows.Scan(
+			&kit.Id,
+			&kit.SellerId,
+			&kit.ProductId,
+			&kit.VariantId,
+			&kit.Sku,
+			&kit.CreatedAt,
+			&updatedAt,
+		)
+		if err != nil {
+			return nil, models.HandleDBError(ctx, err, "inventory.store.InventoryKitGetByIDs", nil)
+		}
+
+		if updatedAt > 0 {
+			kit.UpdatedAt = &updatedAt
+		}
+		result = append(result, &kit)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, models.HandleDBError(ctx, err, "inventory.store.InventoryKitGetByIDs", nil)
+	}
+
+	return result, nil
+}
+
+// InventoryKitComponentCreate adds a component to a kit
+func (is *InventoryStore) InventoryKitComponentCreate(ctx *models.Context, tx pgx.Tx, params *pb.InventoryKitComponent) *models.DBError {
+	stmt := `
+		INSERT INTO inventory_kit_components (
+			id,
+			kit_id,
+			inventory_item_id,
+			quantity,
+			created_at
+		) VALUES ($1, $2, $3, $4, $5)
+  `
+
+	_, err := tx.Exec(
+		ctx.Ctx(),
+		stmt,
+		params.Id,
+		params.KitId,
+		params.InventoryItemId,
+		params.Quantity,
+		params.CreatedAt,
+	)
+
+	return models.HandleDBError(ctx, err, "inventory.store.InventoryKitComponentCreate", tx)
+}
+
+// InventoryKitComponentsDelete removes every component of a kit
+func (is *InventoryStore) InventoryKitComponentsDelete(ctx *models.Context, tx pgx.Tx, kitID string) *models.DBError {
+	stmt := `DELETE FROM inventory_kit_components WHERE kit_id = $1`
+
+	_, err := tx.Exec(ctx.Ctx(), stmt, kitID)
+
+	return models.HandleDBError(ctx, err, "inventory.store.InventoryKitComponentsDelete", tx)
+}
+
+// InventoryKitComponentsGet gets the components of a kit, ordered by inventory item,
+// you can pass nil for the tx argument, and a normal db query will be used
+func (is *InventoryStore) InventoryKitComponentsGet(ctx *models.Context, tx pgx.Tx, kitID string) ([]*pb.InventoryKitComponent, *models.DBError) {
+	stmt := `
+		SELECT
+			id,
+			kit_id,
+			inventory_item_id,
+			quantity,
+			created_at
+		FROM inventory_kit_components
+		WHERE kit_id = $1
+		ORDER BY inventory_item_id
+  `
+
+	var rows pgx.Rows
+	var err error
+	if tx != nil {
+		rows, err = tx.Query(ctx.Context, stmt, kitID)
+	} else {
+		rows, err = is.db.Query(ctx.Context, stmt, kitID)
+	}
+	if err != nil {
+		return nil, models.HandleDBError(ctx, err, "inventory.store.InventoryKitComponentsGet", tx)
+	}
+	defer rows.Close()
+
+	result := make([]*pb.InventoryKitComponent, 0)
+	for rows.Next() {
+		var kc pb.InventoryKitComponent
+		err := rows.Scan(
+			&kc.Id,
+			&kc.KitId,
+			&kc.InventoryItemId,
+			&kc.Quantity,
+			&kc.CreatedAt,
+		)
+		if err != nil {
+			return nil, models.HandleDBError(ctx, err, "inventory.store.InventoryKitComponentsGet", tx)
+		}
+		result = append(result, &kc)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, models.HandleDBError(ctx, err, "inventory.store.InventoryKitComponentsGet", tx)
+	}
+
+	return result, nil
+}
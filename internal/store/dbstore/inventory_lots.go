@@ -0,0 +1,300 @@
+package dbstore
+
+import (
+	pb "github.com/ahmad-khatib0-org/megacommerce-proto/gen/go/inventory/v1"
+	"github.com/ahmad-khatib0-org/megacommerce-shared-go/pkg/models"
+	"github.com/ahmad-khatib0-org/megacommerce-shared-go/pkg/utils"
+	"github.com/jackc/pgx/v5"
+)
+
+// InventoryLotCreate creates a new lot
+func (is *InventoryStore) InventoryLotCreate(ctx *models.Context, tx pgx.Tx, params *pb.InventoryLot) *models.DBError {
+	stmt := `
+		INSERT INTO inventory_lots (
+			id,
+			inventory_item_id,
+			lot_number,
+			expires_at,
+			quantity,
+			quantity_reserved,
+			created_at,
+			updated_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+  `
+
+	_, err := tx.Exec(
+		ctx.Ctx(),
+		stmt,
+		params.Id,
+		params.InventoryItemId,
+		params.LotNumber,
+		params.ExpiresAt,
+		params.Quantity,
+		params.QuantityReserved,
+		params.CreatedAt,
+		params.UpdatedAt,
+	)
+
+	return models.HandleDBError(ctx, err, "inventory.store.InventoryLotCreate", tx)
+}
+
+// InventoryLotGetByNumber gets a lot of an inventory item by its number and locks it until the end of tx
+func (is *InventoryStore) InventoryLotGetByNumber(ctx *models.Context, tx pgx.Tx, inventoryItemID string, lotNumber string) (*pb.InventoryLot, *models.DBError) {
+	stmt := `
+		SELECT
+			id,
+			inventory_item_id,
+			lot_number,
+			expires_at,
+			quantity,
+			quantity_reserved,
+			created_at,
+			updated_at
+		FROM inventory_lots
+		WHERE inventory_item_id = $1 AND lot_number = $2
+		FOR UPDATE
+  `
+
+	var lot pb.InventoryLot
+	var updatedAt int64
+	err := tx.QueryRow(ctx.Ctx(), stmt, inventoryItemID, lotNumber).Scan(
+		&lot.Id,
+		&lot.InventoryItemId,
+		&lot.LotNumber,
+		&lot.ExpiresAt,
+		&lot.Quantity,
+		&lot.QuantityReserved,
+		&lot.CreatedAt,
+		&updatedAt,
+	)
+	if err != nil {
+		return nil, models.HandleDBError(ctx, err, "inventory.store.InventoryLotGetByNumber", tx)
+	}
+
+	if updatedAt > 0 {
+		lot.UpdatedAt = &updatedAt
+	}
+
+	return &lot, nil
+}
+
+// InventoryLotsGet gets the lots of an inventory item first expired first out, the lots without an
+// expiry date come last, the lots are locked until the end of tx, or a normal db query is used if tx is nil
+func (is *InventoryStore) InventoryLotsGet(ctx *models.Context, tx pgx.Tx, inventoryItemID string) ([]*pb.InventoryLot, *models.DBError) {
+	stmt := `
+		SELECT
+			id,
+			inventory_item_id,
+			lot_number,
+			expires_at,
+			quantity,
+			quantity_reserved,
+			created_at,
+			updated_at
+		FROM inventory_lots
+		WHERE inventory_item_id = $1
+		ORDER BY expires_at NULLS LAST, created_at, id
+  `
+
+	var rows pgx.Rows
+	var err error
+	if tx != nil {
+		rows, err = tx.Query(ctx.Context, stmt+" FOR UPDATE", inventoryItemID)
+	} else {
+		rows, err = is.db.Query(ctx.Context, stmt, inventoryItemID)
+	}
+	if err != nil {
+		return nil, models.HandleDBError(ctx, err, "inventory.store.InventoryLotsGet", tx)
+	}
+	defer rows.Close()
+
+	result := make([]*pb.InventoryLot, 0)
+	for rows.Next() {
+		var lot pb.InventoryLot
+		var updatedAt int64
+		err := rows.Scan(
+			&lot.Id,
+			&lot.InventoryItemId,
+			&lot.LotNumber,
+			&lot.ExpiresAt,
+			&lot.Quantity,
+			&lot.QuantityReserved,
+			&lot.CreatedAt,
+			&updatedAt,
+		)
+		if err != nil {
+			return nil, models.HandleDBError(ctx, err, "inventory.store.InventoryLotsGet", tx)
+		}
+
+		if updatedAt > 0 {
+			lot.UpdatedAt = &updatedAt
+		}
+		result = append(result, &lot)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, models.HandleDBError(ctx, err, "inventory.store.InventoryLotsGet", tx)
+	}
+
+	return result, nil
+}
+
+// InventoryLotReceive adds received units to the on hand quantity of a lot
+func (is *InventoryStore) InventoryLotReceive(ctx *models.Context, tx pgx.Tx, id string, quantity int32) *models.DBError {
+	stmt := `UPDATE inventory_lots SET quantity = quantity + $1, updated_at = $2 WHERE id = $3`
+
+	_, err := tx.Exec(ctx.Ctx(), stmt, quantity, utils.TimeGetMillis(), id)
+
+	return models.HandleDBError(ctx, err, "inventory.store.InventoryLotReceive", tx)
+}
+
+// InventoryLotReserve reserves units of a lot, it returns reserved = false if the
+// lot has fewer units that aren't reserved yet than quantity
+func (is *InventoryStore) InventoryLotReserve(ctx *models.Context, tx pgx.Tx, id string, quantity int32) (bool, *models.DBError) {
+	stmt := `
+		UPDATE inventory_lots
+		SET quantity_reserved = quantity_reserved + $1, updated_at = $2
+		WHERE id = $3 AND quantity - quantity_reserved >= $1
+  `
+
+	result, err := tx.Exec(ctx.Ctx(), stmt, quantity, utils.TimeGetMillis(), id)
+	if err != nil {
+		return false, models.HandleDBError(ctx, err, "inventory.store.InventoryLotReserve", tx)
+	}
+
+	return result.RowsAffected() > 0, nil
+}
+
+// InventoryLotRelease releases reserved units of a lot, it returns released = false
+// if the lot has fewer units reserved than quantity
+func (is *InventoryStore) InventoryLotRelease(ctx *models.Context, tx pgx.Tx, id string, quantity int32) (bool, *models.DBError) {
+	stmt := `
+		UPDATE inventory_lots
+		SET quantity_reserved = quantity_reserved - $1, updated_at = $2
+		WHERE id = $3 AND quantity_reserved >= $1
+  `
+
+	result, err := tx.Exec(ctx.Ctx(), stmt, quantity, utils.TimeGetMillis(), id)
+	if err != nil {
+		return false, models.HandleDBError(ctx, err, "inventory.store.InventoryLotRelease", tx)
+	}
+
+	return result.RowsAffected() > 0, nil
+}
+
+// InventoryLotFulfill ships reserved units of a lot, the units leave both quantity_reserved
+// and quantity, it returns fulfilled = false if the lot has fewer units reserved than quantity
+func (is *InventoryStore) InventoryLotFulfill(ctx *models.Context, tx pgx.Tx, id string, quantity int32) (bool, *models.DBError) {
+	stmt := `
+		UPDATE inventory_lots
+		SET quantity = quantity - $1, quantity_reserved = quantity_reserved - $1, updated_at = $2
+		WHERE id = $3 AND quantity_reserved >= $1
+  `
+
+	result, err := tx.Exec(ctx.Ctx(), stmt, quantity, utils.TimeGetMillis(), id)
+	if err != nil {
+		return false, models.HandleDBError(ctx, err, "inventory.store.InventoryLotFulfill", tx)
+	}
+
+	return result.RowsAffected() > 0, nil
+}
+
+// InventoryLotIssue takes units that aren't reserved out of a lot, it returns issued = false
+// if the lot has fewer units that aren't reserved than quantity
+func (is *InventoryStore) InventoryLotIssue(ctx *models.Context, tx pgx.Tx, id string, quantity int32) (bool, *models.DBError) {
+	stmt := `
+		UPDATE inventory_lots
+		SET quantity = quantity - $1, updated_at = $2
+		WHERE id = $3 AND $1 > 0 AND quantity - quantity_reserved >= $1
+  `
+
+	result, err := tx.Exec(ctx.Ctx(), stmt, quantity, utils.TimeGetMillis(), id)
+	if err != nil {
+		return false, models.HandleDBError(ctx, err, "inventory.store.InventoryLotIssue", tx)
+	}
+
+	return result.RowsAffected() > 0, nil
+}
+
+// InventoryReservationLotAdd adds units of a lot to what a reservation item holds of that lot
+func (is *InventoryStore) InventoryReservationLotAdd(ctx *models.Context, tx pgx.Tx, reservationItemID string, lotID string, quantity int32) *models.DBError {
+	stmt := `
+		INSERT INTO inventory_reservation_lots (
+			id,
+			reservation_item_id,
+			lot_id,
+			quantity,
+			created_at
+		) VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (reservation_item_id, lot_id) DO UPDATE SET
+			quantity = inventory_reservation_lots.quantity + EXCLUDED.quantity
+  `
+
+	_, err := tx.Exec(ctx.Ctx(), stmt, utils.NewID(), reservationItemID, lotID, quantity, utils.TimeGetMillis())
+
+	return models.HandleDBError(ctx, err, "inventory.store.InventoryReservationLotAdd", tx)
+}
+
+// InventoryReservationLotsGet gets the lots that a reservation item holds units of, first expired first out,
+// you can pass nil for the tx argument, and a normal db query will be used
+func (is *InventoryStore) InventoryReservationLotsGet(ctx *models.Context, tx pgx.Tx, reservationItemID string) ([]*pb.InventoryReservationLot, *models.DBError) {
+	stmt := `
+		SELECT
+			rl.id,
+			rl.reservation_item_id,
+			rl.lot_id,
+			rl.quantity,
+			rl.created_at
+		FROM inventory_reservation_lots rl
+		JOIN inventory_lots l ON l.id = rl.lot_id
+		WHERE rl.reservation_item_id = $1 AND rl.quantity > 0
+		ORDER BY l.expires_at NULLS LAST, l.created_at, l.id
+  `
+
+	var rows pgx.Rows
+	var err error
+	if tx != nil {
+		rows, err = tx.Query(ctx.Context, stmt, reservationItemID)
+	} else {
+		rows, err = is.db.Query(ctx.Context, stmt, reservationItemID)
+	}
+	if err != nil {
+		return nil, models.HandleDBError(ctx, err, "inventory.store.InventoryReservationLotsGet", tx)
+	}
+	defer rows.Close()
+
+	result := make([]*pb.InventoryReservationLot, 0)
+	for rows.Next() {
+		var rl pb.InventoryReservationLot
+		err := rows.Scan(
+			&rl.Id,
+			&rl.ReservationItemId,
+			&rl.LotId,
+			&rl.Quantity,
+			&rl.CreatedAt,
+		)
+		if err != nil {
+			return nil, models.HandleDBError(ctx, err, "inventory.store.InventoryReservationLotsGet", tx)
+		}
+		result = append(result, &rl)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, models.HandleDBError(ctx, err, "inventory.store.InventoryReservationLotsGet", tx)
+	}
+
+	return result, nil
+}
+
+// InventoryReservationLotSettle takes released or fulfilled units out of what a reservation item
+// holds of a lot, it returns settled = false if it holds fewer units of the lot than quantity
+func (is *InventoryStore) InventoryReservationLotSettle(ctx *models.Context, tx pgx.Tx, id string, quantity int32) (bool, *models.DBError) {
+	stmt := `UPDATE inventory_reservation_lots SET quantity = quantity - $1 WHERE id = $2 AND quantity >= $1`
+
+	result, err := tx.Exec(ctx.Ctx(), stmt, quantity, id)
+	if err != nil {
+		return false, models.HandleDBError(ctx, err, "inventory.store.InventoryReservationLotSettle", tx)
+	}
+
+	return result.RowsAffected() > 0, nil
+}
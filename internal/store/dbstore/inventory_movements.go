@@ -12,13 +12,16 @@ func (is *InventoryStore) InventoryMovementCreate(ctx *models.Context, tx pgx.Tx
 		INSERT INTO inventory_movements (
 			id,
 			inventory_item_id,
+			lot_id,
 			movement_type,
 			quantity,
+			quantity_change,
+			unit_cost,
 			reference_id,
 			reason,
 			metadata,
 			created_at
-		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
   `
 
 	_, err := tx.Exec(
@@ -26,8 +29,11 @@ func (is *InventoryStore) InventoryMovementCreate(ctx *models.Context, tx pgx.Tx
 		stmt,
 		params.Id,
 		params.InventoryItemId,
+		params.LotId,
 		params.MovementType,
 		params.Quantity,
+		params.QuantityChange,
+		params.UnitCost,
 		params.ReferenceId,
 		params.Reason,
 		params.Metadata,
@@ -36,3 +42,20 @@ func (is *InventoryStore) InventoryMovementCreate(ctx *models.Context, tx pgx.Tx
 
 	return models.HandleDBError(ctx, err, "inventory.store.InventoryMovementCreate", tx)
 }
+
+// InventoryMovementsQuantityChange adds up the changes of the on hand units of an inventory
+// item that the movements created after a date, and up to another one, have made
+func (is *InventoryStore) InventoryMovementsQuantityChange(ctx *models.Context, inventoryItemID string, after int64, upTo int64) (int64, *models.DBError) {
+	stmt := `
+		SELECT COALESCE(SUM(quantity_change), 0)
+		FROM inventory_movements
+		WHERE inventory_item_id = $1 AND created_at > $2 AND created_at <= $3
+  `
+
+	var change int64
+	if err := is.db.QueryRow(ctx.Ctx(), stmt, inventoryItemID, after, upTo).Scan(&change); err != nil {
+		return 0, models.HandleDBError(ctx, err, "inventory.store.InventoryMovementsQuantityChange", nil)
+	}
+
+	return change, nil
+}
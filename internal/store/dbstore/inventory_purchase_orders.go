@@ -0,0 +1,423 @@
+package dbstore
+
+import (
+	intModels "github.com/ahmad-khatib0-org/megacommerce-inventory/pkg/models"
+	pb "github.com/ahmad-khatib0-org/megacommerce-proto/gen/go/inventory/v1"
+	"github.com/ahmad-khatib0-org/megacommerce-shared-go/pkg/models"
+	"github.com/ahmad-khatib0-org/megacommerce-shared-go/pkg/utils"
+	"github.com/jackc/pgx/v5"
+)
+
+// InventoryPurchaseOrderCreate creates a new purchase order
+func (is *InventoryStore) InventoryPurchaseOrderCreate(ctx *models.Context, tx pgx.Tx, params *pb.InventoryPurchaseOrder) *models.DBError {
+	stmt := `
+		INSERT INTO inventory_purchase_orders (
+			id,
+			po_number,
+			seller_id,
+			location_id,
+			supplier_reference,
+			status,
+			expected_at,
+			note,
+			created_at,
+			updated_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+  `
+
+	_, err := tx.Exec(
+		ctx.Ctx(),
+		stmt,
+		params.Id,
+		params.PoNumber,
+		params.SellerId,
+		params.LocationId,
+		params.SupplierReference,
+		params.Status,
+		params.ExpectedAt,
+		params.Note,
+		params.CreatedAt,
+		params.UpdatedAt,
+	)
+
+	return models.HandleDBError(ctx, err, "inventory.store.InventoryPurchaseOrderCreate", tx)
+}
+
+// InventoryPurchaseOrderGetByNumber gets a purchase order by its number, the purchase order
+// row is locked until the end of tx if tx is not nil
+func (is *InventoryStore) InventoryPurchaseOrderGetByNumber(ctx *models.Context, tx pgx.Tx, poNumber string) (*pb.InventoryPurchaseOrder, *models.DBError) {
+	stmt := `
+		SELECT
+			id,
+			po_number,
+			seller_id,
+			location_id,
+			supplier_reference,
+			status,
+			expected_at,
+			note,
+			created_at,
+			updated_at
+		FROM inventory_purchase_orders
+		WHERE po_number = $1
+  `
+
+	var po pb.InventoryPurchaseOrder
+	var updatedAt int64
+	var row pgx.Row
+	if tx != nil {
+		row = tx.QueryRow(ctx.Context, stmt+" FOR UPDATE", poNumber)
+	} else {
+		row = is.db.QueryRow(ctx.Context, stmt, poNumber)
+	}
+	err := row.Scan(
+		&po.Id,
+		&po.PoNumber,
+		&po.SellerId,
+		&po.LocationId,
+		&po.SupplierReference,
+		&po.Status,
+		&po.ExpectedAt,
+		&po.Note,
+		&po.CreatedAt,
+		&updatedAt,
+	)
+	if err != nil {
+		return nil, models.HandleDBError(ctx, err, "inventory.store.InventoryPurchaseOrderGetByNumber", tx)
+	}
+
+	if updatedAt > 0 {
+		po.UpdatedAt = &updatedAt
+	}
+
+	return &po, nil
+}
+
+// InventoryPurchaseOrderUpdateStatus updates the status of a purchase order
+func (is *InventoryStore) InventoryPurchaseOrderUpdateStatus(ctx *models.Context, tx pgx.Tx, id string, status string) *models.DBError {
+	stmt := `
+		UPDATE inventory_purchase_orders
+		SET status = $1, updated_at = $2
+		WHERE id = $3
+  `
+
+	_, err := tx.Exec(ctx.Ctx(), stmt, status, utils.TimeGetMillis(), id)
+
+	return models.HandleDBError(ctx, err, "inventory.store.InventoryPurchaseOrderUpdateStatus", tx)
+}
+
+// InventoryPurchaseOrderUpdate updates the status, the expected date and the note of a purchase order
+func (is *InventoryStore) InventoryPurchaseOrderUpdate(ctx *models.Context, tx pgx.Tx, params *pb.InventoryPurchaseOrder) *models.DBError {
+	stmt := `
+		UPDATE inventory_purchase_orders
+		SET status = $1, expected_at = $2, note = $3, updated_at = $4
+		WHERE id = $5
+  `
+
+	_, err := tx.Exec(ctx.Ctx(), stmt, params.Status, params.ExpectedAt, params.Note, utils.TimeGetMillis(), params.Id)
+
+	return models.HandleDBError(ctx, err, "inventory.store.InventoryPurchaseOrderUpdate", tx)
+}
+
+// InventoryPurchaseOrdersList lists the purchase orders newest first, an empty sellerID or locationID
+// matches every seller or location, and an empty statuses matches every status
+func (is *InventoryStore) InventoryPurchaseOrdersList(ctx *models.Context, sellerID string, locationID string, statuses []string, limit int) ([]*pb.InventoryPurchaseOrder, *models.DBError) {
+	stmt := `
+		SELECT
+			id,
+			po_number,
+			seller_id,
+			location_id,
+			supplier_reference,
+			status,
+			expected_at,
+			note,
+			created_at,
+			updated_at
+		FROM inventory_purchase_orders
+		WHERE ($1 = '' OR seller_id = $1)
+			AND ($2 = '' OR location_id = $2)
+			AND (cardinality($3::text[]) = 0 OR status = ANY($3))
+		ORDER BY created_at DESC, id DESC
+		LIMIT $4
+  `
+
+	rows, err := is.db.Query(ctx.Ctx(), stmt, sellerID, locationID, statuses, limit)
+	if err != nil {
+		return nil, models.HandleDBError(ctx, err, "inventory.store.InventoryPurchaseOrdersList", nil)
+	}
+	defer rows.Close()
+
+	result := make([]*pb.InventoryPurchaseOrder, 0, limit)
+	for rows.Next() {
+		var po pb.InventoryPurchaseOrder
+		var updatedAt int64
+		err := rows.Scan(
+			&po.Id,
+			&po.PoNumber,
+			&po.SellerId,
+			&po.LocationId,
+			&po.SupplierReference,
+			&po.Status,
+			&po.ExpectedAt,
+			&po.Note,
+			&po.CreatedAt,
+			&updatedAt,
+		)
+		if err != nil {
+			return nil, models.HandleDBError(ctx, err, "inventory.store.InventoryPurchaseOrdersList", nil)
+		}
+		if updatedAt > 0 {
+			po.UpdatedAt = &updatedAt
+		}
+		result = append(result, &po)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, models.HandleDBError(ctx, err, "inventory.store.InventoryPurchaseOrdersList", nil)
+	}
+
+	return result, nil
+}
+
+// InventoryPurchaseOrderItemCreate creates a new purchase order item
+func (is *InventoryStore) InventoryPurchaseOrderItemCreate(ctx *models.Context, tx pgx.Tx, params *pb.InventoryPurchaseOrderItem) *models.DBError {
+	stmt := `
+		INSERT INTO inventory_purchase_order_items (
+			id,
+			purchase_order_id,
+			sku,
+			inventory_item_id,
+			quantity_ordered,
+			quantity_received,
+			expected_at,
+			created_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+  `
+
+	_, err := tx.Exec(
+		ctx.Ctx(),
+		stmt,
+		params.Id,
+		params.PurchaseOrderId,
+		params.Sku,
+		params.InventoryItemId,
+		params.QuantityOrdered,
+		params.QuantityReceived,
+		params.ExpectedAt,
+		params.CreatedAt,
+	)
+
+	return models.HandleDBError(ctx, err, "inventory.store.InventoryPurchaseOrderItemCreate", tx)
+}
+
+// InventoryPurchaseOrderItemsGetByPurchaseOrderID gets all items for a purchase order,
+// you can pass nil for the tx argument, and a normal db query will be used
+func (is *InventoryStore) InventoryPurchaseOrderItemsGetByPurchaseOrderID(ctx *models.Context, tx pgx.Tx, purchaseOrderID string) ([]*pb.InventoryPurchaseOrderItem, *models.DBError) {
+	stmt := `
+		SELECT
+			id,
+			purchase_order_id,
+			sku,
+			inventory_item_id,
+			quantity_ordered,
+			quantity_received,
+			expected_at,
+			created_at
+		FROM inventory_purchase_order_items
+		WHERE purchase_order_id = $1
+		ORDER BY sku
+  `
+
+	var rows pgx.Rows
+	var err error
+	if tx != nil {
+		rows, err = tx.Query(ctx.Context, stmt, purchaseOrderID)
+	} else {
+		rows, err = is.db.Query(ctx.Context, stmt, purchaseOrderID)
+	}
+	if err != nil {
+		return nil, models.HandleDBError(ctx, err, "inventory.store.InventoryPurchaseOrderItemsGetByPurchaseOrderID", tx)
+	}
+	defer rows.Close()
+
+	var items []*pb.InventoryPurchaseOrderItem
+	for rows.Next() {
+		var item pb.InventoryPurchaseOrderItem
+		err := rows.Scan(
+			&item.Id,
+			&item.PurchaseOrderId,
+			&item.Sku,
+			&item.InventoryItemId,
+			&item.QuantityOrdered,
+			&item.QuantityReceived,
+			&item.ExpectedAt,
+			&item.CreatedAt,
+		)
+		if err != nil {
+			return nil, models.HandleDBError(ctx, err, "inventory.store.InventoryPurchaseOrderItemsGetByPurchaseOrderID", tx)
+		}
+		items = append(items, &item)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, models.HandleDBError(ctx, err, "inventory.store.InventoryPurchaseOrderItemsGetByPurchaseOrderID", tx)
+	}
+
+	return items, nil
+}
+
+// InventoryPurchaseOrderItemUpdate updates the ordered units and the expected date of a purchase order item
+func (is *InventoryStore) InventoryPurchaseOrderItemUpdate(ctx *models.Context, tx pgx.Tx, id string, quantityOrdered int32, expectedAt int64) *models.DBError {
+	stmt := `
+		UPDATE inventory_purchase_order_items
+		SET quantity_ordered = $1, expected_at = $2
+		WHERE id = $3
+  `
+
+	_, err := tx.Exec(ctx.Ctx(), stmt, quantityOrdered, expectedAt, id)
+
+	return models.HandleDBError(ctx, err, "inventory.store.InventoryPurchaseOrderItemUpdate", tx)
+}
+
+// InventoryPurchaseOrderItemDelete deletes a purchase order item
+func (is *InventoryStore) InventoryPurchaseOrderItemDelete(ctx *models.Context, tx pgx.Tx, id string) *models.DBError {
+	stmt := `DELETE FROM inventory_purchase_order_items WHERE id = $1`
+
+	_, err := tx.Exec(ctx.Ctx(), stmt, id)
+
+	return models.HandleDBError(ctx, err, "inventory.store.InventoryPurchaseOrderItemDelete", tx)
+}
+
+// InventoryPurchaseOrderDraftedItems tells which of the inventory items are on a draft purchase order
+func (is *InventoryStore) InventoryPurchaseOrderDraftedItems(ctx *models.Context, tx pgx.Tx, inventoryItemIDs []string) (map[string]bool, *models.DBError) {
+	stmt := `
+		SELECT DISTINCT poi.inventory_item_id
+		FROM inventory_purchase_order_items poi
+		JOIN inventory_purchase_orders po ON po.id = poi.purchase_order_id
+		WHERE poi.inventory_item_id = ANY($1) AND po.status = 'DRAFT'
+  `
+
+	var rows pgx.Rows
+	var err error
+	if tx != nil {
+		rows, err = tx.Query(ctx.Ctx(), stmt, inventoryItemIDs)
+	} else {
+		rows, err = is.db.Query(ctx.Ctx(), stmt, inventoryItemIDs)
+	}
+	if err != nil {
+		return nil, models.HandleDBError(ctx, err, "inventory.store.InventoryPurchaseOrderDraftedItems", tx)
+	}
+	defer rows.Close()
+
+	result := make(map[string]bool)
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, models.HandleDBError(ctx, err, "inventory.store.InventoryPurchaseOrderDraftedItems", nil)
+		}
+		result[id] = true
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, models.HandleDBError(ctx, err, "inventory.store.InventoryPurchaseOrderDraftedItems", nil)
+	}
+
+	return result, nil
+}
+
+// InventoryPurchaseOrderItemReceive adds received units to a purchase order item, it returns
+// received = false if that would receive more units than were ordered
+func (is *InventoryStore) InventoryPurchaseOrderItemReceive(ctx *models.Context, tx pgx.Tx, id string, quantity int32) (bool, *models.DBError) {
+	stmt := `
+		UPDATE inventory_purchase_order_items
+		SET quantity_received = quantity_received + $1
+		WHERE id = $2 AND quantity_received + $1 <= quantity_ordered
+  `
+
+	result, err := tx.Exec(ctx.Ctx(), stmt, quantity, id)
+	if err != nil {
+		return false, models.HandleDBError(ctx, err, "inventory.store.InventoryPurchaseOrderItemReceive", tx)
+	}
+
+	return result.RowsAffected() > 0, nil
+}
+
+// InventoryPurchaseOrderIncoming sums the units of a sku that are still expected on open purchase orders,
+// per expected date in ascending order, an empty sellerID or locationID matches every seller or location
+func (is *InventoryStore) InventoryPurchaseOrderIncoming(ctx *models.Context, sellerID string, sku string, locationID string) ([]*intModels.InventoryIncoming, *models.DBError) {
+	stmt := `
+		SELECT poi.expected_at, SUM(poi.quantity_ordered - poi.quantity_received)
+		FROM inventory_purchase_order_items poi
+		JOIN inventory_purchase_orders po ON po.id = poi.purchase_order_id
+		WHERE poi.sku = $1
+			AND po.status IN ('OPEN', 'PARTIALLY_RECEIVED')
+			AND poi.quantity_received < poi.quantity_ordered
+			AND ($2 = '' OR po.seller_id = $2)
+			AND ($3 = '' OR po.location_id = $3)
+		GROUP BY poi.expected_at
+		ORDER BY poi.expected_at
+  `
+
+	rows, err := is.db.Query(ctx.Ctx(), stmt, sku, sellerID, locationID)
+	if err != nil {
+		return nil, models.HandleDBError(ctx, err, "inventory.store.InventoryPurchaseOrderIncoming", nil)
+	}
+	defer rows.Close()
+
+	result := make([]*intModels.InventoryIncoming, 0)
+	for rows.Next() {
+		var in intModels.InventoryIncoming
+		if err := rows.Scan(&in.ExpectedAt, &in.Quantity); err != nil {
+			return nil, models.HandleDBError(ctx, err, "inventory.store.InventoryPurchaseOrderIncoming", nil)
+		}
+		result = append(result, &in)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, models.HandleDBError(ctx, err, "inventory.store.InventoryPurchaseOrderIncoming", nil)
+	}
+
+	return result, nil
+}
+
+// InventoryPurchaseOrderIncomingByItems sums the units of inventory items that are still expected on open purchase
+// orders of their seller and location, the items that have none are left out
+func (is *InventoryStore) InventoryPurchaseOrderIncomingByItems(ctx *models.Context, inventoryItemIDs []string) (map[string]int64, *models.DBError) {
+	stmt := `
+		SELECT ii.id, SUM(poi.quantity_ordered - poi.quantity_received)
+		FROM inventory_items ii
+		JOIN inventory_purchase_orders po
+			ON po.seller_id = ii.seller_id
+			AND COALESCE(po.location_id, '') = COALESCE(ii.location_id, '')
+			AND po.status IN ('OPEN', 'PARTIALLY_RECEIVED')
+		JOIN inventory_purchase_order_items poi
+			ON poi.purchase_order_id = po.id
+			AND poi.sku = ii.sku
+			AND poi.quantity_received < poi.quantity_ordered
+		WHERE ii.id = ANY($1)
+		GROUP BY ii.id
+  `
+
+	rows, err := is.db.Query(ctx.Ctx(), stmt, inventoryItemIDs)
+	if err != nil {
+		return nil, models.HandleDBError(ctx, err, "inventory.store.InventoryPurchaseOrderIncomingByItems", nil)
+	}
+	defer rows.Close()
+
+	result := make(map[string]int64)
+	for rows.Next() {
+		var id string
+		var quantity int64
+		if err := rows.Scan(&id, &quantity); err != nil {
+			return nil, models.HandleDBError(ctx, err, "inventory.store.InventoryPurchaseOrderIncomingByItems", nil)
+		}
+		result[id] = quantity
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, models.HandleDBError(ctx, err, "inventory.store.InventoryPurchaseOrderIncomingByItems", nil)
+	}
+
+	return result, nil
+}
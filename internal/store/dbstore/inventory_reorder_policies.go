@@ -0,0 +1,160 @@
+package dbstore
+
+import (
+	pb "github.com/ahmad-khatib0-org/megacommerce-proto/gen/go/inventory/v1"
+	"github.com/ahmad-khatib0-org/megacommerce-shared-go/pkg/models"
+	"github.com/jackc/pgx/v5"
+)
+
+// InventoryReorderPolicyUpsert creates the reorder policy of an inventory item, or replaces an existing one
+func (is *InventoryStore) InventoryReorderPolicyUpsert(ctx *models.Context, tx pgx.Tx, params *pb.InventoryReorderPolicy) *models.DBError {
+	stmt := `
+		INSERT INTO inventory_reorder_policies (
+			inventory_item_id,
+			supplier_reference,
+			lead_time_days,
+			reorder_point,
+			order_up_to,
+			min_order_quantity,
+			case_pack,
+			created_at,
+			updated_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		ON CONFLICT (inventory_item_id) DO UPDATE SET
+			supplier_reference = EXCLUDED.supplier_reference,
+			lead_time_days = EXCLUDED.lead_time_days,
+			reorder_point = EXCLUDED.reorder_point,
+			order_up_to = EXCLUDED.order_up_to,
+			min_order_quantity = EXCLUDED.min_order_quantity,
+			case_pack = EXCLUDED.case_pack,
+			updated_at = EXCLUDED.created_at
+  `
+
+	_, err := tx.Exec(
+		ctx.Ctx(),
+		stmt,
+		params.InventoryItemId,
+		params.SupplierReference,
+		params.LeadTimeDays,
+		params.ReorderPoint,
+		params.OrderUpTo,
+		params.MinOrderQuantity,
+		params.CasePack,
+		params.CreatedAt,
+		params.UpdatedAt,
+	)
+
+	return models.HandleDBError(ctx, err, "inventory.store.InventoryReorderPolicyUpsert", tx)
+}
+
+// InventoryReorderPolicyGet gets the reorder policy of an inventory item, the policy
+// row is locked until the end of tx if tx is not nil
+func (is *InventoryStore) InventoryReorderPolicyGet(ctx *models.Context, tx pgx.Tx, inventoryItemID string) (*pb.InventoryReorderPolicy, *models.DBError) {
+	stmt := `
+		SELECT
+			inventory_item_id,
+			supplier_reference,
+			lead_time_days,
+			reorder_point,
+			order_up_to,
+			min_order_quantity,
+			case_pack,
+			created_at,
+			updated_at
+		FROM inventory_reorder_policies
+		WHERE inventory_item_id = $1
+  `
+
+	var rp pb.InventoryReorderPolicy
+	var updatedAt int64
+	var row pgx.Row
+	if tx != nil {
+		row = tx.QueryRow(ctx.Context, stmt+" FOR UPDATE", inventoryItemID)
+	} else {
+		row = is.db.QueryRow(ctx.Context, stmt, inventoryItemID)
+	}
+	err := row.Scan(
+		&rp.InventoryItemId,
+		&rp.SupplierReference,
+		&rp.LeadTimeDays,
+		&rp.ReorderPoint,
+		&rp.OrderUpTo,
+		&rp.MinOrderQuantity,
+		&rp.CasePack,
+		&rp.CreatedAt,
+		&updatedAt,
+	)
+	if err != nil {
+		return nil, models.HandleDBError(ctx, err, "inventory.store.InventoryReorderPolicyGet", tx)
+	}
+
+	if updatedAt > 0 {
+		rp.UpdatedAt = &updatedAt
+	}
+
+	return &rp, nil
+}
+
+// InventoryReorderPoliciesGetByItems gets the reorder policies of inventory items by item id,
+// the items that have none are left out
+func (is *InventoryStore) InventoryReorderPoliciesGetByItems(ctx *models.Context, inventoryItemIDs []string) (map[string]*pb.InventoryReorderPolicy, *models.DBError) {
+	stmt := `
+		SELECT
+			inventory_item_id,
+			supplier_reference,
+			lead_time_days,
+			reorder_point,
+			order_up_to,
+			min_order_quantity,
+			case_pack,
+			created_at,
+			updated_at
+		FROM inventory_reorder_policies
+		WHERE inventory_item_id = ANY($1)
+  `
+
+	rows, err := is.db.Query(ctx.Ctx(), stmt, inventoryItemIDs)
+	if err != nil {
+		return nil, models.HandleDBError(ctx, err, "inventory.store.InventoryReorderPoliciesGetByItems", nil)
+	}
+	defer rows.Close()
+
+	result := make(map[string]*pb.InventoryReorderPolicy)
+	for rows.Next() {
+		var rp pb.InventoryReorderPolicy
+		var updatedAt int64
+		err := rows.Scan(
+			&rp.InventoryItemId,
+			&rp.SupplierReference,
+			&rp.LeadTimeDays,
+			&rp.ReorderPoint,
+			&rp.OrderUpTo,
+			&rp.MinOrderQuantity,
+			&rp.CasePack,
+			&rp.CreatedAt,
+			&updatedAt,
+		)
+		if err != nil {
+			return nil, models.HandleDBError(ctx, err, "inventory.store.InventoryReorderPoliciesGetByItems", nil)
+		}
+		if updatedAt > 0 {
+			rp.UpdatedAt = &updatedAt
+		}
+		result[rp.InventoryItemId] = &rp
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, models.HandleDBError(ctx, err, "inventory.store.InventoryReorderPoliciesGetByItems", nil)
+	}
+
+	return result, nil
+}
+
+// InventoryReplenishmentLock waits until no other transaction drafts replenishment purchase orders, and
+// holds the lock until the end of tx, so concurrent runs don't draft the same items twice
+func (is *InventoryStore) InventoryReplenishmentLock(ctx *models.Context, tx pgx.Tx) *models.DBError {
+	stmt := `SELECT pg_advisory_xact_lock(hashtext('inventory_replenishment'))`
+
+	_, err := tx.Exec(ctx.Ctx(), stmt)
+	return models.HandleDBError(ctx, err, "inventory.store.InventoryReplenishmentLock", tx)
+}
@@ -14,8 +14,11 @@ func (is *InventoryStore) InventoryReservationItemCreate(ctx *models.Context, tx
 			reservation_id, 
 			inventory_item_id, 
 			quantity, 
+			quantity_backordered,
+			kit_id,
+			kit_quantity,
 			created_at
-		) VALUES ($1, $2, $3, $4, $5)
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
     `
 
 	_, err := tx.Exec(
@@ -25,31 +28,41 @@ func (is *InventoryStore) InventoryReservationItemCreate(ctx *models.Context, tx
 		params.ReservationId,
 		params.InventoryItemId,
 		params.Quantity,
+		params.QuantityBackordered,
+		params.KitId,
+		params.KitQuantity,
 		params.CreatedAt,
 	)
 
 	return models.HandleDBError(ctx, err, "inventory.store.InventoryReservationItemCreate", tx)
 }
 
-// InventoryReservationItemsGetByReservationID gets all items for a reservation
-func (is *InventoryStore) InventoryReservationItemsGetByReservationID(ctx *models.Context, tx pgx.Tx, reservationID string) ([]*pb.InventoryReservationItem, *models.DBError) {
+// InventoryReservationItemsGetByReservationID gets all items for a reservation,
+// limited to the items of the given seller unless sellerID is empty
+func (is *InventoryStore) InventoryReservationItemsGetByReservationID(ctx *models.Context, tx pgx.Tx, sellerID string, reservationID string) ([]*pb.InventoryReservationItem, *models.DBError) {
 	stmt := `
 		SELECT 
-			id,
-			reservation_id,
-			inventory_item_id,
-			quantity,
-			created_at
-		FROM inventory_reservation_items
-		WHERE reservation_id = $1
+			ri.id,
+			ri.reservation_id,
+			ri.inventory_item_id,
+			ri.quantity,
+			ri.quantity_backordered,
+			ri.quantity_released,
+			ri.quantity_fulfilled,
+			ri.kit_id,
+			ri.kit_quantity,
+			ri.created_at
+		FROM inventory_reservation_items ri
+		JOIN inventory_items ii ON ii.id = ri.inventory_item_id
+		WHERE ri.reservation_id = $1 AND ($2 = '' OR ii.seller_id = $2)
   `
 
 	var rows pgx.Rows
 	var err error
 	if tx != nil {
-		rows, err = tx.Query(ctx.Context, stmt, reservationID)
+		rows, err = tx.Query(ctx.Context, stmt, reservationID, sellerID)
 	} else {
-		rows, err = is.db.Query(ctx.Context, stmt, reservationID)
+		rows, err = is.db.Query(ctx.Context, stmt, reservationID, sellerID)
 	}
 	if err != nil {
 		return nil, models.HandleDBError(ctx, err, "inventory.store.InventoryReservationItemsGetByReservationID", tx)
@@ -64,6 +77,11 @@ func (is *InventoryStore) InventoryReservationItemsGetByReservationID(ctx *model
 			&item.ReservationId,
 			&item.InventoryItemId,
 			&item.Quantity,
+			&item.QuantityBackordered,
+			&item.QuantityReleased,
+			&item.QuantityFulfilled,
+			&item.KitId,
+			&item.KitQuantity,
 			&item.CreatedAt,
 		)
 		if err != nil {
@@ -78,3 +96,103 @@ func (is *InventoryStore) InventoryReservationItemsGetByReservationID(ctx *model
 
 	return items, nil
 }
+
+// InventoryReservationItemUpdateQuantity sets the reserved and backordered quantities of a reservation item
+func (is *InventoryStore) InventoryReservationItemUpdateQuantity(ctx *models.Context, tx pgx.Tx, id string, quantity int32, backordered int32) *models.DBError {
+	stmt := `UPDATE inventory_reservation_items SET quantity = $1, quantity_backordered = $2 WHERE id = $3`
+
+	_, err := tx.Exec(ctx.Ctx(), stmt, quantity, backordered, id)
+
+	return models.HandleDBError(ctx, err, "inventory.store.InventoryReservationItemUpdateQuantity", tx)
+}
+
+// InventoryReservationItemDelete removes an item from its reservation, along with the lots it held
+func (is *InventoryStore) InventoryReservationItemDelete(ctx *models.Context, tx pgx.Tx, id string) *models.DBError {
+	stmt := `DELETE FROM inventory_reservation_lots WHERE reservation_item_id = $1`
+
+	_, err := tx.Exec(ctx.Ctx(), stmt, id)
+	if err != nil {
+		return models.HandleDBError(ctx, err, "inventory.store.InventoryReservationItemDelete", tx)
+	}
+
+	stmt = `DELETE FROM inventory_reservation_items WHERE id = $1`
+
+	_, err = tx.Exec(ctx.Ctx(), stmt, id)
+
+	return models.HandleDBError(ctx, err, "inventory.store.InventoryReservationItemDelete", tx)
+}
+
+// InventoryReservationItemSettle moves released and fulfilled units out of the reserved quantity of a
+// reservation item, it returns settled = false if the item has fewer units reserved than released + fulfilled,
+// or if either of them is negative
+func (is *InventoryStore) InventoryReservationItemSettle(ctx *models.Context, tx pgx.Tx, id string, released int32, fulfilled int32) (bool, *models.DBError) {
+	stmt := `
+		UPDATE inventory_reservation_items 
+		SET 
+			quantity = quantity - ($1 + $2),
+			quantity_released = quantity_released + $1,
+			quantity_fulfilled = quantity_fulfilled + $2
+		WHERE id = $3 AND $1 >= 0 AND $2 >= 0 AND quantity >= $1 + $2
+  `
+
+	result, err := tx.Exec(ctx.Ctx(), stmt, released, fulfilled, id)
+	if err != nil {
+		return false, models.HandleDBError(ctx, err, "inventory.store.InventoryReservationItemSettle", tx)
+	}
+
+	return result.RowsAffected() > 0, nil
+}
+
+// InventoryReservationItemsGetByReservationIDs gets the items of several reservations at once,
+// limited to the items of the given seller unless sellerID is empty
+func (is *InventoryStore) InventoryReservationItemsGetByReservationIDs(ctx *models.Context, sellerID string, reservationIDs []string) ([]*pb.InventoryReservationItem, *models.DBError) {
+	stmt := `
+		SELECT 
+			ri.id,
+			ri.reservation_id,
+			ri.inventory_item_id,
+			ri.quantity,
+			ri.quantity_backordered,
+			ri.quantity_released,
+			ri.quantity_fulfilled,
+			ri.kit_id,
+			ri.kit_quantity,
+			ri.created_at
+		FROM inventory_reservation_items ri
+		JOIN inventory_items ii ON ii.id = ri.inventory_item_id
+		WHERE ri.reservation_id = ANY($1) AND ($2 = '' OR ii.seller_id = $2)
+  `
+
+	rows, err := is.db.Query(ctx.Ctx(), stmt, reservationIDs, sellerID)
+	if err != nil {
+		return nil, models.HandleDBError(ctx, err, "inventory.store.InventoryReservationItemsGetByReservationIDs", nil)
+	}
+	defer rows.Close()
+
+	items := make([]*pb.InventoryReservationItem, 0)
+	for rows.Next() {
+		var item pb.InventoryReservationItem
+		err := rows.Scan(
+			&item.Id,
+			&item.ReservationId,
+			&item.InventoryItemId,
+			&item.Quantity,
+			&item.QuantityBackordered,
+			&item.QuantityReleased,
+			&item.QuantityFulfilled,
+			&item.KitId,
+			&item.KitQuantity,
+			&item.CreatedAt,
+		)
+		if err != nil {
+			return nil, models.HandleDBError(ctx, err, "inventory.store.InventoryReservationItemsGetByReservationIDs", nil)
+		}
+		items = append(items, &item)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, models.HandleDBError(ctx, err, "inventory.store.InventoryReservationItemsGetByReservationIDs", nil)
+	}
+
+	return items, nil
+}
@@ -1,32 +1,35 @@
 package dbstore
 
 import (
+	intModels "github.com/ahmad-khatib0-org/megacommerce-inventory/pkg/models"
 	pb "github.com/ahmad-khatib0-org/megacommerce-proto/gen/go/inventory/v1"
 	"github.com/ahmad-khatib0-org/megacommerce-shared-go/pkg/models"
 	"github.com/ahmad-khatib0-org/megacommerce-shared-go/pkg/utils"
 	"github.com/jackc/pgx/v5"
 )
 
-// InventoryReservationGetByToken gets a reservation by its token
+// InventoryReservationGetByToken gets a reservation by its token, the reservation row
+// is locked until the end of tx if tx is not nil
 func (is *InventoryStore) InventoryReservationGetByToken(ctx *models.Context, tx pgx.Tx, token string) (*pb.InventoryReservation, *models.DBError) {
 	stmt := `
 		SELECT 
 			id, 
 			reservation_token, 
 			order_id, 
+			channel,
 			status, 
 			expires_at, 
 			created_at, 
 			updated_at
 		FROM inventory_reservations 
-		WHERE reservation_token = $1;
+		WHERE reservation_token = $1
   `
 
 	var ir pb.InventoryReservation
 	var updatedAt int64
 	var row pgx.Row
 	if tx != nil {
-		row = tx.QueryRow(ctx.Context, stmt, token)
+		row = tx.QueryRow(ctx.Context, stmt+" FOR UPDATE", token)
 	} else {
 		row = is.db.QueryRow(ctx.Context, stmt, token)
 	}
@@ -34,6 +37,7 @@ func (is *InventoryStore) InventoryReservationGetByToken(ctx *models.Context, tx
 		&ir.Id,
 		&ir.ReservationToken,
 		&ir.OrderId,
+		&ir.Channel,
 		&ir.Status,
 		&ir.ExpiresAt,
 		&ir.CreatedAt,
@@ -57,11 +61,12 @@ func (is *InventoryStore) InventoryReservationCreate(ctx *models.Context, tx pgx
 			id,
 			reservation_token,
 			order_id,
+			channel,
 			status,
 			expires_at,
 			created_at,
 			updated_at
-		) VALUES ($1, $2, $3, $4, $5, $6, $7)
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
     `
 
 	_, err := tx.Exec(
@@ -70,6 +75,7 @@ func (is *InventoryStore) InventoryReservationCreate(ctx *models.Context, tx pgx
 		params.Id,
 		params.ReservationToken,
 		params.OrderId,
+		params.Channel,
 		params.Status,
 		params.ExpiresAt,
 		params.CreatedAt,
@@ -91,3 +97,100 @@ func (is *InventoryStore) InventoryReservationUpdateStatus(ctx *models.Context,
 
 	return models.HandleDBError(ctx, err, "inventory.store.InventoryReservationUpdateStatus", tx)
 }
+
+// InventoryReservationExtend moves the expiry of a reservation to expiresAt, it returns
+// extended = false if the reservation is no longer in the given status or has already expired
+func (is *InventoryStore) InventoryReservationExtend(ctx *models.Context, tx pgx.Tx, id string, status string, expiresAt int64) (bool, *models.DBError) {
+	stmt := `
+		UPDATE inventory_reservations 
+		SET expires_at = $1, updated_at = $2
+		WHERE id = $3 AND status = $4 AND expires_at > $2
+  `
+
+	result, err := tx.Exec(ctx.Ctx(), stmt, expiresAt, utils.TimeGetMillis(), id, status)
+	if err != nil {
+		return false, models.HandleDBError(ctx, err, "inventory.store.InventoryReservationExtend", tx)
+	}
+
+	return result.RowsAffected() > 0, nil
+}
+
+// InventoryReservationsList lists the reservations matching filter, newest first, a reservation
+// matches the sku and seller filters if any of its items does
+func (is *InventoryStore) InventoryReservationsList(ctx *models.Context, filter *intModels.InventoryReservationFilter) ([]*pb.InventoryReservation, *models.DBError) {
+	stmt := `
+		SELECT 
+			r.id, 
+			r.reservation_token, 
+			r.order_id, 
+			r.channel,
+			r.status, 
+			r.expires_at, 
+			r.created_at, 
+			r.updated_at
+		FROM inventory_reservations r
+		WHERE ($1 = '' OR r.order_id = $1)
+			AND ($2 = '' OR r.status = $2)
+			AND ($3 = 0 OR r.created_at >= $3) AND ($4 = 0 OR r.created_at < $4)
+			AND ($5 = 0 OR r.expires_at >= $5) AND ($6 = 0 OR r.expires_at < $6)
+			AND (($7 = '' AND $8 = '') OR EXISTS (
+				SELECT 1 
+				FROM inventory_reservation_items ri
+				JOIN inventory_items ii ON ii.id = ri.inventory_item_id
+				WHERE ri.reservation_id = r.id AND ($7 = '' OR ii.sku = $7) AND ($8 = '' OR ii.seller_id = $8)
+			))
+			AND ($9 = 0 OR (r.created_at, r.id) < ($9, $10))
+		ORDER BY r.created_at DESC, r.id DESC
+		LIMIT $11
+  `
+
+	rows, err := is.db.Query(
+		ctx.Ctx(),
+		stmt,
+		filter.OrderID,
+		filter.Status,
+		filter.CreatedFrom,
+		filter.CreatedTo,
+		filter.ExpiresFrom,
+		filter.ExpiresTo,
+		filter.Sku,
+		filter.SellerID,
+		filter.AfterCreatedAt,
+		filter.AfterID,
+		filter.Limit,
+	)
+	if err != nil {
+		return nil, models.HandleDBError(ctx, err, "inventory.store.InventoryReservationsList", nil)
+	}
+	defer rows.Close()
+
+	result := make([]*pb.InventoryReservation, 0, filter.Limit)
+	for rows.Next() {
+		var ir pb.InventoryReservation
+		var updatedAt int64
+		err := rows.Scan(
+			&ir.Id,
+			&ir.ReservationToken,
+			&ir.OrderId,
+			&ir.Channel,
+			&ir.Status,
+			&ir.ExpiresAt,
+			&ir.CreatedAt,
+			&updatedAt,
+		)
+		if err != nil {
+			return nil, models.HandleDBError(ctx, err, "inventory.store.InventoryReservationsList", nil)
+		}
+
+		if updatedAt > 0 {
+			ir.UpdatedAt = &updatedAt
+		}
+		result = append(result, &ir)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, models.HandleDBError(ctx, err, "inventory.store.InventoryReservationsList", nil)
+	}
+
+	return result, nil
+}
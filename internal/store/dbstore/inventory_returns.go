@@ -0,0 +1,240 @@
+package dbstore
+
+import (
+	pb "github.com/ahmad-khatib0-org/megacommerce-proto/gen/go/inventory/v1"
+	"github.com/ahmad-khatib0-org/megacommerce-shared-go/pkg/models"
+	"github.com/ahmad-khatib0-org/megacommerce-shared-go/pkg/utils"
+	"github.com/jackc/pgx/v5"
+)
+
+// InventoryReturnCreate creates a new return (RMA)
+func (is *InventoryStore) InventoryReturnCreate(ctx *models.Context, tx pgx.Tx, params *pb.InventoryReturn) *models.DBError {
+	stmt := `
+		INSERT INTO inventory_returns (
+			id,
+			rma_number,
+			reservation_id,
+			order_id,
+			status,
+			reason,
+			created_at,
+			updated_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+  `
+
+	_, err := tx.Exec(
+		ctx.Ctx(),
+		stmt,
+		params.Id,
+		params.RmaNumber,
+		params.ReservationId,
+		params.OrderId,
+		params.Status,
+		params.Reason,
+		params.CreatedAt,
+		params.UpdatedAt,
+	)
+
+	return models.HandleDBError(ctx, err, "inventory.store.InventoryReturnCreate", tx)
+}
+
+// InventoryReturnGetByRmaNumber gets a return by its rma number, the return row
+// is locked until the end of tx if tx is not nil
+func (is *InventoryStore) InventoryReturnGetByRmaNumber(ctx *models.Context, tx pgx.Tx, rmaNumber string) (*pb.InventoryReturn, *models.DBError) {
+	stmt := `
+		SELECT
+			id,
+			rma_number,
+			reservation_id,
+			order_id,
+			status,
+			reason,
+			created_at,
+			updated_at
+		FROM inventory_returns
+		WHERE rma_number = $1
+  `
+
+	var ir pb.InventoryReturn
+	var updatedAt int64
+	var row pgx.Row
+	if tx != nil {
+		row = tx.QueryRow(ctx.Context, stmt+" FOR UPDATE", rmaNumber)
+	} else {
+		row = is.db.QueryRow(ctx.Context, stmt, rmaNumber)
+	}
+	err := row.Scan(
+		&ir.Id,
+		&ir.RmaNumber,
+		&ir.ReservationId,
+		&ir.OrderId,
+		&ir.Status,
+		&ir.Reason,
+		&ir.CreatedAt,
+		&updatedAt,
+	)
+	if err != nil {
+		return nil, models.HandleDBError(ctx, err, "inventory.store.InventoryReturnGetByRmaNumber", tx)
+	}
+
+	if updatedAt > 0 {
+		ir.UpdatedAt = &updatedAt
+	}
+
+	return &ir, nil
+}
+
+// InventoryReturnUpdateStatus updates the status of a return
+func (is *InventoryStore) InventoryReturnUpdateStatus(ctx *models.Context, tx pgx.Tx, id string, status string) *models.DBError {
+	stmt := `
+		UPDATE inventory_returns
+		SET status = $1, updated_at = $2
+		WHERE id = $3
+  `
+
+	_, err := tx.Exec(ctx.Ctx(), stmt, status, utils.TimeGetMillis(), id)
+
+	return models.HandleDBError(ctx, err, "inventory.store.InventoryReturnUpdateStatus", tx)
+}
+
+// InventoryReturnItemCreate creates a new return item
+func (is *InventoryStore) InventoryReturnItemCreate(ctx *models.Context, tx pgx.Tx, params *pb.InventoryReturnItem) *models.DBError {
+	stmt := `
+		INSERT INTO inventory_return_items (
+			id,
+			return_id,
+			inventory_item_id,
+			quantity,
+			quantity_restocked,
+			quantity_quarantined,
+			quantity_written_off,
+			created_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+  `
+
+	_, err := tx.Exec(
+		ctx.Ctx(),
+		stmt,
+		params.Id,
+		params.ReturnId,
+		params.InventoryItemId,
+		params.Quantity,
+		params.QuantityRestocked,
+		params.QuantityQuarantined,
+		params.QuantityWrittenOff,
+		params.CreatedAt,
+	)
+
+	return models.HandleDBError(ctx, err, "inventory.store.InventoryReturnItemCreate", tx)
+}
+
+// InventoryReturnItemsGetByReturnID gets all items for a return,
+// limited to the items of the given seller unless sellerID is empty
+func (is *InventoryStore) InventoryReturnItemsGetByReturnID(ctx *models.Context, tx pgx.Tx, sellerID string, returnID string) ([]*pb.InventoryReturnItem, *models.DBError) {
+	stmt := `
+		SELECT
+			ri.id,
+			ri.return_id,
+			ri.inventory_item_id,
+			ri.quantity,
+			ri.quantity_restocked,
+			ri.quantity_quarantined,
+			ri.quantity_written_off,
+			ri.created_at
+		FROM inventory_return_items ri
+		JOIN inventory_items ii ON ii.id = ri.inventory_item_id
+		WHERE ri.return_id = $1 AND ($2 = '' OR ii.seller_id = $2)
+  `
+
+	var rows pgx.Rows
+	var err error
+	if tx != nil {
+		rows, err = tx.Query(ctx.Context, stmt, returnID, sellerID)
+	} else {
+		rows, err = is.db.Query(ctx.Context, stmt, returnID, sellerID)
+	}
+	if err != nil {
+		return nil, models.HandleDBError(ctx, err, "inventory.store.InventoryReturnItemsGetByReturnID", tx)
+	}
+	defer rows.Close()
+
+	var items []*pb.InventoryReturnItem
+	for rows.Next() {
+		var item pb.InventoryReturnItem
+		err := rows.Scan(
+			&item.Id,
+			&item.ReturnId,
+			&item.InventoryItemId,
+			&item.Quantity,
+			&item.QuantityRestocked,
+			&item.QuantityQuarantined,
+			&item.QuantityWrittenOff,
+			&item.CreatedAt,
+		)
+		if err != nil {
+			return nil, models.HandleDBError(ctx, err, "inventory.store.InventoryReturnItemsGetByReturnID", tx)
+		}
+		items = append(items, &item)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, models.HandleDBError(ctx, err, "inventory.store.InventoryReturnItemsGetByReturnID", tx)
+	}
+
+	return items, nil
+}
+
+// InventoryReturnItemsAuthorized sums the units that were already authorized for return
+// per inventory item, over every return of a reservation
+func (is *InventoryStore) InventoryReturnItemsAuthorized(ctx *models.Context, tx pgx.Tx, reservationID string) (map[string]int32, *models.DBError) {
+	stmt := `
+		SELECT ri.inventory_item_id, SUM(ri.quantity)
+		FROM inventory_return_items ri
+		JOIN inventory_returns r ON r.id = ri.return_id
+		WHERE r.reservation_id = $1
+		GROUP BY ri.inventory_item_id
+  `
+
+	rows, err := tx.Query(ctx.Context, stmt, reservationID)
+	if err != nil {
+		return nil, models.HandleDBError(ctx, err, "inventory.store.InventoryReturnItemsAuthorized", tx)
+	}
+	defer rows.Close()
+
+	result := map[string]int32{}
+	for rows.Next() {
+		var id string
+		var quantity int32
+		if err := rows.Scan(&id, &quantity); err != nil {
+			return nil, models.HandleDBError(ctx, err, "inventory.store.InventoryReturnItemsAuthorized", tx)
+		}
+		result[id] = quantity
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, models.HandleDBError(ctx, err, "inventory.store.InventoryReturnItemsAuthorized", tx)
+	}
+
+	return result, nil
+}
+
+// InventoryReturnItemReceive adds received units to a return item by disposition, it returns
+// received = false if that would receive more units than were authorized for the item, or if any of them is negative
+func (is *InventoryStore) InventoryReturnItemReceive(ctx *models.Context, tx pgx.Tx, id string, restocked int32, quarantined int32, writtenOff int32) (bool, *models.DBError) {
+	stmt := `
+		UPDATE inventory_return_items
+		SET
+			quantity_restocked = quantity_restocked + $1,
+			quantity_quarantined = quantity_quarantined + $2,
+			quantity_written_off = quantity_written_off + $3
+		WHERE id = $4 AND $1 >= 0 AND $2 >= 0 AND $3 >= 0
+			AND quantity_restocked + quantity_quarantined + quantity_written_off + $1 + $2 + $3 <= quantity
+  `
+
+	result, err := tx.Exec(ctx.Ctx(), stmt, restocked, quarantined, writtenOff, id)
+	if err != nil {
+		return false, models.HandleDBError(ctx, err, "inventory.store.InventoryReturnItemReceive", tx)
+	}
+
+	return result.RowsAffected() > 0, nil
+}
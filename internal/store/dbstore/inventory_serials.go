@@ -0,0 +1,317 @@
+package dbstore
+
+import (
+	pb "github.com/ahmad-khatib0-org/megacommerce-proto/gen/go/inventory/v1"
+	"github.com/ahmad-khatib0-org/megacommerce-shared-go/pkg/models"
+	"github.com/ahmad-khatib0-org/megacommerce-shared-go/pkg/utils"
+	"github.com/jackc/pgx/v5"
+)
+
+// InventorySerialCreate creates a new serial
+func (is *InventoryStore) InventorySerialCreate(ctx *models.Context, tx pgx.Tx, params *pb.InventorySerial) *models.DBError {
+	stmt := `
+		INSERT INTO inventory_serials (
+			id,
+			inventory_item_id,
+			location_id,
+			serial_number,
+			status,
+			reservation_item_id,
+			created_at,
+			updated_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+  `
+
+	_, err := tx.Exec(
+		ctx.Ctx(),
+		stmt,
+		params.Id,
+		params.InventoryItemId,
+		params.LocationId,
+		params.SerialNumber,
+		params.Status,
+		params.ReservationItemId,
+		params.CreatedAt,
+		params.UpdatedAt,
+	)
+
+	return models.HandleDBError(ctx, err, "inventory.store.InventorySerialCreate", tx)
+}
+
+// InventorySerialGetByNumber gets a serial of an inventory item by its number and locks it until the end of tx
+func (is *InventoryStore) InventorySerialGetByNumber(ctx *models.Context, tx pgx.Tx, inventoryItemID string, serialNumber string) (*pb.InventorySerial, *models.DBError) {
+	stmt := `
+		SELECT
+			id,
+			inventory_item_id,
+			location_id,
+			serial_number,
+			status,
+			reservation_item_id,
+			created_at,
+			updated_at
+		FROM inventory_serials
+		WHERE inventory_item_id = $1 AND serial_number = $2
+		FOR UPDATE
+  `
+
+	var serial pb.InventorySerial
+	var updatedAt int64
+	err := tx.QueryRow(ctx.Ctx(), stmt, inventoryItemID, serialNumber).Scan(
+		&serial.Id,
+		&serial.InventoryItemId,
+		&serial.LocationId,
+		&serial.SerialNumber,
+		&serial.Status,
+		&serial.ReservationItemId,
+		&serial.CreatedAt,
+		&updatedAt,
+	)
+	if err != nil {
+		return nil, models.HandleDBError(ctx, err, "inventory.store.InventorySerialGetByNumber", tx)
+	}
+
+	if updatedAt > 0 {
+		serial.UpdatedAt = &updatedAt
+	}
+
+	return &serial, nil
+}
+
+// InventorySerialsGetByNumber gets the serials with the given number across the inventory items of a seller,
+// a serial number is only unique per item, an empty sellerID matches every seller
+func (is *InventoryStore) InventorySerialsGetByNumber(ctx *models.Context, sellerID string, serialNumber string) ([]*pb.InventorySerial, *models.DBError) {
+	stmt := `
+		SELECT
+			s.id,
+			s.inventory_item_id,
+			s.location_id,
+			s.serial_number,
+			s.status,
+			s.reservation_item_id,
+			s.created_at,
+			s.updated_at
+		FROM inventory_serials s
+		JOIN inventory_items i ON i.id = s.inventory_item_id
+		WHERE s.serial_number = $1 AND ($2 = '' OR i.seller_id = $2)
+		ORDER BY s.created_at, s.id
+  `
+
+	rows, err := is.db.Query(ctx.Ctx(), stmt, serialNumber, sellerID)
+	if err != nil {
+		return nil, models.HandleDBError(ctx, err, "inventory.store.InventorySerialsGetByNumber", nil)
+	}
+	defer rows.Close()
+
+	result := make([]*pb.InventorySerial, 0)
+	for rows.Next() {
+		var serial pb.InventorySerial
+		var updatedAt int64
+		err := rows.Scan(
+			&serial.Id,
+			&serial.InventoryItemId,
+			&serial.LocationId,
+			&serial.SerialNumber,
+			&serial.Status,
+			&serial.ReservationItemId,
+			&serial.CreatedAt,
+			&updatedAt,
+		)
+		if err != nil {
+			return nil, models.HandleDBError(ctx, err, "inventory.store.InventorySerialsGetByNumber", nil)
+		}
+
+		if updatedAt > 0 {
+			serial.UpdatedAt = &updatedAt
+		}
+		result = append(result, &serial)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, models.HandleDBError(ctx, err, "inventory.store.InventorySerialsGetByNumber", nil)
+	}
+
+	return result, nil
+}
+
+// InventorySerialsGetByReservationItem gets the serials that a reservation item holds and locks them until the end of tx
+func (is *InventoryStore) InventorySerialsGetByReservationItem(ctx *models.Context, tx pgx.Tx, reservationItemID string) ([]*pb.InventorySerial, *models.DBError) {
+	stmt := `
+		SELECT
+			id,
+			inventory_item_id,
+			location_id,
+			serial_number,
+			status,
+			reservation_item_id,
+			created_at,
+			updated_at
+		FROM inventory_serials
+		WHERE reservation_item_id = $1
+		ORDER BY updated_at, id
+		FOR UPDATE
+  `
+
+	rows, err := tx.Query(ctx.Ctx(), stmt, reservationItemID)
+	if err != nil {
+		return nil, models.HandleDBError(ctx, err, "inventory.store.InventorySerialsGetByReservationItem", tx)
+	}
+	defer rows.Close()
+
+	result := make([]*pb.InventorySerial, 0)
+	for rows.Next() {
+		var serial pb.InventorySerial
+		var updatedAt int64
+		err := rows.Scan(
+			&serial.Id,
+			&serial.InventoryItemId,
+			&serial.LocationId,
+			&serial.SerialNumber,
+			&serial.Status,
+			&serial.ReservationItemId,
+			&serial.CreatedAt,
+			&updatedAt,
+		)
+		if err != nil {
+			return nil, models.HandleDBError(ctx, err, "inventory.store.InventorySerialsGetByReservationItem", tx)
+		}
+
+		if updatedAt > 0 {
+			serial.UpdatedAt = &updatedAt
+		}
+		result = append(result, &serial)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, models.HandleDBError(ctx, err, "inventory.store.InventorySerialsGetByReservationItem", tx)
+	}
+
+	return result, nil
+}
+
+// InventorySerialsCount counts the serials of an inventory item that are in one of statuses, every serial
+// is counted if statuses is empty. You can pass nil for the tx argument, and a normal db query will be used
+func (is *InventoryStore) InventorySerialsCount(ctx *models.Context, tx pgx.Tx, inventoryItemID string, statuses []string) (int32, *models.DBError) {
+	stmt := `
+		SELECT COUNT(*)
+		FROM inventory_serials
+		WHERE inventory_item_id = $1 AND (cardinality($2::text[]) = 0 OR status = ANY($2))
+  `
+
+	if statuses == nil {
+		statuses = []string{}
+	}
+
+	var count int32
+	var err error
+	if tx != nil {
+		err = tx.QueryRow(ctx.Ctx(), stmt, inventoryItemID, statuses).Scan(&count)
+	} else {
+		err = is.db.QueryRow(ctx.Ctx(), stmt, inventoryItemID, statuses).Scan(&count)
+	}
+	if err != nil {
+		return 0, models.HandleDBError(ctx, err, "inventory.store.InventorySerialsCount", tx)
+	}
+
+	return count, nil
+}
+
+// InventorySerialUpdateStatus sets the status of a serial and the reservation item that holds it, a nil
+// reservationItemID detaches the serial from any reservation item
+func (is *InventoryStore) InventorySerialUpdateStatus(ctx *models.Context, tx pgx.Tx, id string, status string, reservationItemID *string) *models.DBError {
+	stmt := `UPDATE inventory_serials SET status = $1, reservation_item_id = $2, updated_at = $3 WHERE id = $4`
+
+	_, err := tx.Exec(ctx.Ctx(), stmt, status, reservationItemID, utils.TimeGetMillis(), id)
+
+	return models.HandleDBError(ctx, err, "inventory.store.InventorySerialUpdateStatus", tx)
+}
+
+// InventorySerialRelocate moves a serial to another inventory item and location with the given status,
+// it's released from the reservation item that held it if any
+func (is *InventoryStore) InventorySerialRelocate(ctx *models.Context, tx pgx.Tx, id string, inventoryItemID string, locationID *string, status string) *models.DBError {
+	stmt := `
+		UPDATE inventory_serials
+		SET inventory_item_id = $1, location_id = $2, status = $3, reservation_item_id = NULL, updated_at = $4
+		WHERE id = $5
+  `
+
+	_, err := tx.Exec(ctx.Ctx(), stmt, inventoryItemID, locationID, status, utils.TimeGetMillis(), id)
+
+	return models.HandleDBError(ctx, err, "inventory.store.InventorySerialRelocate", tx)
+}
+
+// InventorySerialMovementCreate records a change of the status of a serial
+func (is *InventoryStore) InventorySerialMovementCreate(ctx *models.Context, tx pgx.Tx, params *pb.InventorySerialMovement) *models.DBError {
+	stmt := `
+		INSERT INTO inventory_serial_movements (
+			id,
+			serial_id,
+			movement_type,
+			status,
+			reference_id,
+			reason,
+			created_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7)
+  `
+
+	_, err := tx.Exec(
+		ctx.Ctx(),
+		stmt,
+		params.Id,
+		params.SerialId,
+		params.MovementType,
+		params.Status,
+		params.ReferenceId,
+		params.Reason,
+		params.CreatedAt,
+	)
+
+	return models.HandleDBError(ctx, err, "inventory.store.InventorySerialMovementCreate", tx)
+}
+
+// InventorySerialMovementsGet gets the movements of a serial, oldest first
+func (is *InventoryStore) InventorySerialMovementsGet(ctx *models.Context, serialID string) ([]*pb.InventorySerialMovement, *models.DBError) {
+	stmt := `
+		SELECT
+			id,
+			serial_id,
+			movement_type,
+			status,
+			reference_id,
+			reason,
+			created_at
+		FROM inventory_serial_movements
+		WHERE serial_id = $1
+		ORDER BY created_at, id
+  `
+
+	rows, err := is.db.Query(ctx.Ctx(), stmt, serialID)
+	if err != nil {
+		return nil, models.HandleDBError(ctx, err, "inventory.store.InventorySerialMovementsGet", nil)
+	}
+	defer rows.Close()
+
+	result := make([]*pb.InventorySerialMovement, 0)
+	for rows.Next() {
+		var m pb.InventorySerialMovement
+		err := rows.Scan(
+			&m.Id,
+			&m.SerialId,
+			&m.MovementType,
+			&m.Status,
+			&m.ReferenceId,
+			&m.Reason,
+			&m.CreatedAt,
+		)
+		if err != nil {
+			return nil, models.HandleDBError(ctx, err, "inventory.store.InventorySerialMovementsGet", nil)
+		}
+		result = append(result, &m)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, models.HandleDBError(ctx, err, "inventory.store.InventorySerialMovementsGet", nil)
+	}
+
+	return result, nil
+}
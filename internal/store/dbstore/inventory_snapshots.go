@@ -0,0 +1,108 @@
+package dbstore
+
+import (
+	pb "github.com/ahmad-khatib0-org/megacommerce-proto/gen/go/inventory/v1"
+	"github.com/ahmad-khatib0-org/megacommerce-shared-go/pkg/models"
+)
+
+// InventorySnapshotsTake records the quantities of every inventory item at takenAt, once per item and day (UTC),
+// the items that already have a snapshot of that day are skipped. It returns the number of snapshots taken
+func (is *InventoryStore) InventorySnapshotsTake(ctx *models.Context, takenAt int64) (int64, *models.DBError) {
+	stmt := `
+		INSERT INTO inventory_snapshots (
+			inventory_item_id,
+			snapshot_date,
+			quantity_total,
+			quantity_reserved,
+			quantity_available,
+			quantity_in_transit,
+			quantity_quarantined,
+			quantity_damaged,
+			quantity_on_hold,
+			taken_at
+		)
+		SELECT
+			id,
+			(to_timestamp($1 / 1000.0) AT TIME ZONE 'UTC')::date,
+			quantity_total,
+			quantity_reserved,
+			quantity_available,
+			quantity_in_transit,
+			quantity_quarantined,
+			quantity_damaged,
+			quantity_on_hold,
+			$1
+		FROM inventory_items
+		ON CONFLICT (inventory_item_id, snapshot_date) DO NOTHING
+  `
+
+	result, err := is.db.Exec(ctx.Ctx(), stmt, takenAt)
+	if err != nil {
+		return 0, models.HandleDBError(ctx, err, "inventory.store.InventorySnapshotsTake", nil)
+	}
+
+	return result.RowsAffected(), nil
+}
+
+// InventorySnapshotsDelete deletes the snapshots taken before a date, it returns the number of snapshots deleted
+func (is *InventoryStore) InventorySnapshotsDelete(ctx *models.Context, before int64) (int64, *models.DBError) {
+	stmt := `DELETE FROM inventory_snapshots WHERE taken_at < $1`
+
+	result, err := is.db.Exec(ctx.Ctx(), stmt, before)
+	if err != nil {
+		return 0, models.HandleDBError(ctx, err, "inventory.store.InventorySnapshotsDelete", nil)
+	}
+
+	return result.RowsAffected(), nil
+}
+
+// InventorySnapshotsFirstTakenAt gets the date of the earliest snapshot that is kept
+func (is *InventoryStore) InventorySnapshotsFirstTakenAt(ctx *models.Context) (int64, *models.DBError) {
+	stmt := `SELECT taken_at FROM inventory_snapshots ORDER BY taken_at LIMIT 1`
+
+	var takenAt int64
+	if err := is.db.QueryRow(ctx.Ctx(), stmt).Scan(&takenAt); err != nil {
+		return 0, models.HandleDBError(ctx, err, "inventory.store.InventorySnapshotsFirstTakenAt", nil)
+	}
+
+	return takenAt, nil
+}
+
+// InventorySnapshotNearest gets the latest snapshot of an inventory item taken at or before a date,
+// or the earliest one taken after it if there's none
+func (is *InventoryStore) InventorySnapshotNearest(ctx *models.Context, inventoryItemID string, at int64) (*pb.InventorySnapshot, *models.DBError) {
+	stmt := `
+		SELECT
+			inventory_item_id,
+			quantity_total,
+			quantity_reserved,
+			quantity_available,
+			quantity_in_transit,
+			quantity_quarantined,
+			quantity_damaged,
+			quantity_on_hold,
+			taken_at
+		FROM inventory_snapshots
+		WHERE inventory_item_id = $1
+		ORDER BY taken_at <= $2 DESC, ABS(taken_at - $2)
+		LIMIT 1
+  `
+
+	var s pb.InventorySnapshot
+	err := is.db.QueryRow(ctx.Ctx(), stmt, inventoryItemID, at).Scan(
+		&s.InventoryItemId,
+		&s.QuantityTotal,
+		&s.QuantityReserved,
+		&s.QuantityAvailable,
+		&s.QuantityInTransit,
+		&s.QuantityQuarantined,
+		&s.QuantityDamaged,
+		&s.QuantityOnHold,
+		&s.TakenAt,
+	)
+	if err != nil {
+		return nil, models.HandleDBError(ctx, err, "inventory.store.InventorySnapshotNearest", nil)
+	}
+
+	return &s, nil
+}
@@ -0,0 +1,236 @@
+package dbstore
+
+import (
+	pb "github.com/ahmad-khatib0-org/megacommerce-proto/gen/go/inventory/v1"
+	"github.com/ahmad-khatib0-org/megacommerce-shared-go/pkg/models"
+	"github.com/ahmad-khatib0-org/megacommerce-shared-go/pkg/utils"
+	"github.com/jackc/pgx/v5"
+)
+
+// InventoryTransferCreate creates a new transfer
+func (is *InventoryStore) InventoryTransferCreate(ctx *models.Context, tx pgx.Tx, params *pb.InventoryTransfer) *models.DBError {
+	stmt := `
+		INSERT INTO inventory_transfers (
+			id,
+			transfer_number,
+			seller_id,
+			from_location_id,
+			to_location_id,
+			status,
+			note,
+			created_at,
+			updated_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+  `
+
+	_, err := tx.Exec(
+		ctx.Ctx(),
+		stmt,
+		params.Id,
+		params.TransferNumber,
+		params.SellerId,
+		params.FromLocationId,
+		params.ToLocationId,
+		params.Status,
+		params.Note,
+		params.CreatedAt,
+		params.UpdatedAt,
+	)
+
+	return models.HandleDBError(ctx, err, "inventory.store.InventoryTransferCreate", tx)
+}
+
+// InventoryTransferGetByNumber gets a transfer by its number, the transfer row
+// is locked until the end of tx if tx is not nil
+func (is *InventoryStore) InventoryTransferGetByNumber(ctx *models.Context, tx pgx.Tx, transferNumber string) (*pb.InventoryTransfer, *models.DBError) {
+	stmt := `
+		SELECT
+			id,
+			transfer_number,
+			seller_id,
+			from_location_id,
+			to_location_id,
+			status,
+			note,
+			created_at,
+			updated_at
+		FROM inventory_transfers
+		WHERE transfer_number = $1
+  `
+
+	var it pb.InventoryTransfer
+	var updatedAt int64
+	var row pgx.Row
+	if tx != nil {
+		row = tx.QueryRow(ctx.Context, stmt+" FOR UPDATE", transferNumber)
+	} else {
+		row = is.db.QueryRow(ctx.Context, stmt, transferNumber)
+	}
+	err := row.Scan(
+		&it.Id,
+		&it.TransferNumber,
+		&it.SellerId,
+		&it.FromLocationId,
+		&it.ToLocationId,
+		&it.Status,
+		&it.Note,
+		&it.CreatedAt,
+		&updatedAt,
+	)
+	if err != nil {
+		return nil, models.HandleDBError(ctx, err, "inventory.store.InventoryTransferGetByNumber", tx)
+	}
+
+	if updatedAt > 0 {
+		it.UpdatedAt = &updatedAt
+	}
+
+	return &it, nil
+}
+
+// InventoryTransferUpdateStatus updates the status of a transfer
+func (is *InventoryStore) InventoryTransferUpdateStatus(ctx *models.Context, tx pgx.Tx, id string, status string) *models.DBError {
+	stmt := `
+		UPDATE inventory_transfers
+		SET status = $1, updated_at = $2
+		WHERE id = $3
+  `
+
+	_, err := tx.Exec(ctx.Ctx(), stmt, status, utils.TimeGetMillis(), id)
+
+	return models.HandleDBError(ctx, err, "inventory.store.InventoryTransferUpdateStatus", tx)
+}
+
+// InventoryTransferItemCreate creates a new transfer item
+func (is *InventoryStore) InventoryTransferItemCreate(ctx *models.Context, tx pgx.Tx, params *pb.InventoryTransferItem) *models.DBError {
+	stmt := `
+		INSERT INTO inventory_transfer_items (
+			id,
+			transfer_id,
+			sku,
+			source_item_id,
+			destination_item_id,
+			quantity,
+			quantity_shipped,
+			quantity_received,
+			serial_numbers,
+			lot_number,
+			cost_shipped,
+			created_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+  `
+
+	_, err := tx.Exec(
+		ctx.Ctx(),
+		stmt,
+		params.Id,
+		params.TransferId,
+		params.Sku,
+		params.SourceItemId,
+		params.DestinationItemId,
+		params.Quantity,
+		params.QuantityShipped,
+		params.QuantityReceived,
+		params.SerialNumbers,
+		params.LotNumber,
+		params.CostShipped,
+		params.CreatedAt,
+	)
+
+	return models.HandleDBError(ctx, err, "inventory.store.InventoryTransferItemCreate", tx)
+}
+
+// InventoryTransferItemsGetByTransferID gets all items for a transfer,
+// you can pass nil for the tx argument, and a normal db query will be used
+func (is *InventoryStore) InventoryTransferItemsGetByTransferID(ctx *models.Context, tx pgx.Tx, transferID string) ([]*pb.InventoryTransferItem, *models.DBError) {
+	stmt := `
+		SELECT
+			id,
+			transfer_id,
+			sku,
+			source_item_id,
+			destination_item_id,
+			quantity,
+			quantity_shipped,
+			quantity_received,
+			COALESCE(serial_numbers, '{}'),
+			lot_number,
+			cost_shipped,
+			created_at
+		FROM inventory_transfer_items
+		WHERE transfer_id = $1
+		ORDER BY sku
+  `
+
+	var rows pgx.Rows
+	var err error
+	if tx != nil {
+		rows, err = tx.Query(ctx.Context, stmt, transferID)
+	} else {
+		rows, err = is.db.Query(ctx.Context, stmt, transferID)
+	}
+	if err != nil {
+		return nil, models.HandleDBError(ctx, err, "inventory.store.InventoryTransferItemsGetByTransferID", tx)
+	}
+	defer rows.Close()
+
+	var items []*pb.InventoryTransferItem
+	for rows.Next() {
+		var item pb.InventoryTransferItem
+		err := rows.Scan(
+			&item.Id,
+			&item.TransferId,
+			&item.Sku,
+			&item.SourceItemId,
+			&item.DestinationItemId,
+			&item.Quantity,
+			&item.QuantityShipped,
+			&item.QuantityReceived,
+			&item.SerialNumbers,
+			&item.LotNumber,
+			&item.CostShipped,
+			&item.CreatedAt,
+		)
+		if err != nil {
+			return nil, models.HandleDBError(ctx, err, "inventory.store.InventoryTransferItemsGetByTransferID", tx)
+		}
+		items = append(items, &item)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, models.HandleDBError(ctx, err, "inventory.store.InventoryTransferItemsGetByTransferID", tx)
+	}
+
+	return items, nil
+}
+
+// InventoryTransferItemShip records the shipped quantity of a transfer item, what the shipped units cost at
+// the source, and the inventory item at the destination location that will receive it
+func (is *InventoryStore) InventoryTransferItemShip(ctx *models.Context, tx pgx.Tx, id string, destinationItemID string, quantity int32, cost int64) *models.DBError {
+	stmt := `
+		UPDATE inventory_transfer_items
+		SET destination_item_id = $1, quantity_shipped = $2, cost_shipped = $3
+		WHERE id = $4
+  `
+
+	_, err := tx.Exec(ctx.Ctx(), stmt, destinationItemID, quantity, cost, id)
+
+	return models.HandleDBError(ctx, err, "inventory.store.InventoryTransferItemShip", tx)
+}
+
+// InventoryTransferItemReceive adds received units to a transfer item, it returns received = false
+// if that would receive more units than were shipped
+func (is *InventoryStore) InventoryTransferItemReceive(ctx *models.Context, tx pgx.Tx, id string, quantity int32) (bool, *models.DBError) {
+	stmt := `
+		UPDATE inventory_transfer_items
+		SET quantity_received = quantity_received + $1
+		WHERE id = $2 AND quantity_received + $1 <= quantity_shipped
+  `
+
+	result, err := tx.Exec(ctx.Ctx(), stmt, quantity, id)
+	if err != nil {
+		return false, models.HandleDBError(ctx, err, "inventory.store.InventoryTransferItemReceive", tx)
+	}
+
+	return result.RowsAffected() > 0, nil
+}
@@ -0,0 +1,91 @@
+package dbstore
+
+import (
+	pb "github.com/ahmad-khatib0-org/megacommerce-proto/gen/go/inventory/v1"
+	"github.com/ahmad-khatib0-org/megacommerce-shared-go/pkg/models"
+	"github.com/jackc/pgx/v5"
+)
+
+// InventoryUnitCreate adds a unit of measure to an inventory item
+func (is *InventoryStore) InventoryUnitCreate(ctx *models.Context, tx pgx.Tx, params *pb.InventoryUnit) *models.DBError {
+	stmt := `
+		INSERT INTO inventory_units (
+			id,
+			inventory_item_id,
+			unit,
+			factor,
+			created_at
+		) VALUES ($1, $2, $3, $4, $5)
+  `
+
+	_, err := tx.Exec(
+		ctx.Ctx(),
+		stmt,
+		params.Id,
+		params.InventoryItemId,
+		params.Unit,
+		params.Factor,
+		params.CreatedAt,
+	)
+
+	return models.HandleDBError(ctx, err, "inventory.store.InventoryUnitCreate", tx)
+}
+
+// InventoryUnitsDelete removes every unit of measure of an inventory item
+func (is *InventoryStore) InventoryUnitsDelete(ctx *models.Context, tx pgx.Tx, inventoryItemID string) *models.DBError {
+	stmt := `DELETE FROM inventory_units WHERE inventory_item_id = $1`
+
+	_, err := tx.Exec(ctx.Ctx(), stmt, inventoryItemID)
+
+	return models.HandleDBError(ctx, err, "inventory.store.InventoryUnitsDelete", tx)
+}
+
+// InventoryUnitsGet gets the units of measure of an inventory item, smallest first,
+// you can pass nil for the tx argument, and a normal db query will be used
+func (is *InventoryStore) InventoryUnitsGet(ctx *models.Context, tx pgx.Tx, inventoryItemID string) ([]*pb.InventoryUnit, *models.DBError) {
+	stmt := `
+		SELECT
+			id,
+			inventory_item_id,
+			unit,
+			factor,
+			created_at
+		FROM inventory_units
+		WHERE inventory_item_id = $1
+		ORDER BY factor, unit
+  `
+
+	var rows pgx.Rows
+	var err error
+	if tx != nil {
+		rows, err = tx.Query(ctx.Context, stmt, inventoryItemID)
+	} else {
+		rows, err = is.db.Query(ctx.Context, stmt, inventoryItemID)
+	}
+	if err != nil {
+		return nil, models.HandleDBError(ctx, err, "inventory.store.InventoryUnitsGet", tx)
+	}
+	defer rows.Close()
+
+	result := make([]*pb.InventoryUnit, 0)
+	for rows.Next() {
+		var iu pb.InventoryUnit
+		err := rows.Scan(
+			&iu.Id,
+			&iu.InventoryItemId,
+			&iu.Unit,
+			&iu.Factor,
+			&iu.CreatedAt,
+		)
+		if err != nil {
+			return nil, models.HandleDBError(ctx, err, "inventory.store.InventoryUnitsGet", tx)
+		}
+		result = append(result, &iu)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, models.HandleDBError(ctx, err, "inventory.store.InventoryUnitsGet", tx)
+	}
+
+	return result, nil
+}
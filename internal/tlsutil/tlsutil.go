@@ -0,0 +1,218 @@
+// Package tlsutil builds the tls configurations of the grpc server and clients,
+// the certificates are reloaded from disk whenever their files change
+package tlsutil
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/ahmad-khatib0-org/megacommerce-shared-go/pkg/logger"
+	"github.com/ahmad-khatib0-org/megacommerce-shared-go/pkg/models"
+	"github.com/fsnotify/fsnotify"
+)
+
+type Reloader struct {
+	certFile string
+	keyFile  string
+	caFile   string
+	log      *logger.Logger
+	watcher  *fsnotify.Watcher
+	mu       sync.RWMutex
+	cert     *tls.Certificate
+	pool     *x509.CertPool
+}
+
+// NewReloader loads the key pair and the optional ca bundle, then watches them for changes
+func NewReloader(certFile, keyFile, caFile string, log *logger.Logger) (*Reloader, *models.InternalError) {
+	ie := func(err error, msg string) *models.InternalError {
+		return &models.InternalError{Path: "inventory.tlsutil.NewReloader", Err: err, Msg: msg}
+	}
+
+	r := &Reloader{certFile: certFile, keyFile: keyFile, caFile: caFile, log: log}
+	if err := r.reload(); err != nil {
+		return nil, ie(err, "failed to load the tls certificates")
+	}
+
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, ie(err, "failed to create a certificates watcher")
+	}
+
+	// watch the directories rather than the files, so replacing a file
+	// (or swapping a mounted secret symlink) is still noticed
+	dirs := map[string]bool{}
+	for _, f := range r.files() {
+		dirs[filepath.Dir(f)] = true
+	}
+	for dir := range dirs {
+		if err := watcher.Add(dir); err != nil {
+			watcher.Close()
+			return nil, ie(err, fmt.Sprintf("failed to watch the certificates directory %s", dir))
+		}
+	}
+
+	r.watcher = watcher
+	go r.watch()
+
+	return r, nil
+}
+
+func (r *Reloader) files() []string {
+	files := []string{}
+	for _, f := range []string{r.certFile, r.keyFile, r.caFile} {
+		if f != "" {
+			files = append(files, filepath.Clean(f))
+		}
+	}
+	return files
+}
+
+func (r *Reloader) reload() error {
+	var cert *tls.Certificate
+	if r.certFile != "" || r.keyFile != "" {
+		c, err := tls.LoadX509KeyPair(r.certFile, r.keyFile)
+		if err != nil {
+			return err
+		}
+		cert = &c
+	}
+
+	var pool *x509.CertPool
+	if r.caFile != "" {
+		data, err := os.ReadFile(r.caFile)
+		if err != nil {
+			return err
+		}
+		pool = x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(data) {
+			return errors.New("no certificates found in the ca file " + r.caFile)
+		}
+	}
+
+	r.mu.Lock()
+	r.cert = cert
+	r.pool = pool
+	r.mu.Unlock()
+	return nil
+}
+
+func (r *Reloader) watch() {
+	watched := map[string]bool{}
+	for _, f := range r.files() {
+		watched[f] = true
+	}
+
+	for {
+		select {
+		case event, ok := <-r.watcher.Events:
+			if !ok {
+				return
+			}
+			// kubernetes swaps secrets through a ..data symlink, which never matches a file name
+			if !watched[filepath.Clean(event.Name)] && filepath.Base(event.Name) != "..data" {
+				continue
+			}
+			if event.Op&(fsnotify.Write|fsnotify.Create|fsnotify.Rename) == 0 {
+				continue
+			}
+			if err := r.reload(); err != nil {
+				r.log.Errorf("inventory.tlsutil.watch: failed to reload the tls certificates, keeping the current ones, err: %v", err)
+				continue
+			}
+			r.log.Infof("tls certificates reloaded from %s", r.certFile)
+		case err, ok := <-r.watcher.Errors:
+			if !ok {
+				return
+			}
+			r.log.Errorf("inventory.tlsutil.watch: certificates watcher error: %v", err)
+		}
+	}
+}
+
+func (r *Reloader) Close() error {
+	return r.watcher.Close()
+}
+
+func (r *Reloader) certificate() (*tls.Certificate, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if r.cert == nil {
+		return nil, errors.New("no certificate is configured")
+	}
+	return r.cert, nil
+}
+
+func (r *Reloader) caPool() *x509.CertPool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.pool
+}
+
+// ServerConfig returns the tls config of a server, client certificates are verified
+// against the ca bundle if one is configured, and required if requireClientCert is true
+func (r *Reloader) ServerConfig(requireClientCert bool) *tls.Config {
+	base := &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		GetCertificate: func(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+			return r.certificate()
+		},
+	}
+
+	// a fresh config per handshake is the only way to pick up a reloaded ClientCAs pool
+	base.GetConfigForClient = func(*tls.ClientHelloInfo) (*tls.Config, error) {
+		cfg := base.Clone()
+		cfg.GetConfigForClient = nil
+		pool := r.caPool()
+		switch {
+		case pool != nil && requireClientCert:
+			cfg.ClientCAs = pool
+			cfg.ClientAuth = tls.RequireAndVerifyClientCert
+		case pool != nil:
+			cfg.ClientCAs = pool
+			cfg.ClientAuth = tls.VerifyClientCertIfGiven
+		}
+		return cfg, nil
+	}
+
+	return base
+}
+
+// ClientConfig returns the tls config of a client, presenting the key pair (if any)
+// and verifying the server against the current ca bundle (or the system roots if none)
+func (r *Reloader) ClientConfig(serverName string) *tls.Config {
+	return &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		ServerName: serverName,
+		GetClientCertificate: func(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+			cert, err := r.certificate()
+			if err != nil {
+				// no client certificate, let the server decide whether that's acceptable
+				return &tls.Certificate{}, nil
+			}
+			return cert, nil
+		},
+		// the default verification is replaced by VerifyConnection below, which
+		// checks against the reloaded pool instead of a RootCAs fixed at dial time
+		InsecureSkipVerify: true,
+		VerifyConnection: func(cs tls.ConnectionState) error {
+			if len(cs.PeerCertificates) == 0 {
+				return errors.New("the server presented no certificates")
+			}
+			opts := x509.VerifyOptions{
+				DNSName:       serverName,
+				Roots:         r.caPool(),
+				Intermediates: x509.NewCertPool(),
+			}
+			for _, c := range cs.PeerCertificates[1:] {
+				opts.Intermediates.AddCert(c)
+			}
+			_, err := cs.PeerCertificates[0].Verify(opts)
+			return err
+		},
+	}
+}
@@ -0,0 +1,263 @@
+package tlsutil
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ahmad-khatib0-org/megacommerce-shared-go/pkg/logger"
+)
+
+type testCA struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+	pem  []byte
+}
+
+func newTestCA(t *testing.T, name string) *testCA {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(time.Now().UnixNano()),
+		Subject:               pkix.Name{CommonName: name},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &testCA{cert: cert, key: key, pem: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})}
+}
+
+// issue signs a leaf certificate for name, usable by a server if server is true and by a client otherwise
+func (ca *testCA) issue(t *testing.T, name string, server bool) (certPEM []byte, keyPEM []byte) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: name},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	if server {
+		tmpl.ExtKeyUsage = []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth}
+		tmpl.DNSNames = []string{name}
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, ca.cert, &key.PublicKey, ca.key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+}
+
+// writeFiles writes the key pair and the ca bundle of a reloader to dir, and returns their paths
+func writeFiles(t *testing.T, dir string, certPEM []byte, keyPEM []byte, caPEM []byte) (certFile string, keyFile string, caFile string) {
+	t.Helper()
+	certFile, keyFile, caFile = filepath.Join(dir, "tls.crt"), filepath.Join(dir, "tls.key"), filepath.Join(dir, "ca.crt")
+	for file, data := range map[string][]byte{keyFile: keyPEM, certFile: certPEM, caFile: caPEM} {
+		if err := os.WriteFile(file, data, 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return certFile, keyFile, caFile
+}
+
+func newTestReloader(t *testing.T, certFile string, keyFile string, caFile string) *Reloader {
+	t.Helper()
+	log, err := logger.InitLogger("test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	r, ie := NewReloader(certFile, keyFile, caFile, log)
+	if ie != nil {
+		t.Fatalf("failed to create a reloader: %v", ie.Err)
+	}
+	t.Cleanup(func() { r.Close() })
+	return r
+}
+
+// newFilesReloader writes the key pair and the ca bundle to a temporary directory, and loads them
+func newFilesReloader(t *testing.T, certPEM []byte, keyPEM []byte, caPEM []byte) *Reloader {
+	t.Helper()
+	certFile, keyFile, caFile := writeFiles(t, t.TempDir(), certPEM, keyPEM, caPEM)
+	return newTestReloader(t, certFile, keyFile, caFile)
+}
+
+// handshake runs a tls handshake over a loopback connection, and returns the errors of the server and the client
+func handshake(t *testing.T, serverCfg *tls.Config, clientCfg *tls.Config) (serverErr error, clientErr error) {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+
+	result := make(chan error, 1)
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			result <- err
+			return
+		}
+		defer conn.Close()
+		conn.SetDeadline(time.Now().Add(5 * time.Second))
+		server := tls.Server(conn, serverCfg)
+		if err := server.Handshake(); err != nil {
+			result <- err
+			return
+		}
+		_, err = server.Write([]byte{1})
+		result <- err
+	}()
+
+	conn, err := net.Dial("tcp", ln.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(5 * time.Second))
+	client := tls.Client(conn, clientCfg)
+	clientErr = client.Handshake()
+	if clientErr == nil {
+		// with tls 1.3 the server checks the client certificate after the client finished its handshake,
+		// a rejection only reaches the client on its first read
+		_, clientErr = client.Read(make([]byte, 1))
+	}
+
+	return <-result, clientErr
+}
+
+func TestMutualTLSHandshake(t *testing.T) {
+	ca := newTestCA(t, "test ca")
+	serverCert, serverKey := ca.issue(t, "localhost", true)
+	clientCert, clientKey := ca.issue(t, "orders", false)
+
+	server := newFilesReloader(t, serverCert, serverKey, ca.pem)
+	client := newFilesReloader(t, clientCert, clientKey, ca.pem)
+
+	serverErr, clientErr := handshake(t, server.ServerConfig(true), client.ClientConfig("localhost"))
+	if serverErr != nil {
+		t.Errorf("the server rejected a trusted client: %v", serverErr)
+	}
+	if clientErr != nil {
+		t.Errorf("the client rejected a trusted server: %v", clientErr)
+	}
+}
+
+func TestUntrustedClientRejected(t *testing.T) {
+	ca, other := newTestCA(t, "test ca"), newTestCA(t, "other ca")
+	serverCert, serverKey := ca.issue(t, "localhost", true)
+	clientCert, clientKey := other.issue(t, "intruder", false)
+
+	server := newFilesReloader(t, serverCert, serverKey, ca.pem)
+	// the client trusts the server, but its own certificate isn't signed by the ca of the server
+	client := newFilesReloader(t, clientCert, clientKey, ca.pem)
+
+	serverErr, clientErr := handshake(t, server.ServerConfig(true), client.ClientConfig("localhost"))
+	if serverErr == nil {
+		t.Error("the server accepted a client certificate of an untrusted ca")
+	}
+	if clientErr == nil {
+		t.Error("the client wasn't told that its certificate was rejected")
+	}
+}
+
+func TestClientWithoutCertificateRejected(t *testing.T) {
+	ca := newTestCA(t, "test ca")
+	serverCert, serverKey := ca.issue(t, "localhost", true)
+
+	server := newFilesReloader(t, serverCert, serverKey, ca.pem)
+	dir := t.TempDir()
+	caFile := filepath.Join(dir, "ca.crt")
+	if err := os.WriteFile(caFile, ca.pem, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	client := newTestReloader(t, "", "", caFile)
+
+	if serverErr, _ := handshake(t, server.ServerConfig(true), client.ClientConfig("localhost")); serverErr == nil {
+		t.Error("the server accepted a client without a certificate")
+	}
+}
+
+func TestReloaderPicksUpRotatedCertificate(t *testing.T) {
+	ca := newTestCA(t, "test ca")
+	oldCert, oldKey := ca.issue(t, "localhost", true)
+	certFile, keyFile, caFile := writeFiles(t, t.TempDir(), oldCert, oldKey, ca.pem)
+	r := newTestReloader(t, certFile, keyFile, caFile)
+
+	current := func() *x509.Certificate {
+		cert, err := r.certificate()
+		if err != nil {
+			t.Fatal(err)
+		}
+		leaf, err := x509.ParseCertificate(cert.Certificate[0])
+		if err != nil {
+			t.Fatal(err)
+		}
+		return leaf
+	}
+	old := current()
+
+	// the key is written first, a reload in between fails on the mismatched pair and keeps the old one
+	newCert, newKey := ca.issue(t, "localhost", true)
+	if err := os.WriteFile(keyFile, newKey, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(certFile, newCert, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	for current().SerialNumber.Cmp(old.SerialNumber) == 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("the rotated certificate wasn't reloaded")
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+
+	// the rotated certificate is the one served
+	client := newTestReloader(t, "", "", caFile)
+	var served *x509.Certificate
+	cfg := client.ClientConfig("localhost")
+	verify := cfg.VerifyConnection
+	cfg.VerifyConnection = func(cs tls.ConnectionState) error {
+		served = cs.PeerCertificates[0]
+		return verify(cs)
+	}
+	if serverErr, clientErr := handshake(t, r.ServerConfig(false), cfg); serverErr != nil || clientErr != nil {
+		t.Fatalf("handshake failed after the rotation, server: %v, client: %v", serverErr, clientErr)
+	}
+	if served == nil || served.SerialNumber.Cmp(old.SerialNumber) == 0 {
+		t.Error("the server still presents the old certificate")
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"os"
 
+	"github.com/ahmad-khatib0-org/megacommerce-inventory/internal/cli"
 	"github.com/ahmad-khatib0-org/megacommerce-inventory/internal/server"
 	"github.com/ahmad-khatib0-org/megacommerce-shared-go/pkg/logger"
 )
@@ -19,6 +20,15 @@ func main() {
 		panic(err)
 	}
 
+	// any argument runs a command line tool instead of the service
+	if len(os.Args) > 1 {
+		if err := cli.Run(config, os.Args[1:]); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		return
+	}
+
 	logger, err := logger.InitLogger(config.Service.Env)
 	if err != nil {
 		panic(err)
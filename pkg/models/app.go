@@ -2,18 +2,146 @@
 package models
 
 const (
-	EventNameInventoryReserve = "inventory_reserve"
-	EventNameInventoryRelease = "inventory_release"
-	EventNameInventoryGet     = "inventory_get"
-	EventNameInventoryUpdate  = "inventory_update"
+	EventNameInventoryReserve              = "inventory_reserve"
+	EventNameInventoryRelease              = "inventory_release"
+	EventNameInventoryGet                  = "inventory_get"
+	EventNameInventoryUpdate               = "inventory_update"
+	EventNameInventoryImport               = "inventory_import"
+	EventNameInventoryReservationExtend    = "inventory_reservation_extend"
+	EventNameInventoryReservationModify    = "inventory_reservation_modify"
+	EventNameInventoryFulfill              = "inventory_fulfill"
+	EventNameInventoryReturnCreate         = "inventory_return_create"
+	EventNameInventoryReturnReceive        = "inventory_return_receive"
+	EventNameInventoryTransferCreate       = "inventory_transfer_create"
+	EventNameInventoryTransferShip         = "inventory_transfer_ship"
+	EventNameInventoryTransferReceive      = "inventory_transfer_receive"
+	EventNameInventoryTransferCancel       = "inventory_transfer_cancel"
+	EventNameInventoryPurchaseOrderCreate  = "inventory_purchase_order_create"
+	EventNameInventoryPurchaseOrderReceive = "inventory_purchase_order_receive"
+	EventNameInventoryPurchaseOrderCancel  = "inventory_purchase_order_cancel"
+	EventNameInventoryBackorderPolicySet   = "inventory_backorder_policy_set"
+	EventNameInventoryAllocationSet        = "inventory_allocation_set"
+	EventNameInventoryKitSet               = "inventory_kit_set"
+	EventNameInventoryLotReceive           = "inventory_lot_receive"
+	EventNameInventorySerialRegister       = "inventory_serial_register"
+	EventNameInventoryCycleCountCreate     = "inventory_cycle_count_create"
+	EventNameInventoryCycleCountSubmit     = "inventory_cycle_count_submit"
+	EventNameInventoryCycleCountApprove    = "inventory_cycle_count_approve"
+	EventNameInventoryCycleCountReject     = "inventory_cycle_count_reject"
+	EventNameInventoryAdjustmentRequest    = "inventory_adjustment_request"
+	EventNameInventoryAdjustmentApprove    = "inventory_adjustment_approve"
+	EventNameInventoryAdjustmentReject     = "inventory_adjustment_reject"
+	EventNameInventoryAdjustmentExpire     = "inventory_adjustment_expire"
+	EventNameInventoryStockMove            = "inventory_stock_move"
+	EventNameInventoryUnitSet              = "inventory_unit_set"
+	EventNameInventoryReorderPolicySet     = "inventory_reorder_policy_set"
+	EventNameInventoryReplenishmentRun     = "inventory_replenishment_run"
+	EventNameInventoryPurchaseOrderSubmit  = "inventory_purchase_order_submit"
 )
 
 type Config struct {
-	Service Service `mapstructure:"service"`
+	Service       Service       `mapstructure:"service"`
+	Auth          Auth          `mapstructure:"auth"`
+	TLS           TLS           `mapstructure:"tls"`
+	Reservations  Reservations  `mapstructure:"reservations"`
+	Adjustments   Adjustments   `mapstructure:"adjustments"`
+	Valuation     Valuation     `mapstructure:"valuation"`
+	Snapshots     Snapshots     `mapstructure:"snapshots"`
+	Forecasting   Forecasting   `mapstructure:"forecasting"`
+	Replenishment Replenishment `mapstructure:"replenishment"`
 }
 
 type Service struct {
 	Env                  string `mapstructure:"env"`
 	GrpcURL              string `mapstructure:"grpc_url"`
 	CommonServiceGrpcURL string `mapstructure:"common_service_grpc_url"`
+	// CommonCacheDir stores the last config and translations fetched from the common service
+	CommonCacheDir string `mapstructure:"common_cache_dir"`
+}
+
+type Reservations struct {
+	// MaxLifetimeSeconds caps how far extensions can push a reservation's expiry past its creation
+	MaxLifetimeSeconds uint32 `mapstructure:"max_lifetime_seconds"`
+}
+
+type Adjustments struct {
+	// ApprovalQuantityThreshold holds a manual adjustment for approval if it changes the on hand units by more than this
+	ApprovalQuantityThreshold uint32 `mapstructure:"approval_quantity_threshold"`
+	// ApprovalPercentThreshold holds a manual adjustment for approval if it changes the on hand units by more than this percentage
+	ApprovalPercentThreshold uint32 `mapstructure:"approval_percent_threshold"`
+	// ApprovalTTLSeconds is how long a held adjustment waits for its approval before it expires
+	ApprovalTTLSeconds uint32 `mapstructure:"approval_ttl_seconds"`
+}
+
+type Valuation struct {
+	// Currency is the currency of the unit costs, which are given in its minor unit (cents)
+	Currency string `mapstructure:"currency"`
+	// Method is the valuation method of the reports that don't ask for one, FIFO or WEIGHTED_AVERAGE
+	Method string `mapstructure:"method"`
+}
+
+type Snapshots struct {
+	// Hour is the hour of the day (UTC) that the daily snapshot of the inventory items is taken at
+	Hour uint32 `mapstructure:"hour"`
+	// RetentionDays is how long snapshots are kept, 0 keeps them forever
+	RetentionDays uint32 `mapstructure:"retention_days"`
+}
+
+type Forecasting struct {
+	// Method is the forecast method of the requests that don't ask for one, MOVING_AVERAGE or SEASONAL_SMOOTHING
+	Method string `mapstructure:"method"`
+	// HistoryDays is how many days of movements the demand is forecasted from
+	HistoryDays uint32 `mapstructure:"history_days"`
+	// MovingAverageDays is the window of the moving average, the last days of the history
+	MovingAverageDays uint32 `mapstructure:"moving_average_days"`
+	// SeasonDays is the length of the seasonal pattern of the demand, 7 for a weekly one
+	SeasonDays uint32 `mapstructure:"season_days"`
+	// Alpha, Beta and Gamma smooth the level, the trend and the seasonal pattern of the demand, between 0 and 1
+	Alpha float64 `mapstructure:"alpha"`
+	Beta  float64 `mapstructure:"beta"`
+	Gamma float64 `mapstructure:"gamma"`
+	// ServiceLevelZ is the standard score of the service level that the safety stock covers, 1.65 for 95%
+	ServiceLevelZ float64 `mapstructure:"service_level_z"`
+	// LeadTimeDays and ReviewPeriodDays are used for the requests that don't give them
+	LeadTimeDays     uint32 `mapstructure:"lead_time_days"`
+	ReviewPeriodDays uint32 `mapstructure:"review_period_days"`
+}
+
+type Replenishment struct {
+	// Enabled runs the replenishment job every day, draft purchase orders can still be generated on demand if it's not
+	Enabled bool `mapstructure:"enabled"`
+	// Hour is the hour of the day (UTC) that the replenishment job runs at
+	Hour uint32 `mapstructure:"hour"`
+}
+
+type Auth struct {
+	// JWTPublicKeyFiles are the PEM encoded keys that service tokens are verified against
+	JWTPublicKeyFiles []string `mapstructure:"jwt_public_key_files"`
+	JWTIssuer         string   `mapstructure:"jwt_issuer"`
+	JWTAudience       string   `mapstructure:"jwt_audience"`
+	// MTLSIdentities maps the common name of a verified client certificate to its roles, a certificate
+	// has no seller id, so the seller role isn't allowed
+	MTLSIdentities map[string][]string `mapstructure:"mtls_identities"`
+}
+
+type TLS struct {
+	Server       TLSServer `mapstructure:"server"`
+	CommonClient TLSClient `mapstructure:"common_client"`
+}
+
+type TLSServer struct {
+	Enabled  bool   `mapstructure:"enabled"`
+	CertFile string `mapstructure:"cert_file"`
+	KeyFile  string `mapstructure:"key_file"`
+	// ClientCAFile verifies the client certificates, needed for mtls identities
+	ClientCAFile      string `mapstructure:"client_ca_file"`
+	RequireClientCert bool   `mapstructure:"require_client_cert"`
+}
+
+type TLSClient struct {
+	Enabled    bool   `mapstructure:"enabled"`
+	CertFile   string `mapstructure:"cert_file"`
+	KeyFile    string `mapstructure:"key_file"`
+	CAFile     string `mapstructure:"ca_file"`
+	ServerName string `mapstructure:"server_name"`
 }
@@ -0,0 +1,104 @@
+package models
+
+import (
+	"strings"
+
+	pb "github.com/ahmad-khatib0-org/megacommerce-proto/gen/go/inventory/v1"
+)
+
+func GetInventoryAdjustmentStatus(status pb.InventoryAdjustmentStatus) string {
+	switch status {
+	case pb.InventoryAdjustmentStatus_INVENTORY_ADJUSTMENT_STATUS_PENDING:
+		return "PENDING"
+	case pb.InventoryAdjustmentStatus_INVENTORY_ADJUSTMENT_STATUS_APPROVED:
+		return "APPROVED"
+	case pb.InventoryAdjustmentStatus_INVENTORY_ADJUSTMENT_STATUS_REJECTED:
+		return "REJECTED"
+	case pb.InventoryAdjustmentStatus_INVENTORY_ADJUSTMENT_STATUS_EXPIRED:
+		return "EXPIRED"
+	case pb.InventoryAdjustmentStatus_INVENTORY_ADJUSTMENT_STATUS_UNSPECIFIED:
+		fallthrough
+	default:
+		return "UNSPECIFIED"
+	}
+}
+
+func GetInventoryAdjustmentStatusFromString(statusStr string) pb.InventoryAdjustmentStatus {
+	switch strings.ToUpper(statusStr) {
+	case "PENDING":
+		return pb.InventoryAdjustmentStatus_INVENTORY_ADJUSTMENT_STATUS_PENDING
+	case "APPROVED":
+		return pb.InventoryAdjustmentStatus_INVENTORY_ADJUSTMENT_STATUS_APPROVED
+	case "REJECTED":
+		return pb.InventoryAdjustmentStatus_INVENTORY_ADJUSTMENT_STATUS_REJECTED
+	case "EXPIRED":
+		return pb.InventoryAdjustmentStatus_INVENTORY_ADJUSTMENT_STATUS_EXPIRED
+	default:
+		return pb.InventoryAdjustmentStatus_INVENTORY_ADJUSTMENT_STATUS_UNSPECIFIED
+	}
+}
+
+// InventoryAdjustmentNeedsApproval tells whether a manual adjustment that takes the on hand units of an item from
+// before to after changes them by more than the configured thresholds, a threshold of 0 is disabled, and the
+// percentage isn't checked for an item that has nothing on hand
+func InventoryAdjustmentNeedsApproval(before int32, after int32, cfg *Adjustments) bool {
+	change := after - before
+	if change < 0 {
+		change = -change
+	}
+
+	if cfg.ApprovalQuantityThreshold > 0 && int64(change) > int64(cfg.ApprovalQuantityThreshold) {
+		return true
+	}
+	if cfg.ApprovalPercentThreshold > 0 && before > 0 && int64(change)*100 > int64(cfg.ApprovalPercentThreshold)*int64(before) {
+		return true
+	}
+
+	return false
+}
+
+// InventoryAdjustmentStatusAt is the status of an adjustment request at now, a pending request
+// that is past its expiry is expired, even before it's marked as such
+func InventoryAdjustmentStatusAt(adjustment *pb.InventoryAdjustmentRequest, now int64) pb.InventoryAdjustmentStatus {
+	status := GetInventoryAdjustmentStatusFromString(adjustment.Status)
+	if status == pb.InventoryAdjustmentStatus_INVENTORY_ADJUSTMENT_STATUS_PENDING && adjustment.ExpiresAt <= now {
+		return pb.InventoryAdjustmentStatus_INVENTORY_ADJUSTMENT_STATUS_EXPIRED
+	}
+	return status
+}
+
+// InventoryAdjustmentRequestAuditable is the state of an adjustment request that is recorded
+// with every step of its review, so the audit records hold its whole trail
+func InventoryAdjustmentRequestAuditable(adjustment *pb.InventoryAdjustmentRequest) map[string]any {
+	if adjustment == nil {
+		return map[string]any{}
+	}
+
+	return map[string]any{
+		"request_number":        adjustment.RequestNumber,
+		"seller_id":             adjustment.SellerId,
+		"inventory_item_id":     adjustment.InventoryItemId,
+		"operation":             adjustment.Operation,
+		"quantity":              adjustment.Quantity,
+		"unit_cost":             adjustment.UnitCost,
+		"quantity_total_before": adjustment.QuantityTotalBefore,
+		"quantity_total_after":  adjustment.QuantityTotalAfter,
+		"reason":                adjustment.Reason,
+		"status":                adjustment.Status,
+		"requested_by":          adjustment.RequestedBy,
+		"reviewed_by":           adjustment.ReviewedBy,
+		"review_note":           adjustment.ReviewNote,
+		"expires_at":            adjustment.ExpiresAt,
+	}
+}
+
+func InventoryAdjustmentReviewRequestAuditable(req *pb.InventoryAdjustmentReviewRequest) map[string]any {
+	if req == nil {
+		return map[string]any{}
+	}
+
+	return map[string]any{
+		"request_number": req.RequestNumber,
+		"note":           req.Note,
+	}
+}
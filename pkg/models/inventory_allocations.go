@@ -0,0 +1,100 @@
+package models
+
+import (
+	"strings"
+
+	pb "github.com/ahmad-khatib0-org/megacommerce-proto/gen/go/inventory/v1"
+)
+
+func GetInventoryChannelAllocationType(allocationType pb.InventoryChannelAllocationType) string {
+	switch allocationType {
+	case pb.InventoryChannelAllocationType_INVENTORY_CHANNEL_ALLOCATION_TYPE_FIXED:
+		return "FIXED"
+	case pb.InventoryChannelAllocationType_INVENTORY_CHANNEL_ALLOCATION_TYPE_PERCENT:
+		return "PERCENT"
+	case pb.InventoryChannelAllocationType_INVENTORY_CHANNEL_ALLOCATION_TYPE_UNSPECIFIED:
+		fallthrough
+	default:
+		return "UNSPECIFIED"
+	}
+}
+
+func GetInventoryChannelAllocationTypeFromString(allocationTypeStr string) pb.InventoryChannelAllocationType {
+	switch strings.ToUpper(allocationTypeStr) {
+	case "FIXED":
+		return pb.InventoryChannelAllocationType_INVENTORY_CHANNEL_ALLOCATION_TYPE_FIXED
+	case "PERCENT":
+		return pb.InventoryChannelAllocationType_INVENTORY_CHANNEL_ALLOCATION_TYPE_PERCENT
+	default:
+		return pb.InventoryChannelAllocationType_INVENTORY_CHANNEL_ALLOCATION_TYPE_UNSPECIFIED
+	}
+}
+
+// InventoryChannelPools splits the on hand units of an item that aren't safety stock between the channels
+// that have an allocation rule, in the order of the rules, each rule gets its quota (or percentage) of
+// what is left by the previous ones, and the rest is shared by every channel that has no rule
+func InventoryChannelPools(total int32, safetyStock int32, rules []*pb.InventoryChannelAllocation) (pools map[string]int32, shared int32) {
+	shared = max(total-safetyStock, 0)
+	base := shared
+
+	pools = make(map[string]int32, len(rules))
+	for _, rule := range rules {
+		var pool int32
+		switch GetInventoryChannelAllocationTypeFromString(rule.AllocationType) {
+		case pb.InventoryChannelAllocationType_INVENTORY_CHANNEL_ALLOCATION_TYPE_FIXED:
+			pool = rule.Value
+		case pb.InventoryChannelAllocationType_INVENTORY_CHANNEL_ALLOCATION_TYPE_PERCENT:
+			pool = int32(int64(base) * int64(rule.Value) / 100)
+		}
+		pool = min(max(pool, 0), shared)
+		pools[rule.Channel] = pool
+		shared -= pool
+	}
+
+	return pools, shared
+}
+
+// InventoryChannelSellable returns how many more units of an item a reservation from channel can take, it's
+// what is left of the channel's pool (or of the shared pool if the channel has no rule) after the units its
+// active reservations hold, and never more than the available units that aren't safety stock. reserved
+// is the units held per channel, as returned by InventoryReservationItemsReservedByChannel
+func InventoryChannelSellable(item *pb.InventoryItem, safetyStock int32, rules []*pb.InventoryChannelAllocation, reserved map[string]int32, channel string) int32 {
+	pools, shared := InventoryChannelPools(item.QuantityTotal, safetyStock, rules)
+
+	pool, dedicated := pools[channel]
+	held := reserved[channel]
+	if !dedicated {
+		pool, held = shared, 0
+		for ch, quantity := range reserved {
+			if _, ok := pools[ch]; !ok {
+				held += quantity
+			}
+		}
+	}
+
+	return max(min(item.QuantityAvailable-safetyStock, pool-held), 0)
+}
+
+func InventoryAllocationSetRequestAuditable(req *pb.InventoryAllocationSetRequest) map[string]any {
+	if req == nil {
+		return map[string]any{}
+	}
+
+	channels := make([]map[string]any, 0, len(req.Channels))
+	for _, ch := range req.Channels {
+		channels = append(channels, map[string]any{
+			"channel":         ch.Channel,
+			"allocation_type": GetInventoryChannelAllocationType(ch.AllocationType),
+			"value":           ch.Value,
+		})
+	}
+
+	return map[string]any{
+		"seller_id":    req.SellerId,
+		"product_id":   req.ProductId,
+		"variant_id":   req.VariantId,
+		"location_id":  req.LocationId,
+		"safety_stock": req.SafetyStock,
+		"channels":     channels,
+	}
+}
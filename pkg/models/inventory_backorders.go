@@ -0,0 +1,71 @@
+package models
+
+import (
+	"strings"
+
+	pb "github.com/ahmad-khatib0-org/megacommerce-proto/gen/go/inventory/v1"
+)
+
+func GetInventoryBackorderPolicyType(policy pb.InventoryBackorderPolicyType) string {
+	switch policy {
+	case pb.InventoryBackorderPolicyType_INVENTORY_BACKORDER_POLICY_TYPE_DENY:
+		return "DENY"
+	case pb.InventoryBackorderPolicyType_INVENTORY_BACKORDER_POLICY_TYPE_BACKORDER:
+		return "BACKORDER"
+	case pb.InventoryBackorderPolicyType_INVENTORY_BACKORDER_POLICY_TYPE_PREORDER:
+		return "PREORDER"
+	case pb.InventoryBackorderPolicyType_INVENTORY_BACKORDER_POLICY_TYPE_UNSPECIFIED:
+		fallthrough
+	default:
+		return "UNSPECIFIED"
+	}
+}
+
+func GetInventoryBackorderPolicyTypeFromString(policyStr string) pb.InventoryBackorderPolicyType {
+	switch strings.ToUpper(policyStr) {
+	case "DENY":
+		return pb.InventoryBackorderPolicyType_INVENTORY_BACKORDER_POLICY_TYPE_DENY
+	case "BACKORDER":
+		return pb.InventoryBackorderPolicyType_INVENTORY_BACKORDER_POLICY_TYPE_BACKORDER
+	case "PREORDER":
+		return pb.InventoryBackorderPolicyType_INVENTORY_BACKORDER_POLICY_TYPE_PREORDER
+	default:
+		return pb.InventoryBackorderPolicyType_INVENTORY_BACKORDER_POLICY_TYPE_UNSPECIFIED
+	}
+}
+
+// InventoryBackorderable returns how many more units of an item can be backordered under its policy at now,
+// unlimited is set if there is no limit. A missing policy, or a pre-order past its release date, denies backorders
+func InventoryBackorderable(policy *pb.InventoryBackorderPolicy, now int64) (quantity int32, unlimited bool) {
+	if policy == nil {
+		return 0, false
+	}
+
+	switch GetInventoryBackorderPolicyTypeFromString(policy.Policy) {
+	case pb.InventoryBackorderPolicyType_INVENTORY_BACKORDER_POLICY_TYPE_BACKORDER:
+		return max(policy.BackorderLimit-policy.QuantityBackordered, 0), false
+	case pb.InventoryBackorderPolicyType_INVENTORY_BACKORDER_POLICY_TYPE_PREORDER:
+		if policy.PreorderReleaseAt != nil && *policy.PreorderReleaseAt <= now {
+			return 0, false
+		}
+		return 0, true
+	default:
+		return 0, false
+	}
+}
+
+func InventoryBackorderPolicySetRequestAuditable(req *pb.InventoryBackorderPolicySetRequest) map[string]any {
+	if req == nil {
+		return map[string]any{}
+	}
+
+	return map[string]any{
+		"seller_id":           req.SellerId,
+		"product_id":          req.ProductId,
+		"variant_id":          req.VariantId,
+		"location_id":         req.LocationId,
+		"policy":              GetInventoryBackorderPolicyType(req.Policy),
+		"backorder_limit":     req.BackorderLimit,
+		"preorder_release_at": req.PreorderReleaseAt,
+	}
+}
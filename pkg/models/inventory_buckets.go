@@ -0,0 +1,121 @@
+package models
+
+import (
+	"strings"
+
+	pb "github.com/ahmad-khatib0-org/megacommerce-proto/gen/go/inventory/v1"
+)
+
+func GetInventoryStockBucket(bucket pb.InventoryStockBucket) string {
+	switch bucket {
+	case pb.InventoryStockBucket_INVENTORY_STOCK_BUCKET_SELLABLE:
+		return "SELLABLE"
+	case pb.InventoryStockBucket_INVENTORY_STOCK_BUCKET_QUARANTINED:
+		return "QUARANTINED"
+	case pb.InventoryStockBucket_INVENTORY_STOCK_BUCKET_DAMAGED:
+		return "DAMAGED"
+	case pb.InventoryStockBucket_INVENTORY_STOCK_BUCKET_ON_HOLD:
+		return "ON_HOLD"
+	case pb.InventoryStockBucket_INVENTORY_STOCK_BUCKET_UNSPECIFIED:
+		fallthrough
+	default:
+		return "UNSPECIFIED"
+	}
+}
+
+func GetInventoryStockBucketFromString(bucketStr string) pb.InventoryStockBucket {
+	switch strings.ToUpper(bucketStr) {
+	case "SELLABLE":
+		return pb.InventoryStockBucket_INVENTORY_STOCK_BUCKET_SELLABLE
+	case "QUARANTINED":
+		return pb.InventoryStockBucket_INVENTORY_STOCK_BUCKET_QUARANTINED
+	case "DAMAGED":
+		return pb.InventoryStockBucket_INVENTORY_STOCK_BUCKET_DAMAGED
+	case "ON_HOLD":
+		return pb.InventoryStockBucket_INVENTORY_STOCK_BUCKET_ON_HOLD
+	default:
+		return pb.InventoryStockBucket_INVENTORY_STOCK_BUCKET_UNSPECIFIED
+	}
+}
+
+// InventoryStockBucketMovementType is the type of the movement that moves units into a bucket
+func InventoryStockBucketMovementType(to pb.InventoryStockBucket) pb.InventoryMovementType {
+	switch to {
+	case pb.InventoryStockBucket_INVENTORY_STOCK_BUCKET_QUARANTINED:
+		return pb.InventoryMovementType_INVENTORY_MOVEMENT_TYPE_QUARANTINE
+	case pb.InventoryStockBucket_INVENTORY_STOCK_BUCKET_DAMAGED:
+		return pb.InventoryMovementType_INVENTORY_MOVEMENT_TYPE_DAMAGE
+	case pb.InventoryStockBucket_INVENTORY_STOCK_BUCKET_ON_HOLD:
+		return pb.InventoryMovementType_INVENTORY_MOVEMENT_TYPE_HOLD
+	default:
+		return pb.InventoryMovementType_INVENTORY_MOVEMENT_TYPE_RESTORE
+	}
+}
+
+// inventoryStockBucket points to the quantity of a bucket of an item, the sellable bucket
+// is quantity_total, so nil is returned for it and for unknown buckets
+func inventoryStockBucket(item *pb.InventoryItem, bucket pb.InventoryStockBucket) *int32 {
+	switch bucket {
+	case pb.InventoryStockBucket_INVENTORY_STOCK_BUCKET_QUARANTINED:
+		return &item.QuantityQuarantined
+	case pb.InventoryStockBucket_INVENTORY_STOCK_BUCKET_DAMAGED:
+		return &item.QuantityDamaged
+	case pb.InventoryStockBucket_INVENTORY_STOCK_BUCKET_ON_HOLD:
+		return &item.QuantityOnHold
+	default:
+		return nil
+	}
+}
+
+// InventoryStockBucketMove moves quantity units of an item from one of its buckets to another. quantity_total only
+// holds the sellable bucket, so quantity_available is computed from it alone, the units that leave it must be
+// available and not reserved, and the units that enter it become available. errID is set to a translation id if
+// the move can't be applied, and the item is left as it was then
+func InventoryStockBucketMove(item *pb.InventoryItem, from pb.InventoryStockBucket, to pb.InventoryStockBucket, quantity int32) (errID string) {
+	if from == pb.InventoryStockBucket_INVENTORY_STOCK_BUCKET_UNSPECIFIED || to == pb.InventoryStockBucket_INVENTORY_STOCK_BUCKET_UNSPECIFIED {
+		return "inventory.stock_move.invalid_bucket"
+	}
+	if from == to {
+		return "inventory.stock_move.same_bucket"
+	}
+
+	source, destination := inventoryStockBucket(item, from), inventoryStockBucket(item, to)
+	if source == nil {
+		if quantity > item.QuantityAvailable {
+			return "inventory.stock_move.insufficient_available"
+		}
+		item.QuantityTotal -= quantity
+		item.QuantityAvailable -= quantity
+	} else {
+		if quantity > *source {
+			return "inventory.stock_move.insufficient_quantity"
+		}
+		*source -= quantity
+	}
+
+	if destination == nil {
+		item.QuantityTotal += quantity
+		item.QuantityAvailable += quantity
+	} else {
+		*destination += quantity
+	}
+
+	return ""
+}
+
+func InventoryStockMoveRequestAuditable(req *pb.InventoryStockMoveRequest) map[string]any {
+	if req == nil {
+		return map[string]any{}
+	}
+
+	return map[string]any{
+		"seller_id":   req.SellerId,
+		"product_id":  req.ProductId,
+		"variant_id":  req.VariantId,
+		"location_id": req.LocationId,
+		"from":        GetInventoryStockBucket(req.From),
+		"to":          GetInventoryStockBucket(req.To),
+		"quantity":    req.Quantity,
+		"reason":      req.Reason,
+	}
+}
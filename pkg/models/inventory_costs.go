@@ -0,0 +1,87 @@
+package models
+
+import (
+	"strings"
+
+	pb "github.com/ahmad-khatib0-org/megacommerce-proto/gen/go/inventory/v1"
+)
+
+func GetInventoryValuationMethod(method pb.InventoryValuationMethod) string {
+	switch method {
+	case pb.InventoryValuationMethod_INVENTORY_VALUATION_METHOD_FIFO:
+		return "FIFO"
+	case pb.InventoryValuationMethod_INVENTORY_VALUATION_METHOD_WEIGHTED_AVERAGE:
+		return "WEIGHTED_AVERAGE"
+	case pb.InventoryValuationMethod_INVENTORY_VALUATION_METHOD_UNSPECIFIED:
+		fallthrough
+	default:
+		return "UNSPECIFIED"
+	}
+}
+
+func GetInventoryValuationMethodFromString(methodStr string) pb.InventoryValuationMethod {
+	switch strings.ToUpper(methodStr) {
+	case "FIFO":
+		return pb.InventoryValuationMethod_INVENTORY_VALUATION_METHOD_FIFO
+	case "WEIGHTED_AVERAGE":
+		return pb.InventoryValuationMethod_INVENTORY_VALUATION_METHOD_WEIGHTED_AVERAGE
+	default:
+		return pb.InventoryValuationMethod_INVENTORY_VALUATION_METHOD_UNSPECIFIED
+	}
+}
+
+// InventoryCostAverage is the weighted average unit cost of quantity units that are worth value, 0 if there are none
+func InventoryCostAverage(quantity int64, value int64) int64 {
+	if quantity <= 0 {
+		return 0
+	}
+	return value / quantity
+}
+
+// InventoryCostIssueAverage is the cost of quantity units that leave an item under the weighted average method, from
+// the units and the value that the item has. The last units that leave take the rest of the value, so it doesn't
+// keep what the integer division of the average unit cost rounded off
+func InventoryCostIssueAverage(quantity int64, balanceQuantity int64, balanceValue int64) int64 {
+	average := InventoryCostAverage(balanceQuantity, balanceValue)
+	if balanceQuantity > 0 && quantity >= balanceQuantity {
+		return balanceValue + (quantity-balanceQuantity)*average
+	}
+	return quantity * average
+}
+
+// InventoryValuationFilter filters the items of a valuation report, a zero value field doesn't filter. The
+// stock is valued as of At, and the cost of goods sold is the cost of the units sold from From to At
+type InventoryValuationFilter struct {
+	SellerID   string
+	LocationID string
+	Sku        string
+	From       int64
+	At         int64
+}
+
+// InventoryValuationRow is what the cost layers and the issues of an item add up to as of a valuation date, the
+// value of the stock is what was received minus the cost of what was issued, under either valuation method
+type InventoryValuationRow struct {
+	InventoryItemID   string
+	Sku               string
+	ProductID         string
+	VariantID         string
+	LocationID        string
+	QuantityReceived  int64
+	ValueReceived     int64
+	QuantityIssued    int64
+	CostFifoIssued    int64
+	CostAverageIssued int64
+	SoldCostFifo      int64
+	SoldCostAverage   int64
+}
+
+// InventoryValuationOf is the on hand units of a valuation row, what they're worth, and
+// the cost of the units sold in the window of the report under a valuation method
+func InventoryValuationOf(row *InventoryValuationRow, method pb.InventoryValuationMethod) (quantity int64, value int64, costOfGoodsSold int64) {
+	quantity = row.QuantityReceived - row.QuantityIssued
+	if method == pb.InventoryValuationMethod_INVENTORY_VALUATION_METHOD_WEIGHTED_AVERAGE {
+		return quantity, row.ValueReceived - row.CostAverageIssued, row.SoldCostAverage
+	}
+	return quantity, row.ValueReceived - row.CostFifoIssued, row.SoldCostFifo
+}
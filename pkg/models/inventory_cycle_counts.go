@@ -0,0 +1,98 @@
+package models
+
+import (
+	"strings"
+
+	pb "github.com/ahmad-khatib0-org/megacommerce-proto/gen/go/inventory/v1"
+)
+
+func GetInventoryCycleCountStatus(status pb.InventoryCycleCountStatus) string {
+	switch status {
+	case pb.InventoryCycleCountStatus_INVENTORY_CYCLE_COUNT_STATUS_OPEN:
+		return "OPEN"
+	case pb.InventoryCycleCountStatus_INVENTORY_CYCLE_COUNT_STATUS_COUNTED:
+		return "COUNTED"
+	case pb.InventoryCycleCountStatus_INVENTORY_CYCLE_COUNT_STATUS_APPROVED:
+		return "APPROVED"
+	case pb.InventoryCycleCountStatus_INVENTORY_CYCLE_COUNT_STATUS_REJECTED:
+		return "REJECTED"
+	case pb.InventoryCycleCountStatus_INVENTORY_CYCLE_COUNT_STATUS_UNSPECIFIED:
+		fallthrough
+	default:
+		return "UNSPECIFIED"
+	}
+}
+
+func GetInventoryCycleCountStatusFromString(statusStr string) pb.InventoryCycleCountStatus {
+	switch strings.ToUpper(statusStr) {
+	case "OPEN":
+		return pb.InventoryCycleCountStatus_INVENTORY_CYCLE_COUNT_STATUS_OPEN
+	case "COUNTED":
+		return pb.InventoryCycleCountStatus_INVENTORY_CYCLE_COUNT_STATUS_COUNTED
+	case "APPROVED":
+		return pb.InventoryCycleCountStatus_INVENTORY_CYCLE_COUNT_STATUS_APPROVED
+	case "REJECTED":
+		return pb.InventoryCycleCountStatus_INVENTORY_CYCLE_COUNT_STATUS_REJECTED
+	default:
+		return pb.InventoryCycleCountStatus_INVENTORY_CYCLE_COUNT_STATUS_UNSPECIFIED
+	}
+}
+
+// InventoryCycleCountVariance is the difference between the counted and the expected units of a
+// cycle count line, positive if more units were found than the system had, 0 if it wasn't counted
+func InventoryCycleCountVariance(line *pb.InventoryCycleCountItem) int32 {
+	if line.QuantityCounted == nil {
+		return 0
+	}
+	return *line.QuantityCounted - line.QuantityExpected
+}
+
+// InventoryCycleCountShortOfReserved is how many of the units that were reserved when a line was counted
+// weren't found, those reservations can't be fulfilled from the counted stock
+func InventoryCycleCountShortOfReserved(line *pb.InventoryCycleCountItem) int32 {
+	if line.QuantityCounted == nil {
+		return 0
+	}
+	return max(line.QuantityReserved-*line.QuantityCounted, 0)
+}
+
+func InventoryCycleCountCreateRequestAuditable(req *pb.InventoryCycleCountCreateRequest) map[string]any {
+	if req == nil {
+		return map[string]any{}
+	}
+
+	return map[string]any{
+		"seller_id":   req.SellerId,
+		"location_id": req.LocationId,
+		"skus":        req.Skus,
+		"freeze":      req.Freeze,
+		"note":        req.Note,
+	}
+}
+
+func InventoryCycleCountSubmitRequestAuditable(req *pb.InventoryCycleCountSubmitRequest) map[string]any {
+	if req == nil {
+		return map[string]any{}
+	}
+
+	items := make([]map[string]any, len(req.Items))
+	for i, item := range req.Items {
+		items[i] = map[string]any{"sku": item.Sku, "quantity_counted": item.QuantityCounted}
+	}
+
+	return map[string]any{
+		"count_number": req.CountNumber,
+		"items":        items,
+	}
+}
+
+func InventoryCycleCountReviewRequestAuditable(req *pb.InventoryCycleCountReviewRequest) map[string]any {
+	if req == nil {
+		return map[string]any{}
+	}
+
+	return map[string]any{
+		"count_number": req.CountNumber,
+		"note":         req.Note,
+	}
+}
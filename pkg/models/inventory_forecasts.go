@@ -0,0 +1,210 @@
+package models
+
+import (
+	"math"
+	"strings"
+
+	pb "github.com/ahmad-khatib0-org/megacommerce-proto/gen/go/inventory/v1"
+)
+
+// InventoryDayMillis is the length of a day in milliseconds, demand is bucketed by UTC day
+const InventoryDayMillis = int64(24 * 60 * 60 * 1000)
+
+// InventoryDemandDay is the demand of an inventory item on a day, Day is the start of the day (UTC)
+type InventoryDemandDay struct {
+	InventoryItemID string
+	Day             int64
+	Quantity        int64
+}
+
+// InventoryMovementDay is the units of an inventory item that moved on a day by a movement type, Fulfilment
+// tells whether they settled a reservation, i.e. whether they're the units of orders that shipped
+type InventoryMovementDay struct {
+	InventoryItemID string
+	MovementType    string
+	Fulfilment      bool
+	Day             int64
+	Quantity        int64
+}
+
+// InventoryReorderSuggestion is what an item needs to be reordered at, and how many units to order now. Units
+// are ordered once the stock position (available and incoming units) drops to the reorder point, and the
+// order brings the position back up to what covers the lead time and the review period
+type InventoryReorderSuggestion struct {
+	SafetyStock   int64
+	ReorderPoint  int64
+	OrderUpTo     int64
+	OrderQuantity int64
+	Reorder       bool
+}
+
+func GetInventoryForecastMethod(method pb.InventoryForecastMethod) string {
+	switch method {
+	case pb.InventoryForecastMethod_INVENTORY_FORECAST_METHOD_MOVING_AVERAGE:
+		return "MOVING_AVERAGE"
+	case pb.InventoryForecastMethod_INVENTORY_FORECAST_METHOD_SEASONAL_SMOOTHING:
+		return "SEASONAL_SMOOTHING"
+	case pb.InventoryForecastMethod_INVENTORY_FORECAST_METHOD_UNSPECIFIED:
+		fallthrough
+	default:
+		return "UNSPECIFIED"
+	}
+}
+
+func GetInventoryForecastMethodFromString(methodStr string) pb.InventoryForecastMethod {
+	switch strings.ToUpper(methodStr) {
+	case "MOVING_AVERAGE":
+		return pb.InventoryForecastMethod_INVENTORY_FORECAST_METHOD_MOVING_AVERAGE
+	case "SEASONAL_SMOOTHING":
+		return pb.InventoryForecastMethod_INVENTORY_FORECAST_METHOD_SEASONAL_SMOOTHING
+	default:
+		return pb.InventoryForecastMethod_INVENTORY_FORECAST_METHOD_UNSPECIFIED
+	}
+}
+
+// InventoryDemandFromMovements sums the demand of inventory items per day from their movements, in the order of the
+// movements. The demand is the units that were fulfilled: reserved units are orders that can still be released, and
+// the other units that leave the stock (write-downs, transfers to another location) weren't sold
+func InventoryDemandFromMovements(movements []*InventoryMovementDay) []*InventoryDemandDay {
+	out := GetInventoryMovementType(pb.InventoryMovementType_INVENTORY_MOVEMENT_TYPE_OUT)
+
+	type key struct {
+		itemID string
+		day    int64
+	}
+	days := make(map[key]*InventoryDemandDay)
+	result := make([]*InventoryDemandDay, 0)
+	for _, m := range movements {
+		if m.MovementType != out || !m.Fulfilment || m.Quantity <= 0 {
+			continue
+		}
+
+		k := key{itemID: m.InventoryItemID, day: m.Day}
+		d, ok := days[k]
+		if !ok {
+			d = &InventoryDemandDay{InventoryItemID: m.InventoryItemID, Day: m.Day}
+			days[k] = d
+			result = append(result, d)
+		}
+		d.Quantity += m.Quantity
+	}
+	return result
+}
+
+// InventoryDemandSeries spreads the demand days of an item over days consecutive days starting at from, the days
+// without demand are 0
+func InventoryDemandSeries(demand []*InventoryDemandDay, from int64, days int) []float64 {
+	series := make([]float64, days)
+	for _, d := range demand {
+		i := int((d.Day - from) / InventoryDayMillis)
+		if i >= 0 && i < days && d.Quantity > 0 {
+			series[i] = float64(d.Quantity)
+		}
+	}
+	return series
+}
+
+// InventoryForecastMovingAverage is the daily demand averaged over the last window days of the series
+func InventoryForecastMovingAverage(series []float64, window int) float64 {
+	window = min(window, len(series))
+	if window <= 0 {
+		return 0
+	}
+
+	sum := 0.0
+	for _, v := range series[len(series)-window:] {
+		sum += v
+	}
+	return sum / float64(window)
+}
+
+// InventoryForecastSeasonal is the daily demand of the next horizon days forecasted with additive Holt-Winters
+// smoothing, alpha smooths the level, beta the trend, and gamma the seasonal pattern that repeats every season
+// days. A series shorter than two seasons has no pattern to learn, it's forecasted with simple exponential smoothing
+func InventoryForecastSeasonal(series []float64, season int, alpha float64, beta float64, gamma float64, horizon int) float64 {
+	n := len(series)
+	if n == 0 {
+		return 0
+	}
+	horizon = max(horizon, 1)
+
+	if season < 2 || n < 2*season {
+		level := series[0]
+		for _, v := range series[1:] {
+			level = alpha*v + (1-alpha)*level
+		}
+		return max(level, 0)
+	}
+
+	first, second := 0.0, 0.0
+	for i := range season {
+		first += series[i]
+		second += series[season+i]
+	}
+	level := first / float64(season)
+	trend := (second - first) / float64(season*season)
+	seasonal := make([]float64, season)
+	for i := range season {
+		seasonal[i] = series[i] - level
+	}
+
+	for t := season; t < n; t++ {
+		i := t % season
+		previous := level
+		level = alpha*(series[t]-seasonal[i]) + (1-alpha)*(level+trend)
+		trend = beta*(level-previous) + (1-beta)*trend
+		seasonal[i] = gamma*(series[t]-level) + (1-gamma)*seasonal[i]
+	}
+
+	sum := 0.0
+	for k := 1; k <= horizon; k++ {
+		sum += max(level+float64(k)*trend+seasonal[(n+k-1)%season], 0)
+	}
+	return sum / float64(horizon)
+}
+
+// InventoryDemandStdDev is the standard deviation of the daily demand of the series
+func InventoryDemandStdDev(series []float64) float64 {
+	if len(series) == 0 {
+		return 0
+	}
+
+	mean := 0.0
+	for _, v := range series {
+		mean += v
+	}
+	mean /= float64(len(series))
+
+	variance := 0.0
+	for _, v := range series {
+		variance += (v - mean) * (v - mean)
+	}
+	return math.Sqrt(variance / float64(len(series)))
+}
+
+// InventoryDaysOfCover is how many days the available units last at a daily demand, nil if there's no demand
+func InventoryDaysOfCover(available int64, velocity float64) *float64 {
+	if velocity <= 0 {
+		return nil
+	}
+	days := float64(max(available, 0)) / velocity
+	return &days
+}
+
+// InventoryReorderSuggest suggests the reorder point of an item that sells velocity units a day, and how many units to
+// order given its stock position. The safety stock covers the variation of the demand over the lead time at the
+// service level that z is the standard score of
+func InventoryReorderSuggest(velocity float64, stdDev float64, z float64, leadTimeDays uint32, reviewPeriodDays uint32, position int64) *InventoryReorderSuggestion {
+	safety := int64(math.Ceil(z * stdDev * math.Sqrt(float64(leadTimeDays))))
+	s := &InventoryReorderSuggestion{
+		SafetyStock:  safety,
+		ReorderPoint: int64(math.Ceil(velocity*float64(leadTimeDays))) + safety,
+		OrderUpTo:    int64(math.Ceil(velocity*float64(leadTimeDays+reviewPeriodDays))) + safety,
+	}
+
+	if velocity > 0 && position <= s.ReorderPoint {
+		s.Reorder = true
+		s.OrderQuantity = max(s.OrderUpTo-position, 0)
+	}
+	return s
+}
@@ -0,0 +1,50 @@
+package models
+
+import (
+	"testing"
+
+	pb "github.com/ahmad-khatib0-org/megacommerce-proto/gen/go/inventory/v1"
+)
+
+func TestInventoryDemandFromMovements(t *testing.T) {
+	movementType := func(mt pb.InventoryMovementType) string { return GetInventoryMovementType(mt) }
+	day1, day2 := int64(0), InventoryDayMillis
+
+	// two days of the movements of a selling item and of one that only moves between locations
+	movements := []*InventoryMovementDay{
+		// orders reserved then shipped, the reservation itself writes no movement
+		{InventoryItemID: "a", Day: day1, MovementType: movementType(pb.InventoryMovementType_INVENTORY_MOVEMENT_TYPE_OUT), Fulfilment: true, Quantity: 5},
+		// a cancelled order and a backorder that got stock
+		{InventoryItemID: "a", Day: day1, MovementType: movementType(pb.InventoryMovementType_INVENTORY_MOVEMENT_TYPE_RELEASE), Fulfilment: true, Quantity: 3},
+		{InventoryItemID: "a", Day: day1, MovementType: movementType(pb.InventoryMovementType_INVENTORY_MOVEMENT_TYPE_RESERVATION), Fulfilment: true, Quantity: 2},
+		// a manual write-down and a delivery
+		{InventoryItemID: "a", Day: day1, MovementType: movementType(pb.InventoryMovementType_INVENTORY_MOVEMENT_TYPE_OUT), Quantity: 4},
+		{InventoryItemID: "a", Day: day1, MovementType: movementType(pb.InventoryMovementType_INVENTORY_MOVEMENT_TYPE_IN), Quantity: 50},
+		{InventoryItemID: "a", Day: day2, MovementType: movementType(pb.InventoryMovementType_INVENTORY_MOVEMENT_TYPE_OUT), Fulfilment: true, Quantity: 7},
+		// shipped to another location
+		{InventoryItemID: "b", Day: day1, MovementType: movementType(pb.InventoryMovementType_INVENTORY_MOVEMENT_TYPE_OUT), Quantity: 10},
+		{InventoryItemID: "b", Day: day2, MovementType: movementType(pb.InventoryMovementType_INVENTORY_MOVEMENT_TYPE_IN), Quantity: 10},
+	}
+
+	got := InventoryDemandFromMovements(movements)
+	want := []InventoryDemandDay{
+		{InventoryItemID: "a", Day: day1, Quantity: 5},
+		{InventoryItemID: "a", Day: day2, Quantity: 7},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("got %d demand days, want %d", len(got), len(want))
+	}
+	for i := range want {
+		if *got[i] != want[i] {
+			t.Errorf("demand day %d: got %+v, want %+v", i, *got[i], want[i])
+		}
+	}
+
+	series := InventoryDemandSeries(got, day1, 2)
+	if series[0] != 5 || series[1] != 7 {
+		t.Errorf("got series %v, want [5 7]", series)
+	}
+	if v := InventoryForecastMovingAverage(series, 2); v != 6 {
+		t.Errorf("got velocity %v, want 6", v)
+	}
+}
@@ -0,0 +1,38 @@
+package models
+
+import (
+	pb "github.com/ahmad-khatib0-org/megacommerce-proto/gen/go/inventory/v1"
+)
+
+// InventoryImportMaxRows caps the rows of a single import, bigger files must be split
+const InventoryImportMaxRows = 100_000
+
+// InventoryImportRowIsValid returns the translation id of the first problem of the row, or an empty string
+func InventoryImportRowIsValid(row *pb.InventoryImportRow) string {
+	if row.GetSku() == "" {
+		return "inventory.import.sku_required"
+	}
+
+	switch row.GetOperation() {
+	case pb.InventoryUpdateOperation_INVENTORY_UPDATE_OPERATION_SET:
+	case pb.InventoryUpdateOperation_INVENTORY_UPDATE_OPERATION_ADD, pb.InventoryUpdateOperation_INVENTORY_UPDATE_OPERATION_SUBTRACT:
+		if row.GetQuantity() == 0 {
+			return "inventory.import.quantity_required"
+		}
+	default:
+		return "inventory.update.invalid_operation"
+	}
+
+	return ""
+}
+
+func InventoryImportAuditable(dryRun bool, reason *string, data *pb.InventoryImportResponseData) map[string]any {
+	res := map[string]any{"dry_run": dryRun, "reason": reason}
+	if data != nil {
+		res["total"] = data.Total
+		res["applied"] = data.Applied
+		res["pending"] = data.Pending
+		res["failed"] = data.Failed
+	}
+	return res
+}
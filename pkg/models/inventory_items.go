@@ -0,0 +1,7 @@
+package models
+
+import "errors"
+
+// ErrInventoryItemAmbiguous is returned by the inventory item lookups that must match a single item,
+// when more than one seller or location stocks the requested product
+var ErrInventoryItemAmbiguous = errors.New("more than one inventory item matches the lookup")
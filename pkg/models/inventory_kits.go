@@ -0,0 +1,29 @@
+package models
+
+import (
+	pb "github.com/ahmad-khatib0-org/megacommerce-proto/gen/go/inventory/v1"
+)
+
+func InventoryKitSetRequestAuditable(req *pb.InventoryKitSetRequest) map[string]any {
+	if req == nil {
+		return map[string]any{}
+	}
+
+	components := make([]map[string]any, 0, len(req.Components))
+	for _, c := range req.Components {
+		components = append(components, map[string]any{
+			"product_id": c.ProductId,
+			"variant_id": c.VariantId,
+			"quantity":   c.Quantity,
+		})
+	}
+
+	return map[string]any{
+		"seller_id":   req.SellerId,
+		"product_id":  req.ProductId,
+		"variant_id":  req.VariantId,
+		"location_id": req.LocationId,
+		"sku":         req.Sku,
+		"components":  components,
+	}
+}
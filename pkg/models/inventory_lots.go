@@ -0,0 +1,39 @@
+package models
+
+import (
+	pb "github.com/ahmad-khatib0-org/megacommerce-proto/gen/go/inventory/v1"
+)
+
+// InventoryLotExpired tells whether a lot is past its expiry date at now, a lot without one never expires
+func InventoryLotExpired(lot *pb.InventoryLot, now int64) bool {
+	return lot.ExpiresAt != nil && *lot.ExpiresAt <= now
+}
+
+// InventoryLotsSellable sums the units of the lots that can still be sold at now,
+// the units of expired lots and the units that are already reserved are not
+func InventoryLotsSellable(lots []*pb.InventoryLot, now int64) int32 {
+	sellable := int32(0)
+	for _, lot := range lots {
+		if !InventoryLotExpired(lot, now) {
+			sellable += max(lot.Quantity-lot.QuantityReserved, 0)
+		}
+	}
+	return sellable
+}
+
+func InventoryLotReceiveRequestAuditable(req *pb.InventoryLotReceiveRequest) map[string]any {
+	if req == nil {
+		return map[string]any{}
+	}
+
+	return map[string]any{
+		"seller_id":   req.SellerId,
+		"product_id":  req.ProductId,
+		"variant_id":  req.VariantId,
+		"location_id": req.LocationId,
+		"lot_number":  req.LotNumber,
+		"expires_at":  req.ExpiresAt,
+		"quantity":    req.Quantity,
+		"reason":      req.Reason,
+	}
+}
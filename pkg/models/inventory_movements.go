@@ -1,6 +1,10 @@
 package models
 
-import pb "github.com/ahmad-khatib0-org/megacommerce-proto/gen/go/inventory/v1"
+import (
+	"strings"
+
+	pb "github.com/ahmad-khatib0-org/megacommerce-proto/gen/go/inventory/v1"
+)
 
 func GetInventoryMovementType(movementType pb.InventoryMovementType) string {
 	switch movementType {
@@ -14,9 +18,42 @@ func GetInventoryMovementType(movementType pb.InventoryMovementType) string {
 		return "RESERVATION"
 	case pb.InventoryMovementType_INVENTORY_MOVEMENT_TYPE_RELEASE:
 		return "RELEASE"
+	case pb.InventoryMovementType_INVENTORY_MOVEMENT_TYPE_QUARANTINE:
+		return "QUARANTINE"
+	case pb.InventoryMovementType_INVENTORY_MOVEMENT_TYPE_DAMAGE:
+		return "DAMAGE"
+	case pb.InventoryMovementType_INVENTORY_MOVEMENT_TYPE_HOLD:
+		return "HOLD"
+	case pb.InventoryMovementType_INVENTORY_MOVEMENT_TYPE_RESTORE:
+		return "RESTORE"
 	case pb.InventoryMovementType_INVENTORY_MOVEMENT_TYPE_UNSPECIFIED:
 		fallthrough
 	default:
 		return "UNSPECIFIED"
 	}
 }
+
+func GetInventoryMovementTypeFromString(movementTypeStr string) pb.InventoryMovementType {
+	switch strings.ToUpper(movementTypeStr) {
+	case "IN":
+		return pb.InventoryMovementType_INVENTORY_MOVEMENT_TYPE_IN
+	case "OUT":
+		return pb.InventoryMovementType_INVENTORY_MOVEMENT_TYPE_OUT
+	case "ADJUSTMENT":
+		return pb.InventoryMovementType_INVENTORY_MOVEMENT_TYPE_ADJUSTMENT
+	case "RESERVATION":
+		return pb.InventoryMovementType_INVENTORY_MOVEMENT_TYPE_RESERVATION
+	case "RELEASE":
+		return pb.InventoryMovementType_INVENTORY_MOVEMENT_TYPE_RELEASE
+	case "QUARANTINE":
+		return pb.InventoryMovementType_INVENTORY_MOVEMENT_TYPE_QUARANTINE
+	case "DAMAGE":
+		return pb.InventoryMovementType_INVENTORY_MOVEMENT_TYPE_DAMAGE
+	case "HOLD":
+		return pb.InventoryMovementType_INVENTORY_MOVEMENT_TYPE_HOLD
+	case "RESTORE":
+		return pb.InventoryMovementType_INVENTORY_MOVEMENT_TYPE_RESTORE
+	default:
+		return pb.InventoryMovementType_INVENTORY_MOVEMENT_TYPE_UNSPECIFIED
+	}
+}
@@ -0,0 +1,103 @@
+package models
+
+import (
+	"strings"
+
+	pb "github.com/ahmad-khatib0-org/megacommerce-proto/gen/go/inventory/v1"
+)
+
+// InventoryIncoming is the quantity of a sku that is expected to arrive at a point in time
+type InventoryIncoming struct {
+	ExpectedAt int64
+	Quantity   int32
+}
+
+func GetInventoryPurchaseOrderStatus(status pb.InventoryPurchaseOrderStatus) string {
+	switch status {
+	case pb.InventoryPurchaseOrderStatus_INVENTORY_PURCHASE_ORDER_STATUS_DRAFT:
+		return "DRAFT"
+	case pb.InventoryPurchaseOrderStatus_INVENTORY_PURCHASE_ORDER_STATUS_OPEN:
+		return "OPEN"
+	case pb.InventoryPurchaseOrderStatus_INVENTORY_PURCHASE_ORDER_STATUS_PARTIALLY_RECEIVED:
+		return "PARTIALLY_RECEIVED"
+	case pb.InventoryPurchaseOrderStatus_INVENTORY_PURCHASE_ORDER_STATUS_RECEIVED:
+		return "RECEIVED"
+	case pb.InventoryPurchaseOrderStatus_INVENTORY_PURCHASE_ORDER_STATUS_CANCELLED:
+		return "CANCELLED"
+	case pb.InventoryPurchaseOrderStatus_INVENTORY_PURCHASE_ORDER_STATUS_UNSPECIFIED:
+		fallthrough
+	default:
+		return "UNSPECIFIED"
+	}
+}
+
+func GetInventoryPurchaseOrderStatusFromString(statusStr string) pb.InventoryPurchaseOrderStatus {
+	switch strings.ToUpper(statusStr) {
+	case "DRAFT":
+		return pb.InventoryPurchaseOrderStatus_INVENTORY_PURCHASE_ORDER_STATUS_DRAFT
+	case "OPEN":
+		return pb.InventoryPurchaseOrderStatus_INVENTORY_PURCHASE_ORDER_STATUS_OPEN
+	case "PARTIALLY_RECEIVED":
+		return pb.InventoryPurchaseOrderStatus_INVENTORY_PURCHASE_ORDER_STATUS_PARTIALLY_RECEIVED
+	case "RECEIVED":
+		return pb.InventoryPurchaseOrderStatus_INVENTORY_PURCHASE_ORDER_STATUS_RECEIVED
+	case "CANCELLED":
+		return pb.InventoryPurchaseOrderStatus_INVENTORY_PURCHASE_ORDER_STATUS_CANCELLED
+	default:
+		return pb.InventoryPurchaseOrderStatus_INVENTORY_PURCHASE_ORDER_STATUS_UNSPECIFIED
+	}
+}
+
+func InventoryPurchaseOrderCreateRequestAuditable(req *pb.InventoryPurchaseOrderCreateRequest) map[string]any {
+	if req == nil {
+		return map[string]any{}
+	}
+
+	items := make([]map[string]any, len(req.Items))
+	for i, item := range req.Items {
+		items[i] = map[string]any{"sku": item.Sku, "quantity": item.Quantity, "expected_at": item.ExpectedAt}
+	}
+
+	return map[string]any{
+		"seller_id":          req.SellerId,
+		"location_id":        req.LocationId,
+		"supplier_reference": req.SupplierReference,
+		"expected_at":        req.ExpectedAt,
+		"note":               req.Note,
+		"items":              items,
+	}
+}
+
+func InventoryPurchaseOrderReceiveRequestAuditable(req *pb.InventoryPurchaseOrderReceiveRequest) map[string]any {
+	if req == nil {
+		return map[string]any{}
+	}
+
+	items := make([]map[string]any, len(req.Items))
+	for i, item := range req.Items {
+		items[i] = map[string]any{"sku": item.Sku, "quantity": item.Quantity, "unit_cost": item.UnitCost, "lot_number": item.LotNumber, "expires_at": item.ExpiresAt}
+	}
+
+	return map[string]any{
+		"po_number": req.PoNumber,
+		"items":     items,
+	}
+}
+
+func InventoryPurchaseOrderSubmitRequestAuditable(req *pb.InventoryPurchaseOrderSubmitRequest) map[string]any {
+	if req == nil {
+		return map[string]any{}
+	}
+
+	items := make([]map[string]any, len(req.Items))
+	for i, item := range req.Items {
+		items[i] = map[string]any{"sku": item.Sku, "quantity": item.Quantity}
+	}
+
+	return map[string]any{
+		"po_number":   req.PoNumber,
+		"expected_at": req.ExpectedAt,
+		"note":        req.Note,
+		"items":       items,
+	}
+}
@@ -0,0 +1,59 @@
+package models
+
+import (
+	pb "github.com/ahmad-khatib0-org/megacommerce-proto/gen/go/inventory/v1"
+)
+
+// InventoryReplenishmentQuantity is how many units of an item to order when its stock position is below its reorder
+// point, 0 if it isn't. The order brings the position up to orderUpTo (the reorder point if it's lower), it's at
+// least the minimum order quantity of the supplier, and it's rounded up to whole case packs
+func InventoryReplenishmentQuantity(position int64, reorderPoint int64, orderUpTo int64, minOrderQuantity int64, casePack int64) int64 {
+	if position >= reorderPoint {
+		return 0
+	}
+
+	quantity := max(max(orderUpTo, reorderPoint)-position, minOrderQuantity)
+	if casePack > 1 && quantity%casePack != 0 {
+		quantity += casePack - quantity%casePack
+	}
+	return quantity
+}
+
+// InventoryReplenishmentGroup is the key that the suggestions of a replenishment run are drafted by,
+// one purchase order per seller, supplier and location
+type InventoryReplenishmentGroup struct {
+	SellerID          string
+	SupplierReference string
+	LocationID        string
+}
+
+func InventoryReorderPolicySetRequestAuditable(req *pb.InventoryReorderPolicySetRequest) map[string]any {
+	if req == nil {
+		return map[string]any{}
+	}
+
+	return map[string]any{
+		"seller_id":          req.SellerId,
+		"product_id":         req.ProductId,
+		"variant_id":         req.VariantId,
+		"location_id":        req.LocationId,
+		"supplier_reference": req.SupplierReference,
+		"lead_time_days":     req.LeadTimeDays,
+		"reorder_point":      req.ReorderPoint,
+		"order_up_to":        req.OrderUpTo,
+		"min_order_quantity": req.MinOrderQuantity,
+		"case_pack":          req.CasePack,
+	}
+}
+
+func InventoryReplenishmentRunRequestAuditable(req *pb.InventoryReplenishmentRunRequest) map[string]any {
+	if req == nil {
+		return map[string]any{}
+	}
+
+	return map[string]any{
+		"seller_id":   req.SellerId,
+		"location_id": req.LocationId,
+		"dry_run":     req.DryRun,
+	}
+}
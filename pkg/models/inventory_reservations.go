@@ -55,6 +55,8 @@ func GetInventoryReservationItemStatus(status pb.InventoryReservationItemStatus)
 		return "NOT_RESERVED"
 	case pb.InventoryReservationItemStatus_INVENTORY_RESERVATION_ITEM_STATUS_OUT_OF_STOCK:
 		return "OUT_OF_STOCK"
+	case pb.InventoryReservationItemStatus_INVENTORY_RESERVATION_ITEM_STATUS_BACKORDERED:
+		return "BACKORDERED"
 	case pb.InventoryReservationItemStatus_INVENTORY_RESERVATION_ITEM_STATUS_UNSPECIFIED:
 		fallthrough
 	default:
@@ -70,6 +72,8 @@ func GetInventoryReservationItemStatusFromString(statusStr string) pb.InventoryR
 		return pb.InventoryReservationItemStatus_INVENTORY_RESERVATION_ITEM_STATUS_NOT_RESERVED
 	case "OUT_OF_STOCK":
 		return pb.InventoryReservationItemStatus_INVENTORY_RESERVATION_ITEM_STATUS_OUT_OF_STOCK
+	case "BACKORDERED":
+		return pb.InventoryReservationItemStatus_INVENTORY_RESERVATION_ITEM_STATUS_BACKORDERED
 	default:
 		return pb.InventoryReservationItemStatus_INVENTORY_RESERVATION_ITEM_STATUS_UNSPECIFIED
 	}
@@ -90,6 +94,19 @@ func GetInventoryUpdateOperation(operation pb.InventoryUpdateOperation) string {
 	}
 }
 
+func GetInventoryUpdateOperationFromString(operation string) pb.InventoryUpdateOperation {
+	switch strings.ToUpper(operation) {
+	case "SET":
+		return pb.InventoryUpdateOperation_INVENTORY_UPDATE_OPERATION_SET
+	case "ADD":
+		return pb.InventoryUpdateOperation_INVENTORY_UPDATE_OPERATION_ADD
+	case "SUBTRACT":
+		return pb.InventoryUpdateOperation_INVENTORY_UPDATE_OPERATION_SUBTRACT
+	default:
+		return pb.InventoryUpdateOperation_INVENTORY_UPDATE_OPERATION_UNSPECIFIED
+	}
+}
+
 func InventoryReserveRequestAuditable(req *pb.InventoryReserveRequest) map[string]any {
 	if req == nil {
 		return map[string]any{}
@@ -98,10 +115,13 @@ func InventoryReserveRequestAuditable(req *pb.InventoryReserveRequest) map[strin
 	items := make([]map[string]any, len(req.Items))
 	for i, item := range req.Items {
 		items[i] = map[string]any{
-			"product_id": item.ProductId,
-			"variant_id": item.VariantId,
-			"sku":        item.Sku,
-			"quantity":   item.Quantity,
+			"seller_id":   item.SellerId,
+			"product_id":  item.ProductId,
+			"variant_id":  item.VariantId,
+			"location_id": item.LocationId,
+			"sku":         item.Sku,
+			"quantity":    item.Quantity,
+			"unit":        item.Unit,
 		}
 	}
 
@@ -113,6 +133,47 @@ func InventoryReserveRequestAuditable(req *pb.InventoryReserveRequest) map[strin
 	}
 }
 
+func InventoryReservationModifyRequestAuditable(req *pb.InventoryReservationModifyRequest) map[string]any {
+	if req == nil {
+		return map[string]any{}
+	}
+
+	items := make([]map[string]any, len(req.Items))
+	for i, item := range req.Items {
+		items[i] = map[string]any{
+			"seller_id":   item.SellerId,
+			"product_id":  item.ProductId,
+			"variant_id":  item.VariantId,
+			"location_id": item.LocationId,
+			"sku":         item.Sku,
+			"quantity":    item.Quantity,
+		}
+	}
+
+	return map[string]any{
+		"reservation_token": req.ReservationToken,
+		"items":             items,
+	}
+}
+
+func InventoryReservationSettleAuditable(token string, lines []*pb.InventoryReservationLineQuantity) map[string]any {
+	items := make([]map[string]any, len(lines))
+	for i, item := range lines {
+		items[i] = map[string]any{
+			"seller_id":   item.SellerId,
+			"product_id":  item.ProductId,
+			"variant_id":  item.VariantId,
+			"location_id": item.LocationId,
+			"quantity":    item.Quantity,
+		}
+	}
+
+	return map[string]any{
+		"reservation_token": token,
+		"items":             items,
+	}
+}
+
 func InventoryUpdateRequestAuditable(req *pb.InventoryUpdateRequest) map[string]any {
 	if req == nil {
 		return map[string]any{}
@@ -121,11 +182,15 @@ func InventoryUpdateRequestAuditable(req *pb.InventoryUpdateRequest) map[string]
 	items := make([]map[string]any, len(req.Items))
 	for i, item := range req.Items {
 		items[i] = map[string]any{
-			"product_id": item.ProductId,
-			"variant_id": item.VariantId,
-			"sku":        item.Sku,
-			"operation":  GetInventoryUpdateOperation(item.Operation),
-			"quantity":   item.Quantity,
+			"seller_id":   item.SellerId,
+			"product_id":  item.ProductId,
+			"variant_id":  item.VariantId,
+			"location_id": item.LocationId,
+			"sku":         item.Sku,
+			"operation":   GetInventoryUpdateOperation(item.Operation),
+			"quantity":    item.Quantity,
+			"unit":        item.Unit,
+			"unit_cost":   item.UnitCost,
 		}
 	}
 
@@ -134,3 +199,20 @@ func InventoryUpdateRequestAuditable(req *pb.InventoryUpdateRequest) map[string]
 		"reason": req.Reason,
 	}
 }
+
+// InventoryReservationFilter filters the reservations of a listing, a zero value field doesn't filter,
+// the list is ordered by (created_at, id) descending and starts after (AfterCreatedAt, AfterID) if set
+type InventoryReservationFilter struct {
+	OrderID     string
+	Status      string
+	Sku         string
+	SellerID    string
+	CreatedFrom int64
+	CreatedTo   int64
+	ExpiresFrom int64
+	ExpiresTo   int64
+
+	AfterCreatedAt int64
+	AfterID        string
+	Limit          int
+}
@@ -0,0 +1,103 @@
+package models
+
+import (
+	"strings"
+
+	pb "github.com/ahmad-khatib0-org/megacommerce-proto/gen/go/inventory/v1"
+)
+
+func GetInventoryReturnStatus(status pb.InventoryReturnStatus) string {
+	switch status {
+	case pb.InventoryReturnStatus_INVENTORY_RETURN_STATUS_OPEN:
+		return "OPEN"
+	case pb.InventoryReturnStatus_INVENTORY_RETURN_STATUS_PARTIALLY_RECEIVED:
+		return "PARTIALLY_RECEIVED"
+	case pb.InventoryReturnStatus_INVENTORY_RETURN_STATUS_RECEIVED:
+		return "RECEIVED"
+	case pb.InventoryReturnStatus_INVENTORY_RETURN_STATUS_UNSPECIFIED:
+		fallthrough
+	default:
+		return "UNSPECIFIED"
+	}
+}
+
+func GetInventoryReturnStatusFromString(statusStr string) pb.InventoryReturnStatus {
+	switch strings.ToUpper(statusStr) {
+	case "OPEN":
+		return pb.InventoryReturnStatus_INVENTORY_RETURN_STATUS_OPEN
+	case "PARTIALLY_RECEIVED":
+		return pb.InventoryReturnStatus_INVENTORY_RETURN_STATUS_PARTIALLY_RECEIVED
+	case "RECEIVED":
+		return pb.InventoryReturnStatus_INVENTORY_RETURN_STATUS_RECEIVED
+	default:
+		return pb.InventoryReturnStatus_INVENTORY_RETURN_STATUS_UNSPECIFIED
+	}
+}
+
+func GetInventoryReturnDisposition(disposition pb.InventoryReturnDisposition) string {
+	switch disposition {
+	case pb.InventoryReturnDisposition_INVENTORY_RETURN_DISPOSITION_RESTOCK:
+		return "RESTOCK"
+	case pb.InventoryReturnDisposition_INVENTORY_RETURN_DISPOSITION_QUARANTINE:
+		return "QUARANTINE"
+	case pb.InventoryReturnDisposition_INVENTORY_RETURN_DISPOSITION_WRITE_OFF:
+		return "WRITE_OFF"
+	case pb.InventoryReturnDisposition_INVENTORY_RETURN_DISPOSITION_UNSPECIFIED:
+		fallthrough
+	default:
+		return "UNSPECIFIED"
+	}
+}
+
+// InventoryReturnItemReceived is the number of units of a return item that were received so far
+func InventoryReturnItemReceived(item *pb.InventoryReturnItem) int32 {
+	return item.QuantityRestocked + item.QuantityQuarantined + item.QuantityWrittenOff
+}
+
+func InventoryReturnCreateRequestAuditable(req *pb.InventoryReturnCreateRequest) map[string]any {
+	if req == nil {
+		return map[string]any{}
+	}
+
+	items := make([]map[string]any, len(req.Items))
+	for i, item := range req.Items {
+		items[i] = map[string]any{
+			"seller_id":   item.SellerId,
+			"product_id":  item.ProductId,
+			"variant_id":  item.VariantId,
+			"location_id": item.LocationId,
+			"quantity":    item.Quantity,
+		}
+	}
+
+	return map[string]any{
+		"reservation_token": req.ReservationToken,
+		"reason":            req.Reason,
+		"items":             items,
+	}
+}
+
+func InventoryReturnReceiveRequestAuditable(req *pb.InventoryReturnReceiveRequest) map[string]any {
+	if req == nil {
+		return map[string]any{}
+	}
+
+	items := make([]map[string]any, len(req.Items))
+	for i, item := range req.Items {
+		items[i] = map[string]any{
+			"seller_id":   item.SellerId,
+			"product_id":  item.ProductId,
+			"variant_id":  item.VariantId,
+			"location_id": item.LocationId,
+			"quantity":    item.Quantity,
+			"disposition": GetInventoryReturnDisposition(item.Disposition),
+			"lot_number":  item.LotNumber,
+		}
+	}
+
+	return map[string]any{
+		"rma_number": req.RmaNumber,
+		"note":       req.Note,
+		"items":      items,
+	}
+}
@@ -0,0 +1,78 @@
+package models
+
+import (
+	"strings"
+
+	pb "github.com/ahmad-khatib0-org/megacommerce-proto/gen/go/inventory/v1"
+)
+
+func GetInventorySerialStatus(status pb.InventorySerialStatus) string {
+	switch status {
+	case pb.InventorySerialStatus_INVENTORY_SERIAL_STATUS_AVAILABLE:
+		return "AVAILABLE"
+	case pb.InventorySerialStatus_INVENTORY_SERIAL_STATUS_RESERVED:
+		return "RESERVED"
+	case pb.InventorySerialStatus_INVENTORY_SERIAL_STATUS_SOLD:
+		return "SOLD"
+	case pb.InventorySerialStatus_INVENTORY_SERIAL_STATUS_RETURNED:
+		return "RETURNED"
+	case pb.InventorySerialStatus_INVENTORY_SERIAL_STATUS_DEFECTIVE:
+		return "DEFECTIVE"
+	case pb.InventorySerialStatus_INVENTORY_SERIAL_STATUS_IN_TRANSIT:
+		return "IN_TRANSIT"
+	case pb.InventorySerialStatus_INVENTORY_SERIAL_STATUS_UNSPECIFIED:
+		fallthrough
+	default:
+		return "UNSPECIFIED"
+	}
+}
+
+func GetInventorySerialStatusFromString(statusStr string) pb.InventorySerialStatus {
+	switch strings.ToUpper(statusStr) {
+	case "AVAILABLE":
+		return pb.InventorySerialStatus_INVENTORY_SERIAL_STATUS_AVAILABLE
+	case "RESERVED":
+		return pb.InventorySerialStatus_INVENTORY_SERIAL_STATUS_RESERVED
+	case "SOLD":
+		return pb.InventorySerialStatus_INVENTORY_SERIAL_STATUS_SOLD
+	case "RETURNED":
+		return pb.InventorySerialStatus_INVENTORY_SERIAL_STATUS_RETURNED
+	case "DEFECTIVE":
+		return pb.InventorySerialStatus_INVENTORY_SERIAL_STATUS_DEFECTIVE
+	case "IN_TRANSIT":
+		return pb.InventorySerialStatus_INVENTORY_SERIAL_STATUS_IN_TRANSIT
+	default:
+		return pb.InventorySerialStatus_INVENTORY_SERIAL_STATUS_UNSPECIFIED
+	}
+}
+
+// InventorySerialInTransit tells whether a serial was shipped by a transfer and isn't received yet
+func InventorySerialInTransit(serial *pb.InventorySerial) bool {
+	return GetInventorySerialStatusFromString(serial.Status) == pb.InventorySerialStatus_INVENTORY_SERIAL_STATUS_IN_TRANSIT
+}
+
+// InventorySerialAssignable tells whether a serial is on the shelf and can be assigned to a reservation
+// line, a returned unit that was put back in stock can be sold again
+func InventorySerialAssignable(serial *pb.InventorySerial) bool {
+	switch GetInventorySerialStatusFromString(serial.Status) {
+	case pb.InventorySerialStatus_INVENTORY_SERIAL_STATUS_AVAILABLE, pb.InventorySerialStatus_INVENTORY_SERIAL_STATUS_RETURNED:
+		return true
+	default:
+		return false
+	}
+}
+
+func InventorySerialRegisterRequestAuditable(req *pb.InventorySerialRegisterRequest) map[string]any {
+	if req == nil {
+		return map[string]any{}
+	}
+
+	return map[string]any{
+		"seller_id":      req.SellerId,
+		"product_id":     req.ProductId,
+		"variant_id":     req.VariantId,
+		"location_id":    req.LocationId,
+		"serial_numbers": req.SerialNumbers,
+		"reason":         req.Reason,
+	}
+}
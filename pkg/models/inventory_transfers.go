@@ -0,0 +1,78 @@
+package models
+
+import (
+	"strings"
+
+	pb "github.com/ahmad-khatib0-org/megacommerce-proto/gen/go/inventory/v1"
+)
+
+func GetInventoryTransferStatus(status pb.InventoryTransferStatus) string {
+	switch status {
+	case pb.InventoryTransferStatus_INVENTORY_TRANSFER_STATUS_DRAFT:
+		return "DRAFT"
+	case pb.InventoryTransferStatus_INVENTORY_TRANSFER_STATUS_SHIPPED:
+		return "SHIPPED"
+	case pb.InventoryTransferStatus_INVENTORY_TRANSFER_STATUS_PARTIALLY_RECEIVED:
+		return "PARTIALLY_RECEIVED"
+	case pb.InventoryTransferStatus_INVENTORY_TRANSFER_STATUS_RECEIVED:
+		return "RECEIVED"
+	case pb.InventoryTransferStatus_INVENTORY_TRANSFER_STATUS_CANCELLED:
+		return "CANCELLED"
+	case pb.InventoryTransferStatus_INVENTORY_TRANSFER_STATUS_UNSPECIFIED:
+		fallthrough
+	default:
+		return "UNSPECIFIED"
+	}
+}
+
+func GetInventoryTransferStatusFromString(statusStr string) pb.InventoryTransferStatus {
+	switch strings.ToUpper(statusStr) {
+	case "DRAFT":
+		return pb.InventoryTransferStatus_INVENTORY_TRANSFER_STATUS_DRAFT
+	case "SHIPPED":
+		return pb.InventoryTransferStatus_INVENTORY_TRANSFER_STATUS_SHIPPED
+	case "PARTIALLY_RECEIVED":
+		return pb.InventoryTransferStatus_INVENTORY_TRANSFER_STATUS_PARTIALLY_RECEIVED
+	case "RECEIVED":
+		return pb.InventoryTransferStatus_INVENTORY_TRANSFER_STATUS_RECEIVED
+	case "CANCELLED":
+		return pb.InventoryTransferStatus_INVENTORY_TRANSFER_STATUS_CANCELLED
+	default:
+		return pb.InventoryTransferStatus_INVENTORY_TRANSFER_STATUS_UNSPECIFIED
+	}
+}
+
+func InventoryTransferCreateRequestAuditable(req *pb.InventoryTransferCreateRequest) map[string]any {
+	if req == nil {
+		return map[string]any{}
+	}
+
+	items := make([]map[string]any, len(req.Items))
+	for i, item := range req.Items {
+		items[i] = map[string]any{"sku": item.Sku, "quantity": item.Quantity, "serial_numbers": item.SerialNumbers, "lot_number": item.LotNumber}
+	}
+
+	return map[string]any{
+		"seller_id":        req.SellerId,
+		"from_location_id": req.FromLocationId,
+		"to_location_id":   req.ToLocationId,
+		"note":             req.Note,
+		"items":            items,
+	}
+}
+
+func InventoryTransferReceiveRequestAuditable(req *pb.InventoryTransferReceiveRequest) map[string]any {
+	if req == nil {
+		return map[string]any{}
+	}
+
+	items := make([]map[string]any, len(req.Items))
+	for i, item := range req.Items {
+		items[i] = map[string]any{"sku": item.Sku, "quantity": item.Quantity, "serial_numbers": item.SerialNumbers}
+	}
+
+	return map[string]any{
+		"transfer_number": req.TransferNumber,
+		"items":           items,
+	}
+}
@@ -0,0 +1,73 @@
+package models
+
+import (
+	"math"
+	"strings"
+
+	pb "github.com/ahmad-khatib0-org/megacommerce-proto/gen/go/inventory/v1"
+)
+
+// InventoryUnitFactor is the number of base units in a unit of measure of an item, an empty unit is the base unit,
+// and ok is false if the item doesn't define the unit. Units are matched case insensitively
+func InventoryUnitFactor(units []*pb.InventoryUnit, unit string) (factor int32, ok bool) {
+	if unit == "" {
+		return 1, true
+	}
+
+	for _, u := range units {
+		if strings.EqualFold(u.Unit, unit) {
+			return u.Factor, true
+		}
+	}
+	return 0, false
+}
+
+// InventoryUnitBase is the base unit of an item, the unit with a factor of 1, or an empty string if it has none
+func InventoryUnitBase(units []*pb.InventoryUnit) string {
+	for _, u := range units {
+		if u.Factor == 1 {
+			return u.Unit
+		}
+	}
+	return ""
+}
+
+// InventoryUnitToBase converts a quantity of a unit to base units, ok is false if they don't fit in the stock columns
+func InventoryUnitToBase(quantity uint32, factor int32) (base int32, ok bool) {
+	total := int64(quantity) * int64(factor)
+	if total > math.MaxInt32 {
+		return 0, false
+	}
+	return int32(total), true
+}
+
+// InventoryUnitFromBase converts a quantity of base units to a unit, ok is false if it isn't a whole multiple of the unit
+func InventoryUnitFromBase(base int32, factor int32) (quantity uint32, ok bool) {
+	if factor <= 0 || base < 0 || base%factor != 0 {
+		return 0, false
+	}
+	return uint32(base / factor), true
+}
+
+func InventoryUnitSetRequestAuditable(req *pb.InventoryUnitSetRequest) map[string]any {
+	if req == nil {
+		return map[string]any{}
+	}
+
+	units := make([]map[string]any, 0, len(req.Units))
+	for _, u := range req.Units {
+		units = append(units, map[string]any{
+			"unit":   u.Unit,
+			"factor": u.Factor,
+		})
+	}
+
+	return map[string]any{
+		"seller_id":   req.SellerId,
+		"product_id":  req.ProductId,
+		"variant_id":  req.VariantId,
+		"location_id": req.LocationId,
+		"base_unit":   req.BaseUnit,
+		"units":       units,
+	}
+}